@@ -0,0 +1,17 @@
+//go:build badger
+
+package cache
+
+import (
+	"github.com/os-golib/go-cache/badger"
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+func newBadger[T any](cfg config.Config, o options[T]) (interfaces.Cache[T], error) {
+	var opts []badger.Option[T]
+	if o.serializer != nil {
+		opts = append(opts, badger.WithSerializer(o.serializer))
+	}
+	return badger.New[T](cfg, opts...)
+}
@@ -0,0 +1,17 @@
+//go:build !badger
+
+package cache
+
+import (
+	"fmt"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+// newBadger reports that this binary was built without the badger backend.
+func newBadger[T any](config.Config, options[T]) (interfaces.Cache[T], error) {
+	return nil, base.WrapError(base.OpInit,
+		fmt.Errorf("%w: badger backend requires building with -tags badger", base.ErrInvalidConfig), "")
+}
@@ -0,0 +1,380 @@
+//go:build badger
+
+package badger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v4"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Types ------------------ */
+
+// gcDiscardRatio is the share of stale data a value log file needs before
+// the periodic GC rewrites it.
+const gcDiscardRatio = 0.5
+
+type badgerCache[T any] struct {
+	base       *base.Base
+	db         *badgerdb.DB
+	serializer base.Serializer[T]
+
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+/* ------------------ Constructor ------------------ */
+
+// New opens, or creates, the database at cfg.BadgerPath. Every
+// CleanupInterval the value log is garbage collected, reclaiming the space
+// of expired and deleted entries.
+func New[T any](cfg config.Config, opts ...Option[T]) (*badgerCache[T], error) {
+	db, err := badgerdb.Open(badgerdb.DefaultOptions(cfg.BadgerPath).WithLogger(nil))
+	if err != nil {
+		return nil, base.WrapError(base.OpInit, err, "")
+	}
+
+	b := &badgerCache[T]{
+		base:       base.NewBase(cfg),
+		db:         db,
+		serializer: &base.JsonSerializer[T]{},
+		stop:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if cfg.CleanupInterval > 0 {
+		b.wg.Add(1)
+		go b.gcLoop(cfg.CleanupInterval)
+	}
+	return b, nil
+}
+
+func (b *badgerCache[T]) gcLoop(interval time.Duration) {
+	defer b.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			// Each successful run rewrites one file; stop once there is
+			// nothing left to reclaim.
+			for b.db.RunValueLogGC(gcDiscardRatio) == nil {
+			}
+		}
+	}
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (b *badgerCache[T]) Get(ctx context.Context, key string) (T, error) {
+	val, _, err := b.GetWithTTL(ctx, key)
+	return val, err
+}
+
+// GetWithTTL returns the value and its remaining TTL (0 if it never
+// expires). Badger stores expiry with second precision.
+func (b *badgerCache[T]) GetWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	var zero T
+
+	if err := b.base.ValidateKey(key); err != nil {
+		return zero, 0, err
+	}
+	if err := b.base.CheckContext(ctx); err != nil {
+		return zero, 0, err
+	}
+
+	var (
+		data      []byte
+		expiresAt uint64
+	)
+	err := b.db.View(func(txn *badgerdb.Txn) error {
+		item, err := txn.Get([]byte(b.base.FullKey(key)))
+		if err != nil {
+			return err
+		}
+		expiresAt = item.ExpiresAt()
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badgerdb.ErrKeyNotFound) {
+		b.base.FireMiss(key)
+		return zero, 0, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return zero, 0, base.WrapError(base.OpGet, err, key)
+	}
+
+	val, err := b.serializer.Decode(data)
+	if errors.Is(err, base.ErrStaleVersion) {
+		b.base.FireMiss(key)
+		return zero, 0, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return zero, 0, base.SerializationError(base.OpGet, base.ErrDeserialize, err, key)
+	}
+
+	b.base.FireHit(key)
+
+	var remaining time.Duration
+	if expiresAt > 0 {
+		remaining = time.Until(time.Unix(int64(expiresAt), 0))
+	}
+	return val, remaining, nil
+}
+
+func (b *badgerCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := b.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := b.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	data, err := b.serializer.Encode(value)
+	if err != nil {
+		return base.SerializationError(base.OpSet, base.ErrSerialize, err, key)
+	}
+
+	e := badgerdb.NewEntry([]byte(b.base.FullKey(key)), data)
+	if ttl = b.base.WriteTTL(ctx, ttl); ttl > 0 {
+		e = e.WithTTL(ttl)
+	}
+	if err := b.db.Update(func(txn *badgerdb.Txn) error {
+		return txn.SetEntry(e)
+	}); err != nil {
+		return base.WrapError(base.OpSet, err, key)
+	}
+	return nil
+}
+
+func (b *badgerCache[T]) Delete(ctx context.Context, keys ...string) error {
+	_, err := b.DeleteMany(ctx, keys)
+	return err
+}
+
+// DeleteMany deletes keys in one transaction and returns how many existed.
+func (b *badgerCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
+	if err := b.base.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var total int64
+	err := b.db.Update(func(txn *badgerdb.Txn) error {
+		total = 0
+		for _, k := range keys {
+			fk := []byte(b.base.FullKey(k))
+			_, err := txn.Get(fk)
+			if errors.Is(err, badgerdb.ErrKeyNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := txn.Delete(fk); err != nil {
+				return err
+			}
+			total++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, base.WrapError(base.OpDelete, err, "")
+	}
+	return total, nil
+}
+
+func (b *badgerCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	if err := b.base.ValidateKey(key); err != nil {
+		return false, err
+	}
+
+	err := b.db.View(func(txn *badgerdb.Txn) error {
+		_, err := txn.Get([]byte(b.base.FullKey(key)))
+		return err
+	})
+	if errors.Is(err, badgerdb.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, base.WrapError(base.OpExists, err, key)
+	}
+	return true, nil
+}
+
+// Persist removes key's expiry by rewriting it without a TTL. It returns
+// ErrCacheMiss if key does not exist.
+func (b *badgerCache[T]) Persist(ctx context.Context, key string) error {
+	if err := b.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := b.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	fk := []byte(b.base.FullKey(key))
+	err := b.db.Update(func(txn *badgerdb.Txn) error {
+		item, err := txn.Get(fk)
+		if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		return txn.SetEntry(badgerdb.NewEntry(fk, data))
+	})
+	if errors.Is(err, badgerdb.ErrKeyNotFound) {
+		return base.WrapError(base.OpPersist, base.ErrCacheMiss, key)
+	}
+	return base.WrapError(base.OpPersist, err, key)
+}
+
+/* ------------------ Prefix scans ------------------ */
+
+// scanKeys returns the live keys starting with prefix, without reading
+// their values.
+func (b *badgerCache[T]) scanKeys(ctx context.Context, prefix []byte) ([][]byte, error) {
+	var keys [][]byte
+	err := b.db.View(func(txn *badgerdb.Txn) error {
+		opts := badgerdb.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// deletePrefix deletes every key starting with prefix through a write
+// batch, which splits the work across transactions as needed.
+func (b *badgerCache[T]) deletePrefix(ctx context.Context, prefix []byte) (int64, error) {
+	keys, err := b.scanKeys(ctx, prefix)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, k := range keys {
+		if err := wb.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return 0, err
+	}
+	return int64(len(keys)), nil
+}
+
+func (b *badgerCache[T]) Clear(ctx context.Context) error {
+	_, err := b.deletePrefix(ctx, []byte(b.base.FullPrefix("")))
+	return base.WrapError(base.OpClear, err, "")
+}
+
+func (b *badgerCache[T]) Len(ctx context.Context) (int, error) {
+	if err := b.base.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+	keys, err := b.scanKeys(ctx, []byte(b.base.FullPrefix("")))
+	if err != nil {
+		return 0, base.WrapError(base.OpLen, err, "")
+	}
+	return len(keys), nil
+}
+
+func (b *badgerCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	n, err := b.deletePrefix(ctx, []byte(b.base.FullPrefix(prefix)))
+	return n, base.WrapError(base.OpDeleteByPrefix, err, prefix)
+}
+
+func (b *badgerCache[T]) CountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	keys, err := b.scanKeys(ctx, []byte(b.base.FullPrefix(prefix)))
+	if err != nil {
+		return 0, base.WrapError(base.OpCountByPrefix, err, prefix)
+	}
+	return int64(len(keys)), nil
+}
+
+// Range walks the live entries in key order within one read transaction,
+// so it sees a consistent snapshot. Undecodable values are skipped.
+func (b *badgerCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	prefix := []byte(b.base.FullPrefix(""))
+
+	err := b.db.View(func(txn *badgerdb.Txn) error {
+		opts := badgerdb.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item := it.Item()
+			data, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			val, err := b.serializer.Decode(data)
+			if err != nil {
+				continue
+			}
+			if !fn(string(bytes.TrimPrefix(item.Key(), prefix)), val) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return base.WrapError(base.OpGet, err, "")
+}
+
+/* ------------------ Lifecycle ------------------ */
+
+func (b *badgerCache[T]) Ping(ctx context.Context) error {
+	if err := b.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	if b.db.IsClosed() {
+		return base.WrapError(base.OpPing, base.ErrClosed, "")
+	}
+	return nil
+}
+
+func (b *badgerCache[T]) StartedAt() time.Time {
+	return b.base.StartedAt()
+}
+
+// Close stops the GC loop and closes the database, flushing it to disk.
+func (b *badgerCache[T]) Close() error {
+	var err error
+	b.stopOnce.Do(func() {
+		close(b.stop)
+		b.wg.Wait()
+		err = b.db.Close()
+	})
+	return base.WrapError(base.OpClose, err, "")
+}
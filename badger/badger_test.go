@@ -0,0 +1,128 @@
+//go:build badger
+
+package badger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func testConfig(t *testing.T, dir string) config.Config {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Type = config.TypeBadger
+	cfg.BadgerPath = dir
+	cfg.CleanupInterval = 0
+	return cfg
+}
+
+func open(t *testing.T, cfg config.Config) *badgerCache[string] {
+	t.Helper()
+	b, err := New[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig(t, t.TempDir())
+
+	b := open(t, cfg)
+	if err := b.Set(ctx, "kept", "v1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(ctx, "forever", "v2", base.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(ctx, "gone", "v3", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete(ctx, "gone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b = open(t, cfg)
+	defer b.Close()
+
+	for key, want := range map[string]string{"kept": "v1", "forever": "v2"} {
+		got, err := b.Get(ctx, key)
+		if err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+	if _, err := b.Get(ctx, "gone"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("deleted key after reopen: err = %v, want miss", err)
+	}
+
+	_, ttl, err := b.GetWithTTL(ctx, "kept")
+	if err != nil || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("TTL after reopen = %v, %v", ttl, err)
+	}
+	if n, err := b.Len(ctx); err != nil || n != 2 {
+		t.Errorf("Len = %d, %v; want 2", n, err)
+	}
+}
+
+func TestTTLExpiry(t *testing.T) {
+	ctx := context.Background()
+	b := open(t, testConfig(t, t.TempDir()))
+	defer b.Close()
+
+	if err := b.Set(ctx, "short", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set(ctx, "long", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Exists(ctx, "short"); !ok {
+		t.Fatal("short-lived key missing before expiry")
+	}
+
+	// Badger expires with second precision.
+	time.Sleep(2100 * time.Millisecond)
+
+	if _, err := b.Get(ctx, "short"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("expired key: err = %v, want miss", err)
+	}
+	if ok, _ := b.Exists(ctx, "short"); ok {
+		t.Error("expired key still exists")
+	}
+	if _, err := b.Get(ctx, "long"); err != nil {
+		t.Errorf("unexpired key: %v", err)
+	}
+	if n, _ := b.CountByPrefix(ctx, ""); n != 1 {
+		t.Errorf("CountByPrefix = %d, want 1", n)
+	}
+}
+
+func TestPersistRemovesExpiry(t *testing.T) {
+	ctx := context.Background()
+	b := open(t, testConfig(t, t.TempDir()))
+	defer b.Close()
+
+	if err := b.Set(ctx, "k", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Persist(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2100 * time.Millisecond)
+
+	v, ttl, err := b.GetWithTTL(ctx, "k")
+	if err != nil || v != "v" || ttl != 0 {
+		t.Errorf("GetWithTTL = %q, %v, %v; want v, 0, nil", v, ttl, err)
+	}
+	if err := b.Persist(ctx, "missing"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Persist(missing) = %v, want miss", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package badger is an embedded, persistent cache backend on BadgerDB: a
+// single-node cache whose entries survive restarts, with native per-entry
+// TTLs. It is built only with the "badger" build tag so builds without it
+// do not compile Badger in:
+//
+//	go build -tags badger ./...
+//
+// With the tag, config.TypeBadger and Builder.WithBadger select it through
+// the usual constructors.
+package badger
@@ -0,0 +1,19 @@
+//go:build badger
+
+package badger
+
+import "github.com/os-golib/go-cache/internal/base"
+
+/* ------------------ Options ------------------ */
+
+// Option customizes a badger cache at construction time.
+type Option[T any] func(*badgerCache[T])
+
+// WithSerializer replaces the default JSON serializer.
+func WithSerializer[T any](s base.Serializer[T]) Option[T] {
+	return func(b *badgerCache[T]) {
+		if s != nil {
+			b.serializer = s
+		}
+	}
+}
@@ -2,13 +2,20 @@ package cache
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/interfaces"
 )
 
 /* ------------------ Builder ------------------ */
@@ -26,6 +33,19 @@ func NewBuilder() *Builder {
 	}
 }
 
+// NewBuilderFrom starts from cfg instead of the defaults, so later With*
+// calls only override the fields they set. cfg's maps, slices and
+// pointers are copied; building does not change cfg.
+func NewBuilderFrom(cfg config.Config) *Builder {
+	cfg.TenantQuotas = maps.Clone(cfg.TenantQuotas)
+	cfg.MemcachedServers = slices.Clone(cfg.MemcachedServers)
+	if cfg.RedisDB != nil {
+		db := *cfg.RedisDB
+		cfg.RedisDB = &db
+	}
+	return &Builder{cfg: cfg}
+}
+
 /* ------------------ Load from File ------------------ */
 
 func (b *Builder) WithLoadFromFile(path string) *Builder {
@@ -40,7 +60,12 @@ func (b *Builder) WithLoadFromFile(path string) *Builder {
 	}
 
 	var fileCfg config.Config
-	if err := yaml.Unmarshal(data, &fileCfg); err != nil {
+	if config.IsJSONFile(path) {
+		err = json.Unmarshal(data, &fileCfg)
+	} else {
+		err = yaml.Unmarshal(data, &fileCfg)
+	}
+	if err != nil {
 		b.err = fmt.Errorf("parse config file: %w", err)
 		return b
 	}
@@ -53,6 +78,8 @@ func mergeConfig(dst *config.Config, src config.Config) {
 	mergeCore(dst, &src)
 	mergeMemory(dst, &src)
 	mergeRedis(dst, &src)
+	mergeMemcached(dst, &src)
+	mergeBadger(dst, &src)
 	mergeTimeouts(dst, &src)
 	mergeHealth(dst, &src)
 }
@@ -67,9 +94,51 @@ func mergeCore(dst, src *config.Config) {
 	if src.Prefix != "" {
 		dst.Prefix = src.Prefix
 	}
+	if src.Version != "" {
+		dst.Version = src.Version
+	}
 	if src.RefreshTTLOnHit {
 		dst.RefreshTTLOnHit = true
 	}
+	if src.ShutdownGrace > 0 {
+		dst.ShutdownGrace = src.ShutdownGrace
+	}
+	if src.MaxKeyLength > 0 {
+		dst.MaxKeyLength = src.MaxKeyLength
+	}
+	if src.TTLJitter > 0 {
+		dst.TTLJitter = src.TTLJitter
+	}
+	if src.WarmupKeys > 0 {
+		dst.WarmupKeys = src.WarmupKeys
+	}
+	if src.PipelineConcurrency > 0 {
+		dst.PipelineConcurrency = src.PipelineConcurrency
+	}
+	if src.OperationTimeout > 0 {
+		dst.OperationTimeout = src.OperationTimeout
+	}
+	if src.WriteBehindInterval > 0 {
+		dst.WriteBehindInterval = src.WriteBehindInterval
+	}
+	if src.WriteBehindBuffer > 0 {
+		dst.WriteBehindBuffer = src.WriteBehindBuffer
+	}
+	if src.WriteBehindDrop {
+		dst.WriteBehindDrop = true
+	}
+	if src.FallbackMode != "" {
+		dst.FallbackMode = src.FallbackMode
+	}
+	if src.TrackTopKeys > 0 {
+		dst.TrackTopKeys = src.TrackTopKeys
+	}
+	if src.LockTTL > 0 {
+		dst.LockTTL = src.LockTTL
+	}
+	if src.LockRenewInterval > 0 {
+		dst.LockRenewInterval = src.LockRenewInterval
+	}
 }
 
 func mergeMemory(dst, src *config.Config) {
@@ -79,18 +148,43 @@ func mergeMemory(dst, src *config.Config) {
 	if src.MaxBytes > 0 {
 		dst.MaxBytes = src.MaxBytes
 	}
+	if src.MaxCost > 0 {
+		dst.MaxCost = src.MaxCost
+	}
 	if src.EvictionPolicy != "" {
 		dst.EvictionPolicy = src.EvictionPolicy
 	}
 	if src.CleanupInterval > 0 {
 		dst.CleanupInterval = src.CleanupInterval
 	}
+	if len(src.TenantQuotas) > 0 {
+		dst.TenantQuotas = src.TenantQuotas
+	}
+	if src.TenantSeparator != "" {
+		dst.TenantSeparator = src.TenantSeparator
+	}
+	if src.SnapshotPath != "" {
+		dst.SnapshotPath = src.SnapshotPath
+	}
+	if src.StaleGrace > 0 {
+		dst.StaleGrace = src.StaleGrace
+	}
 }
 
 func mergeRedis(dst, src *config.Config) {
 	if src.RedisURL != "" {
 		dst.RedisURL = src.RedisURL
 	}
+	if src.RedisDB != nil {
+		db := *src.RedisDB
+		dst.RedisDB = &db
+	}
+	if src.RedisUsername != "" {
+		dst.RedisUsername = src.RedisUsername
+	}
+	if src.RedisPassword != "" {
+		dst.RedisPassword = src.RedisPassword
+	}
 	if src.PoolSize > 0 {
 		dst.PoolSize = src.PoolSize
 	}
@@ -103,6 +197,39 @@ func mergeRedis(dst, src *config.Config) {
 	if src.MaxConnAge > 0 {
 		dst.MaxConnAge = src.MaxConnAge
 	}
+	if src.HotKeysKey != "" {
+		dst.HotKeysKey = src.HotKeysKey
+	}
+	if src.HotKeysLimit > 0 {
+		dst.HotKeysLimit = src.HotKeysLimit
+	}
+	if src.HotKeysTTL > 0 {
+		dst.HotKeysTTL = src.HotKeysTTL
+	}
+	if src.DeleteBatchSize > 0 {
+		dst.DeleteBatchSize = src.DeleteBatchSize
+	}
+	if src.ScanCount > 0 {
+		dst.ScanCount = src.ScanCount
+	}
+	if src.BreakerThreshold > 0 {
+		dst.BreakerThreshold = src.BreakerThreshold
+	}
+	if src.BreakerCooldown > 0 {
+		dst.BreakerCooldown = src.BreakerCooldown
+	}
+}
+
+func mergeMemcached(dst, src *config.Config) {
+	if len(src.MemcachedServers) > 0 {
+		dst.MemcachedServers = src.MemcachedServers
+	}
+}
+
+func mergeBadger(dst, src *config.Config) {
+	if src.BadgerPath != "" {
+		dst.BadgerPath = src.BadgerPath
+	}
 }
 
 func mergeTimeouts(dst, src *config.Config) {
@@ -149,11 +276,114 @@ func (b *Builder) WithPrefix(prefix string) *Builder {
 	return b
 }
 
+// WithVersion namespaces every key under "v<v>:" after the prefix, so
+// changing v on deploy invalidates all previously cached entries.
+func (b *Builder) WithVersion(v string) *Builder {
+	b.cfg.Version = v
+	return b
+}
+
 func (b *Builder) WithRefreshOnHit(v bool) *Builder {
 	b.cfg.RefreshTTLOnHit = v
 	return b
 }
 
+// WithShutdownGrace makes Close wait up to d, instead of the default 10s,
+// for in-flight operations before tearing down the backend.
+func (b *Builder) WithShutdownGrace(d time.Duration) *Builder {
+	b.cfg.ShutdownGrace = d
+	return b
+}
+
+// WithMaxKeyLength hashes keys longer than n bytes, prefix included, into
+// a fixed-size SHA-256 form. n must leave room for the prefix and
+// config.HashedKeyLength; zero disables hashing.
+func (b *Builder) WithMaxKeyLength(n int) *Builder {
+	b.cfg.MaxKeyLength = n
+	return b
+}
+
+// WithTTLJitter randomizes write TTLs by ±fraction (e.g. 0.1 for ±10%)
+// to avoid synchronized mass expiry. Zero keeps TTLs exact.
+func (b *Builder) WithTTLJitter(fraction float64) *Builder {
+	b.cfg.TTLJitter = fraction
+	return b
+}
+
+// WithPipelineConcurrency bounds parallelism of the bulk-operation
+// fallback for backends without native pipelines. 1 runs sequentially.
+func (b *Builder) WithPipelineConcurrency(n int) *Builder {
+	b.cfg.PipelineConcurrency = n
+	return b
+}
+
+// WithOperationTimeout gives advanced cache operations called without a
+// deadline a timeout of d. Callers' own deadlines are kept.
+func (b *Builder) WithOperationTimeout(d time.Duration) *Builder {
+	b.cfg.OperationTimeout = d
+	return b
+}
+
+// WithLockTTL sets the GetOrSetLocked lock TTL and how often the lock is
+// extended while fn runs. A zero renew uses ttl/3.
+func (b *Builder) WithLockTTL(ttl, renew time.Duration) *Builder {
+	b.cfg.LockTTL = ttl
+	b.cfg.LockRenewInterval = renew
+	return b
+}
+
+// WithWriteBehind sets how often a write-behind cache flushes and how many
+// writes it buffers. With drop set, writes arriving at a full buffer are
+// discarded instead of waiting for a flush.
+func (b *Builder) WithWriteBehind(interval time.Duration, buffer int, drop bool) *Builder {
+	b.cfg.WriteBehindInterval = interval
+	b.cfg.WriteBehindBuffer = buffer
+	b.cfg.WriteBehindDrop = drop
+	return b
+}
+
+// WithFallbackMode selects whether a fallback cache accepts writes
+// (fail-open) or reports them failed (fail-closed) while its primary is
+// down.
+func (b *Builder) WithFallbackMode(mode config.FallbackMode) *Builder {
+	b.cfg.FallbackMode = mode
+	return b
+}
+
+// WithTopKeys tracks the n most read keys, reported by
+// Metrics().TopKeys. Memory use is bounded by n.
+func (b *Builder) WithTopKeys(n int) *Builder {
+	b.cfg.TrackTopKeys = n
+	return b
+}
+
+// WithWarmupKeys makes a tiered cache copy the n hottest L2 keys into L1
+// at startup. L2 must track hits (see WithHotKeyTracking).
+func (b *Builder) WithWarmupKeys(n int) *Builder {
+	b.cfg.WarmupKeys = n
+	return b
+}
+
+// WithHooks registers lifecycle callbacks (hit, miss, evict, expire).
+func (b *Builder) WithHooks(h config.Hooks) *Builder {
+	b.cfg.Hooks = h
+	return b
+}
+
+// WithTracer wraps advanced cache operations in spans started by t, e.g.
+// an adapter from the otel subpackage.
+func (b *Builder) WithTracer(t interfaces.Tracer) *Builder {
+	b.cfg.Tracer = t
+	return b
+}
+
+// WithLogger logs advanced cache operations to l: hits, misses and
+// evictions at debug level, failures at warn or error. Nil disables it.
+func (b *Builder) WithLogger(l *slog.Logger) *Builder {
+	b.cfg.Logger = l
+	return b
+}
+
 func (b *Builder) WithTimeout(timeout time.Duration) *Builder {
 	if timeout > 0 {
 		b.cfg.ConnTimeout = timeout
@@ -182,6 +412,13 @@ func (b *Builder) WithMaxBytes(n int) *Builder {
 	return b
 }
 
+// WithMaxCost enables cost-based eviction; pair it with the WithCostFunc
+// option to weigh entries.
+func (b *Builder) WithMaxCost(n int64) *Builder {
+	b.cfg.MaxCost = n
+	return b
+}
+
 func (b *Builder) WithCleanupInterval(d time.Duration) *Builder {
 	b.cfg.CleanupInterval = d
 	return b
@@ -192,6 +429,51 @@ func (b *Builder) WithEvictionPolicy(p config.EvictionPolicy) *Builder {
 	return b
 }
 
+// WithTenantQuota caps the entries attributed to tenant before they are
+// preferred eviction victims.
+func (b *Builder) WithTenantQuota(tenant string, maxEntries int) *Builder {
+	if b.cfg.TenantQuotas == nil {
+		b.cfg.TenantQuotas = make(map[string]config.TenantQuota)
+	}
+	q := b.cfg.TenantQuotas[tenant]
+	q.MaxEntries = maxEntries
+	b.cfg.TenantQuotas[tenant] = q
+	return b
+}
+
+// WithTenantByteQuota caps the estimated bytes attributed to tenant before
+// its entries are preferred eviction victims.
+func (b *Builder) WithTenantByteQuota(tenant string, maxBytes int64) *Builder {
+	if b.cfg.TenantQuotas == nil {
+		b.cfg.TenantQuotas = make(map[string]config.TenantQuota)
+	}
+	q := b.cfg.TenantQuotas[tenant]
+	q.MaxBytes = maxBytes
+	b.cfg.TenantQuotas[tenant] = q
+	return b
+}
+
+// WithTenantSeparator sets the separator delimiting the tenant part of a
+// key (default ":").
+func (b *Builder) WithTenantSeparator(sep string) *Builder {
+	b.cfg.TenantSeparator = sep
+	return b
+}
+
+// WithSnapshotPath persists the memory cache to path on Close and reloads
+// it on startup.
+func (b *Builder) WithSnapshotPath(path string) *Builder {
+	b.cfg.SnapshotPath = path
+	return b
+}
+
+// WithStaleGrace keeps expired memory entries around for d so GetStale can
+// still serve them, e.g. while the source of truth is down.
+func (b *Builder) WithStaleGrace(d time.Duration) *Builder {
+	b.cfg.StaleGrace = d
+	return b
+}
+
 /* ------------------ Redis ------------------ */
 
 func (b *Builder) WithRedis(url string) *Builder {
@@ -200,6 +482,21 @@ func (b *Builder) WithRedis(url string) *Builder {
 	return b
 }
 
+// WithRedisDB selects the database index, overriding the one in the URL,
+// including with 0.
+func (b *Builder) WithRedisDB(db int) *Builder {
+	b.cfg.RedisDB = &db
+	return b
+}
+
+// WithRedisAuth sets the ACL username (may be empty) and password,
+// overriding any credentials in the URL.
+func (b *Builder) WithRedisAuth(username, password string) *Builder {
+	b.cfg.RedisUsername = username
+	b.cfg.RedisPassword = password
+	return b
+}
+
 func (b *Builder) WithPoolSize(size int) *Builder {
 	b.cfg.PoolSize = size
 	return b
@@ -220,6 +517,40 @@ func (b *Builder) WithMaxConnAge(d time.Duration) *Builder {
 	return b
 }
 
+// WithHotKeyTracking counts hits per key in the Redis sorted set zsetKey.
+func (b *Builder) WithHotKeyTracking(zsetKey string) *Builder {
+	b.cfg.HotKeysKey = zsetKey
+	return b
+}
+
+// WithHotKeyLimits bounds the hot-key set to its limit hottest keys and
+// lets it expire ttl after the last tracked hit.
+func (b *Builder) WithHotKeyLimits(limit int, ttl time.Duration) *Builder {
+	b.cfg.HotKeysLimit = limit
+	b.cfg.HotKeysTTL = ttl
+	return b
+}
+
+// WithDeleteBatchSize caps the number of keys sent in one DEL command.
+func (b *Builder) WithDeleteBatchSize(n int) *Builder {
+	b.cfg.DeleteBatchSize = n
+	return b
+}
+
+// WithScanCount sets the COUNT hint used by every SCAN the cache issues.
+func (b *Builder) WithScanCount(n int) *Builder {
+	b.cfg.ScanCount = n
+	return b
+}
+
+// WithCircuitBreaker makes Redis commands fail fast with a connection
+// error after threshold consecutive failures, probing again after cooldown.
+func (b *Builder) WithCircuitBreaker(threshold int, cooldown time.Duration) *Builder {
+	b.cfg.BreakerThreshold = threshold
+	b.cfg.BreakerCooldown = cooldown
+	return b
+}
+
 func (b *Builder) WithConnTimeout(d time.Duration) *Builder {
 	b.cfg.ConnTimeout = d
 	return b
@@ -255,6 +586,26 @@ func (b *Builder) WithStartupRetries(n int) *Builder {
 	return b
 }
 
+/* ------------------ Memcached ------------------ */
+
+// WithMemcached selects the memcached backend on servers (host:port).
+// Pool size and timeouts are shared with the Redis settings.
+func (b *Builder) WithMemcached(servers ...string) *Builder {
+	b.cfg.Type = config.TypeMemcached
+	b.cfg.MemcachedServers = servers
+	return b
+}
+
+/* ------------------ Badger ------------------ */
+
+// WithBadger selects the embedded badger backend with its database in dir.
+// It needs a binary built with the "badger" tag.
+func (b *Builder) WithBadger(dir string) *Builder {
+	b.cfg.Type = config.TypeBadger
+	b.cfg.BadgerPath = dir
+	return b
+}
+
 /* ------------------ Build ------------------ */
 
 func (b *Builder) Build() (config.Config, error) {
@@ -292,12 +643,20 @@ func WithContext(
 type KeyBuilder struct {
 	prefix string
 	parts  []string
+	maxLen int
 }
 
 func NewKeyBuilder(prefix string) *KeyBuilder {
 	return &KeyBuilder{prefix: prefix}
 }
 
+// WithMaxLen makes Build return prefix:sha256:<hex digest> instead of any
+// key longer than n bytes. Zero disables the limit.
+func (k *KeyBuilder) WithMaxLen(n int) *KeyBuilder {
+	k.maxLen = n
+	return k
+}
+
 func (k *KeyBuilder) Add(part string) *KeyBuilder {
 	if part != "" {
 		k.parts = append(k.parts, part)
@@ -305,6 +664,15 @@ func (k *KeyBuilder) Add(part string) *KeyBuilder {
 	return k
 }
 
+// AddSorted adds each key and value of params, ordered by key, so the
+// result does not depend on map iteration order.
+func (k *KeyBuilder) AddSorted(params map[string]string) *KeyBuilder {
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		k.Add(name).Add(params[name])
+	}
+	return k
+}
+
 func (k *KeyBuilder) Reset() *KeyBuilder {
 	k.parts = nil
 	return k
@@ -322,5 +690,14 @@ func (k *KeyBuilder) Build() string {
 		}
 		out += p
 	}
+
+	if k.maxLen > 0 && len(out) > k.maxLen {
+		sum := sha256.Sum256([]byte(out))
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		if k.prefix == "" {
+			return digest
+		}
+		return k.prefix + ":" + digest
+	}
 	return out
 }
@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+)
+
+func writeTestConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+/* ------------------ Redis connection settings ------------------ */
+
+func TestWithRedisDBZeroOverridesURL(t *testing.T) {
+	cfg, err := NewBuilder().
+		WithRedis("redis://localhost:6379/3").
+		WithRedisDB(0).
+		WithRedisAuth("admin", "secret").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RedisDB == nil || *cfg.RedisDB != 0 {
+		t.Errorf("RedisDB = %v, want explicit 0", cfg.RedisDB)
+	}
+	if cfg.RedisUsername != "admin" || cfg.RedisPassword != "secret" {
+		t.Errorf("credentials = %q/%q", cfg.RedisUsername, cfg.RedisPassword)
+	}
+
+	cfg, err = NewBuilder().WithRedis("redis://localhost:6379/3").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RedisDB != nil {
+		t.Errorf("RedisDB = %d without WithRedisDB, want unset", *cfg.RedisDB)
+	}
+}
+
+func TestRedisDBFromFile(t *testing.T) {
+	path := writeTestConfig(t, "redis.yaml", "type: redis\nredis_url: redis://localhost:6379/3\nredis_db: 0\n")
+
+	cfg, err := NewBuilder().WithLoadFromFile(path).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RedisDB == nil || *cfg.RedisDB != 0 {
+		t.Errorf("RedisDB from file = %v, want explicit 0", cfg.RedisDB)
+	}
+
+	cfg, err = NewBuilder().WithLoadFromFile(path).WithRedisDB(2).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.RedisDB != 2 {
+		t.Errorf("RedisDB = %d, want builder's 2", *cfg.RedisDB)
+	}
+}
+
+func TestNewBuilderFromCopiesRedisDB(t *testing.T) {
+	src := NewBuilder().WithRedis("redis://localhost:6379").WithRedisDB(4).MustBuild()
+
+	cfg := NewBuilderFrom(src).WithRedisDB(1).MustBuild()
+	if *cfg.RedisDB != 1 || *src.RedisDB != 4 {
+		t.Errorf("RedisDB = %d, source = %d; want 1 and unchanged 4", *cfg.RedisDB, *src.RedisDB)
+	}
+}
+
+func TestNewBuilderFromKeepsLoadedValues(t *testing.T) {
+	path := writeTestConfig(t, "loaded.yaml", `
+type: memory
+ttl: 10m
+prefix: "app:"
+max_entries: 500
+tenant_quotas:
+  acme:
+    max_entries: 100
+`)
+	loaded, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewBuilderFrom(loaded).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := loaded.Diff(cfg); len(d) != 0 {
+		t.Errorf("unpatched build differs from the loaded config: %v", d)
+	}
+
+	cfg, err = NewBuilderFrom(loaded).
+		WithTTL(time.Minute).
+		WithTenantQuota("globex", 5).
+		WithTenantByteQuota("globex", 1<<20).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TTL != time.Minute {
+		t.Errorf("TTL = %v, want the override 1m", cfg.TTL)
+	}
+	if cfg.Prefix != "app:" || cfg.MaxEntries != 500 {
+		t.Errorf("Prefix, MaxEntries = %q, %d; want the loaded app:, 500", cfg.Prefix, cfg.MaxEntries)
+	}
+	if len(cfg.TenantQuotas) != 2 || cfg.TenantQuotas["acme"].MaxEntries != 100 {
+		t.Errorf("TenantQuotas = %v, want acme kept and globex added", cfg.TenantQuotas)
+	}
+	if q := cfg.TenantQuotas["globex"]; q != (config.TenantQuota{MaxEntries: 5, MaxBytes: 1 << 20}) {
+		t.Errorf("globex quota = %+v, want both limits", q)
+	}
+	if _, ok := loaded.TenantQuotas["globex"]; ok || loaded.TTL != 10*time.Minute {
+		t.Error("building changed the source config")
+	}
+}
+
+/* ------------------ Pipeline concurrency ------------------ */
+
+func TestWithPipelineConcurrency(t *testing.T) {
+	cfg, err := NewBuilder().WithPipelineConcurrency(1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PipelineConcurrency != 1 {
+		t.Errorf("PipelineConcurrency = %d, want 1", cfg.PipelineConcurrency)
+	}
+
+	if _, err := NewBuilder().WithPipelineConcurrency(-1).Build(); err == nil {
+		t.Error("negative pipeline concurrency accepted")
+	}
+}
+
+/* ------------------ Key version ------------------ */
+
+func TestWithVersion(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg, err := NewBuilder().WithRedis("redis://" + mr.Addr()).WithPrefix("app:").WithVersion("7").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	if err := c.Set(context.Background(), "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("app:v7:k") {
+		t.Errorf("stored keys = %v, want app:v7:k", mr.Keys())
+	}
+}
+
+/* ------------------ KeyBuilder ------------------ */
+
+func TestKeyBuilderAddSortedIsDeterministic(t *testing.T) {
+	a := NewKeyBuilder("api").Add("GET").AddSorted(map[string]string{"page": "2", "q": "go", "limit": "10"}).Build()
+	b := NewKeyBuilder("api").Add("GET").AddSorted(map[string]string{"limit": "10", "q": "go", "page": "2"}).Build()
+
+	if want := "api:GET:limit:10:page:2:q:go"; a != want {
+		t.Errorf("Build = %q, want %q", a, want)
+	}
+	if a != b {
+		t.Errorf("reordered params built %q and %q", a, b)
+	}
+}
+
+func TestKeyBuilderWithMaxLenHashesLongKeys(t *testing.T) {
+	short := NewKeyBuilder("api").WithMaxLen(32).Add("users").Add("1").Build()
+	if short != "api:users:1" {
+		t.Errorf("short key = %q, want it unchanged", short)
+	}
+
+	long := strings.Repeat("x", 100)
+	k1 := NewKeyBuilder("api").WithMaxLen(32).Add(long).Build()
+	k2 := NewKeyBuilder("api").WithMaxLen(32).Add(long).Build()
+	k3 := NewKeyBuilder("api").WithMaxLen(32).Add(long + "y").Build()
+
+	if !strings.HasPrefix(k1, "api:sha256:") || len(k1) != len("api:sha256:")+64 {
+		t.Errorf("long key = %q, want api:sha256:<digest>", k1)
+	}
+	if k1 != k2 || k1 == k3 {
+		t.Errorf("hashed keys not stable and distinct: %q %q %q", k1, k2, k3)
+	}
+
+	// Exactly at the limit is kept as is.
+	edge := NewKeyBuilder("").WithMaxLen(5).Add("abcde").Build()
+	if edge != "abcde" {
+		t.Errorf("key at the limit = %q", edge)
+	}
+	if got := NewKeyBuilder("").WithMaxLen(5).Add("abcdef").Build(); !strings.HasPrefix(got, "sha256:") {
+		t.Errorf("unprefixed long key = %q, want sha256:<digest>", got)
+	}
+}
@@ -3,29 +3,55 @@ package cache
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
+	goredis "github.com/redis/go-redis/v9"
+
 	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/fallback"
 	"github.com/os-golib/go-cache/internal/advanced"
 	"github.com/os-golib/go-cache/internal/base"
 	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+	"github.com/os-golib/go-cache/memcached"
 	"github.com/os-golib/go-cache/memory"
 	"github.com/os-golib/go-cache/redis"
+	"github.com/os-golib/go-cache/tiered"
+	"github.com/os-golib/go-cache/writebehind"
 )
 
+// NoExpiration, passed as a TTL, stores an entry that never expires. A
+// zero TTL uses the configured default.
+const NoExpiration = base.NoExpiration
+
+// Entry is a value and its remaining TTL, as used by Dump and Restore.
+type Entry[T any] = interfaces.Entry[T]
+
+// Result is one key of a GetManyOrdered call.
+type Result[T any] = interfaces.Result[T]
+
 /* ------------------ core factory ------------------ */
 
-func newCache[T any](ctx context.Context, cfg config.Config) (interfaces.Cache[T], error) {
+func newCache[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (interfaces.Cache[T], error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, base.WrapError(base.OpInit, err, "")
 	}
 
+	o := buildOptions(opts)
+
 	switch cfg.Type {
 	case config.TypeMemory:
-		return memory.NewMemory[T](cfg)
+		return memory.NewMemory[T](cfg, o.memoryOptions()...)
 
 	case config.TypeRedis:
-		return redis.NewRedisContext[T](ctx, cfg)
+		return redis.NewRedisContext[T](ctx, cfg, o.redisOptions()...)
+
+	case config.TypeMemcached:
+		return memcached.NewMemcachedContext[T](ctx, cfg, o.memcachedOptions()...)
+
+	case config.TypeBadger:
+		return newBadger[T](cfg, o)
 
 	default:
 		return nil, base.WrapError(base.OpInit, base.ErrInvalidConfig, string(cfg.Type))
@@ -34,30 +60,130 @@ func newCache[T any](ctx context.Context, cfg config.Config) (interfaces.Cache[T
 
 /* ------------------ public APIs ------------------ */
 
-func New[T any](cfg config.Config) (interfaces.Cache[T], error) {
-	return newCache[T](context.Background(), cfg)
+func New[T any](cfg config.Config, opts ...Option[T]) (interfaces.Cache[T], error) {
+	return newCache[T](context.Background(), cfg, opts...)
 }
 
-func NewWithContext[T any](ctx context.Context, cfg config.Config) (interfaces.Cache[T], error) {
-	return newCache[T](ctx, cfg)
+func NewWithContext[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (interfaces.Cache[T], error) {
+	return newCache[T](ctx, cfg, opts...)
 }
 
-func NewAdvanced[T any](cfg config.Config) (interfaces.AdvancedCache[T], error) {
-	c, err := New[T](cfg)
+func NewAdvanced[T any](cfg config.Config, opts ...Option[T]) (interfaces.AdvancedCache[T], error) {
+	c, err := New[T](cfg, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("create cache: %w", err)
 	}
-	return advanced.NewAdvancedCache[T](c, cfg), nil
+	return advanced.NewAdvancedCache[T](c, cfg, buildOptions(opts).advancedOptions()...), nil
+}
+
+func NewAdvancedWithContext[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (interfaces.AdvancedCache[T], error) {
+	c, err := NewWithContext[T](ctx, cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+	return advanced.NewAdvancedCache[T](c, cfg, buildOptions(opts).advancedOptions()...), nil
+}
+
+// NewAdvancedFromRedisClient builds an advanced cache over an existing
+// Redis client. The client is shared: closing the cache does not close it,
+// and no circuit breaker is installed on it. RedisURL and pool settings in
+// cfg are ignored.
+func NewAdvancedFromRedisClient[T any](
+	client *goredis.Client,
+	cfg config.Config,
+	opts ...Option[T],
+) (interfaces.AdvancedCache[T], error) {
+	o := buildOptions(opts)
+	c, err := redis.NewFromClient[T](client, cfg, o.redisOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+	return advanced.NewAdvancedCache[T](c, cfg, o.advancedOptions()...), nil
 }
 
-func NewAdvancedWithContext[T any](ctx context.Context, cfg config.Config) (interfaces.AdvancedCache[T], error) {
-	c, err := NewWithContext[T](ctx, cfg)
+// NewTiered builds a two-tier cache: l1Cfg (usually memory) in front of
+// l2Cfg (usually Redis). cfg supplies the default TTL of the combined
+// cache and WarmupKeys; each tier applies its own prefix. Set HotKeysKey
+// on l2Cfg so warmup knows which keys are hot.
+func NewTiered[T any](
+	ctx context.Context,
+	cfg, l1Cfg, l2Cfg config.Config,
+) (interfaces.AdvancedCache[T], error) {
+	l1, err := newCache[tiered.Envelope[T]](ctx, l1Cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create l1 cache: %w", err)
+	}
+
+	l2, err := newCache[tiered.Envelope[T]](ctx, l2Cfg)
+	if err != nil {
+		_ = l1.Close()
+		return nil, fmt.Errorf("create l2 cache: %w", err)
+	}
+
+	c, err := tiered.New[T](l1, l2, cfg)
 	if err != nil {
+		_ = l1.Close()
+		_ = l2.Close()
 		return nil, fmt.Errorf("create cache: %w", err)
 	}
+
+	// Warmup is best effort: a cold L1 still works.
+	if cfg.WarmupKeys > 0 {
+		_, _ = c.Warmup(ctx, cfg.WarmupKeys)
+	}
 	return advanced.NewAdvancedCache[T](c, cfg), nil
 }
 
+// NewWriteBehind builds an advanced cache whose writes are buffered and
+// flushed to the cfg backend in batches (see the WriteBehind* settings).
+// Close flushes pending writes.
+func NewWriteBehind[T any](
+	ctx context.Context,
+	cfg config.Config,
+	opts ...Option[T],
+) (interfaces.AdvancedCache[T], error) {
+	c, err := newCache[T](ctx, cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+
+	w, err := writebehind.New[T](c, cfg)
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+	return advanced.NewAdvancedCache[T](w, cfg, buildOptions(opts).advancedOptions()...), nil
+}
+
+// NewFallback builds a cache served by primaryCfg (usually Redis) that
+// falls back to fallbackCfg (usually memory) while the primary is
+// unreachable. Writes are mirrored to both; cfg supplies the default TTL
+// and FallbackMode.
+func NewFallback[T any](
+	ctx context.Context,
+	cfg, primaryCfg, fallbackCfg config.Config,
+	opts ...Option[T],
+) (interfaces.AdvancedCache[T], error) {
+	primary, err := newCache[T](ctx, primaryCfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create primary cache: %w", err)
+	}
+
+	secondary, err := newCache[T](ctx, fallbackCfg, opts...)
+	if err != nil {
+		_ = primary.Close()
+		return nil, fmt.Errorf("create fallback cache: %w", err)
+	}
+
+	c, err := fallback.New[T](primary, secondary, cfg)
+	if err != nil {
+		_ = primary.Close()
+		_ = secondary.Close()
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+	return advanced.NewAdvancedCache[T](c, cfg, buildOptions(opts).advancedOptions()...), nil
+}
+
 /* ------------------ helpers ------------------ */
 
 func Must[T any](c interfaces.Cache[T], err error) interfaces.Cache[T] {
@@ -74,6 +200,11 @@ func MustAdvanced[T any](c interfaces.AdvancedCache[T], err error) interfaces.Ad
 	return c
 }
 
+// MetricsHandler serves c's stats and per-operation metrics as JSON.
+func MetricsHandler[T any](c interfaces.AdvancedCache[T]) http.Handler {
+	return metrics.JSONHandler(c.Metrics())
+}
+
 /* ------------------ convenience ------------------ */
 
 func NewMemory[T any]() (interfaces.Cache[T], error) {
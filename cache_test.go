@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Tiered ------------------ */
+
+func tieredConfigs(mr *miniredis.Miniredis, warmup int) (cfg, l1, l2 config.Config) {
+	cfg = config.DefaultConfig()
+	cfg.WarmupKeys = warmup
+
+	l1 = config.DefaultConfig()
+	l1.CleanupInterval = 0
+
+	l2 = config.DefaultConfig()
+	l2.Type = config.TypeRedis
+	l2.RedisURL = "redis://" + mr.Addr()
+	l2.HotKeysKey = "hot"
+	return cfg, l1, l2
+}
+
+func TestNewTieredWarmsL1WithHotKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg, l1, l2 := tieredConfigs(mr, 3)
+
+	seed, err := NewTiered[string](ctx, cfg, l1, l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		if err := seed.Set(ctx, k, "v:"+k, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = seed.Close()
+
+	mr.Del("hot")
+	for k, score := range map[string]float64{"a": 10, "b": 8, "c": 5, "d": 1, "e": 0} {
+		if _, err := mr.ZAdd("hot", score, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	node, err := NewTiered[string](ctx, cfg, l1, l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = node.Close() })
+
+	// With L2 emptied, only keys warmed into L1 can still be read.
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		mr.Del(l2.Prefix + k)
+	}
+	for _, k := range []string{"a", "b", "c"} {
+		if v, err := node.Get(ctx, k); err != nil || v != "v:"+k {
+			t.Errorf("hot key %s not warmed: %q, %v", k, v, err)
+		}
+	}
+	for _, k := range []string{"d", "e"} {
+		if _, err := node.Get(ctx, k); !base.IsCacheMiss(err) {
+			t.Errorf("cold key %s = %v, want a miss", k, err)
+		}
+	}
+}
+
+func TestNewTieredWithoutWarmupStartsCold(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg, l1, l2 := tieredConfigs(mr, 0)
+
+	seed, err := NewTiered[string](ctx, cfg, l1, l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := seed.Set(ctx, "a", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	_ = seed.Close()
+	if _, err := mr.ZAdd("hot", 10, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := NewTiered[string](ctx, cfg, l1, l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = node.Close() })
+
+	mr.Del(l2.Prefix + "a")
+	if _, err := node.Get(ctx, "a"); !base.IsCacheMiss(err) {
+		t.Errorf("Get = %v, want a miss from a cold L1", err)
+	}
+}
+
+/* ------------------ Metrics ------------------ */
+
+func TestMetricsHandlerReportsCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := newTestAdvanced[string](t)
+	if err := c.Set(ctx, "a", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(c).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var got struct {
+		Stats struct {
+			Backend string  `json:"backend"`
+			Items   int64   `json:"items"`
+			HitRate float64 `json:"hit_rate"`
+			Uptime  int64   `json:"uptime"`
+		} `json:"stats"`
+		Operations map[string]struct {
+			Count int64 `json:"count"`
+		} `json:"operations"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Stats.Backend != "memory" || got.Stats.Items != 1 || got.Stats.Uptime <= 0 {
+		t.Errorf("stats = %+v", got.Stats)
+	}
+	if got.Stats.HitRate != 0.5 {
+		t.Errorf("hit_rate = %v, want 0.5", got.Stats.HitRate)
+	}
+	if got.Operations["get"].Count != 2 || got.Operations["set"].Count != 1 {
+		t.Errorf("operations = %+v", got.Operations)
+	}
+}
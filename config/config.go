@@ -1,13 +1,18 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
 )
 
 /* ------------------ Enums ------------------ */
@@ -15,12 +20,21 @@ import (
 type Type string
 
 const (
-	TypeMemory Type = "memory"
-	TypeRedis  Type = "redis"
+	TypeMemory    Type = "memory"
+	TypeRedis     Type = "redis"
+	TypeMemcached Type = "memcached"
+
+	// TypeBadger needs a binary built with the "badger" tag.
+	TypeBadger Type = "badger"
 )
 
 func (t Type) Valid() bool {
-	return t == TypeMemory || t == TypeRedis
+	switch t {
+	case TypeMemory, TypeRedis, TypeMemcached, TypeBadger:
+		return true
+	default:
+		return false
+	}
 }
 
 type EvictionPolicy string
@@ -42,39 +56,189 @@ func (e EvictionPolicy) Valid() bool {
 	}
 }
 
+// FallbackMode decides how a fallback cache treats writes while its
+// primary is down. Reads are served from the secondary in both modes.
+type FallbackMode string
+
+const (
+	// FallbackFailOpen applies writes to the secondary alone and reports
+	// success.
+	FallbackFailOpen FallbackMode = "fail_open"
+	// FallbackFailClosed still mirrors writes to the secondary but
+	// returns the primary's error.
+	FallbackFailClosed FallbackMode = "fail_closed"
+)
+
+func (m FallbackMode) Valid() bool {
+	switch m {
+	case "", FallbackFailOpen, FallbackFailClosed:
+		return true
+	default:
+		return false
+	}
+}
+
 /* ------------------ Config ------------------ */
 
+// TenantQuota bounds how many entries, and how many estimated bytes, a
+// tenant may hold before its entries become preferred eviction victims.
+// A zero limit is not enforced.
+type TenantQuota struct {
+	MaxEntries int   `yaml:"max_entries" json:"max_entries"`
+	MaxBytes   int64 `yaml:"max_bytes" json:"max_bytes"`
+}
+
+// EvictReason says why an entry was evicted.
+type EvictReason string
+
+const (
+	EvictCapacity EvictReason = "capacity"
+	EvictQuota    EvictReason = "tenant_quota"
+)
+
+// Hooks are optional lifecycle callbacks. They are called synchronously on
+// the calling goroutine but never while a cache lock is held; slow hooks
+// slow down the operation that triggered them.
+type Hooks struct {
+	OnHit    func(key string)
+	OnMiss   func(key string)
+	OnEvict  func(key string, reason EvictReason)
+	OnExpire func(key string)
+}
+
 type Config struct {
 	// Common
-	Type            Type          `yaml:"type"`
-	TTL             time.Duration `yaml:"ttl"`
-	Prefix          string        `yaml:"prefix"`
-	RefreshTTLOnHit bool          `yaml:"refresh_on_hit"`
+	Type            Type          `yaml:"type" json:"type"`
+	TTL             time.Duration `yaml:"ttl" json:"ttl"`
+	Prefix          string        `yaml:"prefix" json:"prefix"`
+	RefreshTTLOnHit bool          `yaml:"refresh_on_hit" json:"refresh_on_hit"`
+	ShutdownGrace   time.Duration `yaml:"shutdown_grace" json:"shutdown_grace"`
+	MaxKeyLength    int           `yaml:"max_key_length" json:"max_key_length"`
+	TTLJitter       float64       `yaml:"ttl_jitter" json:"ttl_jitter"`
+
+	// Version, when set, is folded into every key as "v<Version>:" after
+	// Prefix; bumping it orphans all entries written under the old one.
+	Version string `yaml:"version" json:"version"`
+
+	// PipelineConcurrency bounds the parallel per-key calls used when a
+	// backend has no native bulk operation (default 10; 1 is sequential).
+	PipelineConcurrency int `yaml:"pipeline_concurrency" json:"pipeline_concurrency"`
+
+	// OperationTimeout bounds each advanced cache operation whose context
+	// has no deadline of its own. Zero leaves such contexts unbounded.
+	OperationTimeout time.Duration `yaml:"operation_timeout" json:"operation_timeout"`
+
+	// GetOrSetLocked: TTL of the distributed lock (default 30s) and how
+	// often it is extended while the value is computed (default LockTTL/3).
+	LockTTL           time.Duration `yaml:"lock_ttl" json:"lock_ttl"`
+	LockRenewInterval time.Duration `yaml:"lock_renew_interval" json:"lock_renew_interval"`
+
+	// Write-behind: buffered writes are flushed every WriteBehindInterval
+	// (default 1s) or when WriteBehindBuffer entries (default 1000) are
+	// pending. When the buffer is full Set blocks, or drops the write if
+	// WriteBehindDrop is set.
+	WriteBehindInterval time.Duration `yaml:"write_behind_interval" json:"write_behind_interval"`
+	WriteBehindBuffer   int           `yaml:"write_behind_buffer" json:"write_behind_buffer"`
+	WriteBehindDrop     bool          `yaml:"write_behind_drop" json:"write_behind_drop"`
+
+	// Fallback cache: what writes do while the primary is down (default
+	// fail_open).
+	FallbackMode FallbackMode `yaml:"fallback_mode" json:"fallback_mode"`
+
+	// Tiered cache: number of the hottest L2 keys copied into a new L1.
+	WarmupKeys int `yaml:"warmup_keys" json:"warmup_keys"`
+
+	// TrackTopKeys enables per-key access counting in the metrics
+	// collector, bounded to this many keys. Zero disables it.
+	TrackTopKeys int `yaml:"track_top_keys" json:"track_top_keys"`
+
+	// Hooks, Tracer and Logger cannot be loaded from YAML; set them in code.
+	Hooks  Hooks             `yaml:"-" json:"-"`
+	Tracer interfaces.Tracer `yaml:"-" json:"-"`
+	Logger *slog.Logger      `yaml:"-" json:"-"`
 
 	// Memory cache
-	MaxSize         int            `yaml:"max_size"`
-	MaxEntries      int            `yaml:"max_entries"`
-	MaxBytes        int            `yaml:"max_bytes"`
-	CleanupInterval time.Duration  `yaml:"cleanup_interval"`
-	EvictionPolicy  EvictionPolicy `yaml:"eviction_policy"`
+	MaxSize         int            `yaml:"max_size" json:"max_size"`
+	MaxEntries      int            `yaml:"max_entries" json:"max_entries"`
+	MaxBytes        int            `yaml:"max_bytes" json:"max_bytes"`
+	CleanupInterval time.Duration  `yaml:"cleanup_interval" json:"cleanup_interval"`
+	EvictionPolicy  EvictionPolicy `yaml:"eviction_policy" json:"eviction_policy"`
+
+	// MaxCost caps the summed entry cost (see memory.WithCostFunc);
+	// least recently used entries are evicted until it fits.
+	MaxCost int64 `yaml:"max_cost" json:"max_cost"`
+
+	// Multi-tenant memory cache: the tenant of a key is the part before
+	// the first TenantSeparator. When eviction is needed, entries of
+	// tenants over their quota are evicted first.
+	TenantQuotas    map[string]TenantQuota `yaml:"tenant_quotas" json:"tenant_quotas"`
+	TenantSeparator string                 `yaml:"tenant_separator" json:"tenant_separator"`
+
+	// SnapshotPath, when set, makes the memory cache save its live entries
+	// there on Close and reload them on startup.
+	SnapshotPath string `yaml:"snapshot_path" json:"snapshot_path"`
+
+	// StaleGrace keeps expired memory entries readable through GetStale
+	// for this long before they are removed.
+	StaleGrace time.Duration `yaml:"stale_grace" json:"stale_grace"`
 
 	// Redis cache
-	RedisURL       string        `yaml:"redis_url"`
-	PoolSize       int           `yaml:"pool_size"`
-	MinIdleConn    int           `yaml:"min_idle"`
-	MaxRetries     int           `yaml:"max_retries"`
-	MaxConnAge     time.Duration `yaml:"max_conn_age"`
-	ConnTimeout    time.Duration `yaml:"conn_timeout"`
-	DialTimeout    time.Duration `yaml:"dial_timeout"`
-	ReadTimeout    time.Duration `yaml:"read_timeout"`
-	WriteTimeout   time.Duration `yaml:"write_timeout"`
-	HealthCheck    time.Duration `yaml:"health_check"`
-	RetryOnStart   bool          `yaml:"retry_on_start"`
-	StartupRetries int           `yaml:"startup_retries"`
+	RedisURL       string        `yaml:"redis_url" json:"redis_url"`
+	PoolSize       int           `yaml:"pool_size" json:"pool_size"`
+	MinIdleConn    int           `yaml:"min_idle" json:"min_idle"`
+	MaxRetries     int           `yaml:"max_retries" json:"max_retries"`
+	MaxConnAge     time.Duration `yaml:"max_conn_age" json:"max_conn_age"`
+	ConnTimeout    time.Duration `yaml:"conn_timeout" json:"conn_timeout"`
+	DialTimeout    time.Duration `yaml:"dial_timeout" json:"dial_timeout"`
+	ReadTimeout    time.Duration `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout   time.Duration `yaml:"write_timeout" json:"write_timeout"`
+	HealthCheck    time.Duration `yaml:"health_check" json:"health_check"`
+	RetryOnStart   bool          `yaml:"retry_on_start" json:"retry_on_start"`
+	StartupRetries int           `yaml:"startup_retries" json:"startup_retries"`
+
+	// Explicit connection settings that override those parsed from
+	// RedisURL when set. RedisDB is a pointer so that an explicit 0 can
+	// override the URL's database; nil keeps it.
+	RedisDB       *int   `yaml:"redis_db" json:"redis_db"`
+	RedisUsername string `yaml:"redis_username" json:"redis_username"`
+	RedisPassword string `yaml:"redis_password" json:"redis_password"`
+
+	// HotKeysKey names a sorted set counting hits per key, used to warm
+	// up new tiers. Empty disables tracking. Hits are written in batches;
+	// the set keeps its HotKeysLimit hottest keys (default 10000) and
+	// expires HotKeysTTL (default 24h) after the last batch.
+	HotKeysKey   string        `yaml:"hot_keys_key" json:"hot_keys_key"`
+	HotKeysLimit int           `yaml:"hot_keys_limit" json:"hot_keys_limit"`
+	HotKeysTTL   time.Duration `yaml:"hot_keys_ttl" json:"hot_keys_ttl"`
+
+	// DeleteBatchSize caps the keys per DEL command (default 500).
+	DeleteBatchSize int `yaml:"delete_batch_size" json:"delete_batch_size"`
+
+	// ScanCount is the COUNT hint of the SCANs behind Clear, Len, Range
+	// and the prefix/pattern operations (default 1000). Smaller values
+	// make each call lighter on a shared server at the cost of more
+	// round trips.
+	ScanCount int `yaml:"scan_count" json:"scan_count"`
+
+	// Circuit breaker: after BreakerThreshold consecutive connection
+	// failures, commands fail fast for BreakerCooldown. Zero disables it.
+	// A Redis cache given a client it does not own has no breaker.
+	BreakerThreshold int           `yaml:"breaker_threshold" json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" json:"breaker_cooldown"`
+
+	// MemcachedServers lists the host:port addresses of a memcached
+	// cache. PoolSize, ConnTimeout, DialTimeout and ReadTimeout apply to
+	// it as they do to Redis.
+	MemcachedServers []string `yaml:"memcached_servers" json:"memcached_servers"`
+
+	// BadgerPath is the directory of a badger cache's database, created
+	// if missing. CleanupInterval paces its value log GC.
+	BadgerPath string `yaml:"badger_path" json:"badger_path"`
 }
 
 /* ------------------ Loaders ------------------ */
 
+// Load parses YAML config, applies environment overrides and validates it.
 func Load(data []byte) (Config, error) {
 	cfg := DefaultConfig()
 
@@ -82,6 +246,22 @@ func Load(data []byte) (Config, error) {
 		return cfg, fmt.Errorf("yaml unmarshal: %w", err)
 	}
 
+	return finish(cfg)
+}
+
+// LoadJSON is Load for JSON input. Keys match the YAML names; durations
+// may be strings such as "5m" or integer nanoseconds.
+func LoadJSON(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	return finish(cfg)
+}
+
+func finish(cfg Config) (Config, error) {
 	applyEnvOverrides(&cfg)
 
 	if err := cfg.Normalize(); err != nil {
@@ -95,20 +275,36 @@ func Load(data []byte) (Config, error) {
 	return cfg, nil
 }
 
+// LoadFromFile loads path as JSON if it has a .json extension and as YAML
+// otherwise.
 func LoadFromFile(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return Config{}, fmt.Errorf("read config file: %w", err)
 	}
+	return loadFile(path, data)
+}
+
+// loadFile parses data read from path by its extension.
+func loadFile(path string, data []byte) (Config, error) {
+	if IsJSONFile(path) {
+		return LoadJSON(data)
+	}
 	return Load(data)
 }
 
+// IsJSONFile reports whether path names a JSON config file.
+func IsJSONFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
 /* ------------------ Normalize ------------------ */
 
 // Normalize sets derived or default values WITHOUT validation logic.
 func (c *Config) Normalize() error {
 	c.Type = Type(strings.ToLower(string(c.Type)))
 	c.Prefix = strings.TrimSpace(c.Prefix)
+	c.Version = strings.TrimSpace(c.Version)
 
 	if c.EvictionPolicy == "" {
 		c.EvictionPolicy = EvictLRU
@@ -118,11 +314,28 @@ func (c *Config) Normalize() error {
 		c.CleanupInterval = time.Minute
 	}
 
+	if len(c.TenantQuotas) > 0 && c.TenantSeparator == "" {
+		c.TenantSeparator = ":"
+	}
+
 	return nil
 }
 
 /* ------------------ Validation ------------------ */
 
+// HashedKeyLength is the length of the digest that replaces a key over
+// MaxKeyLength: "sha256:" and 64 hex digits.
+const HashedKeyLength = len("sha256:") + 64
+
+// keyPrefixLen is the length of the prefix and version segment put in
+// front of every key.
+func (c *Config) keyPrefixLen() int {
+	if c.Version != "" {
+		return len(c.Prefix) + len("v"+c.Version+":")
+	}
+	return len(c.Prefix)
+}
+
 func (c *Config) Validate() error {
 	if !c.Type.Valid() {
 		return fmt.Errorf("invalid cache type: %q", c.Type)
@@ -132,25 +345,90 @@ func (c *Config) Validate() error {
 		return errors.New("ttl must be > 0")
 	}
 
+	if c.TTLJitter < 0 || c.TTLJitter >= 1 {
+		return errors.New("ttl_jitter must be in [0, 1)")
+	}
+
+	if c.MaxKeyLength < 0 {
+		return errors.New("max_key_length must be >= 0")
+	}
+	if n := c.keyPrefixLen() + HashedKeyLength; c.MaxKeyLength > 0 && c.MaxKeyLength < n {
+		return fmt.Errorf("max_key_length must be 0 or >= %d to fit the prefix and a hashed key", n)
+	}
+
+	if c.PipelineConcurrency < 0 {
+		return errors.New("pipeline_concurrency must be >= 0")
+	}
+
+	if c.OperationTimeout < 0 {
+		return errors.New("operation_timeout must be >= 0")
+	}
+
+	if c.LockTTL < 0 || c.LockRenewInterval < 0 {
+		return errors.New("lock_ttl and lock_renew_interval must be >= 0")
+	}
+	if c.LockTTL > 0 && c.LockRenewInterval >= c.LockTTL {
+		return errors.New("lock_renew_interval must be < lock_ttl")
+	}
+
+	if c.WriteBehindInterval < 0 || c.WriteBehindBuffer < 0 {
+		return errors.New("write_behind_interval and write_behind_buffer must be >= 0")
+	}
+
+	if c.TrackTopKeys < 0 {
+		return errors.New("track_top_keys must be >= 0")
+	}
+
+	if !c.FallbackMode.Valid() {
+		return fmt.Errorf("invalid fallback_mode: %q", c.FallbackMode)
+	}
+
+	if c.WarmupKeys < 0 {
+		return errors.New("warmup_keys must be >= 0")
+	}
+
 	switch c.Type {
 	case TypeMemory:
 		return validateMemory(c)
 	case TypeRedis:
 		return validateRedis(c)
+	case TypeMemcached:
+		return validateMemcached(c)
+	case TypeBadger:
+		if c.BadgerPath == "" {
+			return errors.New("badger_path is required for badger cache")
+		}
+		return nil
 	default:
 		return fmt.Errorf("unsupported cache type: %s", c.Type)
 	}
 }
 
 func validateMemory(c *Config) error {
-	if c.MaxSize <= 0 && c.MaxEntries <= 0 && c.MaxBytes <= 0 {
-		return errors.New("one of max_size, max_entries, or max_bytes must be set")
+	if c.MaxSize <= 0 && c.MaxEntries <= 0 && c.MaxBytes <= 0 && c.MaxCost <= 0 {
+		return errors.New("one of max_size, max_entries, max_bytes, or max_cost must be set")
+	}
+	if c.MaxCost < 0 {
+		return errors.New("max_cost must be >= 0")
 	}
 
 	if !c.EvictionPolicy.Valid() {
 		return fmt.Errorf("invalid eviction_policy: %q", c.EvictionPolicy)
 	}
 
+	if c.StaleGrace < 0 {
+		return errors.New("stale_grace must be >= 0")
+	}
+
+	for tenant, q := range c.TenantQuotas {
+		if q.MaxEntries < 0 || q.MaxBytes < 0 {
+			return fmt.Errorf("tenant_quotas[%q] limits must be >= 0", tenant)
+		}
+		if q.MaxEntries == 0 && q.MaxBytes == 0 {
+			return fmt.Errorf("tenant_quotas[%q] needs max_entries or max_bytes", tenant)
+		}
+	}
+
 	return nil
 }
 
@@ -159,6 +437,10 @@ func validateRedis(c *Config) error {
 		return errors.New("redis_url is required for redis cache")
 	}
 
+	if c.RedisDB != nil && *c.RedisDB < 0 {
+		return errors.New("redis_db must be >= 0")
+	}
+
 	if c.PoolSize <= 0 {
 		return errors.New("pool_size must be > 0")
 	}
@@ -167,6 +449,44 @@ func validateRedis(c *Config) error {
 		return errors.New("conn_timeout must be > 0")
 	}
 
+	if c.DeleteBatchSize < 0 {
+		return errors.New("delete_batch_size must be >= 0")
+	}
+
+	if c.ScanCount < 0 {
+		return errors.New("scan_count must be >= 0")
+	}
+
+	if c.HotKeysLimit < 0 || c.HotKeysTTL < 0 {
+		return errors.New("hot_keys_limit and hot_keys_ttl must be >= 0")
+	}
+
+	if c.BreakerThreshold < 0 || c.BreakerCooldown < 0 {
+		return errors.New("breaker_threshold and breaker_cooldown must be >= 0")
+	}
+
+	return nil
+}
+
+func validateMemcached(c *Config) error {
+	if len(c.MemcachedServers) == 0 {
+		return errors.New("memcached_servers is required for memcached cache")
+	}
+
+	for _, s := range c.MemcachedServers {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("memcached_servers must not contain empty addresses")
+		}
+	}
+
+	if c.PoolSize <= 0 {
+		return errors.New("pool_size must be > 0")
+	}
+
+	if c.ConnTimeout <= 0 || c.ReadTimeout <= 0 {
+		return errors.New("conn_timeout and read_timeout must be > 0")
+	}
+
 	return nil
 }
 
@@ -207,6 +527,10 @@ func applyEnvOverrides(c *Config) {
 		c.Prefix = v
 	}
 
+	if v := os.Getenv("CACHE_VERSION"); v != "" {
+		c.Version = v
+	}
+
 	if v := os.Getenv("CACHE_TTL"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			c.TTL = d
@@ -216,4 +540,8 @@ func applyEnvOverrides(c *Config) {
 	if v := os.Getenv("REDIS_URL"); v != "" {
 		c.RedisURL = v
 	}
+
+	if v := os.Getenv("MEMCACHED_SERVERS"); v != "" {
+		c.MemcachedServers = strings.Split(v, ",")
+	}
 }
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/* ------------------ Comparison ------------------ */
+
+// FieldDiff is one setting that differs between two configs. Field is its
+// YAML name; Value and Other are the formatted values of the receiver and
+// of the config it was compared with. Durations read as "5m0s".
+type FieldDiff struct {
+	Field string
+	Value string
+	Other string
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Field, d.Value, d.Other)
+}
+
+// redactedFields are compared but never printed.
+var redactedFields = map[string]bool{"redis_password": true}
+
+// Equal reports whether c and other have the same settings. Hooks, Tracer
+// and Logger, which cannot be loaded from YAML either, are ignored.
+func (c Config) Equal(other Config) bool {
+	return len(c.Diff(other)) == 0
+}
+
+// Diff lists the settings that differ between c and other, in field
+// order, skipping the same fields as Equal. RedisPassword is shown as
+// "[redacted]" so the result can be logged.
+func (c Config) Diff(other Config) []FieldDiff {
+	a, b := reflect.ValueOf(c), reflect.ValueOf(other)
+
+	var out []FieldDiff
+	for i := range configType.NumField() {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fa, fb := a.Field(i), b.Field(i)
+		if sameValue(fa, fb) {
+			continue
+		}
+		va, vb := fa.Interface(), fb.Interface()
+
+		d := FieldDiff{Field: name, Value: formatField(va), Other: formatField(vb)}
+		if redactedFields[name] {
+			d.Value, d.Other = redact(va), redact(vb)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// sameValue is reflect.DeepEqual, except that nil and empty maps or
+// slices, which configure the same thing, are equal.
+func sameValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func formatField(v any) string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "unset"
+		}
+		v = rv.Elem().Interface()
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func redact(v any) string {
+	if v == "" {
+		return `""`
+	}
+	return "[redacted]"
+}
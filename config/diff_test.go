@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffIdenticalConfigs(t *testing.T) {
+	a, b := DefaultConfig(), DefaultConfig()
+	if d := a.Diff(b); len(d) != 0 {
+		t.Errorf("Diff = %v, want none", d)
+	}
+	if !a.Equal(b) {
+		t.Error("Equal = false for identical configs")
+	}
+
+	// Nil and empty collections, and fields YAML cannot set, do not count.
+	b.TenantQuotas = map[string]TenantQuota{}
+	b.MemcachedServers = []string{}
+	b.Logger = slog.Default()
+	b.Hooks.OnHit = func(string) {}
+	if d := a.Diff(b); len(d) != 0 {
+		t.Errorf("Diff = %v, want none", d)
+	}
+}
+
+func TestDiffSingleField(t *testing.T) {
+	a, b := DefaultConfig(), DefaultConfig()
+	b.MaxEntries = a.MaxEntries + 1
+
+	want := []FieldDiff{{
+		Field: "max_entries",
+		Value: strconv.Itoa(a.MaxEntries),
+		Other: strconv.Itoa(b.MaxEntries),
+	}}
+	if d := a.Diff(b); !reflect.DeepEqual(d, want) {
+		t.Errorf("Diff = %v, want %v", d, want)
+	}
+	if a.Equal(b) {
+		t.Error("Equal = true for different configs")
+	}
+}
+
+func TestDiffFormatsValues(t *testing.T) {
+	a, b := DefaultConfig(), DefaultConfig()
+	a.TTL, b.TTL = 5*time.Minute, 90*time.Second
+	a.Prefix, b.Prefix = "app:", "svc:"
+	a.RedisPassword, b.RedisPassword = "", "hunter2"
+
+	want := map[string]FieldDiff{
+		"ttl":            {Field: "ttl", Value: "5m0s", Other: "1m30s"},
+		"prefix":         {Field: "prefix", Value: `"app:"`, Other: `"svc:"`},
+		"redis_password": {Field: "redis_password", Value: `""`, Other: "[redacted]"},
+	}
+	d := a.Diff(b)
+	if len(d) != len(want) {
+		t.Fatalf("Diff = %v, want %d fields", d, len(want))
+	}
+	for _, got := range d {
+		if got != want[got.Field] {
+			t.Errorf("diff %s = %+v, want %+v", got.Field, got, want[got.Field])
+		}
+		if strings.Contains(got.String(), "hunter2") {
+			t.Errorf("String() leaks the password: %s", got)
+		}
+	}
+	if s := want["ttl"].String(); s != "ttl: 5m0s -> 1m30s" {
+		t.Errorf("String() = %q", s)
+	}
+}
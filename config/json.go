@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ------------------ JSON ------------------ */
+
+var (
+	configType   = reflect.TypeOf(Config{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// UnmarshalJSON decodes c like encoding/json would, except that duration
+// fields also accept strings in time.ParseDuration form ("30s", "5m").
+// Fields missing from data keep their current values.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	durations := durationFields("json")
+	for name, v := range raw {
+		if !isDurationKey(durations, name) || len(v) == 0 || v[0] != '"' {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		raw[name] = json.RawMessage(strconv.FormatInt(int64(d), 10))
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	// plain has Config's fields but not this method.
+	type plain Config
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// isDurationKey reports whether key names a duration field, ignoring case
+// as encoding/json does when matching keys to fields.
+func isDurationKey(durations map[string]bool, key string) bool {
+	if durations[key] {
+		return true
+	}
+	for name := range durations {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const equivalentYAML = `
+type: redis
+redis_url: "redis://localhost:6379/0"
+ttl: 10m
+prefix: "app:"
+pool_size: 20
+min_idle: 5
+conn_timeout: 2s
+read_timeout: 500ms
+refresh_on_hit: true
+ttl_jitter: 0.1
+tenant_quotas:
+  acme:
+    max_entries: 100
+memcached_servers: ["a:11211", "b:11211"]
+`
+
+const equivalentJSON = `{
+	"type": "redis",
+	"redis_url": "redis://localhost:6379/0",
+	"ttl": "10m",
+	"prefix": "app:",
+	"pool_size": 20,
+	"min_idle": 5,
+	"conn_timeout": "2s",
+	"read_timeout": 500000000,
+	"refresh_on_hit": true,
+	"ttl_jitter": 0.1,
+	"tenant_quotas": {"acme": {"max_entries": 100}},
+	"memcached_servers": ["a:11211", "b:11211"]
+}`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJSONFileMatchesYAMLFile(t *testing.T) {
+	fromYAML, err := LoadFromFile(writeConfig(t, "cache.yaml", equivalentYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := LoadFromFile(writeConfig(t, "cache.JSON", equivalentJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("JSON and YAML configs differ:\nyaml: %+v\njson: %+v", fromYAML, fromJSON)
+	}
+	if fromJSON.TTL != 10*time.Minute || fromJSON.ReadTimeout != 500*time.Millisecond {
+		t.Errorf("durations: ttl=%v read_timeout=%v", fromJSON.TTL, fromJSON.ReadTimeout)
+	}
+	// Fields absent from the file keep their defaults.
+	if fromJSON.DialTimeout != DefaultConfig().DialTimeout {
+		t.Errorf("dial_timeout = %v, want default", fromJSON.DialTimeout)
+	}
+}
+
+func TestUnmarshalJSONDurationKeysIgnoreCase(t *testing.T) {
+	var c Config
+	if err := json.Unmarshal([]byte(`{"TTL": "10m", "Read_Timeout": "500ms", "prefix": "app:"}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.TTL != 10*time.Minute || c.ReadTimeout != 500*time.Millisecond {
+		t.Errorf("ttl=%v read_timeout=%v, want 10m and 500ms", c.TTL, c.ReadTimeout)
+	}
+	if c.Prefix != "app:" {
+		t.Errorf("prefix = %q, want app:", c.Prefix)
+	}
+}
+
+func TestLoadJSONErrors(t *testing.T) {
+	for name, in := range map[string]string{
+		"syntax":   `{"type": `,
+		"duration": `{"ttl": "ten minutes"}`,
+		"invalid":  `{"type": "nosuchbackend"}`,
+	} {
+		if _, err := LoadJSON([]byte(in)); err == nil {
+			t.Errorf("%s: LoadJSON(%s) succeeded", name, in)
+		}
+	}
+}
+
+func TestWatchParsesJSONFile(t *testing.T) {
+	path, ch := watchFile(t, "cache.json", `{"type": "memory", "ttl": "5m"}`)
+
+	// A repeated key is valid JSON (the last one wins) but not YAML, so
+	// this only reloads if the file is parsed as JSON.
+	writeFile(t, path, `{"type": "memory", "ttl": "1m", "ttl": "15m", "max_size": 7}`)
+
+	r := next(t, ch)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.cfg.TTL != 15*time.Minute || r.cfg.MaxSize != 7 {
+		t.Errorf("reloaded TTL=%v MaxSize=%d", r.cfg.TTL, r.cfg.MaxSize)
+	}
+}
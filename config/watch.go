@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+/* ------------------ Watch ------------------ */
+
+// Watch reloads path whenever its content changes and calls onChange with
+// the parsed, validated config (JSON for .json files, YAML otherwise), or
+// with the error that prevented loading it. It watches the file's
+// directory through fsnotify, so edits that replace the file (atomic
+// renames by editors or config management) are seen too. Saves that leave
+// the content unchanged are ignored. The returned stop function ends
+// watching.
+//
+// Only some fields can be applied to a running cache; see the memory
+// cache's Reconfigure.
+func Watch(path string, onChange func(Config, error)) (stop func(), err error) {
+	path = filepath.Clean(path)
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		failing := false
+		for {
+			select {
+			case <-done:
+				return
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				onChange(Config{}, err)
+				continue
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) != path || ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				// Editors may briefly remove the file while saving; report
+				// it once and keep the last known content.
+				if !failing {
+					onChange(Config{}, err)
+				}
+				failing = true
+				continue
+			}
+			failing = false
+			if bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+
+			onChange(loadFile(path, data))
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			_ = w.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type reload struct {
+	cfg Config
+	err error
+}
+
+func watchFile(t *testing.T, name, content string) (string, <-chan reload) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeFile(t, path, content)
+
+	ch := make(chan reload, 10)
+	stop, err := Watch(path, func(cfg Config, err error) { ch <- reload{cfg, err} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return path, ch
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func next(t *testing.T, ch <-chan reload) reload {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload within 5s")
+		return reload{}
+	}
+}
+
+func TestWatchFileEditFiresCallback(t *testing.T) {
+	path, ch := watchFile(t, "cache.yaml", "type: memory\nttl: 5m\nmax_size: 100\n")
+
+	writeFile(t, path, "type: memory\nttl: 10m\nmax_size: 200\ncleanup_interval: 30s\n")
+
+	r := next(t, ch)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.cfg.TTL != 10*time.Minute || r.cfg.MaxSize != 200 || r.cfg.CleanupInterval != 30*time.Second {
+		t.Errorf("reloaded TTL=%v MaxSize=%d CleanupInterval=%v", r.cfg.TTL, r.cfg.MaxSize, r.cfg.CleanupInterval)
+	}
+}
+
+func TestWatchAtomicReplace(t *testing.T) {
+	path, ch := watchFile(t, "cache.yaml", "type: memory\nttl: 5m\n")
+
+	tmp := path + ".tmp"
+	writeFile(t, tmp, "type: memory\nttl: 1h\n")
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	r := next(t, ch)
+	if r.err != nil || r.cfg.TTL != time.Hour {
+		t.Errorf("reload = %v, %v; want TTL 1h", r.cfg.TTL, r.err)
+	}
+}
+
+func TestWatchReportsInvalidConfig(t *testing.T) {
+	path, ch := watchFile(t, "cache.yaml", "type: memory\n")
+
+	writeFile(t, path, "type: nosuchbackend\n")
+	if r := next(t, ch); r.err == nil {
+		t.Error("invalid config reloaded without error")
+	}
+}
+
+func TestWatchIgnoresUnchangedContentAndOtherFiles(t *testing.T) {
+	path, ch := watchFile(t, "cache.yaml", "type: memory\nttl: 5m\n")
+
+	writeFile(t, path, "type: memory\nttl: 5m\n")
+	writeFile(t, filepath.Join(filepath.Dir(path), "other.yaml"), "type: memory\nttl: 1m\n")
+
+	select {
+	case r := <-ch:
+		t.Fatalf("unexpected reload: %+v", r)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+/* ------------------ YAML ------------------ */
+
+// UnmarshalYAML decodes c like yaml.v3 would, except that duration fields
+// also accept bare integers, read as nanoseconds for compatibility with
+// configs written against the raw time.Duration encoding. Strings such as
+// "5m" or "500ms" work as usual.
+func (c *Config) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		durations := durationFields("yaml")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			if !durations[key.Value] || val.Kind != yaml.ScalarNode || val.Tag != "!!int" {
+				continue
+			}
+			n, err := strconv.ParseInt(val.Value, 0, 64)
+			if err != nil {
+				continue // let Decode report it
+			}
+			val.Value = time.Duration(n).String()
+			val.Tag = "!!str"
+		}
+	}
+
+	// plain has Config's fields but not this method.
+	type plain Config
+	return node.Decode((*plain)(c))
+}
+
+// durationFields returns the names, under the given struct tag, of the
+// Config fields of type time.Duration.
+func durationFields(tag string) map[string]bool {
+	out := make(map[string]bool)
+	t := configType
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Type != durationType {
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+			out[name] = true
+		}
+	}
+	return out
+}
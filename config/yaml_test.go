@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadYAMLDurations(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"minutes", "ttl: 10m", 10 * time.Minute},
+		{"milliseconds", "ttl: 500ms", 500 * time.Millisecond},
+		{"compound", "ttl: 1h30m", 90 * time.Minute},
+		{"bare nanoseconds", "ttl: 300000000000", 5 * time.Minute},
+		{"quoted", `ttl: "45s"`, 45 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.TTL != tt.want {
+				t.Errorf("ttl = %v, want %v", cfg.TTL, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadYAMLDurationsOnEveryField(t *testing.T) {
+	cfg, err := Load([]byte("ttl: 5m\ncleanup_interval: 30s\nread_timeout: 250ms\nconn_timeout: 2000000000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TTL != 5*time.Minute || cfg.CleanupInterval != 30*time.Second ||
+		cfg.ReadTimeout != 250*time.Millisecond || cfg.ConnTimeout != 2*time.Second {
+		t.Errorf("ttl=%v cleanup=%v read=%v conn=%v", cfg.TTL, cfg.CleanupInterval, cfg.ReadTimeout, cfg.ConnTimeout)
+	}
+}
+
+func TestLoadYAMLDurationErrors(t *testing.T) {
+	for _, in := range []string{"ttl: ten minutes", "ttl: [1, 2]"} {
+		if _, err := Load([]byte(in)); err == nil {
+			t.Errorf("Load(%q) succeeded", in)
+		}
+	}
+}
+
+func TestDurationFieldsFindsTaggedDurations(t *testing.T) {
+	fields := durationFields("yaml")
+	for _, name := range []string{"ttl", "cleanup_interval", "read_timeout"} {
+		if !fields[name] {
+			t.Errorf("%s not recognised as a duration field", name)
+		}
+	}
+	if fields["pool_size"] || fields["prefix"] {
+		t.Error("non-duration fields reported")
+	}
+}
@@ -159,44 +159,28 @@ func ecommerceCacheExample() {
 }
 
 func cacheWithRetry() {
-	// acache, err := cache.NewMemoryAdvanced[string]()
-	acache, err := cache.NewAdvancedRedis[string]("redis://localhost:6379/0")
+	// Connection and lock errors are retried with jittered exponential
+	// backoff; misses and serialization errors are returned at once.
+	cfg := cache.NewBuilder().
+		WithRedis("redis://localhost:6379/0").
+		WithPoolSize(10).
+		MustBuild()
+
+	acache, err := cache.NewAdvanced[string](cfg, cache.WithRetry[string](3, 100*time.Millisecond))
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer acache.Close()
 
-	ctx := context.Background()
-	key := cache.NewKeyBuilder("retry").Add("example").Build()
-
-	// Retry logic for cache operations
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		// Use shorter timeout for retries
-		timeout := time.Duration(i+1) * time.Second
-		opCtx, cancel := cache.WithContext(ctx, timeout)
-
-		err := acache.Set(opCtx, key, fmt.Sprintf("value-attempt-%d", i+1), 5*time.Minute)
-		cancel()
-
-		if err == nil {
-			fmt.Printf("Success on attempt %d\n", i+1)
-			break
-		}
-
-		lastErr = err
-		fmt.Printf("Attempt %d failed: %v\n", i+1, err)
-
-		if i < maxRetries-1 {
-			time.Sleep(time.Duration(i+1) * 100 * time.Millisecond) // Exponential backoff
-		}
-	}
+	ctx, cancel := cache.WithContext(context.Background(), 5*time.Second)
+	defer cancel()
 
-	if lastErr != nil {
-		log.Printf("All retry attempts failed: %v", lastErr)
+	key := cache.NewKeyBuilder("retry").Add("example").Build()
+	if err := acache.Set(ctx, key, "value", 5*time.Minute); err != nil {
+		log.Printf("All retry attempts failed: %v", err)
+		return
 	}
+	fmt.Println("Stored value with retries enabled")
 }
 
 func batchOperationsExample() {
@@ -296,25 +280,15 @@ func hierarchicalKeysExample() {
 }
 
 func dynamicKeyBuilding() {
-	// Key builder for API responses with parameters
-	apiKeyBuilder := cache.NewKeyBuilder("api")
-
-	// Function to build cache key for API responses
+	// Function to build cache key for API responses. Parameters are added
+	// in key order, and keys over 200 bytes are hashed.
 	buildAPIKey := func(method, endpoint string, params map[string]string) string {
-		builder := apiKeyBuilder.Add(method).Add(endpoint)
-
-		// Add sorted parameters for consistent keys
-		keys := make([]string, 0, len(params))
-		for k := range params {
-			keys = append(keys, k)
-		}
-		// sort.Strings(keys) // Uncomment if parameter order matters
-
-		for _, k := range keys {
-			builder.Add(k).Add(params[k])
-		}
-
-		return builder.Build()
+		return cache.NewKeyBuilder("api").
+			WithMaxLen(200).
+			Add(method).
+			Add(endpoint).
+			AddSorted(params).
+			Build()
 	}
 
 	// Example API calls with different parameters
@@ -305,8 +305,12 @@ func exampleCacheStrategies() {
 	// Cache-Aside Pattern
 	fmt.Println("  Cache-Aside Pattern:")
 	cacheAside := func(key string) (string, error) {
-		// 1. Try cache first
-		if val, err := ac.Get(ctx, key); err == nil {
+		// 1. Try cache first; only a clean miss falls through
+		val, ok, err := ac.TryGet(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
 			fmt.Println("    Cache hit!")
 			return val, nil
 		}
@@ -0,0 +1,172 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+/* ------------------ Types ------------------ */
+
+// fallbackCache serves from primary and keeps secondary as a degraded-mode
+// copy: every write is mirrored to it, and it answers reads only while
+// primary is unreachable. Each call tries primary first, so recovery needs
+// no intervention. Writes made during an outage (fail-open) exist only in
+// secondary and are not replayed to primary afterwards.
+type fallbackCache[T any] struct {
+	base      *base.Base
+	primary   interfaces.Cache[T]
+	secondary interfaces.Cache[T]
+	mode      config.FallbackMode
+
+	// calls answered by secondary because primary was down
+	fallbacks atomic.Int64
+}
+
+/* ------------------ Constructor ------------------ */
+
+// New combines primary (typically Redis) with a secondary (typically
+// memory) used while primary is down; cfg.FallbackMode decides whether
+// writes then fail or go to secondary alone.
+func New[T any](
+	primary interfaces.Cache[T],
+	secondary interfaces.Cache[T],
+	cfg config.Config,
+) (*fallbackCache[T], error) {
+	if primary == nil || secondary == nil {
+		return nil, base.WrapError(base.OpInit, base.ErrInvalidConfig, "")
+	}
+
+	mode := cfg.FallbackMode
+	if mode == "" {
+		mode = config.FallbackFailOpen
+	}
+
+	return &fallbackCache[T]{
+		base:      base.NewBase(cfg),
+		primary:   primary,
+		secondary: secondary,
+		mode:      mode,
+	}, nil
+}
+
+/* ------------------ Helpers ------------------ */
+
+// unavailable reports whether err means primary could not be reached, as
+// opposed to a miss, a bad argument or a cancelled context.
+func unavailable(err error) bool {
+	if err == nil || base.IsContextError(err) {
+		return false
+	}
+	if errors.Is(err, base.ErrConnection) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+// write applies op to primary and mirrors it to secondary. When primary is
+// down, fail-open reports secondary's result and fail-closed primary's
+// error.
+func (f *fallbackCache[T]) write(op func(c interfaces.Cache[T]) error) error {
+	err := op(f.primary)
+	if err != nil && !unavailable(err) {
+		return err
+	}
+
+	mirrorErr := op(f.secondary)
+	if err == nil {
+		return nil
+	}
+
+	f.fallbacks.Add(1)
+	if f.mode == config.FallbackFailClosed {
+		return err
+	}
+	return mirrorErr
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (f *fallbackCache[T]) Get(ctx context.Context, key string) (T, error) {
+	val, err := f.primary.Get(ctx, key)
+	if !unavailable(err) {
+		return val, err
+	}
+
+	f.fallbacks.Add(1)
+	return f.secondary.Get(ctx, key)
+}
+
+func (f *fallbackCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	ttl = f.base.WriteTTL(ctx, ttl)
+	ctx = base.WithExactTTL(ctx)
+	return f.write(func(c interfaces.Cache[T]) error {
+		return c.Set(ctx, key, value, ttl)
+	})
+}
+
+func (f *fallbackCache[T]) Delete(ctx context.Context, keys ...string) error {
+	return f.write(func(c interfaces.Cache[T]) error {
+		return c.Delete(ctx, keys...)
+	})
+}
+
+func (f *fallbackCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	ok, err := f.primary.Exists(ctx, key)
+	if !unavailable(err) {
+		return ok, err
+	}
+
+	f.fallbacks.Add(1)
+	return f.secondary.Exists(ctx, key)
+}
+
+func (f *fallbackCache[T]) Clear(ctx context.Context) error {
+	return f.write(func(c interfaces.Cache[T]) error {
+		return c.Clear(ctx)
+	})
+}
+
+// Len reports primary's size, or secondary's while primary is down.
+func (f *fallbackCache[T]) Len(ctx context.Context) (int, error) {
+	n, err := f.primary.Len(ctx)
+	if !unavailable(err) {
+		return n, err
+	}
+	return f.secondary.Len(ctx)
+}
+
+// Ping checks primary only: the cache is healthy when it is not degraded.
+func (f *fallbackCache[T]) Ping(ctx context.Context) error {
+	return f.primary.Ping(ctx)
+}
+
+func (f *fallbackCache[T]) Close() error {
+	return errors.Join(f.primary.Close(), f.secondary.Close())
+}
+
+/* ------------------ Stats ------------------ */
+
+// Stats reports primary's stats; Fallbacks counts the calls the secondary
+// answered instead.
+func (f *fallbackCache[T]) Stats(ctx context.Context) metrics.CacheStats {
+	stats := metrics.NewStatsBuilder("fallback").
+		WithUptime(f.base.Uptime()).
+		WithStartedAt(f.base.StartedAt()).
+		Build()
+
+	if sp, ok := f.primary.(interfaces.StatProvider); ok {
+		stats = sp.Stats(ctx)
+	}
+	stats.Fallbacks = f.fallbacks.Load()
+	return stats
+}
@@ -0,0 +1,172 @@
+package fallback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/memory"
+	"github.com/os-golib/go-cache/redis"
+)
+
+type ttlCache[T any] interface {
+	interfaces.Cache[T]
+	interfaces.TTLGetter[T]
+}
+
+func newMemory[T any](t *testing.T, cfg config.Config) ttlCache[T] {
+	t.Helper()
+	m, err := memory.NewMemory[T](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+// newOutageCache builds a fallback cache over a miniredis primary and a
+// memory secondary; closing the returned server simulates the outage.
+func newOutageCache(t *testing.T, mode config.FallbackMode) (*fallbackCache[string], *miniredis.Miniredis, ttlCache[string]) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	cfg.FallbackMode = mode
+
+	// The client does not retry, so an outage fails each call at once.
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr(), MaxRetries: -1, DialerRetries: 1})
+	rcfg := cfg
+	rcfg.Type = config.TypeRedis
+	primary, err := redis.NewFromClient[string](client, rcfg, redis.WithClientOwnership[string](true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = primary.Close() })
+
+	secondary := newMemory[string](t, cfg)
+	f, err := New[string](primary, secondary, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, mr, secondary
+}
+
+func TestOutageServesMirroredKeys(t *testing.T) {
+	ctx := context.Background()
+	f, mr, _ := newOutageCache(t, config.FallbackFailOpen)
+
+	if err := f.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.Stats(ctx).Fallbacks; n != 0 {
+		t.Errorf("Fallbacks = %d with primary up", n)
+	}
+
+	mr.Close()
+	if v, err := f.Get(ctx, "k"); err != nil || v != "v" {
+		t.Errorf("Get during outage = %q, %v; want the mirrored value", v, err)
+	}
+	if ok, err := f.Exists(ctx, "k"); err != nil || !ok {
+		t.Errorf("Exists during outage = %v, %v", ok, err)
+	}
+	if _, err := f.Get(ctx, "absent"); !base.IsCacheMiss(err) {
+		t.Errorf("Get(absent) during outage = %v, want a miss", err)
+	}
+	if err := f.Ping(ctx); err == nil {
+		t.Error("Ping reported healthy while primary is down")
+	}
+	if n := f.Stats(ctx).Fallbacks; n < 3 {
+		t.Errorf("Fallbacks = %d, want every outage call counted", n)
+	}
+}
+
+func TestOutageWriteModes(t *testing.T) {
+	ctx := context.Background()
+	for _, mode := range []config.FallbackMode{config.FallbackFailOpen, config.FallbackFailClosed} {
+		t.Run(string(mode), func(t *testing.T) {
+			f, mr, secondary := newOutageCache(t, mode)
+			mr.Close()
+
+			err := f.Set(ctx, "k", "v", time.Minute)
+			if mode == config.FallbackFailOpen && err != nil {
+				t.Errorf("fail-open Set = %v, want success", err)
+			}
+			if mode == config.FallbackFailClosed && err == nil {
+				t.Error("fail-closed Set succeeded while primary is down")
+			}
+			// Both modes still mirror the write.
+			if v, err := secondary.Get(ctx, "k"); err != nil || v != "v" {
+				t.Errorf("secondary Get = %q, %v", v, err)
+			}
+		})
+	}
+}
+
+func TestPrimaryServesAgainAfterRecovery(t *testing.T) {
+	ctx := context.Background()
+	f, mr, secondary := newOutageCache(t, config.FallbackFailOpen)
+
+	if err := f.Set(ctx, "k", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	mr.Close()
+	_ = secondary.Set(ctx, "k", "stale", time.Minute)
+	if v, _ := f.Get(ctx, "k"); v != "stale" {
+		t.Fatalf("Get during outage = %q, want the secondary's value", v)
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := f.Get(ctx, "k"); err != nil || v != "old" {
+		t.Errorf("Get after recovery = %q, %v; want the primary's value", v, err)
+	}
+}
+
+func TestPrimaryMissIsNotAnOutage(t *testing.T) {
+	ctx := context.Background()
+	f, _, secondary := newOutageCache(t, config.FallbackFailOpen)
+
+	_ = secondary.Set(ctx, "only-secondary", "v", time.Minute)
+	if _, err := f.Get(ctx, "only-secondary"); !base.IsCacheMiss(err) {
+		t.Errorf("Get = %v, want the primary's miss", err)
+	}
+	if n := f.Stats(ctx).Fallbacks; n != 0 {
+		t.Errorf("Fallbacks = %d, a miss is not a fallback", n)
+	}
+}
+
+func TestSetJittersTTLOnce(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	cfg.TTLJitter = 0.5
+	primary, secondary := newMemory[string](t, cfg), newMemory[string](t, cfg)
+	f, err := New[string](primary, secondary, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if err := f.Set(ctx, key, "v", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		_, ttl1, err := primary.GetWithTTL(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, ttl2, err := secondary.GetWithTTL(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := (ttl1 - ttl2).Abs(); d > 50*time.Millisecond {
+			t.Errorf("%s: primary TTL %v, secondary TTL %v", key, ttl1, ttl2)
+		}
+	}
+}
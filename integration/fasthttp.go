@@ -25,6 +25,14 @@ type HTTPCacheOptions struct {
 	CacheableStatuses []int
 	VaryHeaders       []string
 	BypassHeader      string
+	// CachedHeaders lists the response headers stored and replayed on a
+	// hit. Empty means DefaultCachedHeaders.
+	CachedHeaders []string
+	// MaxTTL caps TTLs derived from upstream Cache-Control/Expires.
+	// Zero means no cap.
+	MaxTTL time.Duration
+	// WeakETag marks generated ETags as weak (W/"...").
+	WeakETag bool
 }
 
 // DefaultHTTPCacheOptions returns default options
@@ -35,6 +43,7 @@ func DefaultHTTPCacheOptions() HTTPCacheOptions {
 		CacheableStatuses: []int{200, 203, 204, 206, 300, 301, 308, 404, 405, 410, 414, 501},
 		VaryHeaders:       []string{"Accept", "Accept-Encoding", "Authorization"},
 		BypassHeader:      "X-Cache-Bypass",
+		CachedHeaders:     DefaultCachedHeaders,
 	}
 }
 
@@ -50,7 +59,9 @@ type HTTPCacheMiddleware[T any] struct {
 	shouldSkip func(*fasthttp.RequestCtx) bool
 }
 
-// NewHTTPCache creates a new HTTP cache middleware
+// NewHTTPCache creates a new HTTP cache middleware. T must be able to
+// hold a CachedResponse: []byte, CachedResponse or a generic map. With
+// other types responses are passed through but never cached.
 func NewHTTPCache[T any](
 	cache interfaces.AdvancedCache[T],
 	ttl time.Duration,
@@ -65,7 +76,7 @@ func NewHTTPCache[T any](
 		cache:      cache,
 		ttl:        ttl,
 		opts:       options,
-		serializer: &base.JsonSerializer[T]{},
+		serializer: defaultHTTPSerializer[T](),
 	}
 
 	m.keyGen = m.defaultKeyGenerator()
@@ -135,10 +146,11 @@ func (m *HTTPCacheMiddleware[T]) Handler(
 
 		cached, err := m.cache.Get(cctx, key)
 		if err == nil {
-			m.serveFromCache(ctx, cached)
-			return
-		}
-		if !base.IsCacheMiss(err) {
+			if resp, derr := decodeResponse(m.serializer, cached); derr == nil {
+				m.serveFromCache(ctx, resp)
+				return
+			}
+		} else if !base.IsCacheMiss(err) {
 			// Cache error → fail open
 			next(ctx)
 			return
@@ -149,11 +161,20 @@ func (m *HTTPCacheMiddleware[T]) Handler(
 		next(ctx)
 
 		// Async cache write
-		if m.isCacheableResponse(ctx) {
-			body := append([]byte(nil), ctx.Response.Body()...)
-			go func() {
-				_ = m.cacheResponse(key, body)
-			}()
+		if !m.isCacheableResponse(ctx) {
+			return
+		}
+		ttl, ok := m.opts.responseTTL(
+			string(ctx.Response.Header.Peek("Cache-Control")),
+			string(ctx.Response.Header.Peek("Expires")),
+			time.Now(),
+			m.ttl,
+		)
+		if ok {
+			resp := m.captureResponse(ctx)
+			m.cache.Go(func() {
+				_ = m.cacheResponse(key, resp, ttl)
+			})
 		}
 	}
 }
@@ -162,30 +183,53 @@ func (m *HTTPCacheMiddleware[T]) Handler(
 
 func (m *HTTPCacheMiddleware[T]) serveFromCache(
 	ctx *fasthttp.RequestCtx,
-	cached T,
+	resp CachedResponse,
 ) {
-	body, err := m.serializer.Encode(cached)
-	if err != nil {
-		ctx.Response.Header.Set("X-Cache", "MISS")
+	resp = m.opts.withETag(resp)
+
+	ctx.Response.Reset()
+	for name, value := range resp.Headers {
+		ctx.Response.Header.Set(name, value)
+	}
+	ctx.Response.Header.Set("X-Cache", "HIT")
+
+	if etagMatches(string(ctx.Request.Header.Peek("If-None-Match")), resp.Headers["ETag"]) {
+		ctx.Response.SetStatusCode(fasthttp.StatusNotModified)
+		ctx.Response.SkipBody = true
 		return
 	}
 
-	ctx.Response.ResetBody()
-	ctx.Response.SetBody(body)
-	ctx.Response.Header.Set("X-Cache", "HIT")
-	ctx.Response.Header.SetContentType("application/json; charset=utf-8")
+	ctx.Response.SetStatusCode(resp.StatusCode)
+	ctx.Response.SetBody(resp.Body)
+}
+
+func (m *HTTPCacheMiddleware[T]) captureResponse(
+	ctx *fasthttp.RequestCtx,
+) CachedResponse {
+	resp := CachedResponse{
+		StatusCode: ctx.Response.StatusCode(),
+		Headers:    make(map[string]string),
+		Body:       append([]byte(nil), ctx.Response.Body()...),
+	}
+	for _, name := range m.opts.cachedHeaders() {
+		if v := ctx.Response.Header.Peek(name); len(v) > 0 {
+			resp.Headers[name] = string(v)
+		}
+	}
+	return m.opts.withETag(resp)
 }
 
 func (m *HTTPCacheMiddleware[T]) cacheResponse(
 	key string,
-	body []byte,
+	resp CachedResponse,
+	ttl time.Duration,
 ) error {
-	resp, err := m.serializer.Decode(body)
+	val, err := encodeResponse(m.serializer, resp)
 	if err != nil {
 		return err
 	}
 
-	return m.cache.Set(context.Background(), key, resp, m.ttl)
+	return m.cache.Set(context.Background(), key, val, ttl)
 }
 
 /* ------------------ Cacheability ------------------ */
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// doFast runs h on a fresh request for uri with the given method and
+// request headers.
+func doFast(h fasthttp.RequestHandler, method, uri string, headers ...string) *fasthttp.RequestCtx {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for i := 0; i+1 < len(headers); i += 2 {
+		ctx.Request.Header.Set(headers[i], headers[i+1])
+	}
+	h(&ctx)
+	return &ctx
+}
+
+const htmlPage = "<!doctype html><title>home</title><p>caf\xc3\xa9 & {not json}</p>\n"
+
+func htmlHandler(calls *atomic.Int64) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		calls.Add(1)
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.Response.Header.Set("Content-Type", "text/html; charset=utf-8")
+		ctx.Response.Header.Set("Content-Language", "fr")
+		ctx.Response.Header.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		ctx.SetBodyString(htmlPage)
+	}
+}
+
+func TestFastHTTPCacheReplaysHTMLResponse(t *testing.T) {
+	c := newEntityCache[[]byte](t)
+	var calls atomic.Int64
+	h := NewHTTPCache(c, time.Minute).Handler(htmlHandler(&calls))
+
+	first := doFast(h, fasthttp.MethodGet, "/home")
+	if got := string(first.Response.Header.Peek("X-Cache")); got != "MISS" {
+		t.Fatalf("first X-Cache = %q, want MISS", got)
+	}
+	waitLen(t, c, 1)
+
+	hit := doFast(h, fasthttp.MethodGet, "/home")
+	if got := string(hit.Response.Header.Peek("X-Cache")); got != "HIT" {
+		t.Fatalf("second X-Cache = %q, want HIT", got)
+	}
+	if hit.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("status = %d, want 200", hit.Response.StatusCode())
+	}
+	if got := string(hit.Response.Body()); got != htmlPage {
+		t.Errorf("body = %q, want the original bytes %q", got, htmlPage)
+	}
+	for _, name := range []string{"Content-Type", "Content-Language", "Last-Modified"} {
+		if got, want := string(hit.Response.Header.Peek(name)), string(first.Response.Header.Peek(name)); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+}
+
+func TestFastHTTPCacheKeepsStatus(t *testing.T) {
+	c := newEntityCache[[]byte](t)
+	h := NewHTTPCache(c, time.Minute).Handler(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.Response.Header.Set("Content-Type", "text/plain")
+		ctx.SetBodyString("no such page")
+	})
+
+	doFast(h, fasthttp.MethodGet, "/missing")
+	waitLen(t, c, 1)
+
+	hit := doFast(h, fasthttp.MethodGet, "/missing")
+	if hit.Response.StatusCode() != fasthttp.StatusNotFound || string(hit.Response.Body()) != "no such page" {
+		t.Errorf("replayed %d %q, want 404 %q", hit.Response.StatusCode(), hit.Response.Body(), "no such page")
+	}
+	if got := string(hit.Response.Header.Peek("Content-Type")); got != "text/plain" {
+		t.Errorf("Content-Type = %q, want text/plain", got)
+	}
+}
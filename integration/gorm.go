@@ -2,14 +2,22 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/advanced"
+	"github.com/os-golib/go-cache/internal/base"
 	"github.com/os-golib/go-cache/internal/interfaces"
 	"github.com/os-golib/go-cache/internal/metrics"
+	"github.com/os-golib/go-cache/memory"
 )
 
 /* ------------------ Options ------------------ */
@@ -18,25 +26,74 @@ type GORMOptions struct {
 	DefaultTTL time.Duration
 	KeyPrefix  string
 	SkipCache  bool
-	WarmCache  bool
+	// WarmCache makes NewGORMCache start a background Warm of the whole
+	// table, bounded by AsyncWriteTimeout. Meant for small reference
+	// tables.
+	WarmCache bool
+	// DBBatchSize bounds the number of ids per IN (...) query when loading
+	// several entities from the database. Zero loads them in one query.
+	DBBatchSize int
+	// NegativeTTL caches "record not found" results of GetByID and GetBy
+	// for this long, so absent rows do not hit the database on every lookup.
+	// Zero disables it. Writes through RegisterCallbacks and Invalidate
+	// clear the marker.
+	NegativeTTL time.Duration
+	// AsyncWriteTimeout bounds each background cache fill done by GetByIDs
+	// and Preload (default 5s). MaxAsyncWrites caps how many run at once
+	// (default 64); fills beyond it are skipped, as they only warm the
+	// cache.
+	AsyncWriteTimeout time.Duration
+	MaxAsyncWrites    int
 }
 
+const (
+	defaultAsyncWriteTimeout = 5 * time.Second
+	defaultMaxAsyncWrites    = 64
+)
+
+// ErrRecordNotFound is returned by GetByID and GetBy when the row does not
+// exist, whether the database or a negative-cache entry said so. It wraps
+// gorm.ErrRecordNotFound, so either can be matched with errors.Is.
+var ErrRecordNotFound = fmt.Errorf("gorm cache: %w", gorm.ErrRecordNotFound)
+
 func DefaultGORMOptions() GORMOptions {
 	return GORMOptions{
 		DefaultTTL: 10 * time.Minute,
 		KeyPrefix:  "gorm",
 		SkipCache:  false,
 		WarmCache:  false,
+
+		AsyncWriteTimeout: defaultAsyncWriteTimeout,
+		MaxAsyncWrites:    defaultMaxAsyncWrites,
 	}
 }
 
 /* ------------------ Cache Wrapper ------------------ */
 
 type GORMCache[T any] struct {
-	cache    interfaces.AdvancedCache[T]
-	db       *gorm.DB
-	opts     GORMOptions
-	typeName string
+	cache      interfaces.AdvancedCache[T]
+	queryCache interfaces.AdvancedCache[[]T]
+	db         *gorm.DB
+	opts       GORMOptions
+	typeName   string
+	modelType  reflect.Type
+
+	pkOnce sync.Once
+	pk     *schema.Field
+
+	depMu      sync.RWMutex
+	aggregates KeyDeleter
+	typeDeps   map[string]struct{}
+	idDeps     map[string]map[string]struct{}
+
+	// slots of in-flight background fills
+	fills chan struct{}
+
+	// unique-column entries per primary-key entry, and whether a column
+	// lookup has been negatively cached
+	colMu     sync.Mutex
+	colKeys   map[string]map[string]struct{}
+	colMisses bool
 }
 
 /* ------------------ Constructor ------------------ */
@@ -57,12 +114,81 @@ func NewGORMCache[T any](
 		rt = rt.Elem()
 	}
 
-	return &GORMCache[T]{
-		cache:    cache,
-		db:       db,
-		opts:     options,
-		typeName: rt.Name(),
+	if options.AsyncWriteTimeout <= 0 {
+		options.AsyncWriteTimeout = defaultAsyncWriteTimeout
+	}
+	if options.MaxAsyncWrites <= 0 {
+		options.MaxAsyncWrites = defaultMaxAsyncWrites
+	}
+
+	g := &GORMCache[T]{
+		cache:      cache,
+		queryCache: defaultQueryCache[T](options),
+		db:         db,
+		opts:       options,
+		typeName:   rt.Name(),
+		modelType:  rt,
+		fills:      make(chan struct{}, options.MaxAsyncWrites),
+	}
+
+	if options.WarmCache && !options.SkipCache {
+		g.fillAsync(context.Background(), func(ctx context.Context) {
+			_, _ = g.Warm(ctx, nil)
+		})
+	}
+	return g
+}
+
+// defaultQueryCache is the process-local memory cache GetQuery uses until
+// WithQueryCache replaces it. It runs no cleanup goroutine, so it needs no
+// Close; expired entries are dropped when read or evicted.
+func defaultQueryCache[T any](opts GORMOptions) interfaces.AdvancedCache[[]T] {
+	cfg := config.DefaultConfig()
+	cfg.Prefix = opts.KeyPrefix + ":query:"
+	cfg.TTL = opts.DefaultTTL
+	cfg.CleanupInterval = 0
+
+	c, err := memory.NewMemory[[]T](cfg)
+	if err != nil {
+		return nil
 	}
+	return advanced.NewAdvancedCache[[]T](c, cfg)
+}
+
+/* ------------------ Fluent Config ------------------ */
+
+// WithQueryCache sets the cache used by GetQuery for query result slices,
+// replacing the default process-local memory cache. Use a shared cache
+// when several processes must see the same results and invalidations.
+func (g *GORMCache[T]) WithQueryCache(c interfaces.AdvancedCache[[]T]) *GORMCache[T] {
+	g.queryCache = c
+	return g
+}
+
+// WithAggregateCache sets the cache holding keys registered with DependsOn.
+// Defaults to the entity cache.
+func (g *GORMCache[T]) WithAggregateCache(c KeyDeleter) *GORMCache[T] {
+	g.depMu.Lock()
+	g.aggregates = c
+	g.depMu.Unlock()
+	return g
+}
+
+/* ------------------ Per-request Bypass ------------------ */
+
+type skipCacheKey struct{}
+
+// WithSkipCache marks ctx so that GetByID, GetByIDs and GetQuery read from
+// the database instead of the cache, and write what they load back before
+// returning, refreshing the cached copy. Rows found missing have their
+// entries invalidated. Use it for force-refresh requests.
+func WithSkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+func skipCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipCacheKey{}).(bool)
+	return skip
 }
 
 /* ------------------ Single Entity ------------------ */
@@ -72,21 +198,96 @@ func (g *GORMCache[T]) GetByID(
 	id any,
 	ttl ...time.Duration,
 ) (T, error) {
+	load := func() (T, error) { return g.loadFromDB(ctx, id) }
 	if g.opts.SkipCache {
-		return g.loadFromDB(ctx, id)
+		return load()
 	}
 
 	key := g.buildKey(id)
-	cacheTTL := g.resolveTTL(ttl...)
+	if skipCache(ctx) {
+		entity, err := load()
+		switch {
+		case err == nil:
+			_ = g.cache.Set(ctx, key, entity, g.resolveTTL(ttl...))
+		case errors.Is(err, ErrRecordNotFound):
+			_ = g.cache.Delete(ctx, g.entryKeys(key)...)
+		}
+		return entity, err
+	}
+	return g.cached(ctx, key, g.resolveTTL(ttl...), load)
+}
+
+// GetBy looks an entity up by a unique column such as an email or slug,
+// caching it under a key of its own, distinct from the primary-key entry.
+// Invalidating the row by primary key also drops the column entries seen
+// by this GORMCache; the tracking is process-local, so other processes
+// only clear such entries through their TTL or a full invalidation.
+func (g *GORMCache[T]) GetBy(
+	ctx context.Context,
+	column string,
+	value any,
+	ttl ...time.Duration,
+) (T, error) {
+	load := func() (T, error) { return g.loadBy(ctx, column, value) }
+	if g.opts.SkipCache {
+		return load()
+	}
 
-	val, err := g.cache.GetOrSet(ctx, key, cacheTTL, func() (T, error) {
-		return g.loadFromDB(ctx, id)
+	key := g.columnKey(column, value)
+	entity, err := g.cached(ctx, key, g.resolveTTL(ttl...), load)
+	switch {
+	case err == nil:
+		g.trackColumnKey(ctx, entity, key)
+	case errors.Is(err, ErrRecordNotFound) && g.opts.NegativeTTL > 0:
+		g.colMu.Lock()
+		g.colMisses = true
+		g.colMu.Unlock()
+	}
+	return entity, err
+}
+
+// cached reads key through the entity cache, loading it with load on a
+// miss. With NegativeTTL set, not-found results are cached as well. Cache
+// failures fall back to load; errors of load itself are returned as they
+// are, without querying again.
+func (g *GORMCache[T]) cached(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	load func() (T, error),
+) (T, error) {
+	loaded := false
+	if g.opts.NegativeTTL <= 0 {
+		val, err := g.cache.GetOrSet(ctx, key, ttl, func() (T, error) {
+			loaded = true
+			return load()
+		})
+		if err != nil && !loaded {
+			// Fail open
+			return load()
+		}
+		return val, err
+	}
+
+	val, err := g.cache.GetOrSetWithNegative(ctx, key, ttl, g.opts.NegativeTTL, func() (T, bool, error) {
+		loaded = true
+		entity, err := load()
+		if errors.Is(err, ErrRecordNotFound) {
+			return entity, false, nil
+		}
+		return entity, err == nil, err
 	})
-	if err != nil {
+	switch {
+	case err == nil:
+		return val, nil
+	case base.IsNegativeCached(err):
+		return val, ErrRecordNotFound
+	case loaded:
+		return val, err
+	default:
 		// Fail open
-		return g.loadFromDB(ctx, id)
+		return load()
 	}
-	return val, nil
 }
 
 /* ------------------ Multiple Entities ------------------ */
@@ -106,20 +307,29 @@ func (g *GORMCache[T]) GetByIDs(
 
 	cacheTTL := g.resolveTTL(ttl...)
 
+	// Positions are indexed by cache key rather than raw id so that ids of
+	// different dynamic types (int vs uint) that format alike still match.
 	keys := make([]string, len(ids))
-	keyToIndex := make(map[string]int, len(ids))
+	positions := make(map[string][]int, len(ids))
 	for i, id := range ids {
 		key := g.buildKey(id)
 		keys[i] = key
-		keyToIndex[key] = i
+		positions[key] = append(positions[key], i)
 	}
 
 	results := make([]T, len(ids))
 	missing := make([]any, 0)
 
-	cached, err := g.cache.GetManyPipeline(ctx, keys)
-	if err != nil {
-		return g.loadMultipleFromDB(ctx, ids)
+	// Keys that failed individually are treated as missing. With
+	// WithSkipCache every key is.
+	forced := skipCache(ctx)
+	var cached map[string]T
+	if !forced {
+		var err error
+		cached, err = g.cache.GetManyPipeline(ctx, keys)
+		if err != nil && !base.IsPartial(err) {
+			return g.loadMultipleFromDB(ctx, ids)
+		}
 	}
 
 	for i, id := range ids {
@@ -140,27 +350,73 @@ func (g *GORMCache[T]) GetByIDs(
 		return nil, err
 	}
 
-	// Async cache fill (best effort)
-	go func(parent context.Context) {
-		ctx, cancel := detachWithTimeout(parent, g.opts.DefaultTTL)
-		defer cancel()
-
-		_ = g.cacheEntities(ctx, missing, dbEntities, cacheTTL)
-	}(ctx)
+	// The DB may return rows in any order and omit absent ones, so map
+	// each row back to its positions through its primary key.
+	loaded := g.keyEntities(ctx, dbEntities)
+
+	if forced {
+		g.refreshLoaded(ctx, keys, loaded, cacheTTL)
+	} else {
+		// Async cache fill (best effort); Close on the cache waits for it
+		g.fillAsync(ctx, func(ctx context.Context) {
+			_ = g.cache.SetManyPipeline(ctx, loaded, cacheTTL)
+		})
+	}
 
 	// Merge in O(n)
-	for i, id := range ids {
-		for j, mid := range missing {
-			if reflect.DeepEqual(id, mid) {
-				results[i] = dbEntities[j]
-				break
-			}
+	for key, entity := range loaded {
+		for _, i := range positions[key] {
+			results[i] = entity
 		}
 	}
 
 	return results, nil
 }
 
+/* ------------------ Query Results ------------------ */
+
+// GetQuery returns the result of an arbitrary scoped query, caching the
+// slice under cacheKey in the query cache (see WithQueryCache). With
+// SkipCache the query always hits the database. Database errors are
+// returned as they are; cache failures fall back to the query.
+// Invalidation is up to the caller, e.g. via InvalidateByPrefix.
+func (g *GORMCache[T]) GetQuery(
+	ctx context.Context,
+	cacheKey string,
+	ttl time.Duration,
+	query func(*gorm.DB) *gorm.DB,
+) ([]T, error) {
+	load := func() ([]T, error) {
+		var entities []T
+		err := query(g.db.WithContext(ctx)).Find(&entities).Error
+		return entities, err
+	}
+
+	if g.opts.SkipCache || g.queryCache == nil {
+		return load()
+	}
+
+	cacheTTL := g.resolveTTL(ttl)
+	if skipCache(ctx) {
+		entities, err := load()
+		if err == nil {
+			_ = g.queryCache.Set(ctx, cacheKey, entities, cacheTTL)
+		}
+		return entities, err
+	}
+
+	loaded := false
+	val, err := g.queryCache.GetOrSet(ctx, cacheKey, cacheTTL, func() ([]T, error) {
+		loaded = true
+		return load()
+	})
+	if err != nil && !loaded {
+		// Fail open
+		return load()
+	}
+	return val, err
+}
+
 /* ------------------ Preload ------------------ */
 
 func (g *GORMCache[T]) Preload(
@@ -182,24 +438,87 @@ func (g *GORMCache[T]) Preload(
 
 	cacheTTL := g.resolveTTL(ttl...)
 
-	go func(parent context.Context) {
-		ctx, cancel := detachWithTimeout(parent, g.opts.DefaultTTL)
-		defer cancel()
-
+	g.fillAsync(ctx, func(ctx context.Context) {
 		_ = g.cache.Set(ctx, g.buildKey(id), entity, cacheTTL)
-	}(ctx)
+	})
 
 	return entity, nil
 }
 
+/* ------------------ Warmup ------------------ */
+
+// Warm loads the rows selected by scope, or every row when scope is nil,
+// with a single Find and caches them in one pipelined write under their
+// primary-key entries. It returns how many were cached; keys that could
+// not be written come back as a *base.MultiError.
+func (g *GORMCache[T]) Warm(
+	ctx context.Context,
+	scope func(*gorm.DB) *gorm.DB,
+	ttl ...time.Duration,
+) (int64, error) {
+	if g.opts.SkipCache {
+		return 0, nil
+	}
+
+	db := g.db.WithContext(ctx)
+	if scope != nil {
+		db = scope(db)
+	}
+
+	var entities []T
+	if err := db.Find(&entities).Error; err != nil {
+		return 0, err
+	}
+
+	loaded := g.keyEntities(ctx, entities)
+	if len(loaded) == 0 {
+		return 0, nil
+	}
+
+	failed, err := g.cache.SetManyPipelineResult(ctx, loaded, g.resolveTTL(ttl...))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(loaded) - len(failed)), base.NewMultiError(failed)
+}
+
+/* ------------------ Write-through ------------------ */
+
+// Save persists entity with db.Save and, only if that succeeds, writes it
+// to the cache under id so subsequent reads are warm.
+func (g *GORMCache[T]) Save(
+	ctx context.Context,
+	entity T,
+	id any,
+	ttl ...time.Duration,
+) error {
+	if err := g.db.WithContext(ctx).Save(&entity).Error; err != nil {
+		return err
+	}
+
+	if g.opts.SkipCache {
+		return nil
+	}
+
+	return g.cache.Set(ctx, g.buildKey(id), entity, g.resolveTTL(ttl...))
+}
+
 /* ------------------ Invalidation ------------------ */
 
 func (g *GORMCache[T]) Invalidate(ctx context.Context, id any) error {
-	return g.cache.Delete(ctx, g.buildKey(id))
+	return g.cache.Delete(ctx, g.entryKeys(g.buildKey(id))...)
 }
 
+// InvalidateByPrefix deletes matching entries from the entity cache and,
+// when configured, the query cache.
 func (g *GORMCache[T]) InvalidateByPrefix(ctx context.Context, prefix string) (int64, error) {
-	return g.cache.DeleteByPrefix(ctx, prefix)
+	n, err := g.cache.DeleteByPrefix(ctx, prefix)
+	if err != nil || g.queryCache == nil {
+		return n, err
+	}
+
+	q, err := g.queryCache.DeleteByPrefix(ctx, prefix)
+	return n + q, err
 }
 
 /* ------------------ Refresh ------------------ */
@@ -217,6 +536,64 @@ func (g *GORMCache[T]) Refresh(
 	return g.cache.Set(ctx, g.buildKey(id), entity, g.resolveTTL(ttl...))
 }
 
+// RefreshMany reloads ids with a single query and re-caches them in one
+// pipelined write. Ids whose rows no longer exist are invalidated instead.
+func (g *GORMCache[T]) RefreshMany(
+	ctx context.Context,
+	ids []any,
+	ttl ...time.Duration,
+) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	entities, err := g.loadMultipleFromDB(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	loaded := g.keyEntities(ctx, entities)
+
+	var gone []string
+	for _, id := range ids {
+		key := g.buildKey(id)
+		if _, ok := loaded[key]; !ok {
+			gone = append(gone, key)
+		}
+	}
+	if len(gone) > 0 {
+		slices.Sort(gone)
+		gone = slices.Compact(gone)
+		if err := g.cache.Delete(ctx, g.entryKeys(gone...)...); err != nil {
+			return err
+		}
+	}
+
+	if len(loaded) == 0 {
+		return nil
+	}
+	return g.cache.SetManyPipeline(ctx, loaded, g.resolveTTL(ttl...))
+}
+
+// refreshLoaded writes the entities loaded for keys back to the cache and
+// invalidates the keys whose rows were not found. Failures are ignored,
+// as the caller already has the database result.
+func (g *GORMCache[T]) refreshLoaded(ctx context.Context, keys []string, loaded map[string]T, ttl time.Duration) {
+	var gone []string
+	for _, key := range keys {
+		if _, ok := loaded[key]; !ok {
+			gone = append(gone, key)
+		}
+	}
+	if len(gone) > 0 {
+		slices.Sort(gone)
+		_ = g.cache.Delete(ctx, g.entryKeys(slices.Compact(gone)...)...)
+	}
+	if len(loaded) > 0 {
+		_ = g.cache.SetManyPipeline(ctx, loaded, ttl)
+	}
+}
+
 /* ------------------ Stats ------------------ */
 
 func (g *GORMCache[T]) Stats(ctx context.Context) metrics.CacheStats {
@@ -225,11 +602,34 @@ func (g *GORMCache[T]) Stats(ctx context.Context) metrics.CacheStats {
 
 /* ------------------ Helpers ------------------ */
 
+// detachWithTimeout keeps parent's values but not its cancellation, so a
+// fill outlives the request that triggered it, bounded by d instead.
 func detachWithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
 	if parent == nil {
 		parent = context.Background()
 	}
-	return context.WithTimeout(parent, d)
+	return context.WithTimeout(context.WithoutCancel(parent), d)
+}
+
+// fillAsync runs fn on one of the cache's tracked goroutines with a
+// detached context, unless MaxAsyncWrites fills are already running.
+func (g *GORMCache[T]) fillAsync(ctx context.Context, fn func(ctx context.Context)) {
+	select {
+	case g.fills <- struct{}{}:
+	default:
+		return
+	}
+
+	started := g.cache.Go(func() {
+		defer func() { <-g.fills }()
+
+		ctx, cancel := detachWithTimeout(ctx, g.opts.AsyncWriteTimeout)
+		defer cancel()
+		fn(ctx)
+	})
+	if !started {
+		<-g.fills
+	}
 }
 
 func (g *GORMCache[T]) resolveTTL(ttl ...time.Duration) time.Duration {
@@ -243,27 +643,133 @@ func (g *GORMCache[T]) buildKey(id any) string {
 	return fmt.Sprintf("%s:%s:%v", g.opts.KeyPrefix, g.typeName, id)
 }
 
+// entryKeys returns the primary-key entries keys, the unique-column entries
+// recorded for them and, with negative caching enabled, their not-found
+// markers. The column entries are forgotten, as the caller deletes them.
+func (g *GORMCache[T]) entryKeys(keys ...string) []string {
+	out := make([]string, 0, 2*len(keys))
+	out = append(out, keys...)
+
+	g.colMu.Lock()
+	for _, k := range keys {
+		for ck := range g.colKeys[k] {
+			out = append(out, ck)
+		}
+		delete(g.colKeys, k)
+	}
+	g.colMu.Unlock()
+
+	if g.opts.NegativeTTL > 0 {
+		n := len(out)
+		for _, k := range out[:n] {
+			out = append(out, base.NegativeKey(k))
+		}
+	}
+	return out
+}
+
+func (g *GORMCache[T]) columnKey(column string, value any) string {
+	return fmt.Sprintf("%sby:%s:%v", g.typePrefix(), column, value)
+}
+
+// trackColumnKey records key as a unique-column entry of entity's row.
+func (g *GORMCache[T]) trackColumnKey(ctx context.Context, entity T, key string) {
+	id, ok := g.primaryKey(ctx, entity)
+	if !ok {
+		return
+	}
+	pkKey := g.buildKey(id)
+
+	g.colMu.Lock()
+	defer g.colMu.Unlock()
+	if g.colKeys == nil {
+		g.colKeys = make(map[string]map[string]struct{})
+	}
+	set := g.colKeys[pkKey]
+	if set == nil {
+		set = make(map[string]struct{})
+		g.colKeys[pkKey] = set
+	}
+	set[key] = struct{}{}
+}
+
+func (g *GORMCache[T]) loadBy(ctx context.Context, column string, value any) (T, error) {
+	var entity T
+	err := g.db.WithContext(ctx).Where(map[string]any{column: value}).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, ErrRecordNotFound
+	}
+	return entity, err
+}
+
 func (g *GORMCache[T]) loadFromDB(ctx context.Context, id any) (T, error) {
 	var entity T
 	err := g.db.WithContext(ctx).First(&entity, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, ErrRecordNotFound
+	}
 	return entity, err
 }
 
 func (g *GORMCache[T]) loadMultipleFromDB(ctx context.Context, ids []any) ([]T, error) {
-	var entities []T
-	err := g.db.WithContext(ctx).Find(&entities, ids).Error
-	return entities, err
+	size := g.opts.DBBatchSize
+	if size <= 0 || len(ids) <= size {
+		var entities []T
+		err := g.db.WithContext(ctx).Find(&entities, ids).Error
+		return entities, err
+	}
+
+	entities := make([]T, 0, len(ids))
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+
+		var batch []T
+		if err := g.db.WithContext(ctx).Find(&batch, ids[start:end]).Error; err != nil {
+			return nil, err
+		}
+		entities = append(entities, batch...)
+	}
+	return entities, nil
 }
 
-func (g *GORMCache[T]) cacheEntities(
-	ctx context.Context,
-	ids []any,
-	entities []T,
-	ttl time.Duration,
-) error {
-	items := make(map[string]T, len(ids))
-	for i, id := range ids {
-		items[g.buildKey(id)] = entities[i]
+// keyEntities maps entities to their cache keys via their primary key.
+// Entities whose primary key cannot be read are skipped.
+func (g *GORMCache[T]) keyEntities(ctx context.Context, entities []T) map[string]T {
+	items := make(map[string]T, len(entities))
+	for _, e := range entities {
+		if id, ok := g.primaryKey(ctx, e); ok {
+			items[g.buildKey(id)] = e
+		}
 	}
-	return g.cache.SetManyPipeline(ctx, items, ttl)
+	return items
+}
+
+// primaryKey reads the prioritized primary-key value of entity.
+func (g *GORMCache[T]) primaryKey(ctx context.Context, entity T) (any, bool) {
+	field := g.pkField()
+	if field == nil {
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(entity)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	id, zero := field.ValueOf(ctx, rv)
+	return id, !zero
+}
+
+func (g *GORMCache[T]) pkField() *schema.Field {
+	g.pkOnce.Do(func() {
+		var model T
+		stmt := &gorm.Statement{DB: g.db}
+		if err := stmt.Parse(&model); err == nil && stmt.Schema != nil {
+			g.pk = stmt.Schema.PrioritizedPrimaryField
+		}
+	})
+	return g.pk
 }
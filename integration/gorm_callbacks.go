@@ -0,0 +1,181 @@
+package integration
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Automatic Invalidation ------------------ */
+
+// RegisterCallbacks hooks GORM create/update/delete callbacks so writes to
+// T made through g's *gorm.DB invalidate the affected cache entries.
+// When the primary keys of a write cannot be determined (e.g. a
+// Where(...).Updates touching many rows) every entry of T is invalidated.
+func (g *GORMCache[T]) RegisterCallbacks() error {
+	name := "go-cache:" + g.opts.KeyPrefix + ":" + g.typeName
+	cb := g.db.Callback()
+
+	if err := cb.Create().After("gorm:create").Register(name+":create", g.afterWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register(name+":update", g.afterWrite); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register(name+":delete", g.afterWrite)
+}
+
+func (g *GORMCache[T]) afterWrite(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	if tx.Statement.Schema.ModelType != g.modelType {
+		return
+	}
+
+	ctx := tx.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ids := primaryKeys(tx)
+	if len(ids) == 0 {
+		// This also drops the type's negative-cache markers.
+		_, _ = g.cache.DeleteByPrefix(ctx, g.typePrefix())
+		g.colMu.Lock()
+		g.colKeys = nil
+		g.colMu.Unlock()
+		g.invalidateDependents(ctx, nil)
+		return
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = g.buildKey(id)
+	}
+	_ = g.cache.Delete(ctx, g.entryKeys(keys...)...)
+
+	// A written row may now match a unique-column lookup that was cached
+	// as not found; which one cannot be told from its primary key.
+	g.colMu.Lock()
+	misses := g.colMisses
+	g.colMisses = false
+	g.colMu.Unlock()
+	if misses {
+		_, _ = g.cache.DeleteByPrefix(ctx, base.NegativeKey(g.typePrefix()+"by:"))
+	}
+	g.invalidateDependents(ctx, keys)
+}
+
+/* ------------------ Dependent Keys ------------------ */
+
+// KeyDeleter is the subset of a cache needed to drop dependent keys, so
+// aggregates of any value type can be registered.
+type KeyDeleter interface {
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// DependsOn registers aggregateKey as derived from rows of T. With ids, a
+// write to any of those rows invalidates it; without, a write to any row of
+// T does. Writes whose primary keys are unknown invalidate every registered
+// key. Registrations persist across invalidations and take effect only
+// once RegisterCallbacks has been called.
+func (g *GORMCache[T]) DependsOn(aggregateKey string, ids ...any) {
+	g.depMu.Lock()
+	defer g.depMu.Unlock()
+
+	if len(ids) == 0 {
+		if g.typeDeps == nil {
+			g.typeDeps = make(map[string]struct{})
+		}
+		g.typeDeps[aggregateKey] = struct{}{}
+		return
+	}
+
+	if g.idDeps == nil {
+		g.idDeps = make(map[string]map[string]struct{})
+	}
+	for _, id := range ids {
+		key := g.buildKey(id)
+		set := g.idDeps[key]
+		if set == nil {
+			set = make(map[string]struct{})
+			g.idDeps[key] = set
+		}
+		set[aggregateKey] = struct{}{}
+	}
+}
+
+// invalidateDependents deletes aggregates depending on the written entity
+// keys, or on any row when keys is nil.
+func (g *GORMCache[T]) invalidateDependents(ctx context.Context, keys []string) {
+	g.depMu.RLock()
+	target := g.aggregates
+	seen := make(map[string]struct{}, len(g.typeDeps))
+	for k := range g.typeDeps {
+		seen[k] = struct{}{}
+	}
+	if keys == nil {
+		for _, set := range g.idDeps {
+			for k := range set {
+				seen[k] = struct{}{}
+			}
+		}
+	}
+	for _, key := range keys {
+		for k := range g.idDeps[key] {
+			seen[k] = struct{}{}
+		}
+	}
+	g.depMu.RUnlock()
+
+	if len(seen) == 0 {
+		return
+	}
+	if target == nil {
+		target = g.cache
+	}
+
+	out := make([]string, 0, len(seen))
+	for k := range seen {
+		out = append(out, k)
+	}
+	_ = target.Delete(ctx, out...)
+}
+
+// primaryKeys extracts non-zero primary-key values from the statement's
+// model value, which may be a single struct or a slice of them.
+func primaryKeys(tx *gorm.DB) []any {
+	field := tx.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	ctx := tx.Statement.Context
+
+	var ids []any
+	collect := func(v reflect.Value) {
+		if id, zero := field.ValueOf(ctx, reflect.Indirect(v)); !zero {
+			ids = append(ids, id)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(rv.Index(i))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+
+	return ids
+}
+
+func (g *GORMCache[T]) typePrefix() string {
+	return g.opts.KeyPrefix + ":" + g.typeName + ":"
+}
@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// isCached reports whether the entity cache holds an entry for id.
+func isCached[T any](t *testing.T, g *GORMCache[T], id any) bool {
+	t.Helper()
+	ok, err := g.cache.Exists(context.Background(), g.buildKey(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func newCallbackCache(t *testing.T) *GORMCache[user] {
+	t.Helper()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+	if err := g.RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, id := range []uint{1, 2, 3, 4} {
+		if _, err := g.GetByID(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func TestCallbacksInvalidateUpdatedRow(t *testing.T) {
+	g := newCallbackCache(t)
+
+	if err := g.db.Model(&user{ID: 1}).Update("name", "ann b").Error; err != nil {
+		t.Fatal(err)
+	}
+	if isCached(t, g, 1) {
+		t.Error("entry 1 still cached after an update")
+	}
+	if !isCached(t, g, 2) {
+		t.Error("entry 2 invalidated by an update to row 1")
+	}
+
+	got, err := g.GetByID(context.Background(), 1)
+	if err != nil || got.Name != "ann b" {
+		t.Errorf("GetByID after update = %+v, %v", got, err)
+	}
+}
+
+func TestCallbacksInvalidateSavedAndDeletedRows(t *testing.T) {
+	g := newCallbackCache(t)
+
+	u := user{ID: 2, Name: "bob b", Email: "bob@example.com", OrgID: 5}
+	if err := g.db.Save(&u).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := g.db.Delete(&user{ID: 3}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if isCached(t, g, 2) || isCached(t, g, 3) {
+		t.Error("saved or deleted entries still cached")
+	}
+	if !isCached(t, g, 1) || !isCached(t, g, 4) {
+		t.Error("untouched entries invalidated")
+	}
+}
+
+func TestCallbacksInvalidateAllOnMultiRowUpdate(t *testing.T) {
+	g := newCallbackCache(t)
+
+	if err := g.db.Model(&user{}).Where("org_id = ?", 5).Update("active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{1, 2, 3, 4} {
+		if isCached(t, g, id) {
+			t.Errorf("entry %d still cached after a multi-row update", id)
+		}
+	}
+}
+
+func TestCallbacksIgnoreOtherModels(t *testing.T) {
+	g := newCallbackCache(t)
+
+	type audit struct {
+		ID  uint
+		Msg string
+	}
+	if err := g.db.AutoMigrate(&audit{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.db.Create(&audit{Msg: "x"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !isCached(t, g, 1) {
+		t.Error("write to another model invalidated user entries")
+	}
+}
+
+/* ------------------ Dependent keys ------------------ */
+
+func TestDependsOnInvalidatesAggregates(t *testing.T) {
+	ctx := context.Background()
+	g := newCallbackCache(t)
+	counts := newEntityCache[int64](t)
+	g.WithAggregateCache(counts)
+
+	for _, k := range []string{"count:org5", "count:all", "count:org6"} {
+		if err := counts.Set(ctx, k, 3, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.DependsOn("count:org5", 1, 2, 3)
+	g.DependsOn("count:org6", 4)
+	g.DependsOn("count:all")
+
+	if err := g.db.Model(&user{ID: 2}).Update("active", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]bool{"count:org5": false, "count:all": false, "count:org6": true} {
+		if ok, _ := counts.Exists(ctx, k); ok != want {
+			t.Errorf("after updating row 2, %s cached = %v, want %v", k, ok, want)
+		}
+	}
+
+	// Registrations survive invalidation.
+	if err := counts.Set(ctx, "count:org5", 3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.db.Delete(&user{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := counts.Exists(ctx, "count:org5"); ok {
+		t.Error("count:org5 still cached after deleting row 1")
+	}
+}
+
+func TestDependsOnMultiRowWriteInvalidatesAll(t *testing.T) {
+	ctx := context.Background()
+	g := newCallbackCache(t)
+
+	if err := g.cache.Set(ctx, "count:org6", user{}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	g.DependsOn("count:org6", 4)
+
+	// The primary keys of a Where update are unknown.
+	if err := g.db.Model(&user{}).Where("org_id = ?", 5).Update("active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := g.cache.Exists(ctx, "count:org6"); ok {
+		t.Error("aggregate in the entity cache survived a multi-row update")
+	}
+}
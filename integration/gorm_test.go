@@ -0,0 +1,942 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	cache "github.com/os-golib/go-cache"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+type user struct {
+	ID     uint `gorm:"primaryKey"`
+	Name   string
+	Email  string `gorm:"uniqueIndex"`
+	OrgID  int
+	Active bool
+}
+
+// queryCounter counts the SELECTs run against a test database.
+type queryCounter struct {
+	n atomic.Int64
+}
+
+func (q *queryCounter) count() int64 { return q.n.Load() }
+
+// newTestDB opens a private in-memory SQLite database with a users table.
+func newTestDB(t *testing.T, users ...user) (*gorm.DB, *queryCounter) {
+	t.Helper()
+	dsn := "file:" + url.PathEscape(t.Name()) + "?mode=memory&cache=shared"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) > 0 {
+		if err := db.Create(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := &queryCounter{}
+	if err := db.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) {
+		q.n.Add(1)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return db, q
+}
+
+func newEntityCache[T any](t *testing.T) interfaces.AdvancedCache[T] {
+	t.Helper()
+	c, err := cache.NewAdvancedMemory[T]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func testUsers() []user {
+	return []user{
+		{ID: 1, Name: "ann", Email: "ann@example.com", OrgID: 5, Active: true},
+		{ID: 2, Name: "bob", Email: "bob@example.com", OrgID: 5, Active: false},
+		{ID: 3, Name: "cid", Email: "cid@example.com", OrgID: 5, Active: true},
+		{ID: 4, Name: "dee", Email: "dee@example.com", OrgID: 6, Active: true},
+	}
+}
+
+/* ------------------ GetQuery ------------------ */
+
+func activeInOrg5(db *gorm.DB) *gorm.DB {
+	return db.Where("org_id = ? AND active = ?", 5, true).Order("id")
+}
+
+func TestGetQueryCachesByDefault(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	for range 3 {
+		got, err := g.GetQuery(ctx, "users:active:org5", time.Minute, activeInOrg5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+			t.Fatalf("GetQuery = %+v", got)
+		}
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("database queried %d times, want 1", n)
+	}
+
+	// The caller invalidates by prefix; the next call queries again.
+	if _, err := g.InvalidateByPrefix(ctx, "users:active:"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GetQuery(ctx, "users:active:org5", time.Minute, activeInOrg5); err != nil {
+		t.Fatal(err)
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("database queried %d times after invalidation, want 2", n)
+	}
+}
+
+func TestGetQueryUsesConfiguredQueryCache(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	qc := newEntityCache[[]user](t)
+	g := NewGORMCache[user](newEntityCache[user](t), db).WithQueryCache(qc)
+
+	if _, err := g.GetQuery(ctx, "org5", time.Minute, activeInOrg5); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := qc.Get(ctx, "org5"); err != nil || len(got) != 2 {
+		t.Fatalf("query cache holds %+v, %v", got, err)
+	}
+	if _, err := g.GetQuery(ctx, "org5", time.Minute, activeInOrg5); err != nil {
+		t.Fatal(err)
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("database queried %d times, want 1", n)
+	}
+}
+
+func TestGetQueryReturnsDatabaseErrorOnce(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	_, err := g.GetQuery(ctx, "bad", time.Minute, func(db *gorm.DB) *gorm.DB {
+		return db.Where("no_such_column = 1")
+	})
+	if err == nil {
+		t.Fatal("GetQuery succeeded on a failing query")
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("failing query ran %d times, want 1", n)
+	}
+}
+
+func TestGetQuerySkipCacheRefreshes(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetQuery(ctx, "org5", time.Minute, activeInOrg5); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&user{}).Where("id = ?", 2).Update("active", true).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.GetQuery(WithSkipCache(ctx), "org5", time.Minute, activeInOrg5)
+	if err != nil || len(got) != 3 {
+		t.Fatalf("forced GetQuery = %+v, %v; want 3 users", got, err)
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("database queried %d times, want 2", n)
+	}
+
+	// The refreshed result was written back.
+	got, err = g.GetQuery(ctx, "org5", time.Minute, activeInOrg5)
+	if err != nil || len(got) != 3 {
+		t.Fatalf("GetQuery after refresh = %+v, %v", got, err)
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("database queried %d times after refresh, want 2", n)
+	}
+}
+
+func TestGetQuerySkipCacheOption(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	opts := DefaultGORMOptions()
+	opts.SkipCache = true
+	g := NewGORMCache[user](newEntityCache[user](t), db, opts)
+
+	for range 2 {
+		if _, err := g.GetQuery(ctx, "org5", time.Minute, activeInOrg5); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("database queried %d times with SkipCache, want 2", n)
+	}
+}
+
+/* ------------------ GetByID ------------------ */
+
+func TestGetByIDReturnsDatabaseErrorOnce(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if err := db.Migrator().DropTable(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := g.GetByID(ctx, 1)
+	if err == nil || errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetByID on a dropped table = %v", err)
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("failing load ran %d times, want 1", n)
+	}
+}
+
+func negativeOptions(ttl time.Duration) GORMOptions {
+	opts := DefaultGORMOptions()
+	opts.NegativeTTL = ttl
+	return opts
+}
+
+func TestGetByIDCachesNotFound(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db, negativeOptions(50*time.Millisecond))
+
+	for i := range 3 {
+		_, err := g.GetByID(ctx, 99)
+		if !errors.Is(err, ErrRecordNotFound) || !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetByID #%d = %v, want ErrRecordNotFound", i, err)
+		}
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("%d queries during the negative TTL, want 1", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := g.GetByID(ctx, 99); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetByID after the negative TTL = %v", err)
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("%d queries, want the lookup repeated once the marker expired", n)
+	}
+}
+
+func TestGetByIDNotFoundWithoutNegativeTTL(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	for range 2 {
+		if _, err := g.GetByID(ctx, 99); !errors.Is(err, ErrRecordNotFound) {
+			t.Fatalf("GetByID = %v, want ErrRecordNotFound", err)
+		}
+	}
+	if n := q.count(); n != 2 {
+		t.Errorf("%d queries, want every lookup to reach the database", n)
+	}
+}
+
+func TestNegativeMarkerClearedOnWrite(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db, negativeOptions(time.Minute))
+	if err := g.RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.GetByID(ctx, 99); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetByID = %v", err)
+	}
+	if err := db.Create(&user{ID: 99, Name: "new", Email: "new@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.GetByID(ctx, 99); err != nil || got.Name != "new" {
+		t.Errorf("GetByID after Create = %+v, %v; want the new row", got, err)
+	}
+}
+
+func TestInvalidateClearsNegativeMarker(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db, negativeOptions(time.Minute))
+
+	if _, err := g.GetByID(ctx, 99); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetByID = %v", err)
+	}
+	// Without callbacks the insert leaves the marker in place.
+	if err := db.Create(&user{ID: 99, Name: "new", Email: "new@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GetByID(ctx, 99); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetByID before Invalidate = %v, want the cached not-found", err)
+	}
+	if err := g.Invalidate(ctx, 99); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.GetByID(ctx, 99); err != nil || got.Name != "new" {
+		t.Errorf("GetByID after Invalidate = %+v, %v; want the row", got, err)
+	}
+}
+
+/* ------------------ GetBy ------------------ */
+
+func TestGetByUniqueColumn(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	for range 2 {
+		got, err := g.GetBy(ctx, "email", "bob@example.com")
+		if err != nil || got.ID != 2 {
+			t.Fatalf("GetBy(email) = %+v, %v; want user 2", got, err)
+		}
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("%d queries for two lookups, want 1", n)
+	}
+
+	// The column entry is keyed apart from the primary-key entry.
+	if key := g.columnKey("email", "bob@example.com"); key == g.buildKey(2) {
+		t.Errorf("column key %q collides with the primary key", key)
+	}
+	if isCached(t, g, 2) {
+		t.Error("GetBy filled the primary-key entry")
+	}
+	if _, err := g.GetBy(ctx, "email", "nobody@example.com"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("GetBy(unknown email) = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestInvalidateDropsColumnEntries(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetBy(ctx, "email", "ann@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Invalidate(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	before := q.count()
+	if _, err := g.GetBy(ctx, "email", "ann@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if n := q.count() - before; n != 1 {
+		t.Errorf("GetBy after Invalidate ran %d queries, want 1", n)
+	}
+}
+
+func TestCallbacksRefreshColumnEntries(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db, negativeOptions(time.Minute))
+	if err := g.RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.GetBy(ctx, "email", "ann@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&user{ID: 1}).Update("name", "ann b").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.GetBy(ctx, "email", "ann@example.com"); err != nil || got.Name != "ann b" {
+		t.Errorf("GetBy after Update = %+v, %v; want the updated row", got, err)
+	}
+
+	// A row created with a cached not-found email becomes visible.
+	if _, err := g.GetBy(ctx, "email", "eve@example.com"); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("GetBy = %v, want ErrRecordNotFound", err)
+	}
+	if err := db.Create(&user{ID: 5, Name: "eve", Email: "eve@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.GetBy(ctx, "email", "eve@example.com"); err != nil || got.ID != 5 {
+		t.Errorf("GetBy after Create = %+v, %v; want user 5", got, err)
+	}
+}
+
+/* ------------------ Save ------------------ */
+
+func TestSaveWritesThroughToCache(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	updated := user{ID: 1, Name: "ann b", Email: "ann@example.com", OrgID: 5, Active: true}
+	if err := g.Save(ctx, updated, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	before := q.count()
+	got, err := g.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "ann b" {
+		t.Errorf("GetByID after Save = %+v, want the saved entity", got)
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("GetByID after Save ran %d queries, want 0", n)
+	}
+
+	var row user
+	if err := db.First(&row, 1).Error; err != nil || row.Name != "ann b" {
+		t.Errorf("database row = %+v, %v", row, err)
+	}
+}
+
+func TestSaveLeavesCacheOnDatabaseError(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	// The email belongs to user 2, so the unique index rejects the save.
+	dup := user{ID: 1, Name: "clash", Email: "bob@example.com", OrgID: 5}
+	if err := g.Save(ctx, dup, 1); err == nil {
+		t.Fatal("Save succeeded despite the unique index")
+	}
+
+	got, err := g.GetByID(ctx, 1)
+	if err != nil || got.Name != "ann" {
+		t.Errorf("GetByID after failed Save = %+v, %v; want the original", got, err)
+	}
+}
+
+/* ------------------ GetByIDs ------------------ */
+
+func TestGetByIDsKeepsRequestOrder(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	// Warm one entry so the result mixes cache hits and DB rows, which
+	// SQLite returns in primary-key order rather than request order.
+	if _, err := g.GetByID(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []any{4, 1, 3, 99, 2, 1}
+	got, err := g.GetByIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint{4, 1, 3, 0, 2, 1}
+	if len(got) != len(want) {
+		t.Fatalf("GetByIDs returned %d entities, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].ID != id {
+			t.Errorf("result[%d] = user %d, want %d (ids %v)", i, got[i].ID, id, ids)
+		}
+	}
+}
+
+func TestGetByIDsFillsCacheByPrimaryKey(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	// uint ids load rows whose keys must match later int lookups.
+	if _, err := g.GetByIDs(ctx, []any{uint(2), uint(1)}); err != nil {
+		t.Fatal(err)
+	}
+	waitLen(t, g.cache, 2)
+
+	before := q.count()
+	got, err := g.GetByIDs(ctx, []any{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Name != "ann" || got[1].Name != "bob" {
+		t.Errorf("GetByIDs = %+v", got)
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("warm GetByIDs ran %d queries, want 0", n)
+	}
+}
+
+/* ------------------ Per-request bypass ------------------ */
+
+func TestGetByIDWithSkipCacheRefreshesEntry(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&user{}).Where("id = ?", 1).Update("name", "ann2").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	before := q.count()
+	got, err := g.GetByID(WithSkipCache(ctx), 1)
+	if err != nil || got.Name != "ann2" {
+		t.Fatalf("forced GetByID = %+v, %v; want ann2", got, err)
+	}
+	if n := q.count() - before; n != 1 {
+		t.Errorf("forced GetByID ran %d queries, want 1", n)
+	}
+
+	// The refreshed row was written back synchronously.
+	before = q.count()
+	if got, err := g.GetByID(ctx, 1); err != nil || got.Name != "ann2" {
+		t.Errorf("GetByID after refresh = %+v, %v; want ann2", got, err)
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("GetByID after refresh ran %d queries, want 0", n)
+	}
+}
+
+func TestGetByIDWithSkipCacheDropsDeletedRow(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByID(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&user{}, 2).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.GetByID(WithSkipCache(ctx), 2); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("forced GetByID = %v, want ErrRecordNotFound", err)
+	}
+	if isCached(t, g, 2) {
+		t.Error("entry of a deleted row kept")
+	}
+}
+
+func TestGetByIDsWithSkipCacheRefreshesEntries(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByIDs(ctx, []any{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	waitLen(t, g.cache, 3)
+	if err := db.Model(&user{}).Where("id = ?", 1).Update("name", "ann2").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&user{}, 3).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	before := q.count()
+	got, err := g.GetByIDs(WithSkipCache(ctx), []any{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Name != "ann2" || got[1].Name != "bob" || got[2].ID != 0 {
+		t.Errorf("forced GetByIDs = %+v", got)
+	}
+	if n := q.count() - before; n != 1 {
+		t.Errorf("forced GetByIDs ran %d queries, want 1", n)
+	}
+
+	if isCached(t, g, 3) {
+		t.Error("entry of a deleted row kept")
+	}
+	before = q.count()
+	if got, err := g.GetByID(ctx, 1); err != nil || got.Name != "ann2" {
+		t.Errorf("GetByID after refresh = %+v, %v; want ann2", got, err)
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("GetByID after refresh ran %d queries, want 0", n)
+	}
+}
+
+/* ------------------ Warmup ------------------ */
+
+func TestWarmServesGetByIDFromCache(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	n, err := g.Warm(ctx, nil)
+	if err != nil || n != 4 {
+		t.Fatalf("Warm = %d, %v; want 4", n, err)
+	}
+
+	before := q.count()
+	for _, u := range testUsers() {
+		got, err := g.GetByID(ctx, u.ID)
+		if err != nil || got.Name != u.Name {
+			t.Errorf("GetByID(%d) = %+v, %v", u.ID, got, err)
+		}
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("GetByID after Warm ran %d queries, want 0", n)
+	}
+}
+
+func TestWarmScopeLimitsRows(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	n, err := g.Warm(ctx, activeInOrg5)
+	if err != nil || n != 2 {
+		t.Fatalf("Warm = %d, %v; want the 2 active users of org 5", n, err)
+	}
+	if !isCached(t, g, 1) || !isCached(t, g, 3) {
+		t.Error("scoped rows not cached")
+	}
+	if isCached(t, g, 2) || isCached(t, g, 4) {
+		t.Error("rows outside the scope cached")
+	}
+	if n := q.count(); n != 1 {
+		t.Errorf("Warm ran %d queries, want a single Find", n)
+	}
+}
+
+func TestWarmCacheOptionWarmsInBackground(t *testing.T) {
+	db, _ := newTestDB(t, testUsers()...)
+	opts := DefaultGORMOptions()
+	opts.WarmCache = true
+	g := NewGORMCache[user](newEntityCache[user](t), db, opts)
+
+	waitLen(t, g.cache, 4)
+}
+
+func TestWarmWithSkipCache(t *testing.T) {
+	db, _ := newTestDB(t, testUsers()...)
+	opts := DefaultGORMOptions()
+	opts.SkipCache = true
+	g := NewGORMCache[user](newEntityCache[user](t), db, opts)
+
+	if n, err := g.Warm(context.Background(), nil); err != nil || n != 0 {
+		t.Errorf("Warm = %d, %v; want nothing cached", n, err)
+	}
+	if n, _ := g.cache.Len(context.Background()); n != 0 {
+		t.Errorf("cache holds %d entries, want 0", n)
+	}
+}
+
+/* ------------------ Async fills ------------------ */
+
+// gatedCache holds every Set and SetManyPipeline until gate is closed,
+// tracking how many run at once and the deadline each one got.
+type gatedCache[T any] struct {
+	interfaces.AdvancedCache[T]
+	gate    chan struct{}
+	running atomic.Int64
+	peak    atomic.Int64
+	ctxErr  chan error
+}
+
+func newGatedCache[T any](t *testing.T) *gatedCache[T] {
+	return &gatedCache[T]{
+		AdvancedCache: newEntityCache[T](t),
+		gate:          make(chan struct{}),
+		ctxErr:        make(chan error, 100),
+	}
+}
+
+func (c *gatedCache[T]) hold(ctx context.Context) {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		p := c.peak.Load()
+		if n <= p || c.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	select {
+	case <-c.gate:
+		c.ctxErr <- ctx.Err()
+	case <-ctx.Done():
+		c.ctxErr <- ctx.Err()
+	}
+}
+
+func (c *gatedCache[T]) Set(ctx context.Context, key string, val T, ttl time.Duration) error {
+	c.hold(ctx)
+	return c.AdvancedCache.Set(ctx, key, val, ttl)
+}
+
+func (c *gatedCache[T]) SetManyPipeline(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	c.hold(ctx)
+	return c.AdvancedCache.SetManyPipeline(ctx, items, ttl)
+}
+
+func TestAsyncFillsRespectMaxAsyncWrites(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	gc := newGatedCache[user](t)
+	opts := DefaultGORMOptions()
+	opts.MaxAsyncWrites = 2
+	g := NewGORMCache[user](gc, db, opts)
+
+	for id := 1; id <= 4; id++ {
+		if _, err := g.Preload(ctx, id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for deadline := time.Now().Add(time.Second); gc.running.Load() < 2; time.Sleep(2 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d fills running, want 2", gc.running.Load())
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := gc.peak.Load(); n != 2 {
+		t.Errorf("peak concurrent fills = %d, want 2", n)
+	}
+
+	// The fills that got a slot still land; the rest were skipped.
+	close(gc.gate)
+	waitLen(t, gc.AdvancedCache, 2)
+	if n, _ := gc.Len(ctx); n != 2 {
+		t.Errorf("cache holds %d entries, want the 2 admitted fills", n)
+	}
+
+	// Once the slots are free, later fills run again.
+	if _, err := g.GetByIDs(ctx, []any{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	waitLen(t, gc.AdvancedCache, 4)
+}
+
+func TestAsyncFillUsesAsyncWriteTimeout(t *testing.T) {
+	db, _ := newTestDB(t, testUsers()...)
+	gc := newGatedCache[user](t)
+	opts := DefaultGORMOptions()
+	opts.DefaultTTL = time.Hour
+	opts.AsyncWriteTimeout = 20 * time.Millisecond
+	g := NewGORMCache[user](gc, db, opts)
+
+	// The fill outlives the request's context but not AsyncWriteTimeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := g.GetByIDs(ctx, []any{1}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case err := <-gc.ctxErr:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("fill context ended with %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("fill not bounded by AsyncWriteTimeout")
+	}
+}
+
+func TestGORMOptionsDefaultAsyncLimits(t *testing.T) {
+	db, _ := newTestDB(t)
+	g := NewGORMCache[user](newEntityCache[user](t), db, GORMOptions{})
+	if g.opts.AsyncWriteTimeout != defaultAsyncWriteTimeout || cap(g.fills) != defaultMaxAsyncWrites {
+		t.Errorf("async limits = %v, %d; want the defaults", g.opts.AsyncWriteTimeout, cap(g.fills))
+	}
+}
+
+func BenchmarkGetByIDs10k(b *testing.B) {
+	const n = 10_000
+	ctx := WithSkipCache(context.Background())
+
+	db, err := gorm.Open(sqlite.Open("file:bench10k?mode=memory&cache=shared"),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := db.AutoMigrate(&user{}); err != nil {
+		b.Fatal(err)
+	}
+	users := make([]user, n)
+	ids := make([]any, n)
+	for i := range users {
+		users[i] = user{ID: uint(i + 1), Name: "u", Email: strconv.Itoa(i), OrgID: i % 10}
+		// Request ids in reverse so the merge cannot rely on row order.
+		ids[n-1-i] = i + 1
+	}
+	if err := db.CreateInBatches(users, 500).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	c, err := cache.NewAdvancedMemory[user]()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+	g := NewGORMCache[user](c, db)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		got, err := g.GetByIDs(ctx, ids)
+		if err != nil || len(got) != n || got[0].ID != n {
+			b.Fatalf("GetByIDs = %d results, %v", len(got), err)
+		}
+	}
+}
+
+/* ------------------ RefreshMany ------------------ */
+
+func TestRefreshManyRecachesUpdatedRows(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	ids := []any{1, 2, 3}
+	if _, err := g.GetByIDs(ctx, ids); err != nil {
+		t.Fatal(err)
+	}
+	waitLen(t, g.cache, 3)
+
+	// A bulk update behind the cache's back.
+	if err := db.Model(&user{}).Where("org_id = ?", 5).Update("name", "renamed").Error; err != nil {
+		t.Fatal(err)
+	}
+	before := q.count()
+	if err := g.RefreshMany(ctx, ids); err != nil {
+		t.Fatal(err)
+	}
+	if n := q.count() - before; n != 1 {
+		t.Errorf("RefreshMany ran %d queries, want 1", n)
+	}
+
+	before = q.count()
+	got, err := g.GetByIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range got {
+		if u.Name != "renamed" {
+			t.Errorf("result[%d] = %+v, want the refreshed row", i, u)
+		}
+	}
+	if n := q.count() - before; n != 0 {
+		t.Errorf("GetByIDs after RefreshMany ran %d queries, want 0", n)
+	}
+}
+
+func TestRefreshManyDropsDeletedRows(t *testing.T) {
+	ctx := context.Background()
+	db, _ := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetByID(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&user{ID: 4}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RefreshMany(ctx, []any{1, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := g.cache.Exists(ctx, g.buildKey(4)); ok {
+		t.Error("deleted row still cached after RefreshMany")
+	}
+}
+
+func TestRefreshManyAllRowsGone(t *testing.T) {
+	ctx := context.Background()
+	db, q := newTestDB(t, testUsers()...)
+	g := NewGORMCache[user](newEntityCache[user](t), db)
+
+	if _, err := g.GetBy(ctx, "email", "dee@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GetByID(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&user{ID: 4}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Duplicate ids and no surviving rows: everything for the row goes.
+	before := q.count()
+	if err := g.RefreshMany(ctx, []any{4, 4, 99}); err != nil {
+		t.Fatal(err)
+	}
+	if n := q.count() - before; n != 1 {
+		t.Errorf("RefreshMany ran %d queries, want 1", n)
+	}
+	if isCached(t, g, 4) {
+		t.Error("deleted row still cached by primary key")
+	}
+	if ok, _ := g.cache.Exists(ctx, g.columnKey("email", "dee@example.com")); ok {
+		t.Error("deleted row still cached by email")
+	}
+}
+
+func TestGetByIDsBatchesDatabaseLoads(t *testing.T) {
+	ctx := context.Background()
+	users := make([]user, 10)
+	for i := range users {
+		users[i] = user{ID: uint(i + 1), Name: "u", Email: strconv.Itoa(i), OrgID: 1}
+	}
+	db, q := newTestDB(t, users...)
+
+	var maxVars atomic.Int64
+	if err := db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		if n := int64(len(tx.Statement.Vars)); n > maxVars.Load() {
+			maxVars.Store(n)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultGORMOptions()
+	opts.DBBatchSize = 3
+	g := NewGORMCache[user](newEntityCache[user](t), db, opts)
+
+	ids := make([]any, len(users))
+	for i := range ids {
+		ids[i] = len(users) - i
+	}
+	got, err := g.GetByIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range got {
+		if int(u.ID) != ids[i] {
+			t.Errorf("result[%d] = user %d, want %v", i, u.ID, ids[i])
+		}
+	}
+	if n := q.count(); n != 4 {
+		t.Errorf("ran %d queries for 10 ids in batches of 3, want 4", n)
+	}
+	if n := maxVars.Load(); n == 0 || n > 3 {
+		t.Errorf("a query bound %d ids, want at most 3", n)
+	}
+}
@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Cached Response ------------------ */
+
+// DefaultCachedHeaders are the response headers replayed on a cache hit
+// when HTTPCacheOptions.CachedHeaders is empty.
+var DefaultCachedHeaders = []string{
+	"Content-Type",
+	"Content-Encoding",
+	"Content-Language",
+	"Cache-Control",
+	"Expires",
+	"Last-Modified",
+	"ETag",
+}
+
+// CachedResponse is what the HTTP middlewares store: enough of the
+// upstream response to replay it byte-for-byte.
+type CachedResponse struct {
+	StatusCode int               `json:"status"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       []byte            `json:"body"`
+}
+
+// encodeResponse turns a CachedResponse into the cache's value type via
+// the middleware serializer. Value types that cannot hold a
+// CachedResponse, such as unrelated structs, would decode it to a blank
+// value, so the result is decoded again and rejected unless it still
+// reads as resp.
+func encodeResponse[T any](s base.Serializer[T], resp CachedResponse) (T, error) {
+	var zero T
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return zero, fmt.Errorf("%w: %v", base.ErrSerialize, err)
+	}
+	v, err := s.Decode(data)
+	if err != nil {
+		return zero, err
+	}
+
+	got, err := decodeResponse(s, v)
+	if err != nil || !sameResponse(got, resp) {
+		return zero, fmt.Errorf("%w: %T cannot hold a cached response", base.ErrSerialize, zero)
+	}
+	return v, nil
+}
+
+// decodeResponse is the inverse of encodeResponse. Values without a
+// status code are not cached responses and fail with ErrDeserialize.
+func decodeResponse[T any](s base.Serializer[T], v T) (CachedResponse, error) {
+	var resp CachedResponse
+
+	data, err := s.Encode(v)
+	if err != nil {
+		return resp, err
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, fmt.Errorf("%w: %v", base.ErrDeserialize, err)
+	}
+	if resp.StatusCode == 0 {
+		return resp, fmt.Errorf("%w: not a cached response", base.ErrDeserialize)
+	}
+	return resp, nil
+}
+
+func sameResponse(a, b CachedResponse) bool {
+	return a.StatusCode == b.StatusCode &&
+		maps.Equal(a.Headers, b.Headers) &&
+		bytes.Equal(a.Body, b.Body)
+}
+
+func (o HTTPCacheOptions) cachedHeaders() []string {
+	if len(o.CachedHeaders) == 0 {
+		return DefaultCachedHeaders
+	}
+	return o.CachedHeaders
+}
+
+// responseTTL derives the entry TTL from the upstream Cache-Control
+// (s-maxage, then max-age) or Expires header, falling back to def and
+// capping at MaxTTL. ok=false means the response must not be cached.
+func (o HTTPCacheOptions) responseTTL(
+	cacheControl string,
+	expires string,
+	now time.Time,
+	def time.Duration,
+) (ttl time.Duration, ok bool) {
+	ttl = def
+
+	if secs, found := maxAge(cacheControl); found {
+		if secs <= 0 {
+			return 0, false
+		}
+		ttl = time.Duration(secs) * time.Second
+	} else if expires != "" {
+		at, err := http.ParseTime(expires)
+		if err != nil || !at.After(now) {
+			return 0, false
+		}
+		ttl = at.Sub(now)
+	}
+
+	if o.MaxTTL > 0 && ttl > o.MaxTTL {
+		ttl = o.MaxTTL
+	}
+	return ttl, true
+}
+
+// maxAge returns s-maxage if present, otherwise max-age.
+func maxAge(cacheControl string) (int64, bool) {
+	var (
+		age      int64
+		found    bool
+		shared   int64
+		hasShare bool
+	)
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		n, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "s-maxage":
+			shared, hasShare = n, true
+		case "max-age":
+			age, found = n, true
+		}
+	}
+
+	if hasShare {
+		return shared, true
+	}
+	return age, found
+}
+
+/* ------------------ ETag ------------------ */
+
+// withETag makes sure resp carries an ETag, deriving one from the body
+// hash when the upstream did not send its own.
+func (o HTTPCacheOptions) withETag(resp CachedResponse) CachedResponse {
+	if resp.Headers == nil {
+		resp.Headers = make(map[string]string)
+	}
+	if resp.Headers["ETag"] != "" {
+		return resp
+	}
+
+	sum := sha256.Sum256(resp.Body)
+	tag := `"` + hex.EncodeToString(sum[:]) + `"`
+	if o.WeakETag {
+		tag = "W/" + tag
+	}
+	resp.Headers["ETag"] = tag
+	return resp
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using
+// weak comparison, as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultHTTPSerializer[T any]() base.Serializer[T] {
+	var zero T
+	if _, ok := any(zero).([]byte); ok {
+		return base.IdentitySerializer[T]{}
+	}
+	return &base.JsonSerializer[T]{}
+}
@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestResponseTTL(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	def := 5 * time.Minute
+	tests := []struct {
+		name          string
+		cacheControl  string
+		expires       string
+		maxTTL        time.Duration
+		want          time.Duration
+		wantCacheable bool
+	}{
+		{"no headers", "", "", 0, def, true},
+		{"max-age", "public, max-age=30", "", 0, 30 * time.Second, true},
+		{"s-maxage wins", "max-age=30, s-maxage=120", "", 0, 2 * time.Minute, true},
+		{"quoted", `max-age="60"`, "", 0, time.Minute, true},
+		{"max-age zero", "max-age=0", "", 0, 0, false},
+		{"max-age over Expires", "max-age=30", now.Add(time.Hour).Format(http.TimeFormat), 0, 30 * time.Second, true},
+		{"Expires", "public", now.Add(time.Hour).Format(http.TimeFormat), 0, time.Hour, true},
+		{"Expires in the past", "", now.Add(-time.Minute).Format(http.TimeFormat), 0, 0, false},
+		{"invalid Expires", "", "yesterday", 0, 0, false},
+		{"capped", "max-age=86400", "", time.Hour, time.Hour, true},
+		{"default capped", "", "", time.Minute, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultHTTPCacheOptions()
+			opts.MaxTTL = tt.maxTTL
+
+			got, ok := opts.responseTTL(tt.cacheControl, tt.expires, now, def)
+			if ok != tt.wantCacheable || (ok && got != tt.want) {
+				t.Errorf("responseTTL = %v, %v; want %v, %v", got, ok, tt.want, tt.wantCacheable)
+			}
+		})
+	}
+}
+
+func TestStdHTTPCacheSkipsMaxAgeZero(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	next := &countingHandler{header: http.Header{"Cache-Control": {"max-age=0"}}}
+	h := NewStdHTTPCache(c, time.Minute)(next)
+
+	for range 2 {
+		serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := next.calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+}
+
+func TestStdHTTPCacheHonorsMaxAge(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	next := &countingHandler{header: http.Header{"Cache-Control": {"max-age=1"}}}
+	h := NewStdHTTPCache(c, time.Hour)(next)
+
+	serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	waitLen(t, c, 1)
+	if rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("X-Cache = %q before max-age, want HIT", rec.Header().Get("X-Cache"))
+	}
+
+	// The entry lives for max-age, not the hour-long default.
+	time.Sleep(1100 * time.Millisecond)
+	if rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil)); rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("X-Cache = %q after max-age, want MISS", rec.Header().Get("X-Cache"))
+	}
+}
+
+/* ------------------ ETag ------------------ */
+
+func TestWithETag(t *testing.T) {
+	body := []byte("hello")
+	const sha = `"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"`
+
+	strong := DefaultHTTPCacheOptions().withETag(CachedResponse{Body: body})
+	if got := strong.Headers["ETag"]; got != sha {
+		t.Errorf("ETag = %s, want %s", got, sha)
+	}
+	weak := HTTPCacheOptions{WeakETag: true}.withETag(CachedResponse{Body: body})
+	if got := weak.Headers["ETag"]; got != "W/"+sha {
+		t.Errorf("weak ETag = %s, want W/%s", got, sha)
+	}
+	upstream := DefaultHTTPCacheOptions().withETag(CachedResponse{Body: body, Headers: map[string]string{"ETag": `"v1"`}})
+	if got := upstream.Headers["ETag"]; got != `"v1"` {
+		t.Errorf("upstream ETag replaced with %s", got)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch, etag string
+		want              bool
+	}{
+		{`"a"`, `"a"`, true},
+		{`"b", "a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"a"`, `W/"a"`, true},
+		{`*`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+		{``, `"a"`, false},
+		{`"a"`, ``, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+			t.Errorf("etagMatches(%s, %s) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestStdHTTPCacheConditionalRequests(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	next := &countingHandler{}
+	h := NewStdHTTPCache(c, time.Minute)(next)
+
+	first := serve(h, httptest.NewRequest(http.MethodGet, "/doc", nil))
+	waitLen(t, c, 1)
+	hit := serve(h, httptest.NewRequest(http.MethodGet, "/doc", nil))
+	etag := hit.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("cached response has no ETag")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/doc", nil)
+	r.Header.Set("If-None-Match", etag)
+	rec := serve(h, r)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("matching If-None-Match: %d with %d body bytes, want 304 and empty", rec.Code, rec.Body.Len())
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Errorf("304 ETag = %q, want %q", rec.Header().Get("ETag"), etag)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/doc", nil)
+	r.Header.Set("If-None-Match", `"stale"`)
+	rec = serve(h, r)
+	if rec.Code != http.StatusOK || rec.Body.String() != first.Body.String() {
+		t.Errorf("mismatched If-None-Match: %d %q, want 200 %q", rec.Code, rec.Body.String(), first.Body.String())
+	}
+	if n := next.calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+}
+
+func TestFastHTTPCacheConditionalRequests(t *testing.T) {
+	c := newEntityCache[[]byte](t)
+	var calls atomic.Int64
+	h := NewHTTPCache(c, time.Minute).Handler(htmlHandler(&calls))
+
+	doFast(h, fasthttp.MethodGet, "/")
+	waitLen(t, c, 1)
+	etag := string(doFast(h, fasthttp.MethodGet, "/").Response.Header.Peek("ETag"))
+	if etag == "" {
+		t.Fatal("cached response has no ETag")
+	}
+
+	ctx := doFast(h, fasthttp.MethodGet, "/", "If-None-Match", etag)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotModified || len(ctx.Response.Body()) != 0 {
+		t.Errorf("matching If-None-Match: %d with %d body bytes, want 304 and empty",
+			ctx.Response.StatusCode(), len(ctx.Response.Body()))
+	}
+
+	ctx = doFast(h, fasthttp.MethodGet, "/", "If-None-Match", `"stale"`)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK || string(ctx.Response.Body()) != htmlPage {
+		t.Errorf("mismatched If-None-Match: %d %q, want 200 and the page",
+			ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+}
+
+/* ------------------ Value types ------------------ */
+
+// apiUser is a value type that cannot hold a CachedResponse.
+type apiUser struct {
+	Name string `json:"name"`
+}
+
+func TestEncodeResponseRejectsUnfitValueType(t *testing.T) {
+	resp := CachedResponse{StatusCode: http.StatusOK, Body: []byte(`{"name":"ann"}`)}
+	if _, err := encodeResponse[apiUser](&base.JsonSerializer[apiUser]{}, resp); !errors.Is(err, base.ErrSerialize) {
+		t.Errorf("encodeResponse into a struct = %v, want ErrSerialize", err)
+	}
+	if _, err := decodeResponse[apiUser](&base.JsonSerializer[apiUser]{}, apiUser{Name: "ann"}); !errors.Is(err, base.ErrDeserialize) {
+		t.Errorf("decodeResponse of a struct = %v, want ErrDeserialize", err)
+	}
+
+	// A generic map holds every field, so it still round-trips.
+	s := &base.JsonSerializer[map[string]any]{}
+	v, err := encodeResponse[map[string]any](s, resp)
+	if err != nil {
+		t.Fatalf("encodeResponse into a map = %v", err)
+	}
+	if got, err := decodeResponse(s, v); err != nil || !sameResponse(got, resp) {
+		t.Errorf("round trip through a map = %+v, %v", got, err)
+	}
+}
+
+// userHandler answers with a JSON user and counts its calls.
+func userHandler(calls *atomic.Int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"ann"}`))
+	})
+}
+
+func TestStdHTTPCacheStructValueNeverServesBlank(t *testing.T) {
+	c := newEntityCache[apiUser](t)
+	var calls atomic.Int64
+	h := NewStdHTTPCache(c, time.Minute)(userHandler(&calls))
+
+	for i := range 2 {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"name":"ann"}` {
+			t.Errorf("request %d = %d %q, want the handler's user", i, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("X-Cache"); got != "MISS" {
+			t.Errorf("request %d X-Cache = %q, want MISS", i, got)
+		}
+		// Give the background write time to (not) land.
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n, _ := c.Len(context.Background()); n != 0 {
+		t.Errorf("cache holds %d blank entries, want 0", n)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+}
+
+func TestFastHTTPCacheStructValueNeverServesBlank(t *testing.T) {
+	c := newEntityCache[apiUser](t)
+	var calls atomic.Int64
+	h := NewHTTPCache(c, time.Minute).Handler(func(ctx *fasthttp.RequestCtx) {
+		calls.Add(1)
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(`{"name":"ann"}`)
+	})
+
+	for i := range 2 {
+		rc := doFast(h, fasthttp.MethodGet, "/users/1")
+		if rc.Response.StatusCode() != fasthttp.StatusOK || string(rc.Response.Body()) != `{"name":"ann"}` {
+			t.Errorf("request %d = %d %q, want the handler's user", i, rc.Response.StatusCode(), rc.Response.Body())
+		}
+		if got := string(rc.Response.Header.Peek("X-Cache")); got != "MISS" {
+			t.Errorf("request %d X-Cache = %q, want MISS", i, got)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n, _ := c.Len(context.Background()); n != 0 {
+		t.Errorf("cache holds %d blank entries, want 0", n)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+}
@@ -0,0 +1,231 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Middleware ------------------ */
+
+type stdHTTPCache[T any] struct {
+	cache      interfaces.AdvancedCache[T]
+	serializer base.Serializer[T]
+	ttl        time.Duration
+	opts       HTTPCacheOptions
+	skip       map[string]struct{}
+}
+
+// NewStdHTTPCache creates a net/http middleware with the same semantics as
+// the fasthttp HTTPCacheMiddleware: responses are stored as CachedResponse
+// and replayed with their original status, headers, and body. T has the
+// same constraint as for NewHTTPCache.
+func NewStdHTTPCache[T any](
+	cache interfaces.AdvancedCache[T],
+	ttl time.Duration,
+	opts ...HTTPCacheOptions,
+) func(http.Handler) http.Handler {
+	options := DefaultHTTPCacheOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	m := &stdHTTPCache[T]{
+		cache:      cache,
+		serializer: defaultHTTPSerializer[T](),
+		ttl:        ttl,
+		opts:       options,
+		skip:       make(map[string]struct{}, len(options.SkipMethods)),
+	}
+	for _, method := range options.SkipMethods {
+		m.skip[method] = struct{}{}
+	}
+
+	return m.middleware
+}
+
+/* ------------------ Handler ------------------ */
+
+func (m *stdHTTPCache[T]) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.shouldSkip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := m.key(r)
+
+		cctx, cancel := context.WithTimeout(r.Context(), m.opts.Timeout)
+		cached, err := m.cache.Get(cctx, key)
+		cancel()
+
+		if err == nil {
+			if resp, derr := decodeResponse(m.serializer, cached); derr == nil {
+				m.serveFromCache(w, r, resp)
+				return
+			}
+		} else if !base.IsCacheMiss(err) {
+			// Cache error → fail open
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Cache miss
+		w.Header().Set("X-Cache", "MISS")
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Async cache write
+		if !m.isCacheableResponse(rec) {
+			return
+		}
+		ttl, ok := m.opts.responseTTL(
+			rec.Header().Get("Cache-Control"),
+			rec.Header().Get("Expires"),
+			time.Now(),
+			m.ttl,
+		)
+		if ok {
+			resp := m.captureResponse(rec)
+			m.cache.Go(func() {
+				_ = m.cacheResponse(key, resp, ttl)
+			})
+		}
+	})
+}
+
+/* ------------------ Cache Helpers ------------------ */
+
+func (m *stdHTTPCache[T]) serveFromCache(w http.ResponseWriter, r *http.Request, resp CachedResponse) {
+	resp = m.opts.withETag(resp)
+
+	h := w.Header()
+	for name, value := range resp.Headers {
+		h.Set(name, value)
+	}
+	h.Set("X-Cache", "HIT")
+
+	if etagMatches(r.Header.Get("If-None-Match"), resp.Headers["ETag"]) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(resp.Body)
+}
+
+func (m *stdHTTPCache[T]) captureResponse(rec *responseRecorder) CachedResponse {
+	resp := CachedResponse{
+		StatusCode: rec.status,
+		Headers:    make(map[string]string),
+		Body:       append([]byte(nil), rec.body.Bytes()...),
+	}
+	for _, name := range m.opts.cachedHeaders() {
+		if v := rec.Header().Get(name); v != "" {
+			resp.Headers[name] = v
+		}
+	}
+	return m.opts.withETag(resp)
+}
+
+func (m *stdHTTPCache[T]) cacheResponse(key string, resp CachedResponse, ttl time.Duration) error {
+	val, err := encodeResponse(m.serializer, resp)
+	if err != nil {
+		return err
+	}
+
+	return m.cache.Set(context.Background(), key, val, ttl)
+}
+
+/* ------------------ Cacheability ------------------ */
+
+func (m *stdHTTPCache[T]) isCacheableResponse(rec *responseRecorder) bool {
+	cacheable := false
+	for _, code := range m.opts.CacheableStatuses {
+		if code == rec.status {
+			cacheable = true
+			break
+		}
+	}
+	if !cacheable {
+		return false
+	}
+
+	cc := rec.Header().Get("Cache-Control")
+	return !strings.Contains(cc, "no-cache") && !strings.Contains(cc, "no-store")
+}
+
+/* ------------------ Key / Skip Logic ------------------ */
+
+func (m *stdHTTPCache[T]) key(r *http.Request) string {
+	var key strings.Builder
+
+	key.WriteString(r.Method)
+	key.WriteByte(':')
+	key.WriteString(r.URL.Path)
+
+	if q := r.URL.RawQuery; q != "" {
+		key.WriteByte('?')
+		key.WriteString(q)
+	}
+
+	for _, h := range m.opts.VaryHeaders {
+		if v := r.Header.Get(h); v != "" {
+			key.WriteByte('|')
+			key.WriteString(h)
+			key.WriteByte('=')
+			key.WriteString(v)
+		}
+	}
+
+	return key.String()
+}
+
+func (m *stdHTTPCache[T]) shouldSkip(r *http.Request) bool {
+	if _, ok := m.skip[r.Method]; ok {
+		return true
+	}
+
+	if m.opts.BypassHeader != "" && len(r.Header.Values(m.opts.BypassHeader)) > 0 {
+		return true
+	}
+
+	return strings.Contains(r.Header.Get("Cache-Control"), "no-store")
+}
+
+/* ------------------ Response Recorder ------------------ */
+
+// responseRecorder passes writes through to the client while keeping a
+// copy of the status and body for caching.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+// countingHandler answers with the path and counts the requests it served.
+type countingHandler struct {
+	calls  atomic.Int64
+	status int
+	header http.Header
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	w.Header().Set("Content-Type", "text/plain")
+	for name, v := range h.header {
+		w.Header()[name] = v
+	}
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+	}
+	fmt.Fprintf(w, "%s #%d", r.URL.Path, n)
+}
+
+// waitLen waits for the middleware's async writes to reach n entries.
+func waitLen[T any](t *testing.T, c interfaces.AdvancedCache[T], n int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(2 * time.Millisecond) {
+		if got, _ := c.Len(context.Background()); got >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("cache never reached %d entries", n)
+		}
+	}
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestStdHTTPCacheServesHits(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	next := &countingHandler{header: http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}}}
+	h := NewStdHTTPCache(c, time.Minute)(next)
+
+	first := serve(h, httptest.NewRequest(http.MethodGet, "/items?page=2", nil))
+	if first.Code != http.StatusOK || first.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first response: %d %q", first.Code, first.Header().Get("X-Cache"))
+	}
+	waitLen(t, c, 1)
+
+	second := serve(h, httptest.NewRequest(http.MethodGet, "/items?page=2", nil))
+	if second.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("second response X-Cache = %q, want HIT", second.Header().Get("X-Cache"))
+	}
+	if got, want := second.Body.String(), first.Body.String(); got != want {
+		t.Errorf("cached body = %q, want %q", got, want)
+	}
+	for _, name := range []string{"Content-Type", "Last-Modified"} {
+		if got := second.Header().Get(name); got != first.Header().Get(name) {
+			t.Errorf("cached %s = %q, want %q", name, got, first.Header().Get(name))
+		}
+	}
+	if n := next.calls.Load(); n != 1 {
+		t.Errorf("handler called %d times, want 1", n)
+	}
+
+	// A different query string is a different entry.
+	if rec := serve(h, httptest.NewRequest(http.MethodGet, "/items?page=3", nil)); rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("other query X-Cache = %q, want MISS", rec.Header().Get("X-Cache"))
+	}
+}
+
+func TestStdHTTPCacheVaryHeaders(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	next := &countingHandler{}
+	h := NewStdHTTPCache(c, time.Minute)(next)
+
+	for i, accept := range []string{"text/html", "application/json"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		serve(h, r)
+		waitLen(t, c, i+1)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/html")
+	if rec := serve(h, r); rec.Header().Get("X-Cache") != "HIT" || rec.Body.String() != "/ #1" {
+		t.Errorf("Accept: text/html got %q (%s), want the first response", rec.Body.String(), rec.Header().Get("X-Cache"))
+	}
+	if n := next.calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+}
+
+func TestStdHTTPCacheSkips(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		next    *countingHandler
+	}{
+		{"skipped method", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, "/", nil)
+		}, &countingHandler{}},
+		{"bypass header", func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Cache-Bypass", "1")
+			return r
+		}, &countingHandler{}},
+		{"request no-store", func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Cache-Control", "no-store")
+			return r
+		}, &countingHandler{}},
+		{"uncacheable status", func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/", nil)
+		}, &countingHandler{status: http.StatusInternalServerError}},
+		{"response no-store", func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/", nil)
+		}, &countingHandler{header: http.Header{"Cache-Control": {"no-store"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newEntityCache[CachedResponse](t)
+			h := NewStdHTTPCache(c, time.Minute)(tt.next)
+
+			for range 2 {
+				serve(h, tt.request())
+			}
+			time.Sleep(20 * time.Millisecond)
+			if n := tt.next.calls.Load(); n != 2 {
+				t.Errorf("handler called %d times, want 2", n)
+			}
+			if n, _ := c.Len(context.Background()); n != 0 {
+				t.Errorf("%d entries cached, want none", n)
+			}
+		})
+	}
+}
+
+func TestStdHTTPCacheFailsOpen(t *testing.T) {
+	c := newEntityCache[CachedResponse](t)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	next := &countingHandler{}
+	h := NewStdHTTPCache(c, time.Minute)(next)
+
+	for range 2 {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+		body, _ := io.ReadAll(rec.Body)
+		if rec.Code != http.StatusOK || len(body) == 0 {
+			t.Fatalf("response with a failing cache: %d %q", rec.Code, body)
+		}
+	}
+	if n := next.calls.Load(); n != 2 {
+		t.Errorf("handler called %d times, want 2", n)
+	}
+}
@@ -0,0 +1,124 @@
+package advanced
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* ------------------ Adaptive TTL ------------------ */
+
+const (
+	// maxAdaptivePrefixes bounds how many prefixes' hit rates are tracked.
+	maxAdaptivePrefixes = 10_000
+
+	// adaptiveMinSamples is how many GetOrSet lookups a prefix needs
+	// before its TTL is scaled.
+	adaptiveMinSamples = 10
+
+	// adaptiveWindow halves a prefix's counts once they reach it, so the
+	// hit rate follows recent traffic.
+	adaptiveWindow = 1000
+)
+
+// WithAdaptiveTTL makes GetOrSet and GetOrSetLocked scale the TTL they
+// write by the hit rate their lookups observe for the key's prefix: from
+// a quarter of the TTL for prefixes that are never hit, through the TTL
+// itself at a 50% hit rate, to four times it for prefixes that always
+// are, clamped to [minTTL, maxTTL]. Hot data is kept longer and one-shot
+// data expires sooner. prefixFn maps a key to its prefix; nil uses the key
+// up to and including its first ':' (the whole key if it has none).
+// Rates are process-local. maxTTL < minTTL or maxTTL <= 0 disables it.
+func WithAdaptiveTTL[T any](minTTL, maxTTL time.Duration, prefixFn func(key string) string) Option[T] {
+	return func(a *advancedCache[T]) {
+		if maxTTL <= 0 || maxTTL < minTTL {
+			return
+		}
+		if prefixFn == nil {
+			prefixFn = defaultTTLPrefix
+		}
+		a.adaptive = &adaptiveTTL{
+			min:    minTTL,
+			max:    maxTTL,
+			prefix: prefixFn,
+			stats:  make(map[string]*hitCounts),
+		}
+	}
+}
+
+func defaultTTLPrefix(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
+
+type adaptiveTTL struct {
+	min, max time.Duration
+	prefix   func(key string) string
+
+	mu    sync.Mutex
+	stats map[string]*hitCounts
+}
+
+type hitCounts struct {
+	hits, misses int
+}
+
+// observe records a GetOrSet lookup of key.
+func (t *adaptiveTTL) observe(key string, hit bool) {
+	if t == nil {
+		return
+	}
+	p := t.prefix(key)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.stats[p]
+	if !ok {
+		if len(t.stats) >= maxAdaptivePrefixes {
+			for k := range t.stats {
+				delete(t.stats, k)
+				break
+			}
+		}
+		c = &hitCounts{}
+		t.stats[p] = c
+	}
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	if c.hits+c.misses >= adaptiveWindow {
+		c.hits /= 2
+		c.misses /= 2
+	}
+}
+
+// apply scales the resolved ttl for key. NoExpiration and prefixes with
+// too few lookups keep ttl unchanged.
+func (t *adaptiveTTL) apply(key string, ttl time.Duration) time.Duration {
+	if t == nil || ttl <= 0 {
+		return ttl
+	}
+	p := t.prefix(key)
+
+	t.mu.Lock()
+	c, ok := t.stats[p]
+	var hits, total int
+	if ok {
+		hits, total = c.hits, c.hits+c.misses
+	}
+	t.mu.Unlock()
+
+	if total < adaptiveMinSamples {
+		return ttl
+	}
+
+	rate := float64(hits) / float64(total)
+	scaled := time.Duration(float64(ttl) * math.Pow(4, 2*rate-1))
+	return min(max(scaled, t.min), t.max)
+}
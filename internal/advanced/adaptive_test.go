@@ -0,0 +1,133 @@
+package advanced
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// storedTTL returns the remaining TTL GetOrSet wrote for key.
+func storedTTL[T any](t *testing.T, a *advancedCache[T], key string) time.Duration {
+	t.Helper()
+	_, remaining, err := a.getWithTTL(context.Background(), key, true)
+	if err != nil {
+		t.Fatalf("Get(%s) = %v", key, err)
+	}
+	return remaining
+}
+
+func TestAdaptiveTTLGrowsForHotKey(t *testing.T) {
+	ctx := context.Background()
+	const ttl = time.Minute
+	a := newTestCache[int](t, testConfig(), nil, WithAdaptiveTTL[int](time.Second, time.Hour, nil))
+	fn := func() (int, error) { return 1, nil }
+
+	if _, err := a.GetOrSet(ctx, "user:1", ttl, fn); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTTL(t, a, "user:1"); got > ttl {
+		t.Errorf("first TTL = %v, want at most %v before any samples", got, ttl)
+	}
+
+	for range 30 {
+		if _, err := a.GetOrSet(ctx, "user:1", ttl, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The refill after a delete sees the prefix's high hit rate.
+	_ = a.Delete(ctx, "user:1")
+	if _, err := a.GetOrSet(ctx, "user:1", ttl, fn); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTTL(t, a, "user:1"); got <= 2*ttl {
+		t.Errorf("TTL of a hot key = %v, want well above %v", got, ttl)
+	}
+}
+
+func TestAdaptiveTTLShrinksForChurnyPrefix(t *testing.T) {
+	ctx := context.Background()
+	const ttl = time.Minute
+	a := newTestCache[int](t, testConfig(), nil, WithAdaptiveTTL[int](time.Second, time.Hour, nil))
+	fn := func() (int, error) { return 1, nil }
+
+	// One-shot keys under one prefix: every lookup misses.
+	for i := range 20 {
+		if _, err := a.GetOrSet(ctx, "req:"+strconv.Itoa(i), ttl, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.GetOrSet(ctx, "req:last", ttl, fn); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTTL(t, a, "req:last"); got >= ttl/2 {
+		t.Errorf("TTL of a churny prefix = %v, want well below %v", got, ttl)
+	}
+}
+
+func TestAdaptiveTTLClampsToBounds(t *testing.T) {
+	tt := &adaptiveTTL{
+		min:    30 * time.Second,
+		max:    2 * time.Minute,
+		prefix: defaultTTLPrefix,
+		stats:  map[string]*hitCounts{"hot:": {hits: 100}, "cold:": {misses: 100}},
+	}
+	if got := tt.apply("hot:1", time.Minute); got != 2*time.Minute {
+		t.Errorf("hot TTL = %v, want the 2m maximum", got)
+	}
+	if got := tt.apply("cold:1", time.Minute); got != 30*time.Second {
+		t.Errorf("cold TTL = %v, want the 30s minimum", got)
+	}
+	if got := tt.apply("hot:1", base.NoExpiration); got != base.NoExpiration {
+		t.Errorf("NoExpiration scaled to %v", got)
+	}
+}
+
+func TestAdaptiveTTLCustomPrefix(t *testing.T) {
+	ctx := context.Background()
+	const ttl = time.Minute
+	// Everything after the last '/' is the id; the rest is shared.
+	prefix := func(key string) string { return key[:strings.LastIndexByte(key, '/')+1] }
+	a := newTestCache[int](t, testConfig(), nil, WithAdaptiveTTL[int](time.Second, time.Hour, prefix))
+	fn := func() (int, error) { return 1, nil }
+
+	_, _ = a.GetOrSet(ctx, "org/5/user/1", ttl, fn)
+	for range 30 {
+		_, _ = a.GetOrSet(ctx, "org/5/user/1", ttl, fn)
+	}
+
+	// A different id under the same prefix inherits its hit rate.
+	if _, err := a.GetOrSet(ctx, "org/5/user/2", ttl, fn); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTTL(t, a, "org/5/user/2"); got <= 2*ttl {
+		t.Errorf("TTL = %v, want the hot prefix's longer TTL", got)
+	}
+	if _, ok := a.adaptive.stats["org/5/user/"]; !ok {
+		t.Errorf("stats keyed by %v, want the custom prefix", a.adaptive.stats)
+	}
+}
+
+func TestAdaptiveTTLOffByDefault(t *testing.T) {
+	ctx := context.Background()
+	const ttl = time.Minute
+	a := newTestCache[int](t, testConfig(), nil)
+	fn := func() (int, error) { return 1, nil }
+
+	for range 30 {
+		_, _ = a.GetOrSet(ctx, "user:1", ttl, fn)
+	}
+	_ = a.Delete(ctx, "user:1")
+	_, _ = a.GetOrSet(ctx, "user:1", ttl, fn)
+	if got := storedTTL(t, a, "user:1"); got > ttl {
+		t.Errorf("TTL = %v without WithAdaptiveTTL, want at most %v", got, ttl)
+	}
+
+	if b := newTestCache[int](t, testConfig(), nil, WithAdaptiveTTL[int](time.Minute, time.Second, nil)); b.adaptive != nil {
+		t.Error("maxTTL < minTTL did not disable the policy")
+	}
+}
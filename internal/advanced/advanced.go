@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/os-golib/go-cache/config"
@@ -18,6 +19,50 @@ type advancedCache[T any] struct {
 	cache interfaces.Cache[T]
 	base  *base.Base
 	cfg   config.Config
+	deps  *depGraph
+
+	// background workers (refresh-ahead)
+	refreshMu  sync.Mutex
+	refreshers map[refresherID]chan struct{}
+	newTicker  tickerFunc
+	workers    sync.WaitGroup
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+
+	// root is the cache a namespace view was made from (nil otherwise):
+	// the view's workers also count toward its Close and stop with it
+	root *advancedCache[T]
+
+	loader Loader[T]
+
+	// deduplicated calls, one group per API so callers never share a
+	// call made with another API's function and TTL: the loader,
+	// GetOrSetStale misses and revalidation, GetOrSetProtected misses and
+	// its background refreshes
+	loads     base.FlightGroup[T]
+	stale     base.FlightGroup[T]
+	protected base.FlightGroup[T]
+	refreshes base.FlightGroup[T]
+
+	// XFetch early expiration (nil when disabled)
+	early *earlyExpiration
+
+	// retries of transient failures (nil when disabled)
+	retry *retryPolicy
+
+	// hit-rate driven GetOrSet TTLs (nil when disabled)
+	adaptive *adaptiveTTL
+
+	// health of backends that do not report their own
+	health metrics.HealthTracker
+
+	// in-flight operation tracking for graceful close
+	inflightMu sync.Mutex
+	inflight   int64
+	drained    chan struct{}
+	closed     bool
+	closeOnce  sync.Once
+	closeErr   error
 }
 
 /* ------------------ Constructor ------------------ */
@@ -25,59 +70,148 @@ type advancedCache[T any] struct {
 func NewAdvancedCache[T any](
 	cache interfaces.Cache[T],
 	cfg config.Config,
+	opts ...Option[T],
 ) interfaces.AdvancedCache[T] {
-	return &advancedCache[T]{
-		cache: cache,
-		cfg:   cfg,
-		base:  base.NewBase(cfg),
+	a := &advancedCache[T]{
+		cache:      cache,
+		cfg:        cfg,
+		base:       base.NewBase(cfg),
+		deps:       newDepGraph(),
+		refreshers: make(map[refresherID]chan struct{}),
+		newTicker:  realTicker,
+		stopCh:     make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.base.Metrics().SetStatsSource(a.Stats)
+	return a
 }
 
 /* ------------------ Helpers ------------------ */
 
+// withMetrics runs fn as operation op: it records metrics, counts the
+// operation as in flight and, with a tracer configured, wraps it in a span
+// whose context is passed to fn. Contexts without a deadline get
+// OperationTimeout, if set. Once the cache is closed it fails with
+// ErrClosed without running fn.
 func (a *advancedCache[T]) withMetrics(
+	ctx context.Context,
 	op string,
 	items int,
-	fn func() error,
+	fn func(ctx context.Context) error,
 ) error {
+	if err := a.beginOp(); err != nil {
+		return base.WrapError(base.Op(op), err, "")
+	}
+	defer a.endOp()
+
+	if d := a.cfg.OperationTimeout; d > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+	}
+
+	ctx, span := a.startSpan(ctx, op, items)
+	if span != nil {
+		defer span.End()
+	}
+	ctx, rec := a.startLog(ctx)
+
 	start := time.Now()
-	err := fn()
+	err := a.retry.run(ctx, fn)
+	elapsed := time.Since(start)
 
-	a.base.RecordOperation(op, time.Since(start), items)
+	a.base.RecordOperation(op, elapsed, items)
 	if err != nil {
 		a.base.RecordError(op)
+		if span != nil && !base.IsCacheMiss(err) {
+			span.RecordError(err)
+		}
+	}
+	if rec != nil {
+		a.logOp(ctx, rec, op, items, elapsed, err)
 	}
 	return err
 }
 
 /* ------------------ Core Operations ------------------ */
 
+// Get reads key. With a loader configured, a miss is loaded and cached
+// instead of returned.
 func (a *advancedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	val, err := a.get(ctx, key)
+	if a.loader == nil || !errors.Is(err, base.ErrCacheMiss) {
+		return val, err
+	}
+	return a.load(ctx, key)
+}
+
+// TryGet is Get with the miss reported as ok=false rather than an error;
+// err is only set for real failures.
+func (a *advancedCache[T]) TryGet(ctx context.Context, key string) (T, bool, error) {
+	val, err := a.Get(ctx, key)
+	if err != nil {
+		var zero T
+		if base.IsCacheMiss(err) {
+			return zero, false, nil
+		}
+		return zero, false, err
+	}
+	return val, true, nil
+}
+
+// get reads key from the backend without consulting the loader.
+func (a *advancedCache[T]) get(ctx context.Context, key string) (T, error) {
+	val, _, err := a.getWithTTL(ctx, key, false)
+	return val, err
+}
+
+// getWithTTL is get that, when withTTL is set and the backend can report
+// it, also returns the remaining TTL in the same round trip (0 otherwise).
+func (a *advancedCache[T]) getWithTTL(ctx context.Context, key string, withTTL bool) (T, time.Duration, error) {
 	var zero T
 
 	if err := a.base.ValidateKey(key); err != nil {
-		return zero, err
+		return zero, 0, err
 	}
 	if err := a.base.CheckContext(ctx); err != nil {
-		return zero, err
+		return zero, 0, err
 	}
 
-	var val T
-	err := a.withMetrics("get", 1, func() error {
-		v, err := a.cache.Get(ctx, key)
+	a.base.Metrics().RecordKey(key)
+	getter, _ := a.cache.(interfaces.TTLGetter[T])
+
+	var (
+		val       T
+		remaining time.Duration
+	)
+	err := a.withMetrics(ctx, "get", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		var (
+			v   T
+			err error
+		)
+		if withTTL && getter != nil {
+			v, remaining, err = getter.GetWithTTL(ctx, key)
+		} else {
+			v, err = a.cache.Get(ctx, key)
+		}
 		if err != nil {
 			if errors.Is(err, base.ErrCacheMiss) {
-				a.base.RecordMiss("get", 1)
+				a.miss(ctx, "get")
 			}
 			return err
 		}
 
-		a.base.RecordHit("get", 1)
+		a.hit(ctx, "get")
 		val = v
 		return nil
 	})
 
-	return val, err
+	return val, remaining, err
 }
 
 func (a *advancedCache[T]) Set(
@@ -92,24 +226,97 @@ func (a *advancedCache[T]) Set(
 
 	ttl = a.base.ResolveTTL(ttl)
 
-	return a.withMetrics("set", 1, func() error {
+	return a.withMetrics(ctx, "set", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
 		return a.cache.Set(ctx, key, value, ttl)
 	})
 }
 
+// Persist removes key's TTL so it never expires.
+func (a *advancedCache[T]) Persist(ctx context.Context, key string) error {
+	p, ok := a.cache.(interfaces.Persister)
+	if !ok {
+		return fmt.Errorf("Persist not supported")
+	}
+	if err := a.base.ValidateKey(key); err != nil {
+		return err
+	}
+
+	return a.withMetrics(ctx, "persist", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		return p.Persist(ctx, key)
+	})
+}
+
+// Update atomically replaces key's value with fn(current, found). fn may
+// run more than once on backends that retry on conflict, so it should be
+// free of side effects.
+func (a *advancedCache[T]) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) error {
+	u, ok := a.cache.(interfaces.Updater[T])
+	if !ok {
+		return fmt.Errorf("Update not supported")
+	}
+	if err := a.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if fn == nil {
+		return base.WrapError(base.OpUpdate, base.ErrInvalidArgument, key)
+	}
+
+	ttl = a.base.ResolveTTL(ttl)
+
+	return a.withMetrics(ctx, "update", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		return u.Update(ctx, key, ttl, fn)
+	})
+}
+
+// CompareAndSwap stores newVal only if key currently holds oldVal and
+// reports whether it did.
+func (a *advancedCache[T]) CompareAndSwap(
+	ctx context.Context,
+	key string,
+	oldVal, newVal T,
+	ttl time.Duration,
+) (bool, error) {
+	cas, ok := a.cache.(interfaces.CompareAndSwapper[T])
+	if !ok {
+		return false, fmt.Errorf("CompareAndSwap not supported")
+	}
+	if err := a.base.ValidateKey(key); err != nil {
+		return false, err
+	}
+
+	ttl = a.base.ResolveTTL(ttl)
+
+	var swapped bool
+	err := a.withMetrics(ctx, "compare_and_swap", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		var err error
+		swapped, err = cas.CompareAndSwap(ctx, key, oldVal, newVal, ttl)
+		return err
+	})
+	return swapped, err
+}
+
 func (a *advancedCache[T]) Delete(ctx context.Context, keys ...string) error {
 	if len(keys) == 0 {
 		return nil
 	}
 
-	return a.withMetrics("delete", len(keys), func() error {
+	return a.withMetrics(ctx, "delete", len(keys), func(ctx context.Context) error {
 		return a.cache.Delete(ctx, keys...)
 	})
 }
 
 func (a *advancedCache[T]) Exists(ctx context.Context, key string) (bool, error) {
 	var exists bool
-	err := a.withMetrics("exists", 1, func() error {
+	err := a.withMetrics(ctx, "exists", 1, func(ctx context.Context) error {
 		v, err := a.cache.Exists(ctx, key)
 		exists = v
 		return err
@@ -120,31 +327,58 @@ func (a *advancedCache[T]) Exists(ctx context.Context, key string) (bool, error)
 /* ------------------ Utility ------------------ */
 
 func (a *advancedCache[T]) Clear(ctx context.Context) error {
-	return a.withMetrics("clear", 1, func() error {
+	return a.withMetrics(ctx, "clear", 1, func(ctx context.Context) error {
 		return a.cache.Clear(ctx)
 	})
 }
 
+// Len returns the number of entries, not counting negative-cache markers
+// when the backend can count them by prefix.
 func (a *advancedCache[T]) Len(ctx context.Context) (int, error) {
 	var n int
-	err := a.withMetrics("len", 1, func() error {
+	err := a.withMetrics(ctx, "len", 1, func(ctx context.Context) error {
 		v, err := a.cache.Len(ctx)
+		if err != nil {
+			return err
+		}
 		n = v
-		return err
+
+		if counter, ok := a.cache.(interfaces.PrefixCounter); ok {
+			markers, err := counter.CountByPrefix(ctx, base.NegativeKey(""))
+			if err != nil {
+				return err
+			}
+			n = max(n-int(markers), 0)
+		}
+		return nil
 	})
 	return n, err
 }
 
-func (a *advancedCache[T]) Close() error {
-	return a.cache.Close()
-}
-
 func (a *advancedCache[T]) Ping(ctx context.Context) error {
 	return a.cache.Ping(ctx)
 }
 
+// Health reports the backend's health. Backends without their own report
+// are pinged and tracked here.
+func (a *advancedCache[T]) Health(ctx context.Context) metrics.HealthStatus {
+	if hr, ok := a.cache.(interfaces.HealthReporter); ok {
+		return hr.Health(ctx)
+	}
+
+	start := time.Now()
+	err := a.cache.Ping(ctx)
+	if !base.IsContextError(err) {
+		a.health.Record(time.Since(start), err)
+	}
+	return a.health.Status(a.Stats(ctx).Backend)
+}
+
 /* ------------------ Prefix Ops ------------------ */
 
+// DeleteByPrefix deletes the keys starting with prefix, and the
+// negative-cache markers of such keys, and returns how many keys were
+// removed besides the markers.
 func (a *advancedCache[T]) DeleteByPrefix(
 	ctx context.Context,
 	prefix string,
@@ -155,14 +389,134 @@ func (a *advancedCache[T]) DeleteByPrefix(
 	}
 
 	var count int64
-	err := a.withMetrics("delete_by_prefix", 1, func() error {
+	err := a.withMetrics(ctx, "delete_by_prefix", 1, func(ctx context.Context) error {
 		v, err := deleter.DeleteByPrefix(ctx, prefix)
 		count = v
+		if err != nil || base.IsNegativeKey(prefix) {
+			return err
+		}
+		_, err = deleter.DeleteByPrefix(ctx, base.NegativeKey(prefix))
+		return err
+	})
+	return count, err
+}
+
+// CountByPrefix returns how many keys start with prefix, without touching
+// them.
+func (a *advancedCache[T]) CountByPrefix(
+	ctx context.Context,
+	prefix string,
+) (int64, error) {
+	counter, ok := a.cache.(interfaces.PrefixCounter)
+	if !ok {
+		return 0, fmt.Errorf("CountByPrefix not supported")
+	}
+
+	var count int64
+	err := a.withMetrics(ctx, "count_by_prefix", 1, func(ctx context.Context) error {
+		v, err := counter.CountByPrefix(ctx, prefix)
+		count = v
+		return err
+	})
+	return count, err
+}
+
+// DeleteByPattern deletes the keys matching the glob pattern, and the
+// negative-cache markers of such keys, and returns how many keys were
+// removed besides the markers.
+func (a *advancedCache[T]) DeleteByPattern(
+	ctx context.Context,
+	pattern string,
+) (int64, error) {
+	deleter, ok := a.cache.(interfaces.PatternDeleter)
+	if !ok {
+		return 0, fmt.Errorf("DeleteByPattern not supported")
+	}
+
+	var count int64
+	err := a.withMetrics(ctx, "delete_by_pattern", 1, func(ctx context.Context) error {
+		v, err := deleter.DeleteByPattern(ctx, pattern)
+		count = v
+		if err != nil || base.IsNegativeKey(pattern) {
+			return err
+		}
+		_, err = deleter.DeleteByPattern(ctx, base.NegativeKey(pattern))
 		return err
 	})
 	return count, err
 }
 
+// DeleteMany deletes keys and returns how many of them existed.
+func (a *advancedCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
+	deleter, ok := a.cache.(interfaces.BulkDeleter)
+	if !ok {
+		return 0, fmt.Errorf("DeleteMany not supported")
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var count int64
+	err := a.withMetrics(ctx, "delete_many", len(keys), func(ctx context.Context) error {
+		v, err := deleter.DeleteMany(ctx, keys)
+		count = v
+		return err
+	})
+	return count, err
+}
+
+// Range calls fn for each entry, skipping negative-cache markers, until fn
+// returns false. Consistency depends on the backend: memory walks a
+// snapshot, Redis does not.
+func (a *advancedCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	ranger, ok := a.cache.(interfaces.Ranger[T])
+	if !ok {
+		return fmt.Errorf("Range not supported")
+	}
+
+	return a.withMetrics(ctx, "range", 1, func(ctx context.Context) error {
+		return ranger.Range(ctx, func(key string, value T) bool {
+			return base.IsNegativeKey(key) || fn(key, value)
+		})
+	})
+}
+
+// Dump returns the live entries with their remaining TTLs, without
+// negative-cache markers. Unlike the snapshot file it is for programmatic
+// use: fixtures and warm restarts.
+func (a *advancedCache[T]) Dump(ctx context.Context) (map[string]interfaces.Entry[T], error) {
+	d, ok := a.cache.(interfaces.Dumper[T])
+	if !ok {
+		return nil, fmt.Errorf("Dump not supported")
+	}
+
+	var out map[string]interfaces.Entry[T]
+	err := a.withMetrics(ctx, "dump", 1, func(ctx context.Context) error {
+		var err error
+		out, err = d.Dump(ctx)
+		for k := range out {
+			if base.IsNegativeKey(k) {
+				delete(out, k)
+			}
+		}
+		return err
+	})
+	return out, err
+}
+
+// Restore bulk-loads entries, typically from Dump, expiring each TTL after
+// the call.
+func (a *advancedCache[T]) Restore(ctx context.Context, entries map[string]interfaces.Entry[T]) error {
+	d, ok := a.cache.(interfaces.Dumper[T])
+	if !ok {
+		return fmt.Errorf("Restore not supported")
+	}
+
+	return a.withMetrics(ctx, "restore", len(entries), func(ctx context.Context) error {
+		return d.Restore(ctx, entries)
+	})
+}
+
 /* ------------------ Stats & Metrics ------------------ */
 
 func (a *advancedCache[T]) Stats(ctx context.Context) metrics.CacheStats {
@@ -181,13 +535,27 @@ func (a *advancedCache[T]) Stats(ctx context.Context) metrics.CacheStats {
 	}
 
 	stats.HitRate = metrics.CalculateHitRate(stats.Hits, stats.Misses)
+	stats.InFlight = a.inFlight()
 	return stats
 }
 
+// StartedAt reports the backend's start (or last reconnect) time.
+func (a *advancedCache[T]) StartedAt() time.Time {
+	if sp, ok := a.cache.(interfaces.StartTimeProvider); ok {
+		return sp.StartedAt()
+	}
+	return a.base.StartedAt()
+}
+
 func (a *advancedCache[T]) Metrics() *metrics.Collector {
 	return a.base.Metrics()
 }
 
+// Report returns Stats and the per-operation snapshot in one value.
+func (a *advancedCache[T]) Report(ctx context.Context) metrics.Report {
+	return a.base.Metrics().Report(ctx)
+}
+
 /* ------------------ GetOrSet ------------------ */
 
 func (a *advancedCache[T]) GetOrSet(
@@ -222,54 +590,58 @@ func (a *advancedCache[T]) getOrSet(
 	}
 
 	var result T
-	err := a.withMetrics(op, 1, func() error {
-		val, err := a.Get(ctx, key)
+	err := a.withMetrics(ctx, op, 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		val, remaining, err := a.getWithTTL(ctx, key, a.early != nil)
 		if err == nil {
+			a.adaptive.observe(key, true)
 			result = val
+			a.refreshEarly(key, ttl, remaining, fn)
 			return nil
 		}
 		if !errors.Is(err, base.ErrCacheMiss) {
 			return err
 		}
+		a.adaptive.observe(key, false)
 
 		if locked {
-			if err := a.tryLock(ctx, key); err != nil {
+			release, err := a.lock(ctx, key)
+			if err != nil {
 				return err
 			}
-			defer a.unlock(ctx, key)
+			defer release()
 		}
 
-		val, err = fn()
+		val, err = a.timed(key, fn)()
 		if err != nil {
 			return err
 		}
 
-		_ = a.Set(ctx, key, val, ttl)
-		result = val
+		result = a.store(ctx, key, val, a.adaptive.apply(key, a.base.ResolveTTL(ttl)))
 		return nil
 	})
 
 	return result, err
 }
 
-// tryLock handles distributed lock acquisition
-func (a *advancedCache[T]) tryLock(ctx context.Context, key string) error {
-	locker, ok := a.cache.(interfaces.DistributedLocker)
-	if !ok {
-		return nil
-	}
-	ok, err := locker.TryLock(ctx, "lock:"+key, 30*time.Second)
-	if err != nil || !ok {
-		return err
-	}
-	return nil
-}
-
-// unlock handles releasing the lock
-func (a *advancedCache[T]) unlock(ctx context.Context, key string) {
-	if locker, ok := a.cache.(interfaces.DistributedLocker); ok {
-		if err := locker.Unlock(ctx, "lock:"+key); err != nil {
-			a.base.RecordError("get_or_set_locked")
+// store writes a computed value with the already resolved ttl, metered as
+// a Set. Backends that can set-if-absent keep the first writer's value, so
+// concurrent callers that all missed agree on one result; write errors are
+// ignored as for a plain Set.
+func (a *advancedCache[T]) store(ctx context.Context, key string, val T, ttl time.Duration) T {
+	stored := val
+	as, ok := a.cache.(interfaces.AtomicSetter[T])
+
+	_ = a.withMetrics(ctx, "set", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		if !ok {
+			return a.cache.Set(ctx, key, val, ttl)
 		}
-	}
+		v, _, err := as.SetIfAbsent(ctx, key, val, ttl)
+		if err == nil {
+			stored = v
+		}
+		return err
+	})
+	return stored
 }
@@ -0,0 +1,369 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/memory"
+	"github.com/os-golib/go-cache/redis"
+)
+
+func testConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	return cfg
+}
+
+// newBackend returns a memory cache for newTestCache, which closes it.
+func newBackend[T any](t *testing.T, cfg config.Config) interfaces.Cache[T] {
+	t.Helper()
+	m, err := memory.NewMemory[T](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// newTestCache wraps backend, a memory cache when nil, in an advanced cache
+// that is closed when the test ends.
+func newTestCache[T any](t *testing.T, cfg config.Config, backend interfaces.Cache[T], opts ...Option[T]) *advancedCache[T] {
+	t.Helper()
+	if backend == nil {
+		backend = newBackend[T](t, cfg)
+	}
+	a := NewAdvancedCache[T](backend, cfg, opts...).(*advancedCache[T])
+	t.Cleanup(func() { _ = a.Close() })
+	return a
+}
+
+// slowCache delays every Set by delay and counts the completed ones. Like
+// a torn-down client, it fails writes that finish after Close.
+type slowCache[T any] struct {
+	interfaces.Cache[T]
+	delay  time.Duration
+	sets   atomic.Int64
+	closed atomic.Bool
+}
+
+func (s *slowCache[T]) Set(ctx context.Context, key string, val T, ttl time.Duration) error {
+	time.Sleep(s.delay)
+	if s.closed.Load() {
+		return base.ErrClosed
+	}
+	if err := s.Cache.Set(ctx, key, val, ttl); err != nil {
+		return err
+	}
+	s.sets.Add(1)
+	return nil
+}
+
+/* ------------------ GetOrSet ------------------ */
+
+// newRedisProcess connects a separate advanced cache, as another process
+// would, to the shared miniredis server.
+func newRedisProcess(t *testing.T, mr *miniredis.Miniredis) *advancedCache[int] {
+	t.Helper()
+	cfg := testConfig()
+	cfg.Type = config.TypeRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	r, err := redis.NewRedisCache[int](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestCache[int](t, cfg, r)
+}
+
+func TestGetOrSetConcurrentProcessesAgree(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	const n = 8
+	procs := make([]*advancedCache[int], n)
+	for i := range procs {
+		procs[i] = newRedisProcess(t, mr)
+	}
+
+	// Every process misses before any of them writes.
+	var (
+		wg      sync.WaitGroup
+		missed  sync.WaitGroup
+		results [n]int
+		errs    [n]error
+	)
+	missed.Add(n)
+	for i, p := range procs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = p.GetOrSet(ctx, "report", time.Minute, func() (int, error) {
+				missed.Done()
+				missed.Wait()
+				return i + 1, nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	winner, err := procs[0].Get(ctx, "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range procs {
+		if errs[i] != nil {
+			t.Fatalf("process %d: %v", i, errs[i])
+		}
+		if results[i] != winner {
+			t.Errorf("process %d got %d, the persisted value is %d", i, results[i], winner)
+		}
+		if got, _ := procs[i].Get(ctx, "report"); got != winner {
+			t.Errorf("process %d reads %d afterwards, want %d", i, got, winner)
+		}
+		if sets := procs[i].Metrics().Snapshot()["set"].Count; sets != 1 {
+			t.Errorf("process %d recorded %d sets, want 1", i, sets)
+		}
+	}
+	if ttl := mr.TTL(testConfig().Prefix + "report"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("persisted TTL = %v, want at most the requested minute", ttl)
+	}
+}
+
+func (s *slowCache[T]) Close() error {
+	s.closed.Store(true)
+	return s.Cache.Close()
+}
+
+/* ------------------ TryGet ------------------ */
+
+func TestTryGetSeparatesMissFromError(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	backends := map[string]*advancedCache[int]{
+		"memory": newTestCache[int](t, testConfig(), nil),
+		"redis":  newRedisProcess(t, mr),
+	}
+	for name, a := range backends {
+		t.Run(name, func(t *testing.T) {
+			_ = a.Set(ctx, "k", 7, time.Minute)
+			if v, ok, err := a.TryGet(ctx, "k"); v != 7 || !ok || err != nil {
+				t.Errorf("TryGet(hit) = %d, %v, %v", v, ok, err)
+			}
+			if v, ok, err := a.TryGet(ctx, "absent"); v != 0 || ok || err != nil {
+				t.Errorf("TryGet(miss) = %d, %v, %v; want a clean miss", v, ok, err)
+			}
+			if _, ok, err := a.TryGet(ctx, ""); ok || !errors.Is(err, base.ErrKeyEmpty) {
+				t.Errorf("TryGet(\"\") = %v, %v; want ErrKeyEmpty", ok, err)
+			}
+		})
+	}
+
+	// Redis failures are errors, not misses.
+	a := backends["redis"]
+	mr.Set(a.cfg.Prefix+"bad", "not json")
+	if _, ok, err := a.TryGet(ctx, "bad"); ok || !errors.Is(err, base.ErrDeserialize) {
+		t.Errorf("TryGet(corrupt) = %v, %v; want ErrDeserialize", ok, err)
+	}
+	mr.SetError("ERR server unavailable")
+	defer mr.SetError("")
+	if _, ok, err := a.TryGet(ctx, "k"); ok || err == nil || base.IsCacheMiss(err) {
+		t.Errorf("TryGet during an outage = %v, %v; want a real error", ok, err)
+	}
+}
+
+/* ------------------ Top keys ------------------ */
+
+func TestGetTracksTopKeys(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.TrackTopKeys = 5
+	a := newTestCache[int](t, cfg, nil)
+
+	_ = a.Set(ctx, "hot", 1, time.Minute)
+	for range 10 {
+		_, _ = a.Get(ctx, "hot")
+	}
+	_, _ = a.Get(ctx, "cold")
+
+	top := a.base.Metrics().TopKeys(1)
+	if len(top) != 1 || top[0].Key != "hot" || top[0].Count != 10 {
+		t.Errorf("TopKeys(1) = %+v, want hot with 10 reads", top)
+	}
+}
+
+/* ------------------ Health ------------------ */
+
+// pingCache hides the backend's own health report and fails Ping with err.
+type pingCache[T any] struct {
+	interfaces.Cache[T]
+	err error
+}
+
+func (p *pingCache[T]) Ping(context.Context) error { return p.err }
+
+func TestHealthTracksBackendsWithoutReport(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	backend := &pingCache[int]{Cache: newBackend[int](t, cfg)}
+	a := newTestCache[int](t, cfg, backend)
+
+	if h := a.Health(ctx); !h.Healthy {
+		t.Fatalf("healthy status = %+v", h)
+	}
+	backend.err = errors.New("connection refused")
+	_ = a.Health(ctx)
+	if h := a.Health(ctx); h.Healthy || h.ConsecutiveFailures != 2 || h.LastError != "connection refused" {
+		t.Errorf("failing status = %+v", h)
+	}
+	backend.err = nil
+	if h := a.Health(ctx); !h.Healthy {
+		t.Errorf("recovered status = %+v", h)
+	}
+}
+
+/* ------------------ Persist ------------------ */
+
+func TestPersistUnsupportedBackend(t *testing.T) {
+	cfg := testConfig()
+	a := newTestCache[int](t, cfg, &plainCache[int]{Cache: newBackend[int](t, cfg)})
+	if err := a.Persist(context.Background(), "k"); err == nil {
+		t.Error("Persist succeeded on a backend without it")
+	}
+}
+
+/* ------------------ Dump / Restore ------------------ */
+
+func TestNamespaceDumpRestore(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+	acme, globex := a.Namespace("acme"), a.Namespace("globex")
+
+	_ = acme.Set(ctx, "k", 1, time.Minute)
+	_ = globex.Set(ctx, "k", 2, time.Minute)
+
+	entries, err := acme.Dump(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries["k"].Value != 1 {
+		t.Fatalf("acme Dump = %v, want only its own unprefixed k", entries)
+	}
+
+	_ = acme.Clear(ctx)
+	if err := acme.Restore(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := acme.Get(ctx, "k"); err != nil || v != 1 {
+		t.Errorf("acme Get after Restore = %d, %v", v, err)
+	}
+	if v, _ := globex.Get(ctx, "k"); v != 2 {
+		t.Errorf("globex Get = %d, Restore reached another namespace", v)
+	}
+}
+
+func TestDumpRestoreUnsupportedBackend(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	a := newTestCache[int](t, cfg, &plainCache[int]{Cache: newBackend[int](t, cfg)})
+	if _, err := a.Dump(ctx); err == nil {
+		t.Error("Dump succeeded on a backend without it")
+	}
+	if err := a.Restore(ctx, map[string]interfaces.Entry[int]{"k": {Value: 1}}); err == nil {
+		t.Error("Restore succeeded on a backend without it")
+	}
+}
+
+/* ------------------ Operation timeout ------------------ */
+
+// deadlineCache records the deadline of each Get's context and, when
+// hang is set, blocks until that context is done.
+type deadlineCache[T any] struct {
+	interfaces.Cache[T]
+	hang bool
+
+	mu       sync.Mutex
+	deadline time.Time
+	bounded  bool
+}
+
+func (d *deadlineCache[T]) Get(ctx context.Context, key string) (T, error) {
+	d.mu.Lock()
+	d.deadline, d.bounded = ctx.Deadline()
+	d.mu.Unlock()
+	if d.hang {
+		<-ctx.Done()
+		var zero T
+		return zero, ctx.Err()
+	}
+	return d.Cache.Get(ctx, key)
+}
+
+func (d *deadlineCache[T]) seen() (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.deadline, d.bounded
+}
+
+func TestOperationTimeoutAppliedWithoutDeadline(t *testing.T) {
+	cfg := testConfig()
+	cfg.OperationTimeout = time.Minute
+	backend := &deadlineCache[int]{Cache: newBackend[int](t, cfg)}
+	a := newTestCache[int](t, cfg, backend)
+
+	start := time.Now()
+	_, _ = a.Get(context.Background(), "k")
+	deadline, ok := backend.seen()
+	if !ok {
+		t.Fatal("backend got a context without a deadline")
+	}
+	if d := deadline.Sub(start); d < 59*time.Second || d > time.Minute+time.Second {
+		t.Errorf("deadline %v after the call, want about 1m", d)
+	}
+}
+
+func TestOperationTimeoutKeepsCallerDeadline(t *testing.T) {
+	cfg := testConfig()
+	cfg.OperationTimeout = time.Minute
+	backend := &deadlineCache[int]{Cache: newBackend[int](t, cfg)}
+	a := newTestCache[int](t, cfg, backend)
+
+	want := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+	_, _ = a.Get(ctx, "k")
+	if got, _ := backend.seen(); !got.Equal(want) {
+		t.Errorf("deadline = %v, want the caller's %v", got, want)
+	}
+}
+
+func TestOperationTimeoutZeroLeavesContextUnbounded(t *testing.T) {
+	cfg := testConfig()
+	backend := &deadlineCache[int]{Cache: newBackend[int](t, cfg)}
+	a := newTestCache[int](t, cfg, backend)
+
+	_, _ = a.Get(context.Background(), "k")
+	if _, ok := backend.seen(); ok {
+		t.Error("deadline set with OperationTimeout unset")
+	}
+}
+
+func TestOperationTimeoutUnblocksHungBackend(t *testing.T) {
+	cfg := testConfig()
+	cfg.OperationTimeout = 20 * time.Millisecond
+	a := newTestCache[int](t, cfg, &deadlineCache[int]{Cache: newBackend[int](t, cfg), hang: true})
+
+	start := time.Now()
+	if _, err := a.Get(context.Background(), "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Get returned after %v", d)
+	}
+}
@@ -0,0 +1,101 @@
+package advanced
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/* ------------------ Dependency Graph ------------------ */
+
+// depGraph records parent→child edges between logical keys. Parents do not
+// need to exist in the cache. The graph is process-local.
+type depGraph struct {
+	mu       sync.Mutex
+	children map[string]map[string]struct{}
+}
+
+func newDepGraph() *depGraph {
+	return &depGraph{children: make(map[string]map[string]struct{})}
+}
+
+func (g *depGraph) link(child string, parents ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, p := range parents {
+		if p == "" || p == child {
+			continue
+		}
+		set := g.children[p]
+		if set == nil {
+			set = make(map[string]struct{})
+			g.children[p] = set
+		}
+		set[child] = struct{}{}
+	}
+}
+
+// detach removes root and everything reachable from it, returning the
+// reachable keys (root excluded).
+func (g *depGraph) detach(root string) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seen := map[string]struct{}{root: {}}
+	queue := []string{root}
+	var out []string
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for child := range g.children[node] {
+			if _, ok := seen[child]; ok {
+				continue
+			}
+			seen[child] = struct{}{}
+			out = append(out, child)
+			queue = append(queue, child)
+		}
+		delete(g.children, node)
+	}
+
+	return out
+}
+
+/* ------------------ Hierarchical Invalidation ------------------ */
+
+// SetWithParents stores value and records key as a child of each parent,
+// so InvalidateTree on any ancestor also removes it.
+func (a *advancedCache[T]) SetWithParents(
+	ctx context.Context,
+	key string,
+	value T,
+	ttl time.Duration,
+	parents ...string,
+) error {
+	if err := a.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	a.deps.link(key, parents...)
+	return nil
+}
+
+// InvalidateTree deletes parent and all of its transitive children and
+// returns how many keys were targeted. Siblings are untouched.
+func (a *advancedCache[T]) InvalidateTree(ctx context.Context, parent string) (int64, error) {
+	if err := a.base.ValidateKey(parent); err != nil {
+		return 0, err
+	}
+
+	keys := append([]string{parent}, a.deps.detach(parent)...)
+
+	err := a.withMetrics(ctx, "invalidate_tree", len(keys), func(ctx context.Context) error {
+		return a.cache.Delete(ctx, keys...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(keys)), nil
+}
@@ -0,0 +1,79 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestInvalidateTreeRemovesDescendants(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[string](t, testConfig(), nil)
+
+	// org:1 ── team:a ── alice
+	//      │         └── bob
+	//      └── report:q3
+	// org:2 ── team:b ── carol
+	set := func(key string, parents ...string) {
+		t.Helper()
+		if err := a.SetWithParents(ctx, key, key, time.Minute, parents...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set("org:1")
+	set("team:a", "org:1")
+	set("alice", "team:a")
+	set("bob", "team:a")
+	set("report:q3", "org:1")
+	set("org:2")
+	set("team:b", "org:2")
+	set("carol", "team:b")
+
+	n, err := a.InvalidateTree(ctx, "org:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("InvalidateTree removed %d keys, want 5", n)
+	}
+	for _, k := range []string{"org:1", "team:a", "alice", "bob", "report:q3"} {
+		if _, err := a.Get(ctx, k); !errors.Is(err, base.ErrCacheMiss) {
+			t.Errorf("Get(%s) after invalidating org:1 = %v, want a miss", k, err)
+		}
+	}
+	for _, k := range []string{"org:2", "team:b", "carol"} {
+		if _, err := a.Get(ctx, k); err != nil {
+			t.Errorf("sibling %s removed: %v", k, err)
+		}
+	}
+}
+
+func TestInvalidateTreeSharedChildAndCycles(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[string](t, testConfig(), nil)
+
+	// shared belongs to two parents; a and b point at each other.
+	for _, e := range [][]string{{"shared", "p1", "p2"}, {"a", "b"}, {"b", "a"}} {
+		if err := a.SetWithParents(ctx, e[0], e[0], time.Minute, e[1:]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := a.InvalidateTree(ctx, "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Get(ctx, "shared"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("shared child survived its parent: %v", err)
+	}
+
+	n, err := a.InvalidateTree(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("cycle a<->b invalidated %d keys, want 2", n)
+	}
+}
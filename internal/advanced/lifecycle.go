@@ -0,0 +1,178 @@
+package advanced
+
+import (
+	"context"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// defaultShutdownGrace bounds Close when cfg.ShutdownGrace is not set.
+const defaultShutdownGrace = 10 * time.Second
+
+/* ------------------ Close ------------------ */
+
+// Close stops background workers and closes the backend, first waiting up
+// to cfg.ShutdownGrace (10s if unset) for async writes and in-flight
+// operations to finish.
+func (a *advancedCache[T]) Close() error {
+	grace := a.cfg.ShutdownGrace
+	if grace <= 0 {
+		grace = defaultShutdownGrace
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	return a.CloseWithTimeout(ctx)
+}
+
+// CloseWithTimeout waits for background workers and in-flight operations
+// until ctx is done, then closes the backend regardless. Operations started
+// once the workers are done fail with ErrClosed. A drain cut short by ctx
+// is reported unless closing the backend itself failed. The backend is
+// closed only once, however often Close is called.
+func (a *advancedCache[T]) CloseWithTimeout(ctx context.Context) error {
+	a.stopWorkers()
+	drainErr := a.drain(ctx)
+
+	a.closeOnce.Do(func() { a.closeErr = a.cache.Close() })
+	if a.closeErr != nil {
+		return base.WrapError(base.OpClose, a.closeErr, "")
+	}
+	return base.WrapError(base.OpClose, drainErr, "")
+}
+
+func (a *advancedCache[T]) drain(ctx context.Context) error {
+	workersDone := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(workersDone)
+	}()
+
+	var err error
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// Workers may still be writing until here; from now on only the
+	// operations already running are waited for.
+	a.markClosed()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-a.idle():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/* ------------------ Background Tasks ------------------ */
+
+// Go runs fn on a goroutine that Close waits for, bounded by ShutdownGrace
+// or the CloseWithTimeout context. It reports false, without running fn,
+// once the cache is closing.
+func (a *advancedCache[T]) Go(fn func()) bool {
+	if !a.addWorker() {
+		return false
+	}
+	go func() {
+		defer a.doneWorker()
+		fn()
+	}()
+	return true
+}
+
+// addWorker counts a background worker, failing once the cache, or the
+// root of a namespace view, is closing.
+func (a *advancedCache[T]) addWorker() bool {
+	if a.root != nil && !a.root.addWorker() {
+		return false
+	}
+
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+	select {
+	case <-a.stopCh:
+		if a.root != nil {
+			a.root.doneWorker()
+		}
+		return false
+	default:
+	}
+	a.workers.Add(1)
+	return true
+}
+
+func (a *advancedCache[T]) doneWorker() {
+	a.workers.Done()
+	if a.root != nil {
+		a.root.doneWorker()
+	}
+}
+
+// rootStop is closed when the root of a namespace view starts closing; it
+// is nil, and never ready, for any other cache.
+func (a *advancedCache[T]) rootStop() <-chan struct{} {
+	if a.root == nil {
+		return nil
+	}
+	return a.root.stopCh
+}
+
+/* ------------------ In-flight Tracking ------------------ */
+
+// beginOp counts an operation as in flight, failing with ErrClosed once
+// the cache is closed.
+func (a *advancedCache[T]) beginOp() error {
+	a.inflightMu.Lock()
+	defer a.inflightMu.Unlock()
+
+	if a.closed {
+		return base.ErrClosed
+	}
+	a.inflight++
+	return nil
+}
+
+func (a *advancedCache[T]) endOp() {
+	a.inflightMu.Lock()
+	a.inflight--
+	if a.inflight == 0 && a.drained != nil {
+		close(a.drained)
+		a.drained = nil
+	}
+	a.inflightMu.Unlock()
+}
+
+func (a *advancedCache[T]) markClosed() {
+	a.inflightMu.Lock()
+	a.closed = true
+	a.inflightMu.Unlock()
+}
+
+func (a *advancedCache[T]) inFlight() int64 {
+	a.inflightMu.Lock()
+	defer a.inflightMu.Unlock()
+	return a.inflight
+}
+
+// idle returns a channel closed once no operation is in flight.
+func (a *advancedCache[T]) idle() <-chan struct{} {
+	a.inflightMu.Lock()
+	defer a.inflightMu.Unlock()
+
+	if a.inflight == 0 {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	if a.drained == nil {
+		a.drained = make(chan struct{})
+	}
+	return a.drained
+}
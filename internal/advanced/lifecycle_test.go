@@ -0,0 +1,146 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestCloseWaitsForAsyncWrites(t *testing.T) {
+	cfg := testConfig()
+	slow := &slowCache[int]{Cache: newBackend[int](t, cfg), delay: 50 * time.Millisecond}
+	a := newTestCache[int](t, cfg, slow)
+
+	const n = 20
+	for i := range n {
+		if !a.Go(func() { _ = a.Set(context.Background(), strconv.Itoa(i), i, time.Minute) }) {
+			t.Fatal("Go refused work before Close")
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := slow.sets.Load(); got != n {
+		t.Errorf("%d of %d async writes completed before Close returned", got, n)
+	}
+	if a.Go(func() {}) {
+		t.Error("Go accepted work after Close")
+	}
+}
+
+func TestCloseWaitsForInFlightOperations(t *testing.T) {
+	cfg := testConfig()
+	slow := &slowCache[int]{Cache: newBackend[int](t, cfg), delay: 100 * time.Millisecond}
+	a := newTestCache[int](t, cfg, slow)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := a.Set(context.Background(), "k", 1, time.Minute); err != nil {
+			t.Errorf("in-flight Set: %v", err)
+		}
+	}()
+	for a.inFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if slow.sets.Load() != 1 {
+		t.Error("Close returned before the in-flight Set finished")
+	}
+	wg.Wait()
+}
+
+func TestOperationsAfterCloseFail(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Set(ctx, "k", 1, time.Minute); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("Set after Close = %v, want ErrClosed", err)
+	}
+	if _, err := a.Get(ctx, "k"); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("Get after Close = %v, want ErrClosed", err)
+	}
+	calls := 0
+	_, err := a.GetOrSet(ctx, "k", time.Minute, func() (int, error) { calls++; return 1, nil })
+	if !errors.Is(err, base.ErrClosed) || calls != 0 {
+		t.Errorf("GetOrSet after Close = %v with %d loads, want ErrClosed and none", err, calls)
+	}
+}
+
+func TestCloseIsBoundedByShutdownGrace(t *testing.T) {
+	cfg := testConfig()
+	cfg.ShutdownGrace = 20 * time.Millisecond
+	a := newTestCache[int](t, cfg, nil)
+
+	release := make(chan struct{})
+	defer close(release)
+	a.Go(func() { <-release })
+
+	start := time.Now()
+	err := a.Close()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Close took %v with a 20ms grace", d)
+	}
+	if err := a.Set(context.Background(), "k", 1, time.Minute); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("Set after a timed-out Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestCloseWithTimeoutLetsSlowOperationFinish(t *testing.T) {
+	cfg := testConfig()
+	slow := &slowCache[int]{Cache: newBackend[int](t, cfg), delay: 100 * time.Millisecond}
+	a := newTestCache[int](t, cfg, slow)
+
+	done := make(chan error, 1)
+	go func() { done <- a.Set(context.Background(), "k", 1, time.Minute) }()
+	for a.Stats(context.Background()).InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := a.CloseWithTimeout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("in-flight Set failed during graceful close: %v", err)
+	}
+	if !slow.closed.Load() {
+		t.Error("backend not closed")
+	}
+}
+
+func TestCloseWithTimeoutReportsDeadline(t *testing.T) {
+	cfg := testConfig()
+	slow := &slowCache[int]{Cache: newBackend[int](t, cfg), delay: 200 * time.Millisecond}
+	a := newTestCache[int](t, cfg, slow)
+
+	go func() { _ = a.Set(context.Background(), "k", 1, time.Minute) }()
+	for a.inFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := a.CloseWithTimeout(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CloseWithTimeout = %v, want DeadlineExceeded", err)
+	}
+	if !slow.closed.Load() {
+		t.Error("backend left open after the deadline")
+	}
+}
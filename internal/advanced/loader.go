@@ -0,0 +1,45 @@
+package advanced
+
+import (
+	"context"
+)
+
+/* ------------------ Options ------------------ */
+
+// Option customizes an advanced cache.
+type Option[T any] func(*advancedCache[T])
+
+// Loader fetches the value for key from the source of truth.
+type Loader[T any] func(ctx context.Context, key string) (T, error)
+
+// WithLoader makes Get load and cache missing keys with fn, using the
+// configured TTL. A nil fn leaves Get unchanged.
+func WithLoader[T any](fn Loader[T]) Option[T] {
+	return func(a *advancedCache[T]) {
+		a.loader = fn
+	}
+}
+
+/* ------------------ Read-Through ------------------ */
+
+// load runs the loader for key once across concurrent callers and stores
+// the result. Loader errors are returned as is.
+func (a *advancedCache[T]) load(ctx context.Context, key string) (T, error) {
+	var result T
+	err := a.withMetrics(ctx, "load", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		val, err := a.loads.Do(key, func() (T, error) {
+			v, err := a.loader(ctx, key)
+			if err != nil {
+				return v, err
+			}
+			return a.store(ctx, key, v, a.base.ResolveTTL(0)), nil
+		})
+		if err != nil {
+			return err
+		}
+		result = val
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,98 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/memory"
+)
+
+func TestLoaderFillsMisses(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.TTL = time.Hour
+	backend, err := memory.NewMemory[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int64
+	a := newTestCache[string](t, cfg, backend, WithLoader(func(_ context.Context, key string) (string, error) {
+		calls.Add(1)
+		return "loaded:" + key, nil
+	}))
+
+	for range 3 {
+		if v, err := a.Get(ctx, "k"); err != nil || v != "loaded:k" {
+			t.Fatalf("Get = %q, %v", v, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+
+	// The loaded value is cached with the configured TTL.
+	v, ttl, err := backend.GetWithTTL(ctx, "k")
+	if err != nil || v != "loaded:k" {
+		t.Fatalf("backend Get = %q, %v", v, err)
+	}
+	if ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("cached TTL = %v, want about %v", ttl, cfg.TTL)
+	}
+}
+
+func TestLoaderErrorPropagates(t *testing.T) {
+	ctx := context.Background()
+	errSource := errors.New("source down")
+	a := newTestCache[string](t, testConfig(), nil, WithLoader(func(context.Context, string) (string, error) {
+		return "", errSource
+	}))
+
+	if _, err := a.Get(ctx, "k"); !errors.Is(err, errSource) {
+		t.Errorf("Get = %v, want the loader's error", err)
+	}
+	if ok, _ := a.Exists(ctx, "k"); ok {
+		t.Error("failed load cached a value")
+	}
+}
+
+func TestLoaderDeduplicatesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int64
+	release := make(chan struct{})
+	a := newTestCache[int](t, testConfig(), nil, WithLoader(func(context.Context, string) (int, error) {
+		calls.Add(1)
+		<-release
+		return 7, nil
+	}))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := a.Get(ctx, "k"); err != nil || v != 7 {
+				t.Errorf("Get = %d, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times for concurrent misses, want 1", n)
+	}
+}
+
+func TestNoLoaderKeepsPlainMisses(t *testing.T) {
+	a := newTestCache[int](t, testConfig(), nil)
+	if _, err := a.Get(context.Background(), "k"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Get = %v, want ErrCacheMiss", err)
+	}
+}
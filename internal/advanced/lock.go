@@ -0,0 +1,110 @@
+package advanced
+
+import (
+	"context"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Distributed Lock ------------------ */
+
+const defaultLockTTL = 30 * time.Second
+
+func (a *advancedCache[T]) lockTTL() time.Duration {
+	if a.cfg.LockTTL > 0 {
+		return a.cfg.LockTTL
+	}
+	return defaultLockTTL
+}
+
+func (a *advancedCache[T]) lockRenewInterval() time.Duration {
+	if a.cfg.LockRenewInterval > 0 {
+		return a.cfg.LockRenewInterval
+	}
+	return a.lockTTL() / 3
+}
+
+// lock takes the distributed lock for key and returns a function that
+// releases it. While held, a watchdog keeps extending the lock so it
+// outlives slow computations. Backends without locking, or a lock held by
+// someone else, yield a no-op release.
+func (a *advancedCache[T]) lock(ctx context.Context, key string) (func(), error) {
+	release, _, err := a.tryLock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// tryLock is lock that also reports whether the lock was taken: ok is
+// false, with a no-op release, while someone else holds it. Backends
+// without locking always succeed.
+func (a *advancedCache[T]) tryLock(ctx context.Context, key string) (func(), bool, error) {
+	locker, ok := a.cache.(interfaces.DistributedLocker)
+	if !ok {
+		return func() {}, true, nil
+	}
+
+	lockKey := "lock:" + key
+	token, ok, err := locker.TryLock(ctx, lockKey, a.lockTTL())
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return func() {}, false, nil
+	}
+
+	stop := a.watchdog(ctx, lockKey, token)
+	return func() {
+		stop()
+		if err := locker.Unlock(ctx, lockKey, token); err != nil {
+			a.base.RecordError("get_or_set_locked")
+			a.logLock(ctx, "cache lock release failed", lockKey, err)
+		}
+	}, true, nil
+}
+
+// watchdog extends lockKey every renew interval until the returned stop
+// function is called, ctx ends or the lock is no longer ours.
+func (a *advancedCache[T]) watchdog(ctx context.Context, lockKey, token string) func() {
+	ext, ok := a.cache.(interfaces.LockExtender)
+	if !ok {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	ttl := a.lockTTL()
+
+	go func() {
+		defer close(exited)
+
+		ticker := time.NewTicker(a.lockRenewInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				held, err := ext.ExtendLock(ctx, lockKey, token, ttl)
+				if err != nil {
+					a.base.RecordError("lock_renew")
+					a.logLock(ctx, "cache lock renewal failed", lockKey, err)
+					continue
+				}
+				if !held {
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+	}
+}
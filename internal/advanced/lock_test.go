@@ -0,0 +1,120 @@
+package advanced
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/redis"
+)
+
+// newLockingPair returns an advanced cache with a short lock TTL and a
+// plain Redis cache on the same server acting as a competing worker.
+func newLockingPair(t *testing.T, mr *miniredis.Miniredis, renew time.Duration) (*advancedCache[int], interface {
+	TryLock(context.Context, string, time.Duration) (string, bool, error)
+}) {
+	t.Helper()
+	cfg := testConfig()
+	cfg.Type = config.TypeRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	cfg.LockTTL = 100 * time.Millisecond
+	cfg.LockRenewInterval = renew
+
+	r, err := redis.NewRedisCache[int](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	competitor, err := redis.NewRedisCache[int](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = competitor.Close() })
+	return newTestCache[int](t, cfg, r), competitor
+}
+
+// advanceClock moves miniredis time forward in step with the wall clock,
+// since its TTLs do not expire on their own, until stop is closed.
+func advanceClock(mr *miniredis.Miniredis, stop <-chan struct{}) {
+	const tick = 5 * time.Millisecond
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(tick):
+			mr.FastForward(tick)
+		}
+	}
+}
+
+func TestGetOrSetLockedRenewsLockWhileComputing(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	a, competitor := newLockingPair(t, mr, 20*time.Millisecond)
+
+	stopClock := make(chan struct{})
+	defer close(stopClock)
+	go advanceClock(mr, stopClock)
+
+	var stolen atomic.Bool
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		<-started
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				if _, ok, _ := competitor.TryLock(ctx, "lock:k", time.Minute); ok {
+					stolen.Store(true)
+					return
+				}
+			}
+		}
+	}()
+
+	// fn runs four times longer than the lock TTL.
+	v, err := a.GetOrSetLocked(ctx, "k", time.Minute, func() (int, error) {
+		close(started)
+		time.Sleep(400 * time.Millisecond)
+		return 1, nil
+	})
+	close(done)
+	if err != nil || v != 1 {
+		t.Fatalf("GetOrSetLocked = %d, %v", v, err)
+	}
+	if stolen.Load() {
+		t.Fatal("competitor acquired the lock while fn was running")
+	}
+
+	if _, ok, err := competitor.TryLock(ctx, "lock:k", time.Minute); err != nil || !ok {
+		t.Errorf("lock not released after fn returned: %v, %v", ok, err)
+	}
+}
+
+func TestLockRenewDefaultsToThirdOfTTL(t *testing.T) {
+	cfg := testConfig()
+	cfg.LockTTL = 90 * time.Millisecond
+	a := newTestCache[int](t, cfg, nil)
+	if got := a.lockRenewInterval(); got != 30*time.Millisecond {
+		t.Errorf("lockRenewInterval = %v, want 30ms", got)
+	}
+
+	a = newTestCache[int](t, testConfig(), nil)
+	if a.lockTTL() != defaultLockTTL || a.lockRenewInterval() != defaultLockTTL/3 {
+		t.Errorf("defaults = %v / %v", a.lockTTL(), a.lockRenewInterval())
+	}
+}
+
+func TestLockRenewMustBeShorterThanTTL(t *testing.T) {
+	cfg := testConfig()
+	cfg.LockTTL = time.Second
+	cfg.LockRenewInterval = time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("renew interval equal to the lock TTL accepted")
+	}
+}
@@ -0,0 +1,113 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Logging ------------------ */
+
+type logKey struct{}
+
+// opLog collects what annotate learns about an operation while it runs.
+type opLog struct {
+	key string
+	hit *bool
+}
+
+// startLog attaches an opLog to ctx when a logger is configured.
+func (a *advancedCache[T]) startLog(ctx context.Context) (context.Context, *opLog) {
+	if a.cfg.Logger == nil {
+		return ctx, nil
+	}
+	rec := &opLog{}
+	return context.WithValue(ctx, logKey{}, rec), rec
+}
+
+func (r *opLog) set(key string, value any) {
+	switch key {
+	case "cache.key":
+		if s, ok := value.(string); ok {
+			r.key = s
+		}
+	case "cache.hit":
+		if b, ok := value.(bool); ok {
+			r.hit = &b
+		}
+	}
+}
+
+// logOp writes one record for a finished operation. Successes and misses
+// are debug, partial failures and lock errors warn, anything else error.
+func (a *advancedCache[T]) logOp(
+	ctx context.Context,
+	rec *opLog,
+	op string,
+	items int,
+	elapsed time.Duration,
+	err error,
+) {
+	l := a.cfg.Logger
+	level, msg := slog.LevelDebug, "cache operation"
+	switch {
+	case err == nil || base.IsCacheMiss(err) || base.IsNegativeCached(err):
+	case isPartial(err):
+		level, msg = slog.LevelWarn, "cache operation partially failed"
+	default:
+		level, msg = slog.LevelError, "cache operation failed"
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs,
+		slog.String("op", op),
+		slog.Duration("duration", elapsed),
+	)
+	if rec.key != "" {
+		attrs = append(attrs, slog.String("key", rec.key))
+	}
+	if items != 1 {
+		attrs = append(attrs, slog.Int("items", items))
+	}
+	if rec.hit != nil {
+		attrs = append(attrs, slog.Bool("hit", *rec.hit))
+	}
+	if level > slog.LevelDebug {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	l.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logLock reports a lock that could not be renewed or released.
+func (a *advancedCache[T]) logLock(ctx context.Context, msg, key string, err error) {
+	if l := a.cfg.Logger; l != nil {
+		l.LogAttrs(ctx, slog.LevelWarn, msg, slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// logFailed reports the keys of a pipeline that failed while the rest of
+// the batch went through.
+func (a *advancedCache[T]) logFailed(ctx context.Context, op string, failed map[string]error) {
+	l := a.cfg.Logger
+	if l == nil || len(failed) == 0 {
+		return
+	}
+	l.LogAttrs(ctx, slog.LevelWarn, "cache pipeline partially failed",
+		slog.String("op", op),
+		slog.Int("failed", len(failed)),
+		slog.Any("error", base.FirstError(failed)),
+	)
+}
+
+// isPartial reports errors where the cache itself still works: some keys
+// of a batch failed, a lock was contended or the caller gave up.
+func isPartial(err error) bool {
+	var me *base.MultiError
+	return errors.As(err, &me) || base.IsLockError(err) || base.IsContextError(err)
+}
@@ -0,0 +1,169 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHandler keeps every slog record at or above min.
+type recordHandler struct {
+	min slog.Level
+
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.min }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler           { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler                { return h }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+// find returns the level and attributes of the first record with msg and
+// the given op attribute (any op when op is empty).
+func (h *recordHandler) find(msg, op string) (slog.Level, map[string]slog.Value, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.records {
+		if r.Message != msg {
+			continue
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		if op == "" || attrs["op"].String() == op {
+			return r.Level, attrs, true
+		}
+	}
+	return 0, nil, false
+}
+
+func TestFailedOperationLoggedAsError(t *testing.T) {
+	ctx := context.Background()
+	h := &recordHandler{min: slog.LevelDebug}
+	cfg := testConfig()
+	cfg.Logger = slog.New(h)
+	errDown := errors.New("backend down")
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: newBackend[int](t, cfg),
+		fail:  map[string]error{"k": errDown},
+	})
+
+	if _, err := a.Get(ctx, "k"); !errors.Is(err, errDown) {
+		t.Fatalf("Get = %v", err)
+	}
+	level, attrs, ok := h.find("cache operation failed", "get")
+	if !ok {
+		t.Fatalf("no error record for get: %v", h.records)
+	}
+	if level != slog.LevelError {
+		t.Errorf("level = %v, want ERROR", level)
+	}
+	if attrs["key"].String() != "k" {
+		t.Errorf("key = %v, want k", attrs["key"])
+	}
+	if _, ok := attrs["duration"]; !ok {
+		t.Error("record has no duration")
+	}
+	if err, _ := attrs["error"].Any().(error); !errors.Is(err, errDown) {
+		t.Errorf("error = %v, want %v", attrs["error"], errDown)
+	}
+}
+
+func TestHitsAndMissesLoggedAtDebug(t *testing.T) {
+	ctx := context.Background()
+	h := &recordHandler{min: slog.LevelDebug}
+	cfg := testConfig()
+	cfg.Logger = slog.New(h)
+	a := newTestCache[int](t, cfg, nil)
+
+	_ = a.Set(ctx, "k", 1, time.Minute)
+	_, _ = a.Get(ctx, "k")
+	_, _ = a.Get(ctx, "absent")
+
+	var hits, misses int
+	h.mu.Lock()
+	for _, r := range h.records {
+		if r.Message != "cache operation" {
+			continue
+		}
+		if r.Level != slog.LevelDebug {
+			t.Errorf("%q logged at %v, want DEBUG", r.Message, r.Level)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "hit" {
+				if a.Value.Bool() {
+					hits++
+				} else {
+					misses++
+				}
+			}
+			return true
+		})
+	}
+	h.mu.Unlock()
+	if hits != 1 || misses != 1 {
+		t.Errorf("hits = %d, misses = %d; want 1 and 1", hits, misses)
+	}
+	if _, _, ok := h.find("cache operation failed", ""); ok {
+		t.Error("a miss was logged as a failure")
+	}
+}
+
+func TestPartialPipelineFailureLoggedAsWarning(t *testing.T) {
+	ctx := context.Background()
+	h := &recordHandler{min: slog.LevelWarn}
+	cfg := testConfig()
+	cfg.Logger = slog.New(h)
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: newBackend[int](t, cfg),
+		fail:  map[string]error{"b": errors.New("broken")},
+	})
+
+	if _, err := a.SetManyPipelineResult(ctx, map[string]int{"a": 1, "b": 2}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	level, attrs, ok := h.find("cache pipeline partially failed", "set_many_pipeline")
+	if !ok {
+		t.Fatalf("no pipeline warning: %v", h.records)
+	}
+	if level != slog.LevelWarn || attrs["failed"].Int64() != 1 {
+		t.Errorf("level = %v, failed = %v; want WARN and 1", level, attrs["failed"])
+	}
+}
+
+func TestEvictionLogged(t *testing.T) {
+	ctx := context.Background()
+	h := &recordHandler{min: slog.LevelDebug}
+	cfg := testConfig()
+	cfg.MaxSize = 1
+	cfg.Logger = slog.New(h)
+	a := newTestCache[int](t, cfg, nil)
+
+	_ = a.Set(ctx, "a", 1, time.Minute)
+	_ = a.Set(ctx, "b", 2, time.Minute)
+	if _, attrs, ok := h.find("cache eviction", ""); !ok || attrs["key"].String() != "a" {
+		t.Errorf("eviction record = %v, %v; want key a", attrs, ok)
+	}
+}
+
+func TestNoLoggerSkipsLogState(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	got, rec := a.startLog(ctx)
+	if rec != nil || got != ctx {
+		t.Error("startLog attached log state without a logger")
+	}
+}
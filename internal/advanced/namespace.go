@@ -0,0 +1,429 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+/* ------------------ Namespaces ------------------ */
+
+// Namespace returns a view of the cache whose keys live under name + ":"
+// on the same backend, so the effective prefix is cfg.Prefix + name + ":".
+// Namespaces do not see each other's keys and Clear only removes keys of
+// the namespace. Each view has its own metrics; closing a view does not
+// close the shared backend, while closing the cache stops the views'
+// background workers and waits for them like its own. Keys hashed for exceeding MaxKeyLength are not
+// matched by namespace Clear, Len or Range. An empty name returns the
+// cache itself.
+func (a *advancedCache[T]) Namespace(name string) interfaces.AdvancedCache[T] {
+	if name == "" {
+		return a
+	}
+
+	cfg := a.cfg
+	cfg.Prefix += name + ":"
+
+	ns := &nsCache[T]{next: a.cache, ns: name + ":", started: time.Now()}
+	opts := []Option[T]{WithLoader(a.loader)}
+	if a.early != nil {
+		opts = append(opts, WithEarlyExpiration[T](a.early.beta))
+	}
+	if a.retry != nil {
+		opts = append(opts, WithRetry[T](a.retry.attempts, a.retry.delay))
+	}
+	if t := a.adaptive; t != nil {
+		opts = append(opts, WithAdaptiveTTL[T](t.min, t.max, t.prefix))
+	}
+	view := NewAdvancedCache[T](ns, cfg, opts...).(*advancedCache[T])
+	view.root = a
+	if a.root != nil {
+		view.root = a.root
+	}
+	return view
+}
+
+// nsCache prefixes every key with ns before handing it to next. It
+// implements the optional backend interfaces, falling back to what the
+// advanced cache would do without them when next lacks one.
+type nsCache[T any] struct {
+	next    interfaces.Cache[T]
+	ns      string
+	started time.Time
+}
+
+func (n *nsCache[T]) key(k string) string { return n.ns + k }
+
+func (n *nsCache[T]) keys(ks []string) []string {
+	out := make([]string, len(ks))
+	for i, k := range ks {
+		out[i] = n.ns + k
+	}
+	return out
+}
+
+// strip maps a backend key back to the namespace, reporting whether it
+// belongs to it.
+func (n *nsCache[T]) strip(k string) (string, bool) {
+	return strings.CutPrefix(k, n.ns)
+}
+
+func (n *nsCache[T]) stripErrors(failed map[string]error) map[string]error {
+	if failed == nil {
+		return nil
+	}
+	out := make(map[string]error, len(failed))
+	for k, err := range failed {
+		if s, ok := n.strip(k); ok {
+			k = s
+		}
+		out[k] = err
+	}
+	return out
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (n *nsCache[T]) Get(ctx context.Context, key string) (T, error) {
+	return n.next.Get(ctx, n.key(key))
+}
+
+func (n *nsCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return n.next.Set(ctx, n.key(key), value, ttl)
+}
+
+func (n *nsCache[T]) Delete(ctx context.Context, keys ...string) error {
+	return n.next.Delete(ctx, n.keys(keys)...)
+}
+
+func (n *nsCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	return n.next.Exists(ctx, n.key(key))
+}
+
+// Clear deletes the namespace's keys only.
+func (n *nsCache[T]) Clear(ctx context.Context) error {
+	_, err := n.DeleteByPrefix(ctx, "")
+	return err
+}
+
+// Len counts the namespace's keys by walking the backend.
+func (n *nsCache[T]) Len(ctx context.Context) (int, error) {
+	count := 0
+	err := n.Range(ctx, func(string, T) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+func (n *nsCache[T]) Ping(ctx context.Context) error {
+	return n.next.Ping(ctx)
+}
+
+// Close is a no-op: the backend belongs to the parent cache.
+func (n *nsCache[T]) Close() error {
+	return nil
+}
+
+/* ------------------ Optional API ------------------ */
+
+func (n *nsCache[T]) GetWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	if g, ok := n.next.(interfaces.TTLGetter[T]); ok {
+		return g.GetWithTTL(ctx, n.key(key))
+	}
+	// Unknown remaining TTL is reported as no expiry.
+	v, err := n.Get(ctx, key)
+	return v, 0, err
+}
+
+func (n *nsCache[T]) GetStale(ctx context.Context, key string) (T, bool, error) {
+	if sg, ok := n.next.(interfaces.StaleGetter[T]); ok {
+		return sg.GetStale(ctx, n.key(key))
+	}
+	v, err := n.Get(ctx, key)
+	return v, false, err
+}
+
+func (n *nsCache[T]) GetManyOrdered(ctx context.Context, keys []string) ([]interfaces.Result[T], error) {
+	if og, ok := n.next.(interfaces.OrderedGetter[T]); ok {
+		out, err := og.GetManyOrdered(ctx, n.keys(keys))
+		for i := range out {
+			out[i].Key = keys[i]
+		}
+
+		var me *base.MultiError
+		if errors.As(err, &me) {
+			err = base.NewMultiError(n.stripErrors(me.Errors))
+		}
+		return out, err
+	}
+
+	found, err := n.GetManyPipeline(ctx, keys)
+	out := make([]interfaces.Result[T], len(keys))
+	for i, k := range keys {
+		v, ok := found[k]
+		out[i] = interfaces.Result[T]{Key: k, Value: v, Found: ok}
+	}
+	return out, err
+}
+
+func (n *nsCache[T]) GetManyPipeline(ctx context.Context, keys []string) (map[string]T, error) {
+	if pg, ok := n.next.(interfaces.PipelineGetter[T]); ok {
+		found, err := pg.GetManyPipeline(ctx, n.keys(keys))
+
+		out := make(map[string]T, len(found))
+		for k, v := range found {
+			if s, ok := n.strip(k); ok {
+				out[s] = v
+			}
+		}
+
+		var me *base.MultiError
+		if errors.As(err, &me) {
+			err = base.NewMultiError(n.stripErrors(me.Errors))
+		}
+		return out, err
+	}
+
+	out := make(map[string]T, len(keys))
+	failed := make(map[string]error)
+	for _, k := range keys {
+		v, err := n.Get(ctx, k)
+		switch {
+		case err == nil:
+			out[k] = v
+		case base.IsContextError(err):
+			return out, err
+		case !base.IsCacheMiss(err):
+			failed[k] = err
+		}
+	}
+	return out, base.NewMultiError(failed)
+}
+
+func (n *nsCache[T]) SetManyPipeline(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	failed, err := n.SetManyPipelineResult(ctx, items, ttl)
+	if err != nil {
+		return err
+	}
+	return base.FirstError(failed)
+}
+
+func (n *nsCache[T]) SetManyPipelineResult(ctx context.Context, items map[string]T, ttl time.Duration) (map[string]error, error) {
+	prefixed := make(map[string]T, len(items))
+	for k, v := range items {
+		prefixed[n.key(k)] = v
+	}
+
+	if ps, ok := n.next.(interfaces.PipelineResultSetter[T]); ok {
+		failed, err := ps.SetManyPipelineResult(ctx, prefixed, ttl)
+		return n.stripErrors(failed), err
+	}
+	if ps, ok := n.next.(interfaces.PipelineSetter[T]); ok {
+		return nil, ps.SetManyPipeline(ctx, prefixed, ttl)
+	}
+
+	failed := make(map[string]error)
+	for k, v := range items {
+		if err := n.Set(ctx, k, v, ttl); err != nil {
+			if base.IsContextError(err) {
+				return failed, err
+			}
+			failed[k] = err
+		}
+	}
+	return failed, nil
+}
+
+func (n *nsCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
+	d, ok := n.next.(interfaces.BulkDeleter)
+	if !ok {
+		return 0, fmt.Errorf("DeleteMany not supported")
+	}
+	return d.DeleteMany(ctx, n.keys(keys))
+}
+
+func (n *nsCache[T]) ExistsMany(ctx context.Context, keys []string) (map[string]bool, error) {
+	out := make(map[string]bool, len(keys))
+	if bc, ok := n.next.(interfaces.BulkChecker); ok {
+		found, err := bc.ExistsMany(ctx, n.keys(keys))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range found {
+			if s, ok := n.strip(k); ok {
+				out[s] = v
+			}
+		}
+		return out, nil
+	}
+
+	for _, k := range keys {
+		ok, err := n.Exists(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = ok
+	}
+	return out, nil
+}
+
+func (n *nsCache[T]) Persist(ctx context.Context, key string) error {
+	p, ok := n.next.(interfaces.Persister)
+	if !ok {
+		return fmt.Errorf("Persist not supported")
+	}
+	return p.Persist(ctx, n.key(key))
+}
+
+func (n *nsCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	d, ok := n.next.(interfaces.PrefixDeleter)
+	if !ok {
+		return 0, fmt.Errorf("DeleteByPrefix not supported")
+	}
+	return d.DeleteByPrefix(ctx, n.key(prefix))
+}
+
+func (n *nsCache[T]) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) error {
+	u, ok := n.next.(interfaces.Updater[T])
+	if !ok {
+		return fmt.Errorf("Update not supported")
+	}
+	return u.Update(ctx, n.key(key), ttl, fn)
+}
+
+func (n *nsCache[T]) CompareAndSwap(ctx context.Context, key string, oldVal, newVal T, ttl time.Duration) (bool, error) {
+	cas, ok := n.next.(interfaces.CompareAndSwapper[T])
+	if !ok {
+		return false, fmt.Errorf("CompareAndSwap not supported")
+	}
+	return cas.CompareAndSwap(ctx, n.key(key), oldVal, newVal, ttl)
+}
+
+func (n *nsCache[T]) ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	be, ok := n.next.(interfaces.BulkExpirer)
+	if !ok {
+		return fmt.Errorf("ExpireMany not supported")
+	}
+	return be.ExpireMany(ctx, n.keys(keys), ttl)
+}
+
+func (n *nsCache[T]) CountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	pc, ok := n.next.(interfaces.PrefixCounter)
+	if !ok {
+		return 0, fmt.Errorf("CountByPrefix not supported")
+	}
+	return pc.CountByPrefix(ctx, n.key(prefix))
+}
+
+func (n *nsCache[T]) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	d, ok := n.next.(interfaces.PatternDeleter)
+	if !ok {
+		return 0, fmt.Errorf("DeleteByPattern not supported")
+	}
+	return d.DeleteByPattern(ctx, n.key(pattern))
+}
+
+func (n *nsCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	r, ok := n.next.(interfaces.Ranger[T])
+	if !ok {
+		return fmt.Errorf("Range not supported")
+	}
+	return r.Range(ctx, func(key string, value T) bool {
+		if s, ok := n.strip(key); ok {
+			return fn(s, value)
+		}
+		return true
+	})
+}
+
+func (n *nsCache[T]) Dump(ctx context.Context) (map[string]interfaces.Entry[T], error) {
+	d, ok := n.next.(interfaces.Dumper[T])
+	if !ok {
+		return nil, fmt.Errorf("Dump not supported")
+	}
+	all, err := d.Dump(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interfaces.Entry[T])
+	for k, e := range all {
+		if s, ok := n.strip(k); ok {
+			out[s] = e
+		}
+	}
+	return out, nil
+}
+
+func (n *nsCache[T]) Restore(ctx context.Context, entries map[string]interfaces.Entry[T]) error {
+	d, ok := n.next.(interfaces.Dumper[T])
+	if !ok {
+		return fmt.Errorf("Restore not supported")
+	}
+	prefixed := make(map[string]interfaces.Entry[T], len(entries))
+	for k, e := range entries {
+		prefixed[n.key(k)] = e
+	}
+	return d.Restore(ctx, prefixed)
+}
+
+func (n *nsCache[T]) SetIfAbsent(ctx context.Context, key string, value T, ttl time.Duration) (T, bool, error) {
+	if as, ok := n.next.(interfaces.AtomicSetter[T]); ok {
+		return as.SetIfAbsent(ctx, n.key(key), value, ttl)
+	}
+	return value, true, n.Set(ctx, key, value, ttl)
+}
+
+// TryLock reports an ownerless lock as acquired when the backend cannot
+// lock, matching the advanced cache's behaviour without a locker.
+func (n *nsCache[T]) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	if l, ok := n.next.(interfaces.DistributedLocker); ok {
+		return l.TryLock(ctx, n.key(key), ttl)
+	}
+	return "", true, nil
+}
+
+func (n *nsCache[T]) Unlock(ctx context.Context, key string, token string) error {
+	if l, ok := n.next.(interfaces.DistributedLocker); ok {
+		return l.Unlock(ctx, n.key(key), token)
+	}
+	return nil
+}
+
+func (n *nsCache[T]) ExtendLock(ctx context.Context, key string, token string, ttl time.Duration) (bool, error) {
+	if e, ok := n.next.(interfaces.LockExtender); ok {
+		return e.ExtendLock(ctx, n.key(key), token, ttl)
+	}
+	_, isLocker := n.next.(interfaces.DistributedLocker)
+	return !isLocker, nil
+}
+
+func (n *nsCache[T]) StartedAt() time.Time {
+	if sp, ok := n.next.(interfaces.StartTimeProvider); ok {
+		return sp.StartedAt()
+	}
+	return n.started
+}
+
+// Stats reports the backend's stats; the view's own counters are added by
+// the advanced cache. Items is not tracked per namespace, since counting
+// means walking the backend, and is left at 0; Len counts it on demand.
+func (n *nsCache[T]) Stats(ctx context.Context) metrics.CacheStats {
+	stats := metrics.CacheStats{Backend: "namespace", StartedAt: n.StartedAt()}
+	if sp, ok := n.next.(interfaces.StatProvider); ok {
+		stats = sp.Stats(ctx)
+	}
+	stats.Items = 0
+	return stats
+}
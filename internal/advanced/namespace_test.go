@@ -0,0 +1,214 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/redis"
+)
+
+// namespaceBackends runs fn against a memory and a Redis backend.
+func namespaceBackends(t *testing.T, fn func(t *testing.T, a *advancedCache[string])) {
+	t.Run("memory", func(t *testing.T) {
+		fn(t, newTestCache[string](t, testConfig(), nil))
+	})
+	t.Run("redis", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Type = config.TypeRedis
+		cfg.RedisURL = "redis://" + miniredis.RunT(t).Addr()
+		r, err := redis.NewRedisCache[string](cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn(t, newTestCache[string](t, cfg, r))
+	})
+}
+
+func TestNamespacesAreIsolated(t *testing.T) {
+	namespaceBackends(t, func(t *testing.T, a *advancedCache[string]) {
+		ctx := context.Background()
+		acme, globex := a.Namespace("acme"), a.Namespace("globex")
+
+		_ = acme.Set(ctx, "user:1", "acme-1", time.Minute)
+		_ = globex.Set(ctx, "user:1", "globex-1", time.Minute)
+		_ = acme.Set(ctx, "only-acme", "x", time.Minute)
+
+		if v, _ := acme.Get(ctx, "user:1"); v != "acme-1" {
+			t.Errorf("acme user:1 = %q", v)
+		}
+		if v, _ := globex.Get(ctx, "user:1"); v != "globex-1" {
+			t.Errorf("globex user:1 = %q", v)
+		}
+		if _, err := globex.Get(ctx, "only-acme"); !errors.Is(err, base.ErrCacheMiss) {
+			t.Errorf("globex sees acme's key: %v", err)
+		}
+		if ok, _ := a.Exists(ctx, "user:1"); ok {
+			t.Error("namespaced key visible without the namespace")
+		}
+		// The parent reaches namespaced keys under name + ":".
+		if v, _ := a.Get(ctx, "acme:user:1"); v != "acme-1" {
+			t.Errorf("parent acme:user:1 = %q", v)
+		}
+
+		got, err := acme.GetManyPipeline(ctx, []string{"user:1", "only-acme", "missing"})
+		if want := map[string]string{"user:1": "acme-1", "only-acme": "x"}; err != nil || !maps.Equal(got, want) {
+			t.Errorf("acme GetManyPipeline = %v, %v; want %v", got, err, want)
+		}
+		if n, _ := acme.Len(ctx); n != 2 {
+			t.Errorf("acme Len = %d, want 2", n)
+		}
+	})
+}
+
+func TestNamespaceClearIsScoped(t *testing.T) {
+	namespaceBackends(t, func(t *testing.T, a *advancedCache[string]) {
+		ctx := context.Background()
+		acme, globex := a.Namespace("acme"), a.Namespace("globex")
+
+		_ = a.Set(ctx, "shared", "s", time.Minute)
+		_ = acme.Set(ctx, "k1", "a", time.Minute)
+		_ = acme.Set(ctx, "k2", "a", time.Minute)
+		_ = globex.Set(ctx, "k1", "g", time.Minute)
+
+		if err := acme.Clear(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := acme.Len(ctx); n != 0 {
+			t.Errorf("acme Len after Clear = %d", n)
+		}
+		if v, _ := globex.Get(ctx, "k1"); v != "g" {
+			t.Errorf("globex k1 = %q after acme Clear", v)
+		}
+		if v, _ := a.Get(ctx, "shared"); v != "s" {
+			t.Errorf("parent key = %q after acme Clear", v)
+		}
+	})
+}
+
+func TestNamespaceUsesPrefixedRedisKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.Type = config.TypeRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	r, err := redis.NewRedisCache[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestCache[string](t, cfg, r)
+
+	_ = a.Namespace("acme").Set(ctx, "k", "v", time.Minute)
+	if !mr.Exists(cfg.Prefix + "acme:k") {
+		t.Errorf("keys = %v, want %q", mr.Keys(), cfg.Prefix+"acme:k")
+	}
+}
+
+func TestNamespaceViewLifecycleAndMetrics(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[string](t, testConfig(), nil)
+	if a.Namespace("") != interfaces.AdvancedCache[string](a) {
+		t.Error("empty namespace is not the cache itself")
+	}
+
+	ns := a.Namespace("acme")
+	_ = ns.Set(ctx, "k", "v", time.Minute)
+	_, _ = ns.Get(ctx, "k")
+
+	// Each view keeps its own metrics.
+	if n := ns.Metrics().Snapshot()["get"].Count; n != 1 {
+		t.Errorf("view get count = %d, want 1", n)
+	}
+	if n := a.Metrics().Snapshot()["get"].Count; n != 0 {
+		t.Errorf("parent get count = %d, want 0", n)
+	}
+
+	// Closing a view leaves the shared backend open.
+	if err := ns.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Set(ctx, "after", "v", time.Minute); err != nil {
+		t.Errorf("parent Set after closing a view = %v", err)
+	}
+}
+
+// rangeCounter counts the walks over the wrapped memory backend.
+type rangeCounter struct {
+	interfaces.Cache[string]
+	ranges atomic.Int64
+}
+
+func (r *rangeCounter) Range(ctx context.Context, fn func(string, string) bool) error {
+	r.ranges.Add(1)
+	return r.Cache.(interfaces.Ranger[string]).Range(ctx, fn)
+}
+
+func TestNamespaceStatsDoNotWalkKeys(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	backend := &rangeCounter{Cache: newBackend[string](t, cfg)}
+	ns := newTestCache[string](t, cfg, backend).Namespace("acme")
+
+	_ = ns.Set(ctx, "k", "v", time.Minute)
+	_, _ = ns.Get(ctx, "k")
+	_, _ = ns.Get(ctx, "absent")
+
+	stats := ns.Stats(ctx)
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("view Stats = %d hits, %d misses; want 1 and 1", stats.Hits, stats.Misses)
+	}
+	if n := backend.ranges.Load(); n != 0 {
+		t.Errorf("Stats walked the backend %d times", n)
+	}
+	if n, _ := ns.Len(ctx); n != 1 || backend.ranges.Load() != 1 {
+		t.Errorf("Len = %d, want 1 counted by one walk", n)
+	}
+}
+
+func TestNamespaceWorkersStopWithParent(t *testing.T) {
+	a := newTestCache[int](t, testConfig(), nil)
+	view := a.Namespace("acme").(*advancedCache[int])
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if err := view.RegisterRefresher("k", 5*time.Millisecond, fn); err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	if !view.Go(func() { <-release }) {
+		t.Fatal("view Go refused before Close")
+	}
+
+	// The parent's Close waits for the view's workers.
+	closed := make(chan error, 1)
+	go func() { closed <- a.Close() }()
+	select {
+	case err := <-closed:
+		t.Fatalf("parent Close returned %v with a view worker running", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+
+	n := calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	if got := calls.Load(); got != n {
+		t.Errorf("view refresher ran %d more times after the parent closed", got-n)
+	}
+	if view.Go(func() {}) {
+		t.Error("view Go started a worker after the parent closed")
+	}
+	if err := view.RegisterRefresher("k2", time.Millisecond, fn); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("RegisterRefresher after the parent closed = %v, want ErrClosed", err)
+	}
+}
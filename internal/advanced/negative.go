@@ -0,0 +1,59 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Negative Caching ------------------ */
+
+// GetOrSetWithNegative is GetOrSet for sources that can report a key as
+// absent: when fn returns found == false, a marker is cached for
+// negativeTTL and every call until it expires, including this one, returns
+// ErrNegativeCached without invoking fn.
+func (a *advancedCache[T]) GetOrSetWithNegative(
+	ctx context.Context,
+	key string,
+	ttl, negativeTTL time.Duration,
+	fn func() (T, bool, error),
+) (T, error) {
+	var zero T
+	if negativeTTL <= 0 {
+		return zero, base.WrapError(base.OpGetOrSetNeg, base.ErrInvalidArgument, key)
+	}
+
+	var result T
+	err := a.withMetrics(ctx, string(base.OpGetOrSetNeg), 1, func(ctx context.Context) error {
+		val, err := a.get(ctx, key)
+		if err == nil {
+			result = val
+			return nil
+		}
+		if !errors.Is(err, base.ErrCacheMiss) {
+			return err
+		}
+
+		marker := base.NegativeKey(key)
+		if absent, err := a.cache.Exists(ctx, marker); err == nil && absent {
+			return base.WrapError(base.OpGetOrSetNeg, base.ErrNegativeCached, key)
+		}
+
+		val, found, err := fn()
+		if err != nil {
+			return err
+		}
+		if !found {
+			_ = a.cache.Set(ctx, marker, zero, negativeTTL)
+			return base.WrapError(base.OpGetOrSetNeg, base.ErrNegativeCached, key)
+		}
+
+		_ = a.Set(ctx, key, val, ttl)
+		result = val
+		return nil
+	})
+
+	return result, err
+}
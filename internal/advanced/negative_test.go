@@ -0,0 +1,157 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/memory"
+)
+
+func TestGetOrSetWithNegativeSkipsSourceDuringNegativeTTL(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[string](t, testConfig(), nil)
+
+	calls := 0
+	fn := func() (string, bool, error) {
+		calls++
+		return "", false, nil
+	}
+	for i := range 5 {
+		_, err := a.GetOrSetWithNegative(ctx, "user:42", time.Minute, 50*time.Millisecond, fn)
+		if !errors.Is(err, base.ErrNegativeCached) {
+			t.Fatalf("call %d: %v, want ErrNegativeCached", i, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("source called %d times during the negative TTL, want 1", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	_, err := a.GetOrSetWithNegative(ctx, "user:42", time.Minute, 50*time.Millisecond, fn)
+	if !errors.Is(err, base.ErrNegativeCached) || calls != 2 {
+		t.Errorf("after the negative TTL: %v with %d calls, want a second call", err, calls)
+	}
+}
+
+func TestGetOrSetWithNegativeCachesFoundValues(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[string](t, testConfig(), nil)
+
+	calls := 0
+	fn := func() (string, bool, error) {
+		calls++
+		return "ann", true, nil
+	}
+	for range 3 {
+		v, err := a.GetOrSetWithNegative(ctx, "user:1", time.Minute, time.Minute, fn)
+		if err != nil || v != "ann" {
+			t.Fatalf("GetOrSetWithNegative = %q, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("source called %d times, want 1", calls)
+	}
+
+	if _, err := a.GetOrSetWithNegative(ctx, "user:1", time.Minute, 0, fn); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("zero negative TTL = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestNegativeMarkersAreNotListed(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	backend, err := memory.NewMemory[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newTestCache[string](t, cfg, backend)
+
+	if err := a.Set(ctx, "user:1", "ann", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	absent := func() (string, bool, error) { return "", false, nil }
+	for _, k := range []string{"user:2", "user:3"} {
+		if _, err := a.GetOrSetWithNegative(ctx, k, time.Minute, time.Minute, absent); !errors.Is(err, base.ErrNegativeCached) {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := a.Len(ctx); err != nil || n != 1 {
+		t.Errorf("Len = %d, %v; want 1", n, err)
+	}
+
+	var ranged []string
+	if err := a.Range(ctx, func(k, _ string) bool { ranged = append(ranged, k); return true }); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranged) != 1 || ranged[0] != "user:1" {
+		t.Errorf("Range visited %v, want [user:1]", ranged)
+	}
+
+	dump, err := a.Dump(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dump["user:1"]; !ok || len(dump) != 1 {
+		t.Errorf("Dump has %d entries, want only user:1", len(dump))
+	}
+
+	keys, err := backend.Keys(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if strings.HasPrefix(k, base.NegativeKey("")) {
+			t.Errorf("Keys lists the marker %q", k)
+		}
+	}
+
+	// The markers are still there and still short-circuit.
+	if _, err := a.GetOrSetWithNegative(ctx, "user:2", time.Minute, time.Minute, absent); !errors.Is(err, base.ErrNegativeCached) {
+		t.Errorf("marker lost: %v", err)
+	}
+}
+
+func TestPrefixAndPatternDeletesDropMarkers(t *testing.T) {
+	namespaceBackends(t, func(t *testing.T, a *advancedCache[string]) {
+		ctx := context.Background()
+		absent := func() (string, bool, error) { return "", false, nil }
+		for _, k := range []string{"user:1", "user:2", "order:1"} {
+			_, _ = a.GetOrSetWithNegative(ctx, k, time.Minute, time.Minute, absent)
+		}
+		_ = a.Set(ctx, "user:3", "cy", time.Minute)
+
+		if n, err := a.DeleteByPrefix(ctx, "user:"); err != nil || n != 1 {
+			t.Errorf("DeleteByPrefix = %d, %v; want only user:3 counted", n, err)
+		}
+		for _, k := range []string{"user:1", "user:2"} {
+			if ok, _ := a.Exists(ctx, base.NegativeKey(k)); ok {
+				t.Errorf("marker of %s survived DeleteByPrefix", k)
+			}
+		}
+		if ok, _ := a.Exists(ctx, base.NegativeKey("order:1")); !ok {
+			t.Error("marker outside the prefix was deleted")
+		}
+
+		if _, err := a.DeleteByPattern(ctx, "order:*"); err != nil {
+			t.Fatal(err)
+		}
+		if ok, _ := a.Exists(ctx, base.NegativeKey("order:1")); ok {
+			t.Error("marker of order:1 survived DeleteByPattern")
+		}
+
+		// With its marker gone the source is asked again.
+		called := false
+		_, _ = a.GetOrSetWithNegative(ctx, "user:1", time.Minute, time.Minute, func() (string, bool, error) {
+			called = true
+			return "ann", true, nil
+		})
+		if !called {
+			t.Error("source not consulted after the marker was deleted")
+		}
+	})
+}
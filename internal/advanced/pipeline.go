@@ -2,14 +2,19 @@ package advanced
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/os-golib/go-cache/internal/base"
 	"github.com/os-golib/go-cache/internal/interfaces"
 )
 
 /* ------------------ Pipeline: GET ------------------ */
 
+// GetManyPipeline returns the keys found. Misses are simply absent; other
+// per-key failures come back as a *base.MultiError next to the partial
+// result. The loader is never run, with or without a native pipeline.
 func (a *advancedCache[T]) GetManyPipeline(
 	ctx context.Context,
 	keys []string,
@@ -20,29 +25,66 @@ func (a *advancedCache[T]) GetManyPipeline(
 	}
 
 	result := make(map[string]T, len(keys))
+	failed := make(map[string]error)
 	var mu sync.Mutex
 
 	tasks := make([]func(context.Context) error, 0, len(keys))
 	for _, key := range keys {
 		k := key
 		tasks = append(tasks, func(ctx context.Context) error {
-			val, err := a.Get(ctx, k)
-			if err != nil {
-				return err
-			}
+			val, err := a.cache.Get(ctx, k)
 
 			mu.Lock()
-			result[k] = val
-			mu.Unlock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				result[k] = val
+			case !base.IsCacheMiss(err):
+				failed[k] = err
+			}
 			return nil
 		})
 	}
 
-	err := a.withMetrics("get_many_pipeline", len(keys), func() error {
-		return a.concurrentExecute(ctx, tasks, 10)
+	err := a.withMetrics(ctx, "get_many_pipeline", len(keys), func(ctx context.Context) error {
+		return a.concurrentExecute(ctx, tasks, a.pipelineConcurrency())
 	})
+	if err != nil {
+		return result, err
+	}
+
+	a.logFailed(ctx, "get_many_pipeline", failed)
+	return result, base.NewMultiError(failed)
+}
+
+// GetManyOrdered returns one Result per key in input order, so results can
+// be zipped back onto the request. Backends without a native ordered read
+// go through GetManyPipeline; per-key failures are reported as there.
+func (a *advancedCache[T]) GetManyOrdered(
+	ctx context.Context,
+	keys []string,
+) ([]interfaces.Result[T], error) {
+	if og, ok := a.cache.(interfaces.OrderedGetter[T]); ok {
+		var out []interfaces.Result[T]
+		err := a.withMetrics(ctx, "get_many_ordered", len(keys), func(ctx context.Context) error {
+			var err error
+			out, err = og.GetManyOrdered(ctx, keys)
+			return err
+		})
+		return out, err
+	}
+
+	found, err := a.GetManyPipeline(ctx, keys)
+	if found == nil {
+		return nil, err
+	}
 
-	return result, err
+	out := make([]interfaces.Result[T], len(keys))
+	for i, k := range keys {
+		v, ok := found[k]
+		out[i] = interfaces.Result[T]{Key: k, Value: v, Found: ok}
+	}
+	return out, err
 }
 
 /* ------------------ Pipeline: SET ------------------ */
@@ -52,26 +94,72 @@ func (a *advancedCache[T]) SetManyPipeline(
 	items map[string]T,
 	ttl time.Duration,
 ) error {
-	// Fast path: backend supports pipeline
-	if ps, ok := a.cache.(interfaces.PipelineSetter[T]); ok {
-		return ps.SetManyPipeline(ctx, items, ttl)
+	// Backends without per-key reporting keep their native bulk path
+	if _, ok := a.cache.(interfaces.PipelineResultSetter[T]); !ok {
+		if ps, ok := a.cache.(interfaces.PipelineSetter[T]); ok {
+			return ps.SetManyPipeline(ctx, items, ttl)
+		}
+	}
+
+	failed, err := a.SetManyPipelineResult(ctx, items, ttl)
+	if err != nil {
+		return err
+	}
+	return base.FirstError(failed)
+}
+
+func (a *advancedCache[T]) SetManyPipelineResult(
+	ctx context.Context,
+	items map[string]T,
+	ttl time.Duration,
+) (map[string]error, error) {
+	// Fast path: backend supports per-key pipeline results
+	if ps, ok := a.cache.(interfaces.PipelineResultSetter[T]); ok {
+		var failed map[string]error
+		err := a.withMetrics(ctx, "set_many_pipeline", len(items), func(ctx context.Context) error {
+			var err error
+			failed, err = ps.SetManyPipelineResult(ctx, items, ttl)
+			return err
+		})
+		a.logFailed(ctx, "set_many_pipeline", failed)
+		return failed, err
 	}
 
+	failed := make(map[string]error)
+	var mu sync.Mutex
+
 	tasks := make([]func(context.Context) error, 0, len(items))
 	for key, val := range items {
 		k, v := key, val
 		tasks = append(tasks, func(ctx context.Context) error {
-			return a.Set(ctx, k, v, ttl)
+			if err := a.Set(ctx, k, v, ttl); err != nil {
+				mu.Lock()
+				failed[k] = err
+				mu.Unlock()
+			}
+			return nil
 		})
 	}
 
-	return a.withMetrics("set_many_pipeline", len(items), func() error {
-		return a.concurrentExecute(ctx, tasks, 10)
+	err := a.withMetrics(ctx, "set_many_pipeline", len(items), func(ctx context.Context) error {
+		return a.concurrentExecute(ctx, tasks, a.pipelineConcurrency())
 	})
+
+	a.logFailed(ctx, "set_many_pipeline", failed)
+	return failed, err
 }
 
 /* ------------------ Concurrent Helper ------------------ */
 
+const defaultPipelineConcurrency = 10
+
+func (a *advancedCache[T]) pipelineConcurrency() int {
+	if n := a.cfg.PipelineConcurrency; n > 0 {
+		return n
+	}
+	return defaultPipelineConcurrency
+}
+
 func (a *advancedCache[T]) concurrentExecute(
 	ctx context.Context,
 	tasks []func(context.Context) error,
@@ -81,6 +169,21 @@ func (a *advancedCache[T]) concurrentExecute(
 		maxConcurrency = 1
 	}
 
+	// Sequential: no goroutines needed. Like the concurrent path, every
+	// task runs and the first error is returned.
+	if maxConcurrency == 1 {
+		var firstErr error
+		for _, fn := range tasks {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(ctx); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
 	sem := make(chan struct{}, maxConcurrency)
 	var wg sync.WaitGroup
 	var firstErr error
@@ -108,3 +211,52 @@ func (a *advancedCache[T]) concurrentExecute(
 	wg.Wait()
 	return firstErr
 }
+
+/* ------------------ Pipeline: EXPIRE ------------------ */
+
+// ExpireMany resets the TTL of every existing key in keys; missing keys
+// are skipped.
+func (a *advancedCache[T]) ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	be, ok := a.cache.(interfaces.BulkExpirer)
+	if !ok {
+		return fmt.Errorf("ExpireMany not supported")
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ttl = a.base.ResolveTTL(ttl)
+
+	return a.withMetrics(ctx, "expire_many", len(keys), func(ctx context.Context) error {
+		return be.ExpireMany(ctx, keys, ttl)
+	})
+}
+
+/* ------------------ Pipeline: EXISTS ------------------ */
+
+// ExistsMany reports for every key whether it is cached. Backends without
+// a batch check are asked key by key.
+func (a *advancedCache[T]) ExistsMany(ctx context.Context, keys []string) (map[string]bool, error) {
+	var out map[string]bool
+	err := a.withMetrics(ctx, "exists_many", len(keys), func(ctx context.Context) error {
+		if bc, ok := a.cache.(interfaces.BulkChecker); ok {
+			var err error
+			out, err = bc.ExistsMany(ctx, keys)
+			return err
+		}
+
+		out = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			ok, err := a.cache.Exists(ctx, k)
+			if err != nil {
+				return err
+			}
+			out[k] = ok
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
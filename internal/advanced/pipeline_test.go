@@ -0,0 +1,306 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/memory"
+)
+
+// plainCache hides the optional interfaces of the wrapped backend, so the
+// advanced cache takes its generic paths, and fails Get and Set for the
+// keys in fail.
+type plainCache[T any] struct {
+	interfaces.Cache[T]
+	fail map[string]error
+}
+
+func (p *plainCache[T]) Get(ctx context.Context, key string) (T, error) {
+	if err := p.fail[key]; err != nil {
+		var zero T
+		return zero, err
+	}
+	return p.Cache.Get(ctx, key)
+}
+
+func (p *plainCache[T]) Set(ctx context.Context, key string, val T, ttl time.Duration) error {
+	if err := p.fail[key]; err != nil {
+		return err
+	}
+	return p.Cache.Set(ctx, key, val, ttl)
+}
+
+func TestSetManyPipelineResultFallbackReportsPerKey(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	errBroken := errors.New("broken")
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: newBackend[int](t, cfg),
+		fail:  map[string]error{"b": errBroken},
+	})
+
+	items := map[string]int{"a": 1, "b": 2, "c": 3}
+	failed, err := a.SetManyPipelineResult(ctx, items, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || !errors.Is(failed["b"], errBroken) {
+		t.Fatalf("failed = %v, want only b", failed)
+	}
+	for _, k := range []string{"a", "c"} {
+		if v, err := a.Get(ctx, k); err != nil || v != items[k] {
+			t.Errorf("Get(%s) = %d, %v", k, v, err)
+		}
+	}
+
+	if err := a.SetManyPipeline(ctx, items, time.Minute); !errors.Is(err, errBroken) {
+		t.Errorf("SetManyPipeline = %v, want the per-key error", err)
+	}
+}
+
+// inflightCache hides the optional interfaces of the wrapped backend and
+// records the most Get and Set calls running at once.
+type inflightCache[T any] struct {
+	interfaces.Cache[T]
+	cur, max atomic.Int64
+}
+
+func (c *inflightCache[T]) track() func() {
+	n := c.cur.Add(1)
+	for m := c.max.Load(); n > m && !c.max.CompareAndSwap(m, n); m = c.max.Load() {
+	}
+	time.Sleep(2 * time.Millisecond)
+	return func() { c.cur.Add(-1) }
+}
+
+func (c *inflightCache[T]) Get(ctx context.Context, key string) (T, error) {
+	defer c.track()()
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *inflightCache[T]) Set(ctx context.Context, key string, val T, ttl time.Duration) error {
+	defer c.track()()
+	return c.Cache.Set(ctx, key, val, ttl)
+}
+
+func TestPipelineFallbackHonoursConcurrency(t *testing.T) {
+	ctx := context.Background()
+	items := map[string]int{}
+	keys := make([]string, 0, 40)
+	for i := range 40 {
+		k := "k" + strconv.Itoa(i)
+		items[k] = i
+		keys = append(keys, k)
+	}
+
+	for _, tt := range []struct {
+		configured, want int
+	}{
+		{0, defaultPipelineConcurrency},
+		{1, 1},
+		{4, 4},
+	} {
+		cfg := testConfig()
+		cfg.PipelineConcurrency = tt.configured
+		backend := &inflightCache[int]{Cache: newBackend[int](t, cfg)}
+		a := newTestCache[int](t, cfg, backend)
+
+		if err := a.SetManyPipeline(ctx, items, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if got := backend.max.Load(); got != int64(tt.want) {
+			t.Errorf("concurrency %d: %d Sets in flight, want %d", tt.configured, got, tt.want)
+		}
+
+		backend.max.Store(0)
+		got, err := a.GetManyPipeline(ctx, keys)
+		if err != nil || len(got) != len(keys) {
+			t.Fatalf("GetManyPipeline = %d values, %v", len(got), err)
+		}
+		if n := backend.max.Load(); n != int64(tt.want) {
+			t.Errorf("concurrency %d: %d Gets in flight, want %d", tt.configured, n, tt.want)
+		}
+	}
+}
+
+func TestSequentialFallbackReturnsFirstError(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.PipelineConcurrency = 1
+	errBroken := errors.New("broken")
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: newBackend[int](t, cfg),
+		fail:  map[string]error{"b": errBroken},
+	})
+
+	items := map[string]int{"a": 1, "b": 2, "c": 3}
+	if err := a.SetManyPipeline(ctx, items, time.Minute); !errors.Is(err, errBroken) {
+		t.Errorf("SetManyPipeline = %v, want the per-key error", err)
+	}
+	// Every task still runs after the failure.
+	for _, k := range []string{"a", "c"} {
+		if v, err := a.Get(ctx, k); err != nil || v != items[k] {
+			t.Errorf("Get(%s) = %d, %v", k, v, err)
+		}
+	}
+}
+
+func TestGetManyPipelineFallbackReturnsPartialResult(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	errBroken := errors.New("broken")
+	backend := newBackend[int](t, cfg)
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: backend,
+		fail:  map[string]error{"bad": errBroken},
+	})
+
+	for k, v := range map[string]int{"a": 1, "b": 2, "bad": 3} {
+		if err := backend.Set(ctx, k, v, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := a.GetManyPipeline(ctx, []string{"a", "missing", "bad", "b"})
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(got, want) {
+		t.Errorf("result = %v, want %v", got, want)
+	}
+	var multi *base.MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("err = %v, want a *MultiError", err)
+	}
+	if len(multi.Errors) != 1 || !errors.Is(multi.Errors["bad"], errBroken) {
+		t.Errorf("failures = %v, want only bad", multi.Errors)
+	}
+}
+
+func TestGetManyPipelineNeverRunsLoader(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	var loads atomic.Int64
+	loader := WithLoader(func(context.Context, string) (int, error) {
+		loads.Add(1)
+		return 9, nil
+	})
+
+	for name, backend := range map[string]interfaces.Cache[int]{
+		"native":   newBackend[int](t, cfg),
+		"fallback": &plainCache[int]{Cache: newBackend[int](t, cfg)},
+	} {
+		loads.Store(0)
+		a := newTestCache[int](t, cfg, backend, loader)
+		_ = a.Set(ctx, "a", 1, time.Minute)
+
+		got, err := a.GetManyPipeline(ctx, []string{"a", "missing"})
+		if err != nil || !maps.Equal(got, map[string]int{"a": 1}) {
+			t.Errorf("%s: GetManyPipeline = %v, %v; want only a", name, got, err)
+		}
+		if n := loads.Load(); n != 0 {
+			t.Errorf("%s: loader ran %d times, want 0", name, n)
+		}
+	}
+}
+
+func TestGetManyOrderedFallbackKeepsInputOrder(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	errBroken := errors.New("broken")
+	backend := newBackend[int](t, cfg)
+	a := newTestCache[int](t, cfg, &plainCache[int]{
+		Cache: backend,
+		fail:  map[string]error{"bad": errBroken},
+	})
+	_ = backend.Set(ctx, "a", 1, time.Minute)
+	_ = backend.Set(ctx, "b", 2, time.Minute)
+
+	got, err := a.GetManyOrdered(ctx, []string{"b", "missing", "bad", "a", "b"})
+	want := []interfaces.Result[int]{
+		{Key: "b", Value: 2, Found: true},
+		{Key: "missing"},
+		{Key: "bad"},
+		{Key: "a", Value: 1, Found: true},
+		{Key: "b", Value: 2, Found: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetManyOrdered = %v, want %v", got, want)
+	}
+	var multi *base.MultiError
+	if !errors.As(err, &multi) || !errors.Is(multi.Errors["bad"], errBroken) {
+		t.Errorf("err = %v, want bad reported", err)
+	}
+}
+
+func TestNamespaceGetManyOrderedRestoresKeys(t *testing.T) {
+	namespaceBackends(t, func(t *testing.T, a *advancedCache[string]) {
+		ctx := context.Background()
+		acme := a.Namespace("acme")
+		_ = acme.Set(ctx, "x", "acme-x", time.Minute)
+		_ = a.Set(ctx, "y", "root-y", time.Minute)
+
+		got, err := acme.GetManyOrdered(ctx, []string{"y", "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []interfaces.Result[string]{
+			{Key: "y"},
+			{Key: "x", Value: "acme-x", Found: true},
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("GetManyOrdered = %v, want %v", got, want)
+		}
+	})
+}
+
+// BenchmarkSetManyPipeline1000 compares the memory backend's single-lock
+// bulk write with the per-key fallback it replaced.
+func BenchmarkSetManyPipeline1000(b *testing.B) {
+	ctx := context.Background()
+	items := make(map[string]int, 1000)
+	for i := range 1000 {
+		items["k"+strconv.Itoa(i)] = i
+	}
+
+	for _, bm := range []struct {
+		name string
+		wrap func(interfaces.Cache[int]) interfaces.Cache[int]
+	}{
+		{"native", func(c interfaces.Cache[int]) interfaces.Cache[int] { return c }},
+		{"fallback", func(c interfaces.Cache[int]) interfaces.Cache[int] { return &plainCache[int]{Cache: c} }},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg := testConfig()
+			m, err := memory.NewMemory[int](cfg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			a := NewAdvancedCache[int](bm.wrap(m), cfg)
+			defer func() { _ = a.Close() }()
+
+			for b.Loop() {
+				if err := a.SetManyPipeline(ctx, items, time.Minute); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestExistsManyFallback(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	a := newTestCache[int](t, cfg, &plainCache[int]{Cache: newBackend[int](t, cfg)})
+
+	_ = a.Set(ctx, "a", 1, time.Minute)
+	got, err := a.ExistsMany(ctx, []string{"a", "b"})
+	if want := map[string]bool{"a": true, "b": false}; err != nil || !maps.Equal(got, want) {
+		t.Errorf("ExistsMany = %v, %v; want %v", got, err, want)
+	}
+}
@@ -0,0 +1,151 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Stampede Protection ------------------ */
+
+const (
+	protectPollMin = 5 * time.Millisecond
+	protectPollMax = 100 * time.Millisecond
+)
+
+// GetOrSetProtected combines GetOrSetLocked and GetOrSetStale for hot
+// keys. Entries are kept for ttl + staleTTL. Fresh hits return at once.
+// Stale hits also return at once, and start a single background refresh
+// that runs only if this process takes the key's distributed lock; while
+// another process holds it, that process refreshes. On a miss one caller
+// per process takes the lock and calls fn, the others wait for it; if
+// another process holds the lock, its value is polled for instead of
+// being computed again. Backends that cannot report TTLs fall back to
+// GetOrSetLocked, as does NoExpiration.
+func (a *advancedCache[T]) GetOrSetProtected(
+	ctx context.Context,
+	key string,
+	ttl, staleTTL time.Duration,
+	fn func() (T, error),
+) (T, error) {
+	getter, ok := a.cache.(interfaces.TTLGetter[T])
+	if !ok || staleTTL <= 0 || ttl == base.NoExpiration {
+		return a.GetOrSetLocked(ctx, key, ttl, fn)
+	}
+
+	var zero T
+	if err := a.base.ValidateKey(key); err != nil {
+		return zero, err
+	}
+	if err := a.base.CheckContext(ctx); err != nil {
+		return zero, err
+	}
+
+	hardTTL := a.base.ResolveTTL(ttl) + staleTTL
+
+	var result T
+	err := a.withMetrics(ctx, "get_or_set_protected", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		val, remaining, err := getter.GetWithTTL(ctx, key)
+		if err == nil {
+			a.hit(ctx, "get_or_set_protected")
+			result = val
+			if remaining > 0 && remaining <= staleTTL {
+				a.refreshProtected(key, hardTTL, fn)
+			}
+			return nil
+		}
+		if !errors.Is(err, base.ErrCacheMiss) {
+			return err
+		}
+		a.miss(ctx, "get_or_set_protected")
+
+		val, err = a.protected.Do(key, func() (T, error) {
+			return a.fillProtected(ctx, getter, key, hardTTL, fn)
+		})
+		if err != nil {
+			return err
+		}
+		result = val
+		return nil
+	})
+	return result, err
+}
+
+// fillProtected computes a missing key under its distributed lock. While
+// another process holds the lock, it polls for the value that process
+// stores, trying the lock again between polls in case the holder gave up.
+func (a *advancedCache[T]) fillProtected(
+	ctx context.Context,
+	getter interfaces.TTLGetter[T],
+	key string,
+	ttl time.Duration,
+	fn func() (T, error),
+) (T, error) {
+	var zero T
+	backoff := protectPollMin
+
+	for {
+		release, ok, err := a.tryLock(ctx, key)
+		if err != nil {
+			return zero, err
+		}
+		if ok {
+			defer release()
+
+			// The previous holder may have stored the value just before
+			// releasing the lock.
+			if v, _, err := getter.GetWithTTL(ctx, key); err == nil {
+				return v, nil
+			}
+			v, err := fn()
+			if err == nil {
+				_ = a.cache.Set(ctx, key, v, ttl)
+			}
+			return v, err
+		}
+
+		v, _, err := getter.GetWithTTL(ctx, key)
+		if err == nil {
+			return v, nil
+		}
+		if !errors.Is(err, base.ErrCacheMiss) {
+			return zero, err
+		}
+
+		t := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return zero, ctx.Err()
+		case <-t.C:
+		}
+		backoff = min(backoff*2, protectPollMax)
+	}
+}
+
+// refreshProtected refreshes a stale key in the background unless a
+// refresh is already running here, the cache is closing or another
+// process holds the key's lock.
+func (a *advancedCache[T]) refreshProtected(key string, ttl time.Duration, fn func() (T, error)) {
+	a.refreshes.Spawn(key, a.Go, func() (T, error) {
+		var v T
+		err := a.withMetrics(context.Background(), "revalidate", 1, func(ctx context.Context) error {
+			release, ok, err := a.tryLock(ctx, key)
+			if err != nil || !ok {
+				return err
+			}
+			defer release()
+
+			if v, err = fn(); err != nil {
+				return err
+			}
+			return a.cache.Set(ctx, key, v, ttl)
+		})
+		return v, err
+	})
+}
@@ -0,0 +1,166 @@
+package advanced
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+func TestGetOrSetProtectedMissComputesOnce(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	var calls atomic.Int64
+	fn := func() (int, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return 7, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := a.GetOrSetProtected(ctx, "k", time.Minute, time.Minute, fn); err != nil || v != 7 {
+				t.Errorf("GetOrSetProtected = %d, %v; want 7", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
+
+func TestGetOrSetProtectedServesStaleDuringRefresh(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	const ttl, staleTTL = 30 * time.Millisecond, time.Minute
+	var calls atomic.Int64
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return int(n), nil
+	}
+
+	if v, err := a.GetOrSetProtected(ctx, "k", ttl, staleTTL, fn); err != nil || v != 1 {
+		t.Fatalf("first GetOrSetProtected = %d, %v", v, err)
+	}
+	time.Sleep(2 * ttl)
+
+	// Waiters get the stale value at once while one slow refresh runs.
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			v, err := a.GetOrSetProtected(ctx, "k", ttl, staleTTL, fn)
+			if err != nil || v != 1 {
+				t.Errorf("stale GetOrSetProtected = %d, %v; want 1", v, err)
+			}
+			if d := time.Since(start); d > 20*time.Millisecond {
+				t.Errorf("stale read blocked for %v", d)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	eventuallyValue(t, a, "k", 2)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("fn called %d times, want one compute and one refresh", n)
+	}
+}
+
+func TestGetOrSetProtectedWaitsForLockHolder(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	a, competitor := newLockingPair(t, mr, 0)
+	other := competitor.(interfaces.Cache[int])
+
+	// Another process is computing k.
+	if _, ok, err := competitor.TryLock(ctx, "lock:k", time.Minute); err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	done := make(chan int, 1)
+	go func() {
+		v, err := a.GetOrSetProtected(ctx, "k", time.Minute, time.Minute, fn)
+		if err != nil {
+			t.Errorf("GetOrSetProtected = %v", err)
+		}
+		done <- v
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if err := other.Set(ctx, "k", 42, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case v := <-done:
+		if v != 42 {
+			t.Errorf("GetOrSetProtected = %d, want the holder's 42", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter never saw the holder's value")
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("fn called %d times while another process held the lock", n)
+	}
+}
+
+func TestGetOrSetProtectedSkipsRefreshWhileLockedElsewhere(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	a, competitor := newLockingPair(t, mr, 0)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if _, err := a.GetOrSetProtected(ctx, "k", time.Second, time.Minute, fn); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(2 * time.Second)
+
+	if _, ok, err := competitor.TryLock(ctx, "lock:k", time.Minute); err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	if v, err := a.GetOrSetProtected(ctx, "k", time.Second, time.Minute, fn); err != nil || v != 1 {
+		t.Errorf("stale GetOrSetProtected = %d, %v; want 1", v, err)
+	}
+	a.workers.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want the refresh left to the lock holder", n)
+	}
+}
+
+func TestGetOrSetProtectedWithoutTTLsFallsBackToLocked(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	a := newTestCache[int](t, cfg, &plainCache[int]{Cache: newBackend[int](t, cfg)})
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	for range 3 {
+		if v, err := a.GetOrSetProtected(ctx, "k", time.Minute, time.Minute, fn); err != nil || v != 1 {
+			t.Fatalf("GetOrSetProtected = %d, %v", v, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,201 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Refresh Ahead ------------------ */
+
+// RefreshAhead registers key for background refresh. The value is computed
+// once synchronously, then recomputed by a worker at the fraction at of
+// ttl, 0 < at < 1, so readers always see a fresh entry. The fraction is
+// taken of the shortest TTL TTLJitter can give the entry, so a refresh
+// always lands before it expires. Registering the same key again replaces
+// the previous worker. Workers stop on Close.
+func (a *advancedCache[T]) RefreshAhead(
+	key string,
+	ttl time.Duration,
+	at float64,
+	fn func() (T, error),
+) error {
+	if err := a.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if fn == nil {
+		return base.WrapError(base.OpRefreshAhead, base.ErrInvalidArgument, key)
+	}
+
+	ttl, interval, err := a.refreshAheadInterval(ttl, at)
+	if err != nil {
+		return base.WrapError(base.OpRefreshAhead, err, key)
+	}
+
+	run := func() error { return a.refresh(key, ttl, fn) }
+	if err := run(); err != nil {
+		return err
+	}
+	return a.startRefresher(refresherID{name: key}, interval, run)
+}
+
+// RefreshAheadGroup is RefreshAhead for a group of keys sharing one
+// worker, which recomputes every key with fn(key) at each refresh.
+// Registering the same group again replaces its worker; groups and single
+// keys are registered independently.
+func (a *advancedCache[T]) RefreshAheadGroup(
+	group string,
+	keys []string,
+	ttl time.Duration,
+	at float64,
+	fn func(key string) (T, error),
+) error {
+	if strings.TrimSpace(group) == "" || len(keys) == 0 || fn == nil {
+		return base.WrapError(base.OpRefreshAhead, base.ErrInvalidArgument, group)
+	}
+	for _, key := range keys {
+		if err := a.base.ValidateKey(key); err != nil {
+			return err
+		}
+	}
+
+	ttl, interval, err := a.refreshAheadInterval(ttl, at)
+	if err != nil {
+		return base.WrapError(base.OpRefreshAhead, err, group)
+	}
+
+	keys = slices.Clone(keys)
+	run := func() error {
+		var errs []error
+		for _, key := range keys {
+			if err := a.refresh(key, ttl, func() (T, error) { return fn(key) }); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if err := run(); err != nil {
+		return err
+	}
+	return a.startRefresher(refresherID{name: group, group: true}, interval, run)
+}
+
+// refreshAheadInterval resolves ttl and returns it with the refresh
+// interval: the fraction at of the shortest jittered TTL.
+func (a *advancedCache[T]) refreshAheadInterval(ttl time.Duration, at float64) (time.Duration, time.Duration, error) {
+	if at <= 0 || at >= 1 {
+		return 0, 0, base.ErrInvalidArgument
+	}
+
+	ttl = a.base.ResolveTTL(ttl)
+	interval := time.Duration(float64(a.base.MinWriteTTL(ttl)) * at)
+	if interval <= 0 {
+		return 0, 0, base.ErrInvalidArgument
+	}
+	return ttl, interval, nil
+}
+
+// RegisterRefresher keeps key warm by recomputing it with fn every
+// interval. Entries get the default TTL, raised to twice the interval when
+// shorter, so readers never miss even if one refresh fails. Like
+// RefreshAhead, the first value is computed before it returns, registering
+// the key again replaces its worker and workers stop on Close.
+func (a *advancedCache[T]) RegisterRefresher(key string, interval time.Duration, fn func() (T, error)) error {
+	if err := a.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if fn == nil || interval <= 0 {
+		return base.WrapError(base.OpRefreshAhead, base.ErrInvalidArgument, key)
+	}
+
+	ttl := a.base.ResolveTTL(0)
+	if ttl != base.NoExpiration {
+		ttl = max(ttl, 2*interval)
+	}
+
+	run := func() error { return a.refresh(key, ttl, fn) }
+	if err := run(); err != nil {
+		return err
+	}
+	return a.startRefresher(refresherID{name: key}, interval, run)
+}
+
+// refresherID names a refresh worker: a single key or a group of keys.
+type refresherID struct {
+	name  string
+	group bool
+}
+
+// startRefresher hands id to a worker calling run every interval,
+// replacing a worker already registered under id.
+func (a *advancedCache[T]) startRefresher(id refresherID, interval time.Duration, run func() error) error {
+	if !a.addWorker() {
+		return base.WrapError(base.OpRefreshAhead, base.ErrClosed, id.name)
+	}
+	stop := make(chan struct{})
+
+	a.refreshMu.Lock()
+	if prev, ok := a.refreshers[id]; ok {
+		close(prev)
+	}
+	a.refreshers[id] = stop
+	a.refreshMu.Unlock()
+
+	go a.refreshLoop(interval, run, stop)
+	return nil
+}
+
+func (a *advancedCache[T]) refreshLoop(interval time.Duration, run func() error, stop <-chan struct{}) {
+	defer a.doneWorker()
+
+	tick, stopTicker := a.newTicker(interval)
+	defer stopTicker()
+
+	for {
+		select {
+		case <-tick:
+			// Failures keep the previous value until its TTL runs out;
+			// the next tick retries.
+			_ = run()
+		case <-stop:
+			return
+		case <-a.stopCh:
+			return
+		case <-a.rootStop():
+			return
+		}
+	}
+}
+
+func (a *advancedCache[T]) refresh(key string, ttl time.Duration, fn func() (T, error)) error {
+	return a.withMetrics(context.Background(), "refresh_ahead", 1, func(ctx context.Context) error {
+		val, err := fn()
+		if err != nil {
+			return base.WrapError(base.OpRefreshAhead, err, key)
+		}
+		return a.cache.Set(ctx, key, val, ttl)
+	})
+}
+
+// tickerFunc starts a ticker firing every d; stop releases it. Tests
+// replace it to drive refresh workers with a fake clock.
+type tickerFunc func(d time.Duration) (c <-chan time.Time, stop func())
+
+func realTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// stopWorkers signals every background worker to exit.
+func (a *advancedCache[T]) stopWorkers() {
+	a.stopOnce.Do(func() {
+		a.refreshMu.Lock()
+		close(a.stopCh)
+		a.refreshers = make(map[refresherID]chan struct{})
+		a.refreshMu.Unlock()
+	})
+}
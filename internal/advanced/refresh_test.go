@@ -0,0 +1,336 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+// fakeClock drives refresh workers: its tickers only fire on Advance.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Duration
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	period, next time.Duration
+	c            chan time.Time
+	stopped      bool
+}
+
+func (c *fakeClock) ticker(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{period: d, next: c.now + d, c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t.c, func() {
+		c.mu.Lock()
+		t.stopped = true
+		c.mu.Unlock()
+	}
+}
+
+func (c *fakeClock) Now() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now += d
+	for _, t := range c.tickers {
+		for !t.stopped && t.next <= c.now {
+			select {
+			case t.c <- time.Time{}:
+			default:
+			}
+			t.next += t.period
+		}
+	}
+}
+
+// running reports the tickers started and those not stopped yet.
+func (c *fakeClock) running() (started, running int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range c.tickers {
+		if !t.stopped {
+			running++
+		}
+	}
+	return len(c.tickers), running
+}
+
+// waitTickers waits until started tickers were created and one runs.
+func (c *fakeClock) waitTickers(t *testing.T, started int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if s, r := c.running(); s == started && r == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			s, r := c.running()
+			t.Fatalf("%d tickers started and %d running, want %d and 1", s, r, started)
+		}
+	}
+}
+
+// clockCache is a backend whose entries expire on a fakeClock. It applies
+// TTLJitter like the real backends and counts the writes that found the
+// previous entry already expired.
+type clockCache[T any] struct {
+	interfaces.Cache[T]
+	base  *base.Base
+	clock *fakeClock
+
+	mu      sync.Mutex
+	entries map[string]clockEntry[T]
+	gaps    int
+}
+
+type clockEntry[T any] struct {
+	val     T
+	expires time.Duration
+}
+
+func newClockCache[T any](b *base.Base, clock *fakeClock) *clockCache[T] {
+	return &clockCache[T]{base: b, clock: clock, entries: make(map[string]clockEntry[T])}
+}
+
+func (c *clockCache[T]) Get(_ context.Context, key string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.expires <= c.clock.Now() {
+		var zero T
+		return zero, base.ErrCacheMiss
+	}
+	return e.val, nil
+}
+
+func (c *clockCache[T]) Set(ctx context.Context, key string, val T, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if prev, ok := c.entries[key]; ok && prev.expires <= now {
+		c.gaps++
+	}
+	c.entries[key] = clockEntry[T]{val: val, expires: now + c.base.WriteTTL(ctx, ttl)}
+	return nil
+}
+
+func (c *clockCache[T]) Close() error { return nil }
+
+func (c *clockCache[T]) expiredGaps() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gaps
+}
+
+// newRefreshCache returns an advanced cache over a clockCache with its
+// refresh workers on the returned fake clock.
+func newRefreshCache(t *testing.T, jitter float64) (*advancedCache[int], *clockCache[int], *fakeClock) {
+	t.Helper()
+	cfg := testConfig()
+	cfg.TTLJitter = jitter
+	clock := &fakeClock{}
+	backend := newClockCache[int](base.NewBase(cfg), clock)
+	a := newTestCache[int](t, cfg, backend)
+	a.newTicker = clock.ticker
+	return a, backend, clock
+}
+
+// waitCalls waits until n calls were counted.
+func waitCalls(t *testing.T, calls *atomic.Int64, n int64) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); calls.Load() < n; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d refreshes, want %d", calls.Load(), n)
+		}
+	}
+}
+
+func TestRefreshAheadFiresAtTTLFraction(t *testing.T) {
+	a, _, clock := newRefreshCache(t, 0)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if err := a.RefreshAhead("hot", 10*time.Second, 0.8, fn); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("%d loads on registration, want 1", calls.Load())
+	}
+	clock.waitTickers(t, 1)
+
+	for cycle := int64(1); cycle <= 3; cycle++ {
+		clock.Advance(7900 * time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		if got := calls.Load(); got != cycle {
+			t.Fatalf("cycle %d: refreshed before 80%% of the TTL (%d loads)", cycle, got)
+		}
+		clock.Advance(100 * time.Millisecond)
+		waitCalls(t, &calls, cycle+1)
+	}
+}
+
+func TestRefreshAheadNeverExpiresUnderJitter(t *testing.T) {
+	a, backend, clock := newRefreshCache(t, 0.25)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if err := a.RefreshAhead("hot", 10*time.Second, 0.8, fn); err != nil {
+		t.Fatal(err)
+	}
+	clock.waitTickers(t, 1)
+
+	// The shortest jittered TTL is 7.5s, so refreshes run every 6s.
+	ctx := context.Background()
+	for step := 1; step <= 240; step++ {
+		clock.Advance(500 * time.Millisecond)
+		waitCalls(t, &calls, int64(clock.Now()/(6*time.Second))+1)
+		if _, err := a.Get(ctx, "hot"); err != nil {
+			t.Fatalf("at %v: %v", clock.Now(), err)
+		}
+	}
+	if gaps := backend.expiredGaps(); gaps != 0 {
+		t.Errorf("entry expired before %d of %d refreshes", gaps, calls.Load())
+	}
+}
+
+func TestRefreshAheadRejectsFraction(t *testing.T) {
+	a := newTestCache[int](t, testConfig(), nil)
+	fn := func() (int, error) { return 1, nil }
+
+	for _, at := range []float64{0, -0.5, 1, 1.5} {
+		if err := a.RefreshAhead("k", time.Minute, at, fn); !errors.Is(err, base.ErrInvalidArgument) {
+			t.Errorf("RefreshAhead(at=%v) = %v, want ErrInvalidArgument", at, err)
+		}
+	}
+	if err := a.RefreshAhead("k", time.Minute, 0.5, nil); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("RefreshAhead(nil fn) = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRefreshAheadGroup(t *testing.T) {
+	a, _, clock := newRefreshCache(t, 0)
+	ctx := context.Background()
+
+	var calls atomic.Int64
+	fn := func(key string) (int, error) { return int(calls.Add(1)), nil }
+	if err := a.RefreshAheadGroup("hot", []string{"a", "b", "c"}, 10*time.Second, 0.5, fn); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("%d loads on registration, want 3", calls.Load())
+	}
+	clock.waitTickers(t, 1)
+
+	clock.Advance(5 * time.Second)
+	waitCalls(t, &calls, 6)
+	for _, k := range []string{"a", "b", "c"} {
+		if v, err := a.Get(ctx, k); err != nil || v <= 3 {
+			t.Errorf("Get(%s) = %d, %v; want a refreshed value", k, v, err)
+		}
+	}
+
+	// Registering the group again replaces its worker.
+	if err := a.RefreshAheadGroup("hot", []string{"a"}, 10*time.Second, 0.5, fn); err != nil {
+		t.Fatal(err)
+	}
+	clock.waitTickers(t, 2)
+	clock.Advance(5 * time.Second)
+	waitCalls(t, &calls, 8)
+	time.Sleep(20 * time.Millisecond)
+	if got := calls.Load(); got != 8 {
+		t.Errorf("%d loads, want 8: the replaced worker still runs", got)
+	}
+}
+
+func TestRegisterRefresherKeepsKeyWarm(t *testing.T) {
+	a, backend, clock := newRefreshCache(t, 0)
+	ctx := context.Background()
+
+	// An interval longer than the 5m default TTL raises the TTL to two
+	// intervals, so the entry outlives a refresh that is late.
+	const interval = 10 * time.Minute
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if err := a.RegisterRefresher("hot", interval, fn); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := a.Get(ctx, "hot"); err != nil || v != 1 {
+		t.Fatalf("Get after registering = %d, %v; want the first value", v, err)
+	}
+	clock.waitTickers(t, 1)
+
+	for want := 2; want <= 6; want++ {
+		clock.Advance(interval)
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+			v, err := a.Get(ctx, "hot")
+			if err != nil {
+				t.Fatalf("at %v: Get = %v, want no miss", clock.Now(), err)
+			}
+			if v == want {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("at %v: Get = %d, want %d", clock.Now(), v, want)
+			}
+		}
+	}
+	if gaps := backend.expiredGaps(); gaps != 0 {
+		t.Errorf("entry expired before %d refreshes", gaps)
+	}
+}
+
+func TestRegisterRefresherStopsOnClose(t *testing.T) {
+	a, _, clock := newRefreshCache(t, 0)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	if err := a.RegisterRefresher("hot", time.Second, fn); err != nil {
+		t.Fatal(err)
+	}
+	clock.waitTickers(t, 1)
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, running := clock.running(); running != 0 {
+		t.Errorf("%d refreshers running after Close", running)
+	}
+	clock.Advance(time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d loads after Close, want only the registration's", n)
+	}
+	if err := a.RegisterRefresher("other", time.Second, fn); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("RegisterRefresher after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestRegisterRefresherRejectsArguments(t *testing.T) {
+	a := newTestCache[int](t, testConfig(), nil)
+	fn := func() (int, error) { return 1, nil }
+
+	if err := a.RegisterRefresher("k", 0, fn); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("zero interval = %v, want ErrInvalidArgument", err)
+	}
+	if err := a.RegisterRefresher("k", time.Second, nil); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("nil fn = %v, want ErrInvalidArgument", err)
+	}
+	errLoad := errors.New("load failed")
+	if err := a.RegisterRefresher("k", time.Second, func() (int, error) { return 0, errLoad }); !errors.Is(err, errLoad) {
+		t.Errorf("failing first load = %v, want it returned", err)
+	}
+}
@@ -0,0 +1,74 @@
+package advanced
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Retry ------------------ */
+
+const maxRetryDelay = 5 * time.Second
+
+// WithRetry runs every operation up to maxAttempts times while it fails
+// with a retryable error (see base.IsRetryable: connection and lock
+// errors). Attempts are spaced by jittered exponential backoff starting at
+// baseDelay. Misses, serialization and context errors are returned at
+// once, as is the last error when the context's deadline would pass during
+// the next wait. fn passed to GetOrSet may run again. maxAttempts <= 1
+// disables retrying.
+func WithRetry[T any](maxAttempts int, baseDelay time.Duration) Option[T] {
+	return func(a *advancedCache[T]) {
+		if maxAttempts > 1 {
+			a.retry = &retryPolicy{attempts: maxAttempts, delay: max(baseDelay, time.Millisecond)}
+		}
+	}
+}
+
+type retryPolicy struct {
+	attempts int
+	delay    time.Duration
+}
+
+// retryingKey marks contexts of an operation that is already being
+// retried, so nested operations do not multiply the attempts.
+type retryingKey struct{}
+
+// run calls fn until it succeeds, fails permanently or attempts run out.
+func (p *retryPolicy) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p == nil || ctx.Value(retryingKey{}) != nil {
+		return fn(ctx)
+	}
+	ctx = context.WithValue(ctx, retryingKey{}, true)
+
+	err := fn(ctx)
+	for attempt := 1; attempt < p.attempts && err != nil && base.IsRetryable(err); attempt++ {
+		if !p.wait(ctx, attempt) {
+			return err
+		}
+		err = fn(ctx)
+	}
+	return err
+}
+
+// wait sleeps before retry number attempt, reporting false when ctx ends
+// or its deadline comes first.
+func (p *retryPolicy) wait(ctx context.Context, attempt int) bool {
+	d := min(p.delay<<(attempt-1), maxRetryDelay)
+	d = d/2 + rand.N(d/2+1)
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
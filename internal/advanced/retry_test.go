@@ -0,0 +1,125 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+// flakyCache fails the first failures Gets with err, then serves the
+// wrapped backend.
+type flakyCache[T any] struct {
+	interfaces.Cache[T]
+	err      error
+	failures int64
+	calls    atomic.Int64
+}
+
+func (f *flakyCache[T]) Get(ctx context.Context, key string) (T, error) {
+	if f.calls.Add(1) <= f.failures {
+		var zero T
+		return zero, f.err
+	}
+	return f.Cache.Get(ctx, key)
+}
+
+var errTransient = fmt.Errorf("dial: %w", base.ErrConnection)
+
+func TestRetryRecoversFromTransientError(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: errTransient, failures: 2}
+	a := newTestCache[int](t, cfg, backend, WithRetry[int](5, time.Millisecond))
+	_ = backend.Cache.Set(ctx, "k", 7, time.Minute)
+
+	if v, err := a.Get(ctx, "k"); err != nil || v != 7 {
+		t.Fatalf("Get = %d, %v; want 7 after the outage", v, err)
+	}
+	if n := backend.calls.Load(); n != 3 {
+		t.Errorf("%d attempts, want 3", n)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	cfg := testConfig()
+	backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: errTransient, failures: 100}
+	a := newTestCache[int](t, cfg, backend, WithRetry[int](3, time.Millisecond))
+
+	if _, err := a.Get(context.Background(), "k"); !errors.Is(err, base.ErrConnection) {
+		t.Errorf("Get = %v, want the connection error", err)
+	}
+	if n := backend.calls.Load(); n != 3 {
+		t.Errorf("%d attempts, want 3", n)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	for name, err := range map[string]error{
+		"miss":          base.ErrCacheMiss,
+		"serialization": fmt.Errorf("decode: %w", base.ErrDeserialize),
+		"context":       context.Canceled,
+		"other":         errors.New("WRONGTYPE"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig()
+			backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: err, failures: 100}
+			a := newTestCache[int](t, cfg, backend, WithRetry[int](5, time.Millisecond))
+
+			_, _ = a.Get(context.Background(), "k")
+			if n := backend.calls.Load(); n != 1 {
+				t.Errorf("%d attempts, want 1", n)
+			}
+		})
+	}
+}
+
+func TestRetryStopsAtContextDeadline(t *testing.T) {
+	cfg := testConfig()
+	backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: errTransient, failures: 100}
+	a := newTestCache[int](t, cfg, backend, WithRetry[int](5, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := a.Get(ctx, "k"); !errors.Is(err, base.ErrConnection) {
+		t.Errorf("Get = %v, want the last connection error", err)
+	}
+	if d := time.Since(start); d > 40*time.Millisecond {
+		t.Errorf("Get waited %v although the next retry falls past the deadline", d)
+	}
+	if n := backend.calls.Load(); n != 1 {
+		t.Errorf("%d attempts, want 1", n)
+	}
+}
+
+func TestRetryDisabledWithOneAttempt(t *testing.T) {
+	cfg := testConfig()
+	backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: errTransient, failures: 1}
+	a := newTestCache[int](t, cfg, backend, WithRetry[int](1, time.Millisecond))
+
+	if _, err := a.Get(context.Background(), "k"); !errors.Is(err, base.ErrConnection) {
+		t.Errorf("Get = %v, want the error unretried", err)
+	}
+	if n := backend.calls.Load(); n != 1 {
+		t.Errorf("%d attempts, want 1", n)
+	}
+}
+
+func TestRetryNotMultipliedByNestedOperations(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	backend := &flakyCache[int]{Cache: newBackend[int](t, cfg), err: errTransient, failures: 100}
+	a := newTestCache[int](t, cfg, backend, WithRetry[int](3, time.Millisecond))
+
+	// GetOrSet reads through get, itself an operation.
+	_, _ = a.GetOrSet(ctx, "k", time.Minute, func() (int, error) { return 1, nil })
+	if n := backend.calls.Load(); n != 3 {
+		t.Errorf("%d attempts, want 3", n)
+	}
+}
@@ -0,0 +1,125 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Stale While Revalidate ------------------ */
+
+// GetOrSetStale behaves like GetOrSet, but entries are kept for ttl +
+// staleWindow. Once ttl has elapsed the stale value is returned
+// immediately while a single background call to fn refreshes it. The soft
+// expiry is derived from the entry's remaining TTL, so it is shared by
+// every process using the backend. Backends that cannot report TTLs fall
+// back to GetOrSet, as do NoExpiration entries, which never go stale.
+func (a *advancedCache[T]) GetOrSetStale(
+	ctx context.Context,
+	key string,
+	ttl, staleWindow time.Duration,
+	fn func() (T, error),
+) (T, error) {
+	getter, ok := a.cache.(interfaces.TTLGetter[T])
+	if !ok || staleWindow <= 0 || ttl == base.NoExpiration {
+		return a.GetOrSet(ctx, key, ttl, fn)
+	}
+
+	var zero T
+	if err := a.base.ValidateKey(key); err != nil {
+		return zero, err
+	}
+	if err := a.base.CheckContext(ctx); err != nil {
+		return zero, err
+	}
+
+	hardTTL := a.base.ResolveTTL(ttl) + staleWindow
+
+	var result T
+	err := a.withMetrics(ctx, "get_or_set_stale", 1, func(ctx context.Context) error {
+		val, remaining, err := getter.GetWithTTL(ctx, key)
+		if err == nil {
+			a.hit(ctx, "get_or_set_stale")
+			result = val
+			if remaining > 0 && remaining <= staleWindow {
+				a.revalidate(key, hardTTL, fn)
+			}
+			return nil
+		}
+		if !errors.Is(err, base.ErrCacheMiss) {
+			return err
+		}
+		a.miss(ctx, "get_or_set_stale")
+
+		val, err = a.stale.Do(key, func() (T, error) {
+			v, err := fn()
+			if err == nil {
+				_ = a.cache.Set(ctx, key, v, hardTTL)
+			}
+			return v, err
+		})
+		if err != nil {
+			return err
+		}
+		result = val
+		return nil
+	})
+
+	return result, err
+}
+
+/* ------------------ Stale Reads ------------------ */
+
+// GetStale returns key's value even if it expired but is still held by the
+// backend, with stale set in that case. Backends that drop entries at
+// expiry, such as Redis, only ever return fresh values.
+func (a *advancedCache[T]) GetStale(ctx context.Context, key string) (T, bool, error) {
+	sg, ok := a.cache.(interfaces.StaleGetter[T])
+	if !ok {
+		val, err := a.get(ctx, key)
+		return val, false, err
+	}
+
+	var zero T
+	if err := a.base.ValidateKey(key); err != nil {
+		return zero, false, err
+	}
+
+	var (
+		val   T
+		stale bool
+	)
+	err := a.withMetrics(ctx, "get_stale", 1, func(ctx context.Context) error {
+		annotate(ctx, "cache.key", key)
+		v, s, err := sg.GetStale(ctx, key)
+		if err != nil {
+			if errors.Is(err, base.ErrCacheMiss) {
+				a.miss(ctx, "get_stale")
+			}
+			return err
+		}
+		a.hit(ctx, "get_stale")
+		val, stale = v, s
+		return nil
+	})
+	return val, stale, err
+}
+
+// revalidate refreshes key in the background unless a refresh is already
+// running or the cache is closing.
+func (a *advancedCache[T]) revalidate(key string, ttl time.Duration, fn func() (T, error)) {
+	a.stale.Spawn(key, a.Go, func() (T, error) {
+		var v T
+		err := a.withMetrics(context.Background(), "revalidate", 1, func(ctx context.Context) error {
+			var err error
+			if v, err = fn(); err != nil {
+				return err
+			}
+			return a.cache.Set(ctx, key, v, ttl)
+		})
+		return v, err
+	})
+}
@@ -0,0 +1,183 @@
+package advanced
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestGetOrSetStaleServesStaleWhileRefreshing(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	const ttl, window = 50 * time.Millisecond, time.Minute
+	var calls atomic.Int64
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return int(n), nil
+	}
+
+	if v, err := a.GetOrSetStale(ctx, "k", ttl, window, fn); err != nil || v != 1 {
+		t.Fatalf("first GetOrSetStale = %d, %v", v, err)
+	}
+	time.Sleep(2 * ttl)
+
+	// Past the soft expiry every caller gets the stale value at once, and
+	// only one refresh runs however many callers see it.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			v, err := a.GetOrSetStale(ctx, "k", ttl, window, fn)
+			if err != nil || v != 1 {
+				t.Errorf("stale GetOrSetStale = %d, %v; want 1", v, err)
+			}
+			if d := time.Since(start); d > 20*time.Millisecond {
+				t.Errorf("stale read blocked for %v", d)
+			}
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		v, err := a.Get(ctx, "k")
+		if err == nil && v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("value never refreshed: %d, %v", v, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("fn called %d times, want 2", n)
+	}
+}
+
+func TestGetOrSetStaleRecomputesAfterWindow(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	const ttl, window = 20 * time.Millisecond, 20 * time.Millisecond
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+
+	if _, err := a.GetOrSetStale(ctx, "k", ttl, window, fn); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(ttl + window + 10*time.Millisecond)
+
+	// Past the hard expiry the caller waits for a fresh value.
+	if v, err := a.GetOrSetStale(ctx, "k", ttl, window, fn); err != nil || v != 2 {
+		t.Errorf("GetOrSetStale after the window = %d, %v; want a fresh 2", v, err)
+	}
+}
+
+func TestGetOrSetStaleFreshHitSkipsRefresh(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	for range 5 {
+		if _, err := a.GetOrSetStale(ctx, "k", time.Minute, time.Minute, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times for fresh hits, want 1", n)
+	}
+}
+
+func TestGetStaleReportsExpiredMemoryEntries(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.StaleGrace = time.Minute
+	a := newTestCache[int](t, cfg, nil)
+
+	_ = a.Set(ctx, "k", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if v, stale, err := a.GetStale(ctx, "k"); err != nil || !stale || v != 1 {
+		t.Errorf("GetStale = %d, %v, %v; want the stale value", v, stale, err)
+	}
+	if n := a.Metrics().Snapshot()["get_stale"].Count; n != 1 {
+		t.Errorf("get_stale count = %d, want 1", n)
+	}
+}
+
+func TestGetStaleWithoutBackendSupportIsFresh(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	a := newTestCache[int](t, cfg, &plainCache[int]{Cache: newBackend[int](t, cfg)})
+
+	_ = a.Set(ctx, "k", 1, time.Minute)
+	if v, stale, err := a.GetStale(ctx, "k"); err != nil || stale || v != 1 {
+		t.Errorf("GetStale = %d, %v, %v", v, stale, err)
+	}
+	if _, _, err := a.GetStale(ctx, "absent"); !base.IsCacheMiss(err) {
+		t.Errorf("GetStale(absent) = %v, want a miss", err)
+	}
+}
+
+func TestGetOrSetStaleNoExpirationNeverRefreshes(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil)
+
+	var calls atomic.Int64
+	fn := func() (int, error) { return int(calls.Add(1)), nil }
+	for range 3 {
+		if v, err := a.GetOrSetStale(ctx, "k", base.NoExpiration, time.Millisecond, fn); err != nil || v != 1 {
+			t.Fatalf("GetOrSetStale = %d, %v", v, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
+
+func TestFlightsAreNotSharedAcrossAPIs(t *testing.T) {
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	a := newTestCache[int](t, testConfig(), nil, WithLoader(func(context.Context, string) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}))
+
+	// A loader call for k is in flight.
+	loaded := make(chan int, 1)
+	go func() {
+		v, _ := a.Get(ctx, "k")
+		loaded <- v
+	}()
+	<-started
+
+	// Misses through other APIs run their own function rather than
+	// waiting for the loader's value.
+	if v, err := a.GetOrSetStale(ctx, "k", time.Minute, time.Minute, func() (int, error) { return 2, nil }); err != nil || v != 2 {
+		t.Errorf("GetOrSetStale = %d, %v; want its own 2", v, err)
+	}
+	_ = a.Delete(ctx, "k")
+	if v, err := a.GetOrSetProtected(ctx, "k", time.Minute, time.Minute, func() (int, error) { return 3, nil }); err != nil || v != 3 {
+		t.Errorf("GetOrSetProtected = %d, %v; want its own 3", v, err)
+	}
+
+	close(release)
+	if v := <-loaded; v != 1 {
+		t.Errorf("loader Get = %d, want 1", v)
+	}
+}
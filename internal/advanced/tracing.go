@@ -0,0 +1,47 @@
+package advanced
+
+import (
+	"context"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Tracing ------------------ */
+
+type spanKey struct{}
+
+// startSpan starts a span for op when a tracer is configured. The span is
+// also stored in the returned context so nested helpers can annotate it.
+func (a *advancedCache[T]) startSpan(ctx context.Context, op string, items int) (context.Context, interfaces.Span) {
+	t := a.cfg.Tracer
+	if t == nil {
+		return ctx, nil
+	}
+
+	ctx, span := t.Start(ctx, "cache."+op)
+	span.SetAttribute("cache.operation", op)
+	span.SetAttribute("cache.backend", string(a.cfg.Type))
+	span.SetAttribute("cache.key_count", items)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// annotate sets an attribute on the span of the current operation, if any,
+// and records it for the operation's log line.
+func annotate(ctx context.Context, key string, value any) {
+	if span, ok := ctx.Value(spanKey{}).(interfaces.Span); ok {
+		span.SetAttribute(key, value)
+	}
+	if rec, ok := ctx.Value(logKey{}).(*opLog); ok {
+		rec.set(key, value)
+	}
+}
+
+func (a *advancedCache[T]) hit(ctx context.Context, op string) {
+	a.base.RecordHit(op, 1)
+	annotate(ctx, "cache.hit", true)
+}
+
+func (a *advancedCache[T]) miss(ctx context.Context, op string) {
+	a.base.RecordMiss(op, 1)
+	annotate(ctx, "cache.hit", false)
+}
@@ -0,0 +1,90 @@
+package advanced
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+/* ------------------ Early Expiration (XFetch) ------------------ */
+
+// maxComputeTimes bounds how many keys' compute times are remembered.
+const maxComputeTimes = 10_000
+
+// WithEarlyExpiration makes GetOrSet refresh entries probabilistically
+// before they expire (the XFetch algorithm). A hit triggers one background
+// recomputation with a probability that grows as the entry nears expiry,
+// weighted by how long the value took to compute and by beta (1 is the
+// usual choice; larger refreshes earlier). It needs a backend that reports
+// TTLs. beta <= 0 disables it.
+func WithEarlyExpiration[T any](beta float64) Option[T] {
+	return func(a *advancedCache[T]) {
+		if beta > 0 {
+			a.early = &earlyExpiration{beta: beta, deltas: make(map[string]time.Duration)}
+		}
+	}
+}
+
+// earlyExpiration remembers how long each key took to compute. The
+// durations are process-local: keys computed elsewhere are not refreshed
+// early until this process has computed them once.
+type earlyExpiration struct {
+	beta float64
+
+	mu     sync.Mutex
+	deltas map[string]time.Duration
+}
+
+func (e *earlyExpiration) record(key string, d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.deltas[key]; !ok && len(e.deltas) >= maxComputeTimes {
+		for k := range e.deltas {
+			delete(e.deltas, k)
+			break
+		}
+	}
+	e.deltas[key] = d
+}
+
+func (e *earlyExpiration) delta(key string) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.deltas[key]
+}
+
+// due reports whether an entry with remaining TTL left, whose value took
+// delta to compute, should be refreshed now: delta·beta·-ln(rand) ≥ remaining.
+func (e *earlyExpiration) due(delta, remaining time.Duration) bool {
+	if delta <= 0 || remaining <= 0 {
+		return false
+	}
+	return float64(delta)*e.beta*-math.Log(rand.Float64()) >= float64(remaining)
+}
+
+// timed wraps fn so that, with early expiration enabled, its duration is
+// recorded for key on success.
+func (a *advancedCache[T]) timed(key string, fn func() (T, error)) func() (T, error) {
+	if a.early == nil {
+		return fn
+	}
+	return func() (T, error) {
+		start := time.Now()
+		v, err := fn()
+		if err == nil {
+			a.early.record(key, time.Since(start))
+		}
+		return v, err
+	}
+}
+
+// refreshEarly starts a background recomputation of key when XFetch says
+// it is due. At most one runs per key.
+func (a *advancedCache[T]) refreshEarly(key string, ttl, remaining time.Duration, fn func() (T, error)) {
+	if a.early == nil || !a.early.due(a.early.delta(key), remaining) {
+		return
+	}
+	a.revalidate(key, a.base.ResolveTTL(ttl), a.timed(key, fn))
+}
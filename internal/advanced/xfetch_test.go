@@ -0,0 +1,131 @@
+package advanced
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEarlyExpirationSpreadsRecomputation(t *testing.T) {
+	e := &earlyExpiration{beta: 1}
+	const (
+		ttl    = time.Second
+		delta  = 100 * time.Millisecond
+		step   = 10 * time.Millisecond
+		trials = 5000
+	)
+
+	// Each trial reads the key every step from set to expiry and notes the
+	// remaining TTL at the first read XFetch refreshes on.
+	buckets := make(map[time.Duration]int) // remaining TTL, rounded down to delta
+	atExpiry := 0
+	for range trials {
+		remaining := ttl
+		for ; remaining > 0; remaining -= step {
+			if e.due(delta, remaining) {
+				break
+			}
+		}
+		if remaining <= 0 {
+			atExpiry++
+			continue
+		}
+		buckets[remaining.Truncate(delta)]++
+	}
+
+	if atExpiry > trials/100 {
+		t.Errorf("%d of %d trials reached hard expiry without a refresh", atExpiry, trials)
+	}
+	// The refreshes land across several delta-wide windows before expiry
+	// instead of piling up in the last one.
+	spread := 0
+	for _, n := range buckets {
+		if n >= trials/20 {
+			spread++
+		}
+	}
+	if spread < 3 {
+		t.Errorf("refreshes clustered: %v", buckets)
+	}
+	if n := buckets[0]; n > trials/2 {
+		t.Errorf("%d of %d refreshes in the final %v: %v", n, trials, delta, buckets)
+	}
+}
+
+func TestEarlyExpirationDueEdges(t *testing.T) {
+	e := &earlyExpiration{beta: 1}
+	if e.due(0, time.Millisecond) {
+		t.Error("due without a recorded compute time")
+	}
+	if e.due(time.Second, 0) {
+		t.Error("due without a remaining TTL")
+	}
+	if e.due(time.Millisecond, time.Hour) {
+		t.Error("due an hour before expiry for a 1ms compute")
+	}
+}
+
+func TestGetOrSetRefreshesBeforeExpiry(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil, WithEarlyExpiration[int](100))
+
+	const ttl = 300 * time.Millisecond
+	var calls atomic.Int64
+	fn := func() (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return int(calls.Add(1)), nil
+	}
+
+	start := time.Now()
+	if _, err := a.GetOrSet(ctx, "k", ttl, fn); err != nil {
+		t.Fatal(err)
+	}
+	// A 5ms compute with beta 100 makes a refresh likely on every hit.
+	for calls.Load() < 2 && time.Since(start) < ttl/2 {
+		if _, err := a.GetOrSet(ctx, "k", ttl, fn); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if calls.Load() < 2 {
+		t.Fatal("no early refresh before the TTL ran out")
+	}
+	eventuallyValue(t, a, "k", 2)
+}
+
+func TestGetOrSetWithoutEarlyExpirationWaitsForExpiry(t *testing.T) {
+	ctx := context.Background()
+	a := newTestCache[int](t, testConfig(), nil, WithEarlyExpiration[int](0))
+
+	var calls atomic.Int64
+	fn := func() (int, error) {
+		time.Sleep(time.Millisecond)
+		return int(calls.Add(1)), nil
+	}
+	for range 20 {
+		if _, err := a.GetOrSet(ctx, "k", time.Minute, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
+
+// eventuallyValue waits for key to hold want.
+func eventuallyValue(t *testing.T, a *advancedCache[int], key string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		v, err := a.Get(context.Background(), key)
+		if err == nil && v == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Get(%q) = %d, %v; want %d", key, v, err, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
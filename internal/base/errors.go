@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 )
 
 /* ------------------ Sentinel Errors ------------------ */
@@ -12,17 +13,30 @@ import (
 // NEVER wrap these directly; always wrap via CacheError.
 
 var (
-	ErrKeyEmpty      = errors.New("key is empty")
-	ErrCacheMiss     = errors.New("cache miss")
-	ErrInvalidConfig = errors.New("invalid config")
+	ErrKeyEmpty        = errors.New("key is empty")
+	ErrCacheMiss       = errors.New("cache miss")
+	ErrNegativeCached  = errors.New("negatively cached")
+	ErrInvalidConfig   = errors.New("invalid config")
+	ErrInvalidArgument = errors.New("invalid argument")
 
-	ErrSerialize   = errors.New("serialization failed")
-	ErrDeserialize = errors.New("deserialization failed")
+	ErrSerialize    = errors.New("serialization failed")
+	ErrDeserialize  = errors.New("deserialization failed")
+	ErrStaleVersion = errors.New("stale schema version")
 
-	ErrConnection = errors.New("connection failed")
+	ErrConnection  = errors.New("connection failed")
+	ErrCircuitOpen = errors.New("circuit breaker open")
+	ErrClosed      = errors.New("cache closed")
+
+	// ErrClusterRedirect marks MOVED/ASK replies received by a
+	// non-cluster client pointed at a Redis Cluster node.
+	ErrClusterRedirect = errors.New("redis cluster redirect")
 
 	ErrLockAcquire = errors.New("lock acquisition failed")
 	ErrLockNotHeld = errors.New("lock not held")
+
+	// ErrConflict reports an optimistic update that kept losing to
+	// concurrent writers.
+	ErrConflict = errors.New("concurrent update conflict")
 )
 
 /* ------------------ Operation ------------------ */
@@ -41,10 +55,20 @@ const (
 	OpGetManyPipeline Op = "get_many_pipeline"
 	OpSetManyPipeline Op = "set_many_pipeline"
 	OpDeleteByPrefix  Op = "delete_by_prefix"
+	OpDeleteByPattern Op = "delete_by_pattern"
+	OpCountByPrefix   Op = "count_by_prefix"
 	OpPing            Op = "ping"
+	OpClose           Op = "close"
 	OpLock            Op = "lock"
 	OpUnlock          Op = "unlock"
 	OpTryLock         Op = "try_lock"
+	OpRefreshAhead    Op = "refresh_ahead"
+	OpGetOrSetNeg     Op = "get_or_set_negative"
+	OpPersist         Op = "persist"
+	OpUpdate          Op = "update"
+	OpCompareAndSwap  Op = "compare_and_swap"
+	OpExpireMany      Op = "expire_many"
+	OpRestore         Op = "restore"
 	OpInit            Op = "init"
 )
 
@@ -86,12 +110,28 @@ func WrapError(op Op, err error, key string) error {
 	}
 }
 
+// SerializationError wraps a failed Encode or Decode of key's value. The
+// result matches sentinel (ErrSerialize or ErrDeserialize) and keeps the
+// serializer's own message, so the bad record can be found.
+func SerializationError(op Op, sentinel, err error, key string) error {
+	if !errors.Is(err, sentinel) {
+		err = fmt.Errorf("%w: %v", sentinel, err)
+	}
+	return WrapError(op, err, key)
+}
+
 /* ------------------ Classification Helpers ------------------ */
 
 func IsCacheMiss(err error) bool {
 	return errors.Is(err, ErrCacheMiss)
 }
 
+// IsNegativeCached reports whether the source of truth recently reported
+// the key as absent.
+func IsNegativeCached(err error) bool {
+	return errors.Is(err, ErrNegativeCached)
+}
+
 func IsContextError(err error) bool {
 	return errors.Is(err, context.Canceled) ||
 		errors.Is(err, context.DeadlineExceeded)
@@ -101,6 +141,10 @@ func IsConnectionError(err error) bool {
 	return errors.Is(err, ErrConnection)
 }
 
+func IsClusterRedirect(err error) bool {
+	return errors.Is(err, ErrClusterRedirect)
+}
+
 func IsLockError(err error) bool {
 	return errors.Is(err, ErrLockAcquire) ||
 		errors.Is(err, ErrLockNotHeld)
@@ -117,7 +161,7 @@ func IsRetryable(err error) bool {
 	switch {
 	case IsContextError(err):
 		return false
-	case IsCacheMiss(err):
+	case IsCacheMiss(err), IsNegativeCached(err):
 		return false
 	case IsSerializationError(err):
 		return false
@@ -125,3 +169,64 @@ func IsRetryable(err error) bool {
 		return IsConnectionError(err) || IsLockError(err)
 	}
 }
+
+/* ------------------ Batch Helpers ------------------ */
+
+// FirstError returns the error of the lexically smallest failed key, so
+// batch callers that need a single error get a deterministic one.
+func FirstError(failed map[string]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(failed))
+	for k := range failed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return failed[keys[0]]
+}
+
+// MultiError aggregates the per-key failures of a bulk operation. It
+// unwraps to every failure, so errors.Is matches any of them.
+type MultiError struct {
+	Errors map[string]error
+}
+
+// NewMultiError returns nil when nothing failed, so it can be returned
+// directly as an error.
+func NewMultiError(failed map[string]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &MultiError{Errors: failed}
+}
+
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return FirstError(m.Errors).Error()
+	}
+	return fmt.Sprintf("%d keys failed; first: %v", len(m.Errors), FirstError(m.Errors))
+}
+
+func (m *MultiError) Unwrap() []error {
+	keys := make([]string, 0, len(m.Errors))
+	for k := range m.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]error, len(keys))
+	for i, k := range keys {
+		out[i] = m.Errors[k]
+	}
+	return out
+}
+
+// IsPartial reports whether err only describes per-key failures of a bulk
+// operation whose other results are still valid.
+func IsPartial(err error) bool {
+	var m *MultiError
+	return errors.As(err, &m)
+}
@@ -0,0 +1,68 @@
+package base
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewMultiErrorNilWhenNothingFailed(t *testing.T) {
+	if err := NewMultiError(nil); err != nil {
+		t.Errorf("NewMultiError(nil) = %v, want nil", err)
+	}
+	if err := NewMultiError(map[string]error{}); err != nil {
+		t.Errorf("NewMultiError(empty) = %v, want nil", err)
+	}
+}
+
+func TestMultiErrorUnwrapsEveryFailure(t *testing.T) {
+	errA, errB := errors.New("a broke"), errors.New("b broke")
+	err := NewMultiError(map[string]error{"b": errB, "a": errA})
+
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("errors.Is misses a wrapped failure: %v", err)
+	}
+	if errors.Is(err, ErrCacheMiss) {
+		t.Error("MultiError matches an unrelated sentinel")
+	}
+	if !IsPartial(err) || IsPartial(errA) {
+		t.Error("IsPartial misclassifies")
+	}
+
+	// Unwrap is ordered by key so error messages are stable.
+	unwrapped := err.(*MultiError).Unwrap()
+	if len(unwrapped) != 2 || unwrapped[0] != errA || unwrapped[1] != errB {
+		t.Errorf("Unwrap = %v", unwrapped)
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "2 keys failed") || !strings.Contains(msg, "a broke") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
+
+func TestMultiErrorSingleFailureMessage(t *testing.T) {
+	err := NewMultiError(map[string]error{"k": errors.New("k broke")})
+	if got := err.Error(); got != "k broke" {
+		t.Errorf("Error() = %q, want the failure's message", got)
+	}
+}
+
+func TestSerializationErrorKeepsKeyAndCause(t *testing.T) {
+	cause := errors.New("invalid character 'x'")
+	err := SerializationError(OpGet, ErrDeserialize, cause, "user:7")
+
+	if !errors.Is(err, ErrDeserialize) || !IsSerializationError(err) {
+		t.Errorf("%v does not match ErrDeserialize", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{"user:7", "invalid character 'x'", ErrDeserialize.Error()} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, missing %q", msg, want)
+		}
+	}
+
+	// A cause already matching the sentinel is not wrapped twice.
+	wrapped := SerializationError(OpSet, ErrSerialize, ErrSerialize, "k")
+	if n := strings.Count(wrapped.Error(), ErrSerialize.Error()); n != 1 {
+		t.Errorf("Error() = %q, sentinel repeated", wrapped)
+	}
+}
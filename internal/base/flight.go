@@ -0,0 +1,122 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+/* ------------------ Call Deduplication ------------------ */
+
+// FlightGroup collapses concurrent calls for the same key into one. The
+// zero value is ready to use.
+type FlightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// PanicError is what callers sharing a call get when its function
+// panicked. The caller that ran the function re-panics with it.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("call panicked: %v\n\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns the panic value if it was an error.
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// errGoexit is what callers sharing a call get when its function called
+// runtime.Goexit.
+var errGoexit = errors.New("call exited with runtime.Goexit")
+
+// Do runs fn once for concurrent callers with the same key; they all get
+// its result.
+func (g *FlightGroup[T]) Do(key string, fn func() (T, error)) (T, error) {
+	c, leader := g.begin(key)
+	if !leader {
+		<-c.done
+		return c.val, c.err
+	}
+	g.run(key, c, fn)
+	return c.val, c.err
+}
+
+// Spawn starts fn through goFn unless a call for key is running. The call
+// is registered before goFn returns, so concurrent callers cannot start a
+// second one. It reports whether fn was started.
+func (g *FlightGroup[T]) Spawn(key string, goFn func(func()) bool, fn func() (T, error)) bool {
+	c, leader := g.begin(key)
+	if !leader {
+		return false
+	}
+
+	started := goFn(func() { g.run(key, c, fn) })
+	if !started {
+		g.end(key, c)
+	}
+	return started
+}
+
+// begin returns the running call for key, or registers a new one and
+// reports the caller as its leader.
+func (g *FlightGroup[T]) begin(key string) (*flightCall[T], bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+	c := &flightCall[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+// run calls fn for c and releases its waiters. If fn panics, the waiters
+// get a PanicError before the panic carries on in the caller.
+func (g *FlightGroup[T]) run(key string, c *flightCall[T], fn func() (T, error)) {
+	returned := false
+	defer func() {
+		if returned {
+			g.end(key, c)
+			return
+		}
+		r := recover()
+		if r == nil {
+			// runtime.Goexit: nothing to recover, let it unwind.
+			c.err = errGoexit
+			g.end(key, c)
+			return
+		}
+		pe := &PanicError{Value: r, Stack: debug.Stack()}
+		c.err = pe
+		g.end(key, c)
+		panic(pe)
+	}()
+
+	c.val, c.err = fn()
+	returned = true
+}
+
+func (g *FlightGroup[T]) end(key string, c *flightCall[T]) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+}
@@ -0,0 +1,153 @@
+package base
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupDoSharesOneCall(t *testing.T) {
+	var g FlightGroup[int]
+	var calls atomic.Int64
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Do("k", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 7, nil
+			})
+			if err != nil || v != 7 {
+				t.Errorf("Do = %d, %v; want 7", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+}
+
+func TestFlightGroupPanicReachesWaiters(t *testing.T) {
+	var g FlightGroup[int]
+	started, release := make(chan struct{}), make(chan struct{})
+	errBoom := errors.New("boom")
+
+	leaderPanic := make(chan any, 1)
+	go func() {
+		defer func() { leaderPanic <- recover() }()
+		_, _ = g.Do("k", func() (int, error) {
+			close(started)
+			<-release
+			panic(errBoom)
+		})
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := g.Do("k", func() (int, error) { return 1, nil })
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	var pe *PanicError
+	if r := <-leaderPanic; !errors.As(asError(r), &pe) || pe.Value != errBoom {
+		t.Errorf("leader recovered %v, want a PanicError carrying the panic", r)
+	}
+	select {
+	case err := <-waiter:
+		if !errors.As(err, &pe) || !errors.Is(err, errBoom) {
+			t.Errorf("waiter got %v, want a PanicError wrapping %v", err, errBoom)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter never released after the panic")
+	}
+
+	// The key is free again.
+	if v, err := g.Do("k", func() (int, error) { return 2, nil }); err != nil || v != 2 {
+		t.Errorf("Do after the panic = %d, %v; want 2", v, err)
+	}
+}
+
+func TestFlightGroupGoexitReleasesWaiters(t *testing.T) {
+	var g FlightGroup[int]
+	started, release := make(chan struct{}), make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = g.Do("k", func() (int, error) {
+			close(started)
+			<-release
+			runtime.Goexit()
+			return 0, nil
+		})
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := g.Do("k", func() (int, error) { return 1, nil })
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	<-done
+
+	if err := <-waiter; !errors.Is(err, errGoexit) {
+		t.Errorf("waiter got %v, want errGoexit", err)
+	}
+}
+
+func TestFlightGroupSpawn(t *testing.T) {
+	var g FlightGroup[int]
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	goFn := func(fn func()) bool {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+		return true
+	}
+
+	fn := func() (int, error) {
+		<-release
+		return 1, nil
+	}
+	if !g.Spawn("k", goFn, fn) {
+		t.Fatal("first Spawn did not start")
+	}
+	if g.Spawn("k", goFn, fn) {
+		t.Error("second Spawn started while the first runs")
+	}
+	close(release)
+	wg.Wait()
+
+	// A refused goFn frees the key at once.
+	if g.Spawn("k", func(func()) bool { return false }, fn) {
+		t.Error("Spawn reported a refused start as started")
+	}
+	if !g.Spawn("k", goFn, func() (int, error) { return 2, nil }) {
+		t.Error("Spawn after a refused start did not start")
+	}
+	wg.Wait()
+}
+
+func asError(r any) error {
+	err, _ := r.(error)
+	return err
+}
@@ -2,7 +2,12 @@ package base
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/os-golib/go-cache/config"
@@ -11,10 +16,17 @@ import (
 
 /* ------------------ Base ------------------ */
 
+const hashedKeyMarker = "sha256:"
+
 type Base struct {
 	Cfg       config.Config
-	StartTime time.Time
 	Collector *metrics.Collector
+
+	startMu   sync.RWMutex
+	startTime time.Time
+
+	// guards Cfg.TTL and Cfg.TTLJitter, which can change at runtime
+	ttlMu sync.RWMutex
 }
 
 /* ------------------ Constructor ------------------ */
@@ -22,18 +34,54 @@ type Base struct {
 func NewBase(cfg config.Config) *Base {
 	return &Base{
 		Cfg:       cfg,
-		StartTime: time.Now(),
-		Collector: metrics.NewCollector(),
+		startTime: time.Now(),
+		Collector: metrics.NewCollectorWithConfig(metrics.Config{
+			Enabled: true,
+			TopKeys: cfg.TrackTopKeys,
+		}),
 	}
 }
 
 /* ------------------ Key helpers ------------------ */
 
+// FullKey applies the configured prefix. With Cfg.MaxKeyLength set, a key
+// whose prefixed form is longer than the limit is replaced by a stable
+// SHA-256 digest (the prefix is kept), so hashed keys no longer match
+// prefix scans. Validate ensures the hashed form fits the limit.
 func (b *Base) FullKey(key string) string {
+	fk := b.FullPrefix(key)
+	if b.Cfg.MaxKeyLength > 0 && len(fk) > b.Cfg.MaxKeyLength {
+		sum := sha256.Sum256([]byte(key))
+		return b.FullPrefix(hashedKeyMarker + hex.EncodeToString(sum[:]))
+	}
+	return fk
+}
+
+// FullPrefix applies the configured prefix and version segment without
+// hashing; it is meant for building scan patterns.
+func (b *Base) FullPrefix(prefix string) string {
+	if b.Cfg.Version != "" {
+		return b.Cfg.Prefix + "v" + b.Cfg.Version + ":" + prefix
+	}
 	if b.Cfg.Prefix == "" {
-		return key
+		return prefix
 	}
-	return b.Cfg.Prefix + key
+	return b.Cfg.Prefix + prefix
+}
+
+// negativePrefix namespaces the markers recording keys known to be absent.
+const negativePrefix = "__neg:"
+
+// NegativeKey returns the logical key of key's negative-cache marker, so
+// callers invalidating key can drop the marker too.
+func NegativeKey(key string) string {
+	return negativePrefix + key
+}
+
+// IsNegativeKey reports whether key is a negative-cache marker, which
+// listings and counts of the cached entries leave out.
+func IsNegativeKey(key string) bool {
+	return strings.HasPrefix(key, negativePrefix)
 }
 
 func (b *Base) ValidateKey(key string) error {
@@ -45,13 +93,89 @@ func (b *Base) ValidateKey(key string) error {
 
 /* ------------------ TTL helpers ------------------ */
 
+// NoExpiration stores an entry without a TTL. Zero still means "use the
+// configured default".
+const NoExpiration time.Duration = -1
+
+// ResolveTTL returns ttl, or the configured default when ttl is zero.
+// NoExpiration is passed through unchanged.
 func (b *Base) ResolveTTL(ttl time.Duration) time.Duration {
-	if ttl > 0 {
+	if ttl > 0 || ttl == NoExpiration {
 		return ttl
 	}
+	b.ttlMu.RLock()
+	defer b.ttlMu.RUnlock()
 	return b.Cfg.TTL
 }
 
+// SetTTL replaces the default TTL and jitter while the cache is in use.
+func (b *Base) SetTTL(ttl time.Duration, jitter float64) {
+	b.ttlMu.Lock()
+	b.Cfg.TTL = ttl
+	b.Cfg.TTLJitter = jitter
+	b.ttlMu.Unlock()
+}
+
+// JitterTTL spreads ttl uniformly within ±Cfg.TTLJitter of its value so
+// entries written together do not expire together. Zero jitter, or a ctx
+// from WithExactTTL, returns ttl unchanged.
+func (b *Base) JitterTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if isExactTTL(ctx) {
+		return ttl
+	}
+	b.ttlMu.RLock()
+	f := b.Cfg.TTLJitter
+	b.ttlMu.RUnlock()
+	if f <= 0 || ttl <= 0 {
+		return ttl
+	}
+
+	delta := (rand.Float64()*2 - 1) * f * float64(ttl)
+	if out := ttl + time.Duration(delta); out > 0 {
+		return out
+	}
+	return ttl
+}
+
+// MinWriteTTL is the shortest TTL WriteTTL can return for ttl, for work
+// that has to happen before an entry expires.
+func (b *Base) MinWriteTTL(ttl time.Duration) time.Duration {
+	ttl = b.ResolveTTL(ttl)
+	b.ttlMu.RLock()
+	f := b.Cfg.TTLJitter
+	b.ttlMu.RUnlock()
+	if f <= 0 || ttl <= 0 {
+		return ttl
+	}
+	if out := ttl - time.Duration(f*float64(ttl)); out > 0 {
+		return out
+	}
+	return ttl
+}
+
+// WriteTTL is the TTL backends apply when storing an entry: the resolved
+// TTL with jitter.
+func (b *Base) WriteTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	return b.JitterTTL(ctx, b.ResolveTTL(ttl))
+}
+
+type exactTTLKey struct{}
+
+// WithExactTTL makes backends store the TTLs passed with ctx as given,
+// without jitter. Layers that already jittered a TTL use it so every tier
+// they write to gets the same one.
+func WithExactTTL(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exactTTLKey{}, true)
+}
+
+func isExactTTL(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	exact, _ := ctx.Value(exactTTLKey{}).(bool)
+	return exact
+}
+
 /* ------------------ Context helpers ------------------ */
 
 func (b *Base) CheckContext(ctx context.Context) error {
@@ -70,7 +194,22 @@ func (b *Base) CheckContext(ctx context.Context) error {
 /* ------------------ Lifecycle ------------------ */
 
 func (b *Base) Uptime() time.Duration {
-	return time.Since(b.StartTime)
+	return time.Since(b.StartedAt())
+}
+
+// StartedAt returns when the backend was created or last reconnected.
+func (b *Base) StartedAt() time.Time {
+	b.startMu.RLock()
+	defer b.startMu.RUnlock()
+	return b.startTime
+}
+
+// ResetStartTime restarts the uptime clock; backends call it after
+// recovering from a lost connection.
+func (b *Base) ResetStartTime() {
+	b.startMu.Lock()
+	b.startTime = time.Now()
+	b.startMu.Unlock()
 }
 
 /* ------------------ Metrics helpers ------------------ */
@@ -102,3 +241,32 @@ func (b *Base) RecordError(op string) {
 		b.Collector.RecordError(op)
 	}
 }
+
+/* ------------------ Hooks ------------------ */
+
+func (b *Base) FireHit(key string) {
+	if h := b.Cfg.Hooks.OnHit; h != nil {
+		h(key)
+	}
+}
+
+func (b *Base) FireMiss(key string) {
+	if h := b.Cfg.Hooks.OnMiss; h != nil {
+		h(key)
+	}
+}
+
+func (b *Base) FireEvict(key string, reason config.EvictReason) {
+	if l := b.Cfg.Logger; l != nil {
+		l.Debug("cache eviction", slog.String("key", key), slog.String("reason", string(reason)))
+	}
+	if h := b.Cfg.Hooks.OnEvict; h != nil {
+		h(key, reason)
+	}
+}
+
+func (b *Base) FireExpire(key string) {
+	if h := b.Cfg.Hooks.OnExpire; h != nil {
+		h(key)
+	}
+}
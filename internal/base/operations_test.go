@@ -0,0 +1,160 @@
+package base
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+)
+
+func TestFullKeyHashesOverLongKeys(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Prefix = "app:"
+	cfg.MaxKeyLength = len("app:") + config.HashedKeyLength
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate = %v with room for the prefix and digest", err)
+	}
+	b := NewBase(cfg)
+
+	if got := b.FullKey("user:42"); got != "app:user:42" {
+		t.Errorf("short key = %q, want it unchanged", got)
+	}
+	atLimit := strings.Repeat("k", config.HashedKeyLength)
+	if got := b.FullKey(atLimit); got != "app:"+atLimit {
+		t.Errorf("key at the limit = %q, want it unchanged", got)
+	}
+
+	// The limit covers the prefix: one more byte is hashed, and the
+	// hashed key is never longer than the limit.
+	if got := b.FullKey(atLimit + "k"); !strings.HasPrefix(got, "app:sha256:") {
+		t.Errorf("key one byte over the limit = %q, want it hashed", got)
+	}
+	long := "query:" + strings.Repeat("x", 100)
+	hashed := b.FullKey(long)
+	if !strings.HasPrefix(hashed, "app:sha256:") || len(hashed) != cfg.MaxKeyLength {
+		t.Errorf("long key = %q, want app:sha256:<64 hex digits>", hashed)
+	}
+	if again := NewBase(cfg).FullKey(long); again != hashed {
+		t.Errorf("hash not stable: %q then %q", hashed, again)
+	}
+	if other := b.FullKey(long + "y"); other == hashed {
+		t.Error("different keys hashed to the same key")
+	}
+	if got := b.FullPrefix(long); got != "app:"+long {
+		t.Errorf("FullPrefix hashed its argument: %q", got)
+	}
+}
+
+func TestValidateRejectsMaxKeyLengthBelowHashedKey(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Prefix, cfg.Version = "app:", "2"
+	for _, n := range []int{16, config.HashedKeyLength, len("app:v2:") + config.HashedKeyLength - 1} {
+		cfg.MaxKeyLength = n
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate accepted max_key_length %d, shorter than a prefixed digest", n)
+		}
+	}
+	cfg.MaxKeyLength = len("app:v2:") + config.HashedKeyLength
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate = %v for a limit that fits the digest", err)
+	}
+}
+
+func TestFullKeyWithoutLimit(t *testing.T) {
+	b := NewBase(config.DefaultConfig())
+	long := strings.Repeat("k", 1000)
+	if got := b.FullKey(long); !strings.HasSuffix(got, long) {
+		t.Error("key hashed with MaxKeyLength unset")
+	}
+}
+
+func TestFullKeyVersionSegment(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, version, want string
+	}{
+		{"app:", "3", "app:v3:user:42"},
+		{"", "3", "v3:user:42"},
+		{"app:", "", "app:user:42"},
+	} {
+		cfg := config.DefaultConfig()
+		cfg.Prefix, cfg.Version = tc.prefix, tc.version
+		if got := NewBase(cfg).FullKey("user:42"); got != tc.want {
+			t.Errorf("FullKey(prefix %q, version %q) = %q, want %q", tc.prefix, tc.version, got, tc.want)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Prefix, cfg.Version, cfg.MaxKeyLength = "app:", "2", len("app:v2:")+config.HashedKeyLength
+	if got := NewBase(cfg).FullKey(strings.Repeat("k", 72)); !strings.HasPrefix(got, "app:v2:sha256:") || len(got) != cfg.MaxKeyLength {
+		t.Errorf("hashed key = %q, want the version before the digest", got)
+	}
+	if got := NewBase(cfg).FullPrefix("user:"); got != "app:v2:user:" {
+		t.Errorf("FullPrefix = %q, want app:v2:user:", got)
+	}
+}
+
+/* ------------------ TTL resolution ------------------ */
+
+func TestResolveTTL(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TTL = 5 * time.Minute
+	b := NewBase(cfg)
+
+	for in, want := range map[time.Duration]time.Duration{
+		0:            5 * time.Minute,
+		time.Second:  time.Second,
+		NoExpiration: NoExpiration,
+	} {
+		if got := b.ResolveTTL(in); got != want {
+			t.Errorf("ResolveTTL(%v) = %v, want %v", in, got, want)
+		}
+	}
+	if got := b.WriteTTL(context.Background(), NoExpiration); got != NoExpiration {
+		t.Errorf("WriteTTL(NoExpiration) = %v", got)
+	}
+}
+
+/* ------------------ TTL jitter ------------------ */
+
+func TestJitterTTLStaysInBand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TTLJitter = 0.1
+	b := NewBase(cfg)
+	ctx := context.Background()
+
+	const ttl = time.Hour
+	lo, hi := ttl-6*time.Minute, ttl+6*time.Minute
+	seen := make(map[time.Duration]struct{})
+	for range 1000 {
+		got := b.JitterTTL(ctx, ttl)
+		if got < lo || got > hi {
+			t.Fatalf("JitterTTL = %v, want within [%v, %v]", got, lo, hi)
+		}
+		seen[got] = struct{}{}
+	}
+	if len(seen) < 100 {
+		t.Errorf("only %d distinct TTLs in 1000 draws", len(seen))
+	}
+	if floor := b.MinWriteTTL(ttl); floor != lo {
+		t.Errorf("MinWriteTTL = %v, want %v", floor, lo)
+	}
+}
+
+func TestJitterTTLExact(t *testing.T) {
+	ctx := context.Background()
+	if got := NewBase(config.DefaultConfig()).JitterTTL(ctx, time.Hour); got != time.Hour {
+		t.Errorf("zero jitter: JitterTTL = %v, want exactly 1h", got)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.TTLJitter = 0.5
+	b := NewBase(cfg)
+	if got := b.JitterTTL(WithExactTTL(ctx), time.Hour); got != time.Hour {
+		t.Errorf("WithExactTTL: JitterTTL = %v, want exactly 1h", got)
+	}
+	if got := b.JitterTTL(ctx, NoExpiration); got != NoExpiration {
+		t.Errorf("NoExpiration jittered to %v", got)
+	}
+}
@@ -1,8 +1,10 @@
 package base
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 type Serializer[T any] interface {
@@ -10,6 +12,13 @@ type Serializer[T any] interface {
 	Decode([]byte) (T, error)
 }
 
+// StaleChecker is implemented by serializers that can tell when a payload
+// was written in an outdated format. Backends rewrite such payloads after
+// a successful read.
+type StaleChecker interface {
+	Stale(data []byte) bool
+}
+
 type JsonSerializer[T any] struct{}
 
 func (JsonSerializer[T]) Encode(v T) ([]byte, error) {
@@ -42,3 +51,73 @@ type ConvertSerializer[T ~string] struct{}
 
 func (ConvertSerializer[T]) Encode(v T) ([]byte, error)    { return []byte(v), nil }
 func (ConvertSerializer[T]) Decode(data []byte) (T, error) { return T(data), nil }
+
+/* ------------------ Versioned ------------------ */
+
+// VersionedSerializer prefixes payloads with a schema version ("v<N>:").
+// Payloads written at an older version are upgraded through Migrate when
+// set; otherwise Decode fails with ErrStaleVersion. Unprefixed payloads
+// are treated as version 0.
+type VersionedSerializer[T any] struct {
+	Inner   Serializer[T]
+	Version int
+	Migrate func(oldVersion int, data []byte) (T, error)
+}
+
+func (s VersionedSerializer[T]) Encode(v T) ([]byte, error) {
+	data, err := s.Inner.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(data)+8)
+	out = append(out, 'v')
+	out = strconv.AppendInt(out, int64(s.Version), 10)
+	out = append(out, ':')
+	return append(out, data...), nil
+}
+
+func (s VersionedSerializer[T]) Decode(data []byte) (T, error) {
+	version, payload := splitVersion(data)
+
+	switch {
+	case version == s.Version:
+		return s.Inner.Decode(payload)
+	case version < s.Version && s.Migrate != nil:
+		v, err := s.Migrate(version, payload)
+		if err != nil {
+			return v, fmt.Errorf("%w: migrate v%d: %v", ErrDeserialize, version, err)
+		}
+		return v, nil
+	case version < s.Version:
+		var zero T
+		return zero, fmt.Errorf("%w: v%d < v%d", ErrStaleVersion, version, s.Version)
+	default:
+		var zero T
+		return zero, fmt.Errorf("%w: unknown version v%d", ErrDeserialize, version)
+	}
+}
+
+// Stale reports whether data was written at an older version and should
+// be rewritten after a successful Decode.
+func (s VersionedSerializer[T]) Stale(data []byte) bool {
+	version, _ := splitVersion(data)
+	return version < s.Version
+}
+
+func splitVersion(data []byte) (int, []byte) {
+	if len(data) < 3 || data[0] != 'v' {
+		return 0, data
+	}
+
+	end := bytes.IndexByte(data, ':')
+	if end < 2 {
+		return 0, data
+	}
+
+	n, err := strconv.Atoi(string(data[1:end]))
+	if err != nil {
+		return 0, data
+	}
+	return n, data[end+1:]
+}
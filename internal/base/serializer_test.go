@@ -0,0 +1,42 @@
+package base
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionedSerializerVersions(t *testing.T) {
+	v2 := VersionedSerializer[string]{Inner: JsonSerializer[string]{}, Version: 2}
+
+	data, err := v2.Encode("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `v2:"x"` {
+		t.Errorf("Encode = %s, want v2:\"x\"", data)
+	}
+	if got, err := v2.Decode(data); err != nil || got != "x" || v2.Stale(data) {
+		t.Errorf("Decode(%s) = %q, %v; stale=%v", data, got, err, v2.Stale(data))
+	}
+
+	for in, want := range map[string]error{
+		`v1:"x"`: ErrStaleVersion,
+		`"x"`:    ErrStaleVersion, // unprefixed payloads are version 0
+		`v3:"x"`: ErrDeserialize,
+	} {
+		if _, err := v2.Decode([]byte(in)); !errors.Is(err, want) {
+			t.Errorf("Decode(%s) = %v, want %v", in, err, want)
+		}
+	}
+}
+
+func TestVersionedSerializerMigrateError(t *testing.T) {
+	s := VersionedSerializer[string]{
+		Inner:   JsonSerializer[string]{},
+		Version: 2,
+		Migrate: func(int, []byte) (string, error) { return "", errors.New("boom") },
+	}
+	if _, err := s.Decode([]byte(`v1:"x"`)); !errors.Is(err, ErrDeserialize) {
+		t.Errorf("failed migration = %v, want ErrDeserialize", err)
+	}
+}
@@ -20,13 +20,42 @@ type Cache[T any] interface {
 
 type AdvancedCache[T any] interface {
 	Cache[T]
+	TryGet(ctx context.Context, key string) (T, bool, error)
+	Persist(ctx context.Context, key string) error
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(current T, found bool) (T, error)) error
+	CompareAndSwap(ctx context.Context, key string, oldVal, newVal T, ttl time.Duration) (bool, error)
 	GetOrSet(ctx context.Context, key string, ttl time.Duration, fn func() (T, error)) (T, error)
 	GetOrSetLocked(ctx context.Context, key string, ttl time.Duration, fn func() (T, error)) (T, error)
+	GetOrSetStale(ctx context.Context, key string, ttl, staleWindow time.Duration, fn func() (T, error)) (T, error)
+	GetOrSetProtected(ctx context.Context, key string, ttl, staleTTL time.Duration, fn func() (T, error)) (T, error)
+	GetStale(ctx context.Context, key string) (T, bool, error)
+	GetOrSetWithNegative(ctx context.Context, key string, ttl, negativeTTL time.Duration, fn func() (T, bool, error)) (T, error)
 	GetManyPipeline(ctx context.Context, keys []string) (map[string]T, error)
+	GetManyOrdered(ctx context.Context, keys []string) ([]Result[T], error)
 	SetManyPipeline(ctx context.Context, items map[string]T, ttl time.Duration) error
+	SetManyPipelineResult(ctx context.Context, items map[string]T, ttl time.Duration) (map[string]error, error)
 	DeleteByPrefix(ctx context.Context, prefix string) (int64, error)
+	DeleteByPattern(ctx context.Context, pattern string) (int64, error)
+	CountByPrefix(ctx context.Context, prefix string) (int64, error)
+	DeleteMany(ctx context.Context, keys []string) (int64, error)
+	ExistsMany(ctx context.Context, keys []string) (map[string]bool, error)
+	ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error
+	Range(ctx context.Context, fn func(key string, value T) bool) error
+	Dump(ctx context.Context) (map[string]Entry[T], error)
+	Restore(ctx context.Context, entries map[string]Entry[T]) error
+	SetWithParents(ctx context.Context, key string, value T, ttl time.Duration, parents ...string) error
+	InvalidateTree(ctx context.Context, parent string) (int64, error)
+	CloseWithTimeout(ctx context.Context) error
+	Go(fn func()) bool
+	RefreshAhead(key string, ttl time.Duration, at float64, fn func() (T, error)) error
+	RefreshAheadGroup(group string, keys []string, ttl time.Duration, at float64, fn func(key string) (T, error)) error
+	RegisterRefresher(key string, interval time.Duration, fn func() (T, error)) error
 	Stats(ctx context.Context) metrics.CacheStats
+	StartedAt() time.Time
 	Metrics() *metrics.Collector
+	Health(ctx context.Context) metrics.HealthStatus
+	Report(ctx context.Context) metrics.Report
+	Namespace(name string) AdvancedCache[T]
 }
 
 type Getter[T any] interface {
@@ -58,23 +87,167 @@ type BulkOperations interface {
 	Len(ctx context.Context) (int, error)
 }
 
+// TTLGetter returns a value together with its remaining TTL; 0 means the
+// entry never expires.
+type TTLGetter[T any] interface {
+	GetWithTTL(ctx context.Context, key string) (T, time.Duration, error)
+}
+
+// Persister removes a key's expiry.
+type Persister interface {
+	Persist(ctx context.Context, key string) error
+}
+
+// StaleGetter returns expired entries that are still present, flagging
+// them as stale.
+type StaleGetter[T any] interface {
+	GetStale(ctx context.Context, key string) (T, bool, error)
+}
+
 type PipelineGetter[T any] interface {
 	GetManyPipeline(ctx context.Context, keys []string) (map[string]T, error)
 }
 
+// Result is one key of a GetManyOrdered call; Found is false for a miss.
+type Result[T any] struct {
+	Key   string
+	Value T
+	Found bool
+}
+
+// OrderedGetter reads keys in one batch and returns one Result per key, in
+// input order.
+type OrderedGetter[T any] interface {
+	GetManyOrdered(ctx context.Context, keys []string) ([]Result[T], error)
+}
+
 type PipelineSetter[T any] interface {
 	SetManyPipeline(ctx context.Context, items map[string]T, ttl time.Duration) error
 }
 
+type PipelineResultSetter[T any] interface {
+	SetManyPipelineResult(ctx context.Context, items map[string]T, ttl time.Duration) (map[string]error, error)
+}
+
+// Ranger walks the cache contents until fn returns false.
+type Ranger[T any] interface {
+	Range(ctx context.Context, fn func(key string, value T) bool) error
+}
+
+// Entry is a cached value with its remaining TTL; 0 means it never expires.
+type Entry[T any] struct {
+	Value T
+	TTL   time.Duration
+}
+
+// Dumper exports the live entries of a cache and loads them back. Restore
+// recomputes expiry from each entry's TTL at the time it is called.
+type Dumper[T any] interface {
+	Dump(ctx context.Context) (map[string]Entry[T], error)
+	Restore(ctx context.Context, entries map[string]Entry[T]) error
+}
+
+// BulkChecker reports which of keys are cached.
+type BulkChecker interface {
+	ExistsMany(ctx context.Context, keys []string) (map[string]bool, error)
+}
+
+// BulkDeleter deletes keys and reports how many existed.
+type BulkDeleter interface {
+	DeleteMany(ctx context.Context, keys []string) (int64, error)
+}
+
 type PrefixDeleter interface {
 	DeleteByPrefix(ctx context.Context, prefix string) (int64, error)
 }
 
+// BulkExpirer resets the TTL of many keys at once, skipping missing ones.
+type BulkExpirer interface {
+	ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error
+}
+
+// PrefixCounter counts the live keys starting with prefix.
+type PrefixCounter interface {
+	CountByPrefix(ctx context.Context, prefix string) (int64, error)
+}
+
+// PatternDeleter deletes the keys matching a glob pattern (*, ? and
+// [...]) on the logical key.
+type PatternDeleter interface {
+	DeleteByPattern(ctx context.Context, pattern string) (int64, error)
+}
+
+// HotKeyProvider lists the most frequently read keys, hottest first.
+type HotKeyProvider interface {
+	HotKeys(ctx context.Context, n int) ([]string, error)
+}
+
+type StartTimeProvider interface {
+	StartedAt() time.Time
+}
+
 type StatProvider interface {
 	Stats(ctx context.Context) metrics.CacheStats
 }
 
+// HealthReporter runs a health check and reports its outcome.
+type HealthReporter interface {
+	Health(ctx context.Context) metrics.HealthStatus
+}
+
+// AtomicSetter writes a value only if the key is absent, returning the
+// stored value and false when another writer got there first.
+type AtomicSetter[T any] interface {
+	SetIfAbsent(ctx context.Context, key string, value T, ttl time.Duration) (T, bool, error)
+}
+
+// Updater applies a read-modify-write to one key atomically. fn receives
+// the current value (found is false for a miss); an error from fn aborts
+// the update and is returned unchanged.
+type Updater[T any] interface {
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(current T, found bool) (T, error)) error
+}
+
+// CompareAndSwapper stores newVal only while key still holds oldVal. A
+// mismatch or missing key reports false without an error.
+type CompareAndSwapper[T any] interface {
+	CompareAndSwap(ctx context.Context, key string, oldVal, newVal T, ttl time.Duration) (bool, error)
+}
+
+// DistributedLocker hands out owner tokens: only the token returned by
+// TryLock can release the lock.
 type DistributedLocker interface {
-	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
-	Unlock(ctx context.Context, key string) error
+	TryLock(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error)
+	Unlock(ctx context.Context, key string, token string) error
+}
+
+// LockExtender renews a lock acquired with TryLock. It reports false when
+// the lock no longer exists or has another owner.
+type LockExtender interface {
+	ExtendLock(ctx context.Context, key string, token string, ttl time.Duration) (bool, error)
+}
+
+// Semaphore admits up to a fixed number of concurrent holders. Acquire
+// does not block: ok is false when every slot is taken.
+type Semaphore interface {
+	Acquire(ctx context.Context, ttl time.Duration) (release func(), ok bool, err error)
+}
+
+// SemaphoreProvider hands out distributed semaphores, one per key.
+type SemaphoreProvider interface {
+	Semaphore(key string, limit int) Semaphore
+}
+
+// Tracer starts spans around cache operations. Adapters for concrete
+// tracing systems live in subpackages (see otel), keeping the core free of
+// tracing dependencies.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the part of a tracing span the cache uses.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
 }
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -9,6 +10,9 @@ import (
 
 type Config struct {
 	Enabled bool
+
+	// TopKeys is how many keys to track for TopKeys; 0 disables tracking.
+	TopKeys int
 }
 
 func DefaultConfig() Config {
@@ -25,6 +29,12 @@ type Collector struct {
 	mu         sync.RWMutex
 	operations map[string]*OperationStats
 	errors     map[string]int64
+
+	// statsFn supplies the aggregate CacheStats served with the snapshot.
+	statsFn func(context.Context) CacheStats
+
+	// keys is nil unless top-key tracking is enabled.
+	keys *topKeys
 }
 
 type OperationStats struct {
@@ -37,6 +47,8 @@ type OperationStats struct {
 
 	Hits   int64 `json:"hits"`
 	Misses int64 `json:"misses"`
+
+	latency histogram
 }
 
 /* ------------------ Snapshots ------------------ */
@@ -45,9 +57,16 @@ type SnapshotStats struct {
 	Count      int64 `json:"count"`
 	TotalItems int64 `json:"total_items"`
 
-	MinDuration time.Duration `json:"min_duration"`
-	MaxDuration time.Duration `json:"max_duration"`
-	AvgDuration time.Duration `json:"avg_duration"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MinDuration   time.Duration `json:"min_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+	AvgDuration   time.Duration `json:"avg_duration"`
+
+	// Latency percentiles estimated from a fixed-bucket histogram.
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
 
 	Hits   int64 `json:"hits"`
 	Misses int64 `json:"misses"`
@@ -57,10 +76,19 @@ type SnapshotStats struct {
 /* ------------------ Constructor ------------------ */
 
 func NewCollector() *Collector {
-	return &Collector{
+	return NewCollectorWithConfig(DefaultConfig())
+}
+
+func NewCollectorWithConfig(cfg Config) *Collector {
+	m := &Collector{
+		cfg:        cfg,
 		operations: make(map[string]*OperationStats),
 		errors:     make(map[string]int64),
 	}
+	if cfg.TopKeys > 0 {
+		m.keys = newTopKeys(cfg.TopKeys)
+	}
+	return m
 }
 
 /* ------------------ Recording ------------------ */
@@ -81,6 +109,7 @@ func (m *Collector) RecordOperation(op string, dur time.Duration, itemCount int)
 		if dur > s.MaxDuration {
 			s.MaxDuration = dur
 		}
+		s.latency.observe(dur)
 	})
 }
 
@@ -127,14 +156,19 @@ func (m *Collector) Snapshot() map[string]SnapshotStats {
 		}
 
 		out[op] = SnapshotStats{
-			Count:       s.Count,
-			TotalItems:  s.TotalItems,
-			MinDuration: s.MinDuration,
-			MaxDuration: s.MaxDuration,
-			AvgDuration: avg,
-			Hits:        s.Hits,
-			Misses:      s.Misses,
-			Errors:      m.errors[op],
+			Count:         s.Count,
+			TotalItems:    s.TotalItems,
+			TotalDuration: s.TotalDuration,
+			MinDuration:   s.MinDuration,
+			MaxDuration:   s.MaxDuration,
+			AvgDuration:   avg,
+			P50:           s.latency.quantile(0.50, s.MinDuration, s.MaxDuration),
+			P90:           s.latency.quantile(0.90, s.MinDuration, s.MaxDuration),
+			P95:           s.latency.quantile(0.95, s.MinDuration, s.MaxDuration),
+			P99:           s.latency.quantile(0.99, s.MinDuration, s.MaxDuration),
+			Hits:          s.Hits,
+			Misses:        s.Misses,
+			Errors:        m.errors[op],
 		}
 	}
 
@@ -149,6 +183,9 @@ func (m *Collector) Reset() {
 
 	m.operations = make(map[string]*OperationStats)
 	m.errors = make(map[string]int64)
+	if m.keys != nil {
+		m.keys.reset()
+	}
 }
 
 /* ------------------ Helpers ------------------ */
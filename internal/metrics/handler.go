@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+/* ------------------ Report ------------------ */
+
+const reportTopKeys = 10
+
+// Report combines the aggregate cache stats with per-operation metrics.
+type Report struct {
+	Stats      CacheStats               `json:"stats"`
+	Operations map[string]SnapshotStats `json:"operations"`
+	TopKeys    []KeyStat                `json:"top_keys,omitempty"`
+}
+
+// SetStatsSource registers the function Report uses for aggregate stats,
+// typically the owning cache's Stats method.
+func (m *Collector) SetStatsSource(fn func(context.Context) CacheStats) {
+	m.mu.Lock()
+	m.statsFn = fn
+	m.mu.Unlock()
+}
+
+// Report returns the current snapshot together with aggregate stats and,
+// when tracked, the ten hottest keys. Without a stats source, hits and
+// misses are summed from the snapshot.
+func (m *Collector) Report(ctx context.Context) Report {
+	m.mu.RLock()
+	fn := m.statsFn
+	m.mu.RUnlock()
+
+	ops := m.Snapshot()
+	top := m.TopKeys(reportTopKeys)
+	if fn != nil {
+		return Report{Stats: fn(ctx), Operations: ops, TopKeys: top}
+	}
+
+	b := NewStatsBuilder("")
+	for _, s := range ops {
+		b.AddHits(s.Hits).AddMisses(s.Misses)
+	}
+	return Report{Stats: b.Build(), Operations: ops, TopKeys: top}
+}
+
+/* ------------------ HTTP ------------------ */
+
+// JSONHandler serves c.Report as JSON, for debug endpoints.
+func JSONHandler(c *Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(c.Report(r.Context()))
+	})
+}
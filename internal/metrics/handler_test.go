@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getReport(t *testing.T, h http.Handler) Report {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var r Report
+	if err := json.NewDecoder(rec.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestJSONHandlerServesStatsAndOperations(t *testing.T) {
+	c := NewCollector()
+	c.RecordOperation("get", 2*time.Millisecond, 1)
+	c.RecordHit("get", 3)
+	c.RecordMiss("get", 1)
+	c.SetStatsSource(func(context.Context) CacheStats {
+		return NewStatsBuilder("memory").WithHits(3).WithMisses(1).WithUptime(time.Minute).Build()
+	})
+
+	r := getReport(t, JSONHandler(c))
+	if r.Stats.Backend != "memory" || r.Stats.Uptime != time.Minute {
+		t.Errorf("stats = %+v", r.Stats)
+	}
+	if r.Stats.HitRate != 0.75 {
+		t.Errorf("hit_rate = %v, want 0.75", r.Stats.HitRate)
+	}
+	get, ok := r.Operations["get"]
+	if !ok || get.Count != 1 || get.Hits != 3 || get.Misses != 1 {
+		t.Errorf("operations[get] = %+v, %v", get, ok)
+	}
+}
+
+func TestJSONHandlerSumsSnapshotWithoutSource(t *testing.T) {
+	c := NewCollector()
+	c.RecordHit("get", 1)
+	c.RecordMiss("get_many", 3)
+
+	r := getReport(t, JSONHandler(c))
+	if r.Stats.Hits != 1 || r.Stats.Misses != 3 || r.Stats.HitRate != 0.25 {
+		t.Errorf("stats = %+v", r.Stats)
+	}
+}
+
+func TestJSONHandlerRejectsWrites(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JSONHandler(NewCollector()).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") == "" {
+		t.Errorf("POST: %d, Allow=%q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
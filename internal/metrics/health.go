@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+/* ------------------ Health ------------------ */
+
+// HealthStatus describes the outcome of the latest health check.
+type HealthStatus struct {
+	Backend             string        `json:"backend"`
+	Healthy             bool          `json:"healthy"`
+	Latency             time.Duration `json:"latency"`
+	LastError           string        `json:"last_error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	CheckedAt           time.Time     `json:"checked_at"`
+}
+
+// HealthTracker accumulates ping results. The zero value is ready to use.
+type HealthTracker struct {
+	mu        sync.Mutex
+	latency   time.Duration
+	lastErr   string
+	failures  int
+	checkedAt time.Time
+}
+
+// Record stores the result of one check. LastError is kept after recovery
+// so the status still shows what went wrong last.
+func (h *HealthTracker) Record(latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.latency = latency
+	h.checkedAt = time.Now()
+	if err != nil {
+		h.lastErr = err.Error()
+		h.failures++
+		return
+	}
+	h.failures = 0
+}
+
+// Status returns the recorded state. A tracker that has never checked
+// reports healthy.
+func (h *HealthTracker) Status(backend string) HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return HealthStatus{
+		Backend:             backend,
+		Healthy:             h.failures == 0,
+		Latency:             h.latency,
+		LastError:           h.lastErr,
+		ConsecutiveFailures: h.failures,
+		CheckedAt:           h.checkedAt,
+	}
+}
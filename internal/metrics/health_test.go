@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealthTrackerZeroValueIsHealthy(t *testing.T) {
+	var h HealthTracker
+	s := h.Status("memory")
+	if !s.Healthy || s.ConsecutiveFailures != 0 || s.Backend != "memory" || !s.CheckedAt.IsZero() {
+		t.Errorf("zero tracker status = %+v", s)
+	}
+}
+
+func TestHealthTrackerCountsFailureStreak(t *testing.T) {
+	var h HealthTracker
+	h.Record(time.Millisecond, nil)
+	h.Record(2*time.Millisecond, errors.New("dial tcp: refused"))
+	h.Record(3*time.Millisecond, errors.New("i/o timeout"))
+
+	s := h.Status("redis")
+	if s.Healthy || s.ConsecutiveFailures != 2 || s.LastError != "i/o timeout" || s.Latency != 3*time.Millisecond {
+		t.Errorf("failing status = %+v", s)
+	}
+
+	// Recovery resets the streak but keeps the last error for diagnosis.
+	h.Record(time.Millisecond, nil)
+	s = h.Status("redis")
+	if !s.Healthy || s.ConsecutiveFailures != 0 || s.LastError != "i/o timeout" {
+		t.Errorf("recovered status = %+v", s)
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"math"
+	"time"
+)
+
+/* ------------------ Histogram ------------------ */
+
+// Bucket bounds grow by 2^(1/histSubBuckets) from histMin, so each bucket
+// spans ~19% and estimates stay within ~10% of the true value. Durations
+// above the last bound share the final bucket.
+const (
+	histMin        = time.Microsecond
+	histSubBuckets = 4
+	histOctaves    = 26 // 1µs · 2^26 ≈ 67s
+	histBuckets    = histOctaves*histSubBuckets + 2
+)
+
+// histogram is a fixed-size latency histogram; its memory does not grow
+// with the number of recorded operations. It is not safe for concurrent
+// use on its own; the Collector serializes access.
+type histogram struct {
+	counts [histBuckets]int64
+	total  int64
+}
+
+func bucketOf(d time.Duration) int {
+	if d < histMin {
+		return 0
+	}
+	i := int(math.Log2(float64(d)/float64(histMin))*histSubBuckets) + 1
+	return min(i, histBuckets-1)
+}
+
+// bucketValue is the representative (geometric middle) of bucket i.
+func bucketValue(i int) time.Duration {
+	if i == 0 {
+		return histMin / 2
+	}
+	exp := (float64(i) - 0.5) / histSubBuckets
+	return time.Duration(float64(histMin) * math.Exp2(exp))
+}
+
+func (h *histogram) observe(d time.Duration) {
+	h.counts[bucketOf(d)]++
+	h.total++
+}
+
+// quantile estimates the q-th quantile (0 < q <= 1), clamped to the
+// observed [lo, hi] range.
+func (h *histogram) quantile(q float64, lo, hi time.Duration) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(q * float64(h.total)))
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			return min(max(bucketValue(i), lo), hi)
+		}
+	}
+	return hi
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+func within(got, want time.Duration, tol float64) bool {
+	d := float64(got - want)
+	return d <= tol*float64(want) && d >= -tol*float64(want)
+}
+
+func TestPercentilesOfUniformLatencies(t *testing.T) {
+	c := NewCollector()
+	// 1ms, 2ms, ..., 1000ms in shuffled order.
+	for _, i := range rand.Perm(1000) {
+		c.RecordOperation("get", time.Duration(i+1)*time.Millisecond, 1)
+	}
+
+	s := c.Snapshot()["get"]
+	for name, tt := range map[string]struct{ got, want time.Duration }{
+		"p50": {s.P50, 500 * time.Millisecond},
+		"p90": {s.P90, 900 * time.Millisecond},
+		"p95": {s.P95, 950 * time.Millisecond},
+		"p99": {s.P99, 990 * time.Millisecond},
+	} {
+		if !within(tt.got, tt.want, 0.10) {
+			t.Errorf("%s = %v, want %v ±10%%", name, tt.got, tt.want)
+		}
+	}
+	if s.MinDuration != time.Millisecond || s.MaxDuration != time.Second {
+		t.Errorf("min/max = %v/%v", s.MinDuration, s.MaxDuration)
+	}
+}
+
+func TestPercentilesOfBimodalLatencies(t *testing.T) {
+	c := NewCollector()
+	// 95% fast hits, 5% slow misses: p90 is fast, p99 is slow.
+	for i := range 2000 {
+		d := 200 * time.Microsecond
+		if i%20 == 0 {
+			d = 80 * time.Millisecond
+		}
+		c.RecordOperation("get", d, 1)
+	}
+
+	s := c.Snapshot()["get"]
+	if !within(s.P90, 200*time.Microsecond, 0.10) {
+		t.Errorf("p90 = %v, want about 200µs", s.P90)
+	}
+	if !within(s.P99, 80*time.Millisecond, 0.10) {
+		t.Errorf("p99 = %v, want about 80ms", s.P99)
+	}
+}
+
+func TestPercentilesClampedToObservedRange(t *testing.T) {
+	c := NewCollector()
+	c.RecordOperation("set", 3*time.Millisecond, 1)
+
+	s := c.Snapshot()["set"]
+	if s.P50 != 3*time.Millisecond || s.P99 != 3*time.Millisecond {
+		t.Errorf("single sample: p50=%v p99=%v, want 3ms", s.P50, s.P99)
+	}
+	if c.Snapshot()["missing"].P99 != 0 {
+		t.Error("unrecorded operation has a p99")
+	}
+}
+
+func TestHistogramBucketsCoverRange(t *testing.T) {
+	if bucketOf(0) != 0 || bucketOf(time.Hour) != histBuckets-1 {
+		t.Errorf("bucketOf(0)=%d bucketOf(1h)=%d", bucketOf(0), bucketOf(time.Hour))
+	}
+	for _, d := range []time.Duration{time.Microsecond, 37 * time.Microsecond, 4 * time.Millisecond, 12 * time.Second} {
+		if v := bucketValue(bucketOf(d)); !within(v, d, 0.10) {
+			t.Errorf("bucket value for %v = %v, want within 10%%", d, v)
+		}
+	}
+}
+
+func TestRecordOperationConcurrent(t *testing.T) {
+	c := NewCollector()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				c.RecordOperation("get", time.Duration(g*1000+i+1)*time.Microsecond, 1)
+				if i%100 == 0 {
+					_ = c.Snapshot()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := c.Snapshot()["get"]
+	if s.Count != 8000 {
+		t.Errorf("Count = %d, want 8000", s.Count)
+	}
+	if !within(s.P50, 4*time.Millisecond, 0.10) {
+		t.Errorf("p50 = %v, want about 4ms", s.P50)
+	}
+}
@@ -5,11 +5,33 @@ import "time"
 type CacheStats struct {
 	Backend         string        `json:"backend"`
 	Items           int64         `json:"items"`
+	BytesUsed       int64         `json:"bytes_used,omitempty"`
+	Evictions       int64         `json:"evictions"`
+	Expirations     int64         `json:"expirations"`
 	Hits            int64         `json:"hits"`
 	Misses          int64         `json:"misses"`
 	HitRate         float64       `json:"hit_rate"`
 	Uptime          time.Duration `json:"uptime"`
+	StartedAt       time.Time     `json:"started_at"`
+	InFlight        int64         `json:"in_flight"`
+	CircuitState    string        `json:"circuit_state,omitempty"`
+	Pending         int64         `json:"pending,omitempty"`
+	Dropped         int64         `json:"dropped,omitempty"`
+	Fallbacks       int64         `json:"fallbacks,omitempty"`
 	RefreshTTLOnHit bool          `json:"refresh_on_hit"`
+	Pool            *PoolStats    `json:"pool,omitempty"`
+}
+
+// PoolStats describes a backend connection pool. Hits and Misses count
+// whether a free connection was found; Timeouts counts waits for one that
+// gave up, the first sign of pool exhaustion.
+type PoolStats struct {
+	TotalConns int64 `json:"total_conns"`
+	IdleConns  int64 `json:"idle_conns"`
+	StaleConns int64 `json:"stale_conns"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	Timeouts   int64 `json:"timeouts"`
 }
 
 type StatsBuilder struct {
@@ -40,6 +62,11 @@ func (b *StatsBuilder) WithUptime(uptime time.Duration) *StatsBuilder {
 	return b
 }
 
+func (b *StatsBuilder) WithStartedAt(t time.Time) *StatsBuilder {
+	b.stats.StartedAt = t
+	return b
+}
+
 func (b *StatsBuilder) WithRefreshTTL(refresh bool) *StatsBuilder {
 	b.stats.RefreshTTLOnHit = refresh
 	return b
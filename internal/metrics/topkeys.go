@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+/* ------------------ Top Keys ------------------ */
+
+// KeyStat is an estimated access count. The true count lies in
+// [Count-Error, Count].
+type KeyStat struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+	Error int64  `json:"error,omitempty"`
+}
+
+// topKeys implements the space-saving algorithm: it keeps at most capacity
+// counters, and a new key replaces the smallest one, inheriting its count
+// as the error bound. Memory stays bounded whatever the key cardinality.
+type topKeys struct {
+	mu       sync.Mutex
+	capacity int
+	index    map[string]*keyCounter
+	heap     counterHeap
+}
+
+type keyCounter struct {
+	KeyStat
+	pos int
+}
+
+func newTopKeys(capacity int) *topKeys {
+	return &topKeys{
+		capacity: capacity,
+		index:    make(map[string]*keyCounter, capacity),
+	}
+}
+
+func (t *topKeys) observe(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.index[key]; ok {
+		c.Count++
+		heap.Fix(&t.heap, c.pos)
+		return
+	}
+
+	if len(t.heap) < t.capacity {
+		c := &keyCounter{KeyStat: KeyStat{Key: key, Count: 1}}
+		t.index[key] = c
+		heap.Push(&t.heap, c)
+		return
+	}
+
+	victim := t.heap[0]
+	delete(t.index, victim.Key)
+	victim.Error = victim.Count
+	victim.Key = key
+	victim.Count++
+	t.index[key] = victim
+	heap.Fix(&t.heap, 0)
+}
+
+func (t *topKeys) top(n int) []KeyStat {
+	t.mu.Lock()
+	out := make([]KeyStat, len(t.heap))
+	for i, c := range t.heap {
+		out[i] = c.KeyStat
+	}
+	t.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	if n >= 0 && n < len(out) {
+		out = out[:n]
+	}
+	return out
+}
+
+func (t *topKeys) reset() {
+	t.mu.Lock()
+	t.index = make(map[string]*keyCounter, t.capacity)
+	t.heap = nil
+	t.mu.Unlock()
+}
+
+// counterHeap is a min-heap on Count.
+type counterHeap []*keyCounter
+
+func (h counterHeap) Len() int           { return len(h) }
+func (h counterHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h counterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *counterHeap) Push(x any) {
+	c := x.(*keyCounter)
+	c.pos = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *counterHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+/* ------------------ Collector API ------------------ */
+
+// RecordKey counts an access to key when top-key tracking is enabled.
+func (m *Collector) RecordKey(key string) {
+	if m.keys != nil && m.cfg.Enabled {
+		m.keys.observe(key)
+	}
+}
+
+// TopKeys returns up to n of the most accessed keys, most accessed first.
+// Counts are estimates; it returns nil unless Config.TopKeys is set.
+func (m *Collector) TopKeys(n int) []KeyStat {
+	if m.keys == nil || n <= 0 {
+		return nil
+	}
+	return m.keys.top(n)
+}
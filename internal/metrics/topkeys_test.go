@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"testing"
+)
+
+func TestTopKeysFindsHotKeysInSkewedTraffic(t *testing.T) {
+	c := NewCollectorWithConfig(Config{Enabled: true, TopKeys: 20})
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	// Three hot keys take most of the traffic; the rest is spread over
+	// far more keys than the tracker can hold.
+	truth := map[string]int64{}
+	for range 50_000 {
+		var key string
+		switch r := rng.IntN(100); {
+		case r < 30:
+			key = "hot:a"
+		case r < 50:
+			key = "hot:b"
+		case r < 60:
+			key = "hot:c"
+		default:
+			key = "cold:" + strconv.Itoa(rng.IntN(10_000))
+		}
+		truth[key]++
+		c.RecordKey(key)
+	}
+
+	top := c.TopKeys(3)
+	want := []string{"hot:a", "hot:b", "hot:c"}
+	if len(top) != 3 {
+		t.Fatalf("TopKeys(3) = %v", top)
+	}
+	for i, ks := range top {
+		if ks.Key != want[i] {
+			t.Errorf("TopKeys[%d] = %s, want %s (%v)", i, ks.Key, want[i], top)
+		}
+		if n := truth[ks.Key]; n > ks.Count || n < ks.Count-ks.Error {
+			t.Errorf("%s: true count %d outside [%d, %d]", ks.Key, n, ks.Count-ks.Error, ks.Count)
+		}
+	}
+
+	if n := len(c.keys.index); n > 20 {
+		t.Errorf("tracking %d keys, want at most 20", n)
+	}
+	if all := c.TopKeys(100); len(all) != 20 {
+		t.Errorf("TopKeys(100) returned %d keys, want the 20 tracked", len(all))
+	}
+}
+
+func TestTopKeysDisabledByDefault(t *testing.T) {
+	c := NewCollector()
+	c.RecordKey("k")
+	if top := c.TopKeys(5); top != nil {
+		t.Errorf("TopKeys without tracking = %v, want nil", top)
+	}
+}
+
+func TestTopKeysReset(t *testing.T) {
+	c := NewCollectorWithConfig(Config{Enabled: true, TopKeys: 5})
+	c.RecordKey("k")
+	c.Reset()
+	if top := c.TopKeys(5); len(top) != 0 {
+		t.Errorf("TopKeys after Reset = %v", top)
+	}
+}
+
+func TestJSONHandlerReportsTopKeys(t *testing.T) {
+	c := NewCollectorWithConfig(Config{Enabled: true, TopKeys: 5})
+	for range 3 {
+		c.RecordKey("hot")
+	}
+	c.RecordKey("cold")
+
+	r := getReport(t, JSONHandler(c))
+	if len(r.TopKeys) != 2 || r.TopKeys[0] != (KeyStat{Key: "hot", Count: 3}) {
+		t.Errorf("top_keys = %+v", r.TopKeys)
+	}
+}
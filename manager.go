@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+/* ------------------ Manager ------------------ */
+
+// ManagedCache is the part of AdvancedCache that does not depend on the
+// value type, which the Manager can run across every registered cache.
+type ManagedCache interface {
+	Clear(ctx context.Context) error
+	Len(ctx context.Context) (int, error)
+	Ping(ctx context.Context) error
+	Stats(ctx context.Context) metrics.CacheStats
+	Close() error
+}
+
+// Manager is a registry of named caches of any value types, so a service
+// can manage their lifecycles in one place. Register adds a cache and
+// Typed retrieves it with its type; the Manager methods act on all of
+// them. It is safe for concurrent use.
+type Manager struct {
+	mu     sync.RWMutex
+	caches map[string]ManagedCache
+}
+
+// NewManager returns an empty Manager.
+func NewManager() *Manager {
+	return &Manager{caches: make(map[string]ManagedCache)}
+}
+
+// Register adds c under name. Names must be unique and non-empty.
+func Register[T any](m *Manager, name string, c interfaces.AdvancedCache[T]) error {
+	if name == "" || c == nil {
+		return errors.New("cache manager: name and cache are required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.caches[name]; ok {
+		return fmt.Errorf("cache manager: %q already registered", name)
+	}
+	m.caches[name] = c
+	return nil
+}
+
+// Typed returns the cache registered under name. It fails when there is
+// none or when it holds values of another type than T.
+func Typed[T any](m *Manager, name string) (interfaces.AdvancedCache[T], error) {
+	mc, ok := m.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("cache manager: %q not registered", name)
+	}
+	c, ok := mc.(interfaces.AdvancedCache[T])
+	if !ok {
+		var zero T
+		return nil, fmt.Errorf("cache manager: %q does not hold %T values", name, zero)
+	}
+	return c, nil
+}
+
+// Get returns the cache registered under name without its value type.
+func (m *Manager) Get(name string) (ManagedCache, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.caches[name]
+	return c, ok
+}
+
+// Remove unregisters name without closing its cache, reporting whether it
+// was registered.
+func (m *Manager) Remove(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.caches[name]
+	delete(m.caches, name)
+	return ok
+}
+
+// Names returns the registered names in sorted order.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Sorted(maps.Keys(m.caches))
+}
+
+// StatsByName returns the stats of every registered cache.
+func (m *Manager) StatsByName(ctx context.Context) map[string]metrics.CacheStats {
+	out := make(map[string]metrics.CacheStats)
+	for name, c := range m.snapshot() {
+		out[name] = c.Stats(ctx)
+	}
+	return out
+}
+
+// Stats merges the stats of every registered cache.
+func (m *Manager) Stats(ctx context.Context) metrics.CacheStats {
+	byName := m.StatsByName(ctx)
+	return metrics.MergeStats(slices.Collect(maps.Values(byName))...)
+}
+
+// ClearAll clears every registered cache, in name order. It tries them
+// all and returns their failures joined.
+func (m *Manager) ClearAll(ctx context.Context) error {
+	return m.each(func(c ManagedCache) error { return c.Clear(ctx) })
+}
+
+// Close closes every registered cache, in name order, and unregisters
+// them. It returns their failures joined.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	caches := m.caches
+	m.caches = make(map[string]ManagedCache)
+	m.mu.Unlock()
+
+	return eachCache(caches, func(c ManagedCache) error { return c.Close() })
+}
+
+func (m *Manager) snapshot() map[string]ManagedCache {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.caches)
+}
+
+func (m *Manager) each(fn func(ManagedCache) error) error {
+	return eachCache(m.snapshot(), fn)
+}
+
+func eachCache(caches map[string]ManagedCache, fn func(ManagedCache) error) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(caches)) {
+		if err := fn(caches[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+type managedUser struct {
+	Name string
+}
+
+// newTestManager registers a users cache and a counts cache.
+func newTestManager(t *testing.T) (*Manager, interfaces.AdvancedCache[managedUser], interfaces.AdvancedCache[int]) {
+	t.Helper()
+	m := NewManager()
+	users, counts := newTestAdvanced[managedUser](t), newTestAdvanced[int](t)
+	if err := Register(m, "users", users); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(m, "counts", counts); err != nil {
+		t.Fatal(err)
+	}
+	return m, users, counts
+}
+
+func TestManagerTypedRetrieval(t *testing.T) {
+	ctx := context.Background()
+	m, _, _ := newTestManager(t)
+
+	users, err := Typed[managedUser](m, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := Typed[int](m, "counts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = users.Set(ctx, "1", managedUser{Name: "ann"}, time.Minute)
+	_ = counts.Set(ctx, "1", 7, time.Minute)
+	if u, _ := users.Get(ctx, "1"); u.Name != "ann" {
+		t.Errorf("users 1 = %+v", u)
+	}
+	if n, _ := counts.Get(ctx, "1"); n != 7 {
+		t.Errorf("counts 1 = %d", n)
+	}
+
+	if _, err := Typed[int](m, "users"); err == nil || !strings.Contains(err.Error(), "int") {
+		t.Errorf("Typed[int](users) = %v, want a type error", err)
+	}
+	if _, err := Typed[int](m, "absent"); err == nil {
+		t.Error("Typed of an unregistered name succeeded")
+	}
+	if got := m.Names(); !slices.Equal(got, []string{"counts", "users"}) {
+		t.Errorf("Names = %v", got)
+	}
+}
+
+func TestManagerRegisterRejectsBadEntries(t *testing.T) {
+	m, _, counts := newTestManager(t)
+
+	if err := Register(m, "counts", counts); err == nil {
+		t.Error("duplicate name registered")
+	}
+	if err := Register(m, "", counts); err == nil {
+		t.Error("empty name registered")
+	}
+	if err := Register[int](m, "nil", nil); err == nil {
+		t.Error("nil cache registered")
+	}
+
+	if !m.Remove("counts") || m.Remove("counts") {
+		t.Error("Remove did not report the registration")
+	}
+	if err := Register(m, "counts", counts); err != nil {
+		t.Errorf("re-register after Remove = %v", err)
+	}
+}
+
+func TestManagerStatsAggregates(t *testing.T) {
+	ctx := context.Background()
+	m, users, counts := newTestManager(t)
+
+	_ = users.Set(ctx, "1", managedUser{Name: "ann"}, time.Minute)
+	_ = users.Set(ctx, "2", managedUser{Name: "bob"}, time.Minute)
+	for _, k := range []string{"a", "b", "c"} {
+		_ = counts.Set(ctx, k, 1, time.Minute)
+	}
+	_, _ = users.Get(ctx, "1")
+	_, _ = counts.Get(ctx, "a")
+	_, _ = counts.Get(ctx, "absent")
+
+	byName := m.StatsByName(ctx)
+	if byName["users"].Items != 2 || byName["counts"].Items != 3 {
+		t.Errorf("StatsByName items = %d, %d; want 2 and 3", byName["users"].Items, byName["counts"].Items)
+	}
+
+	got := m.Stats(ctx)
+	if got.Items != 5 || got.Hits != 2 || got.Misses != 1 {
+		t.Errorf("Stats = %d items, %d hits, %d misses; want 5, 2, 1", got.Items, got.Hits, got.Misses)
+	}
+}
+
+func TestManagerClearAll(t *testing.T) {
+	ctx := context.Background()
+	m, users, counts := newTestManager(t)
+	_ = users.Set(ctx, "1", managedUser{Name: "ann"}, time.Minute)
+	_ = counts.Set(ctx, "1", 1, time.Minute)
+
+	if err := m.ClearAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for name, c := range map[string]ManagedCache{"users": users, "counts": counts} {
+		if n, _ := c.Len(ctx); n != 0 {
+			t.Errorf("%s holds %d entries after ClearAll", name, n)
+		}
+	}
+}
+
+// failingClose is a cache whose Close fails.
+type failingClose struct {
+	interfaces.AdvancedCache[int]
+}
+
+var errCloseFailed = errors.New("close failed")
+
+func (failingClose) Close() error { return errCloseFailed }
+
+func TestManagerCloseClosesAll(t *testing.T) {
+	ctx := context.Background()
+	m, users, counts := newTestManager(t)
+	if err := Register[int](m, "broken", failingClose{newTestAdvanced[int](t)}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.Close()
+	if !errors.Is(err, errCloseFailed) || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Close = %v, want the broken cache's failure by name", err)
+	}
+
+	// The failure does not stop the others from closing.
+	if err := users.Set(ctx, "1", managedUser{}, time.Minute); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("users Set after Close = %v, want ErrClosed", err)
+	}
+	if err := counts.Set(ctx, "1", 1, time.Minute); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("counts Set after Close = %v, want ErrClosed", err)
+	}
+	if names := m.Names(); len(names) != 0 {
+		t.Errorf("Names after Close = %v, want none", names)
+	}
+}
@@ -0,0 +1,161 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Client ------------------ */
+
+// errNotFound is the client-level miss: NOT_FOUND replies and absent keys.
+var errNotFound = errors.New("memcached: not found")
+
+// client holds one gomemcache client per server. Keys are mapped to
+// servers by CRC-32, as gomemcache does for a server list, so processes
+// configured with the same list agree on where a key lives; keeping the
+// servers apart lets a multi-key get fail for one server's keys only.
+//
+// gomemcache takes no context: ctx is checked before each command, and
+// the command itself is bounded by the client timeout.
+type client struct {
+	servers []*server
+}
+
+type server struct {
+	addr string
+	mc   *memcache.Client
+}
+
+// newClient resolves addrs; it does not connect to them.
+func newClient(addrs []string, maxIdle int, dialTimeout, timeout time.Duration) (*client, error) {
+	d := &net.Dialer{Timeout: dialTimeout}
+	c := &client{}
+	for _, addr := range addrs {
+		var ss memcache.ServerList
+		if err := ss.SetServers(addr); err != nil {
+			return nil, fmt.Errorf("%s: %w", addr, connError(err))
+		}
+		mc := memcache.NewFromSelector(&ss)
+		mc.DialContext = d.DialContext
+		mc.Timeout = timeout
+		mc.MaxIdleConns = maxIdle
+		c.servers = append(c.servers, &server{addr: addr, mc: mc})
+	}
+	return c, nil
+}
+
+func (c *client) pick(key string) *server {
+	if len(c.servers) == 1 {
+		return c.servers[0]
+	}
+	return c.servers[crc32.ChecksumIEEE([]byte(key))%uint32(len(c.servers))]
+}
+
+func (c *client) close() error {
+	var errs []error
+	for _, s := range c.servers {
+		errs = append(errs, s.mc.Close())
+	}
+	return errors.Join(errs...)
+}
+
+/* ------------------ Commands ------------------ */
+
+// getMulti fetches keys, which must all live on s, and calls found for
+// each hit. Nothing is reported when the get fails part way.
+func (c *client) getMulti(ctx context.Context, s *server, keys []string, found func(key string, data []byte)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	items, err := s.mc.GetMulti(keys)
+	if err != nil {
+		return clientError(err)
+	}
+	for k, it := range items {
+		found(k, it.Value)
+	}
+	return nil
+}
+
+func (c *client) get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	it, err := c.pick(key).mc.Get(key)
+	if err != nil {
+		return nil, clientError(err)
+	}
+	return it.Value, nil
+}
+
+func (c *client) set(ctx context.Context, key string, data []byte, exptime int32) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return clientError(c.pick(key).mc.Set(&memcache.Item{Key: key, Value: data, Expiration: exptime}))
+}
+
+// delete reports errNotFound when key did not exist.
+func (c *client) delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return clientError(c.pick(key).mc.Delete(key))
+}
+
+// touch resets key's expiry, reporting errNotFound when key does not
+// exist.
+func (c *client) touch(ctx context.Context, key string, exptime int32) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return clientError(c.pick(key).mc.Touch(key, exptime))
+}
+
+// ping checks every server with a version command.
+func (c *client) ping(ctx context.Context) error {
+	for _, s := range c.servers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.mc.Ping(); err != nil {
+			return fmt.Errorf("%s: %w", s.addr, clientError(err))
+		}
+	}
+	return nil
+}
+
+/* ------------------ Errors ------------------ */
+
+// clientError maps a gomemcache error: cache misses become errNotFound,
+// network failures match ErrConnection, and protocol errors are returned
+// as they are. gomemcache closes the connection after any of them except
+// a miss, so a bad reply never leaks into the next command.
+func clientError(err error) error {
+	var ne net.Error
+	var cte *memcache.ConnectTimeoutError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, memcache.ErrCacheMiss):
+		return errNotFound
+	case errors.As(err, &ne), errors.As(err, &cte),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return connError(err)
+	default:
+		return err
+	}
+}
+
+func connError(err error) error {
+	return fmt.Errorf("%w: %v", base.ErrConnection, err)
+}
@@ -0,0 +1,213 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// fakeServer is an in-process memcached that understands the commands
+// gomemcache sends. A set whose data is "bad" is rejected the way memcached
+// rejects a bad data chunk, and a get of "garbled" gets a malformed reply.
+type fakeServer struct {
+	addr  string
+	dials atomic.Int64
+
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	f := &fakeServer{addr: ln.Addr().String(), data: make(map[string][]byte)}
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.dials.Add(1)
+			go f.serve(nc)
+		}
+	}()
+	return f
+}
+
+func (f *fakeServer) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			fmt.Fprint(nc, "ERROR\r\n")
+			continue
+		}
+
+		f.mu.Lock()
+		switch fields[0] {
+		case "version":
+			fmt.Fprint(nc, "VERSION fake\r\n")
+		case "get", "gets":
+			for _, k := range fields[1:] {
+				if k == "garbled" {
+					fmt.Fprint(nc, "VALUE garbled 0 x\r\n")
+				} else if v, ok := f.data[k]; ok {
+					fmt.Fprintf(nc, "VALUE %s 0 %d 1\r\n%s\r\n", k, len(v), v)
+				}
+			}
+			fmt.Fprint(nc, "END\r\n")
+		case "set":
+			n, _ := strconv.Atoi(fields[4])
+			buf := make([]byte, n+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				f.mu.Unlock()
+				return
+			}
+			if string(buf[:n]) == "bad" {
+				fmt.Fprint(nc, "CLIENT_ERROR bad data chunk\r\nERROR\r\n")
+			} else {
+				f.data[fields[1]] = buf[:n]
+				fmt.Fprint(nc, "STORED\r\n")
+			}
+		case "delete", "touch":
+			if _, ok := f.data[fields[1]]; !ok {
+				fmt.Fprint(nc, "NOT_FOUND\r\n")
+			} else if fields[0] == "delete" {
+				delete(f.data, fields[1])
+				fmt.Fprint(nc, "DELETED\r\n")
+			} else {
+				fmt.Fprint(nc, "TOUCHED\r\n")
+			}
+		default:
+			fmt.Fprint(nc, "ERROR\r\n")
+		}
+		f.mu.Unlock()
+	}
+}
+
+func newFakeClient(t *testing.T, f *fakeServer) *client {
+	t.Helper()
+	c, err := newClient([]string{f.addr}, 2, time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.close() })
+	return c
+}
+
+func TestClientCommands(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeServer(t)
+	c := newFakeClient(t, f)
+
+	if err := c.ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.get(ctx, "k"); err != nil || string(got) != "v" {
+		t.Errorf("get = %q, %v; want v", got, err)
+	}
+	if _, err := c.get(ctx, "absent"); !errors.Is(err, errNotFound) {
+		t.Errorf("get absent = %v, want errNotFound", err)
+	}
+	if err := c.touch(ctx, "k", 60); err != nil {
+		t.Errorf("touch = %v", err)
+	}
+	if err := c.delete(ctx, "k"); err != nil {
+		t.Errorf("delete = %v", err)
+	}
+	if err := c.delete(ctx, "k"); !errors.Is(err, errNotFound) {
+		t.Errorf("second delete = %v, want errNotFound", err)
+	}
+
+	// Successes and misses leave the connection in sync, so it is reused.
+	if n := f.dials.Load(); n != 1 {
+		t.Errorf("server saw %d connections, want 1", n)
+	}
+}
+
+func TestClientDropsConnAfterErrorReply(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeServer(t)
+	c := newFakeClient(t, f)
+
+	if err := c.set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.set(ctx, "k", []byte("bad"), 0); err == nil || errors.Is(err, base.ErrConnection) {
+		t.Fatalf("set bad = %v, want the server's rejection", err)
+	}
+
+	// The ERROR trailing the CLIENT_ERROR must not be read as the reply
+	// to the next command.
+	if got, err := c.get(ctx, "k"); err != nil || string(got) != "v" {
+		t.Errorf("get after the error reply = %q, %v; want v", got, err)
+	}
+	if n := f.dials.Load(); n != 2 {
+		t.Errorf("server saw %d connections, want a fresh one after the error", n)
+	}
+}
+
+func TestClientDropsConnAfterMalformedReply(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeServer(t)
+	c := newFakeClient(t, f)
+
+	if _, err := c.get(ctx, "garbled"); err == nil || errors.Is(err, errNotFound) {
+		t.Fatalf("get garbled = %v, want a protocol error", err)
+	}
+	if err := c.set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Errorf("set after the malformed reply = %v", err)
+	}
+	if n := f.dials.Load(); n != 2 {
+		t.Errorf("server saw %d connections, want a fresh one after the bad reply", n)
+	}
+}
+
+func TestClientUnreachableServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	c, err := newClient([]string{addr}, 2, time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+
+	err = c.ping(context.Background())
+	if !errors.Is(err, base.ErrConnection) || !strings.Contains(err.Error(), addr) {
+		t.Errorf("ping = %v, want ErrConnection naming %s", err, addr)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.get(ctx, "k"); !errors.Is(err, context.Canceled) {
+		t.Errorf("get with a canceled context = %v, want context.Canceled", err)
+	}
+}
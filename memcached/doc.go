@@ -0,0 +1,7 @@
+// Package memcached is a cache backend for one or more memcached servers,
+// built on github.com/bradfitz/gomemcache. Keys are spread across servers
+// by CRC-32 of the full key.
+//
+// Memcached cannot list keys, so Clear, Len and DeleteByPrefix return an
+// error matching errors.ErrUnsupported.
+package memcached
@@ -0,0 +1,301 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Types ------------------ */
+
+const (
+	maxKeyLength = 250
+
+	// Memcached reads expiry times above 30 days as unix timestamps.
+	maxRelativeExpiry = 30 * 24 * time.Hour
+)
+
+// errUnsupported is returned by the operations that need to enumerate
+// keys, which memcached cannot do.
+var errUnsupported = fmt.Errorf("%w: memcached cannot enumerate keys", errors.ErrUnsupported)
+
+type memcachedCache[T any] struct {
+	base       *base.Base
+	client     *client
+	serializer base.Serializer[T]
+}
+
+/* ------------------ Constructor ------------------ */
+
+func NewMemcached[T any](cfg config.Config, opts ...Option[T]) (*memcachedCache[T], error) {
+	return NewMemcachedContext[T](context.Background(), cfg, opts...)
+}
+
+// NewMemcachedContext connects to cfg.MemcachedServers and pings every
+// one of them; the error for a server that does not answer names it and
+// matches ErrConnection. PoolSize caps the idle connections kept per
+// server; DialTimeout and ReadTimeout bound connecting and each command.
+func NewMemcachedContext[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (*memcachedCache[T], error) {
+	client, err := newClient(cfg.MemcachedServers, cfg.PoolSize, cfg.DialTimeout, cfg.ReadTimeout)
+	if err != nil {
+		return nil, base.WrapError(base.OpPing, err, "")
+	}
+	m := &memcachedCache[T]{
+		base:       base.NewBase(cfg),
+		client:     client,
+		serializer: &base.JsonSerializer[T]{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	// Ensure timeout
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := cfg.ConnTimeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := m.client.ping(ctx); err != nil {
+		_ = m.client.close()
+		if !errors.Is(err, base.ErrConnection) {
+			err = fmt.Errorf("%w: %w", base.ErrConnection, err)
+		}
+		return nil, base.WrapError(base.OpPing, err, "")
+	}
+	return m, nil
+}
+
+/* ------------------ Key helpers ------------------ */
+
+// fullKey applies the prefix and checks the result against memcached's
+// key rules: at most 250 bytes, no spaces or control characters. Set
+// MaxKeyLength to hash long keys instead of rejecting them.
+func (m *memcachedCache[T]) fullKey(op base.Op, key string) (string, error) {
+	fk := m.base.FullKey(key)
+	if len(fk) > maxKeyLength {
+		return "", base.WrapError(op, fmt.Errorf("%w: key longer than %d bytes", base.ErrInvalidArgument, maxKeyLength), key)
+	}
+	for i := 0; i < len(fk); i++ {
+		if c := fk[i]; c <= ' ' || c == 0x7f {
+			return "", base.WrapError(op, fmt.Errorf("%w: key contains spaces or control characters", base.ErrInvalidArgument), key)
+		}
+	}
+	return fk, nil
+}
+
+// exptime maps a resolved TTL to a memcached expiry: 0 never expires,
+// whole seconds up to 30 days, a unix timestamp beyond that, capped at
+// the largest one memcached accepts.
+func exptime(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiry {
+		return int32(min(time.Now().Add(ttl).Unix(), math.MaxInt32))
+	}
+	return int32(math.Ceil(ttl.Seconds()))
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (m *memcachedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+
+	if err := m.base.ValidateKey(key); err != nil {
+		return zero, err
+	}
+	if err := m.base.CheckContext(ctx); err != nil {
+		return zero, err
+	}
+	fk, err := m.fullKey(base.OpGet, key)
+	if err != nil {
+		return zero, err
+	}
+
+	data, err := m.client.get(ctx, fk)
+	if errors.Is(err, errNotFound) {
+		m.base.FireMiss(key)
+		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return zero, base.WrapError(base.OpGet, err, key)
+	}
+
+	val, err := m.decode(key, data)
+	if err != nil {
+		return zero, err
+	}
+	m.base.FireHit(key)
+	return val, nil
+}
+
+func (m *memcachedCache[T]) decode(key string, data []byte) (T, error) {
+	val, err := m.serializer.Decode(data)
+	if errors.Is(err, base.ErrStaleVersion) {
+		m.base.FireMiss(key)
+		return val, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return val, base.SerializationError(base.OpGet, base.ErrDeserialize, err, key)
+	}
+	return val, nil
+}
+
+func (m *memcachedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := m.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := m.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	fk, err := m.fullKey(base.OpSet, key)
+	if err != nil {
+		return err
+	}
+
+	data, err := m.serializer.Encode(value)
+	if err != nil {
+		return base.SerializationError(base.OpSet, base.ErrSerialize, err, key)
+	}
+
+	ttl = m.base.WriteTTL(ctx, ttl)
+	if err := m.client.set(ctx, fk, data, exptime(ttl)); err != nil {
+		return base.WrapError(base.OpSet, err, key)
+	}
+	return nil
+}
+
+func (m *memcachedCache[T]) Delete(ctx context.Context, keys ...string) error {
+	_, err := m.DeleteMany(ctx, keys)
+	return err
+}
+
+// DeleteMany deletes keys one command at a time and returns how many
+// existed. It stops at the first failure.
+func (m *memcachedCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
+	if err := m.base.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, k := range keys {
+		fk, err := m.fullKey(base.OpDelete, k)
+		if err != nil {
+			return total, err
+		}
+		switch err := m.client.delete(ctx, fk); {
+		case err == nil:
+			total++
+		case !errors.Is(err, errNotFound):
+			return total, base.WrapError(base.OpDelete, err, k)
+		}
+	}
+	return total, nil
+}
+
+// Exists fetches key, as memcached has no cheaper existence check.
+func (m *memcachedCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	if err := m.base.ValidateKey(key); err != nil {
+		return false, err
+	}
+	if err := m.base.CheckContext(ctx); err != nil {
+		return false, err
+	}
+	fk, err := m.fullKey(base.OpExists, key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = m.client.get(ctx, fk)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, base.WrapError(base.OpExists, err, key)
+	}
+	return true, nil
+}
+
+// Persist clears key's expiry. It returns ErrCacheMiss if key does not
+// exist.
+func (m *memcachedCache[T]) Persist(ctx context.Context, key string) error {
+	if err := m.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := m.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	fk, err := m.fullKey(base.OpPersist, key)
+	if err != nil {
+		return err
+	}
+
+	err = m.client.touch(ctx, fk, 0)
+	if errors.Is(err, errNotFound) {
+		return base.WrapError(base.OpPersist, base.ErrCacheMiss, key)
+	}
+	return base.WrapError(base.OpPersist, err, key)
+}
+
+// ExpireMany touches every key with ttl; missing keys are skipped.
+func (m *memcachedCache[T]) ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	if err := m.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	exp := exptime(m.base.WriteTTL(ctx, ttl))
+	for _, k := range keys {
+		fk, err := m.fullKey(base.OpExpireMany, k)
+		if err != nil {
+			return err
+		}
+		if err := m.client.touch(ctx, fk, exp); err != nil && !errors.Is(err, errNotFound) {
+			return base.WrapError(base.OpExpireMany, err, k)
+		}
+	}
+	return nil
+}
+
+/* ------------------ Unsupported ------------------ */
+
+// Clear is not supported: memcached cannot list keys by prefix, and a
+// flush_all would also drop entries of other prefixes.
+func (m *memcachedCache[T]) Clear(ctx context.Context) error {
+	return base.WrapError(base.OpClear, errUnsupported, "")
+}
+
+// Len is not supported: memcached cannot count keys by prefix.
+func (m *memcachedCache[T]) Len(ctx context.Context) (int, error) {
+	return 0, base.WrapError(base.OpLen, errUnsupported, "")
+}
+
+// DeleteByPrefix is not supported: memcached cannot list keys.
+func (m *memcachedCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	return 0, base.WrapError(base.OpDeleteByPrefix, errUnsupported, prefix)
+}
+
+/* ------------------ Lifecycle ------------------ */
+
+func (m *memcachedCache[T]) Ping(ctx context.Context) error {
+	if err := m.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	return base.WrapError(base.OpPing, m.client.ping(ctx), "")
+}
+
+func (m *memcachedCache[T]) StartedAt() time.Time {
+	return m.base.StartedAt()
+}
+
+func (m *memcachedCache[T]) Close() error {
+	return m.client.close()
+}
@@ -0,0 +1,156 @@
+//go:build integration
+
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// These tests need running memcached servers, by default the one from
+// docker-compose.yml. Set MEMCACHED_SERVERS to a comma-separated list to
+// use others.
+
+func testConfig(t *testing.T) config.Config {
+	t.Helper()
+	servers := []string{"localhost:11211"}
+	if v := os.Getenv("MEMCACHED_SERVERS"); v != "" {
+		servers = strings.Split(v, ",")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Type = config.TypeMemcached
+	cfg.MemcachedServers = servers
+	cfg.Prefix = fmt.Sprintf("it%d:", time.Now().UnixNano())
+	return cfg
+}
+
+func newTestCache(t *testing.T) *memcachedCache[string] {
+	t.Helper()
+	m, err := NewMemcached[string](testConfig(t))
+	if err != nil {
+		t.Skipf("memcached not reachable: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+func TestIntegrationSetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	m := newTestCache(t)
+
+	if err := m.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Get(ctx, "k"); err != nil || got != "v" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if ok, err := m.Exists(ctx, "k"); err != nil || !ok {
+		t.Fatalf("Exists = %v, %v", ok, err)
+	}
+
+	n, err := m.DeleteMany(ctx, []string{"k", "absent"})
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteMany = %d, %v; want 1", n, err)
+	}
+	if _, err := m.Get(ctx, "k"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Fatalf("Get after delete: %v, want miss", err)
+	}
+}
+
+func TestIntegrationExpiry(t *testing.T) {
+	ctx := context.Background()
+	m := newTestCache(t)
+
+	if err := m.Set(ctx, "short", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set(ctx, "kept", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Persist(ctx, "kept"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ExpireMany(ctx, []string{"short", "absent"}, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Memcached expires with second precision.
+	time.Sleep(2100 * time.Millisecond)
+
+	if _, err := m.Get(ctx, "short"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("expired key: %v, want miss", err)
+	}
+	if _, err := m.Get(ctx, "kept"); err != nil {
+		t.Errorf("persisted key: %v", err)
+	}
+	if err := m.Persist(ctx, "absent"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Persist(absent) = %v, want miss", err)
+	}
+}
+
+func TestIntegrationGetManyPipeline(t *testing.T) {
+	ctx := context.Background()
+	m := newTestCache(t)
+
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+		if i%2 == 0 {
+			if err := m.Set(ctx, keys[i], keys[i], time.Minute); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	got, err := m.GetManyPipeline(ctx, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 25 {
+		t.Fatalf("got %d hits, want 25", len(got))
+	}
+	for i, k := range keys {
+		v, ok := got[k]
+		if ok != (i%2 == 0) || (ok && v != k) {
+			t.Errorf("%s: got %q, %v", k, v, ok)
+		}
+	}
+}
+
+func TestIntegrationUnsupported(t *testing.T) {
+	ctx := context.Background()
+	m := newTestCache(t)
+
+	if err := m.Clear(ctx); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Clear = %v", err)
+	}
+	if _, err := m.Len(ctx); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Len = %v", err)
+	}
+	if _, err := m.DeleteByPrefix(ctx, "k"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("DeleteByPrefix = %v", err)
+	}
+}
+
+func TestIntegrationUnreachableServer(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MemcachedServers = []string{"127.0.0.1:1"}
+	cfg.ConnTimeout = time.Second
+
+	_, err := NewMemcached[string](cfg)
+	if !errors.Is(err, base.ErrConnection) {
+		t.Fatalf("err = %v, want ErrConnection", err)
+	}
+	if !strings.Contains(err.Error(), "127.0.0.1:1") {
+		t.Errorf("error %q does not name the server", err)
+	}
+}
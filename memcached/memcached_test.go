@@ -0,0 +1,107 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// TestCanceledContext checks that operations give up on a canceled
+// context before talking to a server; the cache has no client at all.
+func TestCanceledContext(t *testing.T) {
+	m := &memcachedCache[string]{base: base.NewBase(config.DefaultConfig())}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := m.Exists(ctx, "k"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Exists = %v, want context.Canceled", err)
+	}
+	if err := m.Persist(ctx, "k"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Persist = %v, want context.Canceled", err)
+	}
+	if err := m.ExpireMany(ctx, []string{"a", "b"}, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExpireMany = %v, want context.Canceled", err)
+	}
+}
+
+func TestFullKeyHashesToMemcachedLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Prefix = strings.Repeat("p", 100) + ":"
+	cfg.MaxKeyLength = maxKeyLength
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := &memcachedCache[string]{base: base.NewBase(cfg)}
+
+	// 200 bytes is under the limit on its own but not behind the prefix.
+	fk, err := m.fullKey(base.OpSet, strings.Repeat("k", 200))
+	if err != nil {
+		t.Fatalf("fullKey = %v, want the key hashed to fit", err)
+	}
+	if !strings.HasPrefix(fk, cfg.Prefix+"sha256:") || len(fk) > maxKeyLength {
+		t.Errorf("fullKey = %q (%d bytes), want a hashed key within %d", fk, len(fk), maxKeyLength)
+	}
+}
+
+func TestGetManyPipelineFailsOnlyUnreachableServerKeys(t *testing.T) {
+	ctx := context.Background()
+	live := newFakeServer(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := ln.Addr().String()
+	_ = ln.Close()
+
+	c, err := newClient([]string{live.addr, dead}, 2, time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.close() })
+	m := &memcachedCache[string]{
+		base:       base.NewBase(config.DefaultConfig()),
+		client:     c,
+		serializer: &base.JsonSerializer[string]{},
+	}
+
+	var keys, onLive, onDead []string
+	for i := 0; len(onLive) < 3 || len(onDead) < 3; i++ {
+		k := fmt.Sprintf("k%d", i)
+		fk, _ := m.fullKey(base.OpGet, k)
+		keys = append(keys, k)
+		if m.client.pick(fk).addr == live.addr {
+			onLive = append(onLive, k)
+		} else {
+			onDead = append(onDead, k)
+		}
+	}
+	for _, k := range onLive[1:] {
+		if err := m.Set(ctx, k, "v", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := m.GetManyPipeline(ctx, keys)
+	if len(got) != len(onLive)-1 {
+		t.Errorf("GetManyPipeline = %v, want the %d keys stored on the live server", got, len(onLive)-1)
+	}
+	var multi *base.MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("err = %v, want a *MultiError", err)
+	}
+	if len(multi.Errors) != len(onDead) {
+		t.Errorf("failed keys = %v, want only %v", multi.Errors, onDead)
+	}
+	for _, k := range onDead {
+		if !errors.Is(multi.Errors[k], base.ErrConnection) {
+			t.Errorf("error for %s = %v, want ErrConnection", k, multi.Errors[k])
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package memcached
+
+import "github.com/os-golib/go-cache/internal/base"
+
+/* ------------------ Options ------------------ */
+
+// Option customizes a memcached cache at construction time.
+type Option[T any] func(*memcachedCache[T])
+
+// WithSerializer replaces the default JSON serializer.
+func WithSerializer[T any](s base.Serializer[T]) Option[T] {
+	return func(m *memcachedCache[T]) {
+		if s != nil {
+			m.serializer = s
+		}
+	}
+}
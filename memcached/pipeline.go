@@ -0,0 +1,69 @@
+package memcached
+
+import (
+	"context"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Pipeline: GET ------------------ */
+
+// GetManyPipeline reads keys with one multi-key get per server. Misses
+// are simply absent; keys that fail, including every key of a server
+// that cannot be reached, come back as a *base.MultiError next to the
+// partial result.
+func (m *memcachedCache[T]) GetManyPipeline(ctx context.Context, keys []string) (map[string]T, error) {
+	if err := m.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]T, len(keys))
+	failed := make(map[string]error)
+
+	logical := make(map[string]string, len(keys))
+	byServer := make(map[*server][]string)
+	for _, k := range keys {
+		fk, err := m.fullKey(base.OpGetManyPipeline, k)
+		if err != nil {
+			failed[k] = err
+			continue
+		}
+		if _, dup := logical[fk]; dup {
+			continue
+		}
+		logical[fk] = k
+		s := m.client.pick(fk)
+		byServer[s] = append(byServer[s], fk)
+	}
+
+	for s, batch := range byServer {
+		err := m.client.getMulti(ctx, s, batch, func(fk string, data []byte) {
+			k, ok := logical[fk]
+			if !ok {
+				return
+			}
+			val, err := m.decode(k, data)
+			switch {
+			case err == nil:
+				result[k] = val
+				m.base.FireHit(k)
+			case !base.IsCacheMiss(err):
+				failed[k] = err
+			}
+		})
+		if err != nil {
+			for _, fk := range batch {
+				failed[logical[fk]] = base.WrapError(base.OpGetManyPipeline, err, logical[fk])
+			}
+		}
+	}
+
+	for _, k := range logical {
+		if _, ok := result[k]; !ok {
+			if _, bad := failed[k]; !bad {
+				m.base.FireMiss(k)
+			}
+		}
+	}
+	return result, base.NewMultiError(failed)
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Memoization ------------------ */
+
+// Memoize wraps fn so its results are cached in c under keyFn(arg) for
+// ttl, through GetOrSet. Concurrent calls with the same key share one
+// lookup and at most one call to fn; they all get its result, computed
+// with the context of the call that started it. If fn panics, the callers
+// sharing its call get a *base.PanicError and the panic carries on in the
+// call that ran it.
+func Memoize[A comparable, R any](
+	c interfaces.AdvancedCache[R],
+	keyFn func(A) string,
+	ttl time.Duration,
+	fn func(context.Context, A) (R, error),
+) func(context.Context, A) (R, error) {
+	var g base.FlightGroup[R]
+
+	return func(ctx context.Context, arg A) (R, error) {
+		key := keyFn(arg)
+		return g.Do(key, func() (R, error) {
+			return c.GetOrSet(ctx, key, ttl, func() (R, error) {
+				return fn(ctx, arg)
+			})
+		})
+	}
+}
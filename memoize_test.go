@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestMemoizeSharesConcurrentCalls(t *testing.T) {
+	ctx := context.Background()
+	c := newTestAdvanced[int](t)
+
+	var calls atomic.Int64
+	release := make(chan struct{})
+	square := Memoize(c, strconv.Itoa, time.Minute, func(_ context.Context, n int) (int, error) {
+		calls.Add(1)
+		<-release
+		return n * n, nil
+	})
+
+	const callers = 50
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = square(ctx, 7)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn ran %d times for %d concurrent calls, want 1", n, callers)
+	}
+	for i, r := range results {
+		if r != 49 {
+			t.Fatalf("caller %d got %d, want 49", i, r)
+		}
+	}
+
+	// Later calls are served from the cache; other arguments compute.
+	if v, err := square(ctx, 7); err != nil || v != 49 {
+		t.Fatalf("cached call = %d, %v", v, err)
+	}
+	if v, err := square(ctx, 3); err != nil || v != 9 {
+		t.Fatalf("square(3) = %d, %v", v, err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("fn ran %d times, want 2", n)
+	}
+}
+
+func TestMemoizeDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newTestAdvanced[string](t)
+
+	calls := 0
+	errDown := errors.New("source down")
+	lookup := Memoize(c, func(id int) string { return "user:" + strconv.Itoa(id) }, time.Minute,
+		func(_ context.Context, id int) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", errDown
+			}
+			return "ann", nil
+		})
+
+	if _, err := lookup(ctx, 1); !errors.Is(err, errDown) {
+		t.Fatalf("first call = %v, want the source error", err)
+	}
+	if v, err := lookup(ctx, 1); err != nil || v != "ann" {
+		t.Fatalf("retry = %q, %v", v, err)
+	}
+	if _, err := c.Get(ctx, "user:1"); err != nil {
+		t.Errorf("result not cached under the derived key: %v", err)
+	}
+}
+
+func TestMemoizePanicReleasesSharedCallers(t *testing.T) {
+	ctx := context.Background()
+	c := newTestAdvanced[int](t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	boom := Memoize(c, strconv.Itoa, time.Minute, func(context.Context, int) (int, error) {
+		close(started)
+		<-release
+		panic("boom")
+	})
+
+	leader := make(chan any, 1)
+	go func() {
+		defer func() { leader <- recover() }()
+		_, _ = boom(ctx, 1)
+	}()
+	<-started
+
+	shared := make(chan error, 1)
+	go func() {
+		_, err := boom(ctx, 1)
+		shared <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if r := <-leader; r == nil {
+		t.Error("the call running fn did not panic")
+	}
+	select {
+	case err := <-shared:
+		var pe *base.PanicError
+		if !errors.As(err, &pe) || pe.Value != "boom" {
+			t.Errorf("shared call = %v, want a PanicError", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shared call never returned after the panic")
+	}
+}
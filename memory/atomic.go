@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+/* ------------------ Read-Modify-Write ------------------ */
+
+// Update runs fn on key's current value and stores the result, all under
+// the write lock, so concurrent updates are never lost. fn must not call
+// back into the cache. An error from fn leaves the entry untouched.
+func (c *memoryCache[T]) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) error {
+	if err := c.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := c.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	expired, evicted, err := c.update(key, c.base.WriteTTL(ctx, ttl), fn)
+	if expired {
+		c.base.FireExpire(key)
+	}
+	c.fireEvictions(evicted)
+	return err
+}
+
+func (c *memoryCache[T]) update(
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) (expired bool, evicted []eviction, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var cur T
+	found := false
+	if elem, ok := c.items[c.base.FullKey(key)]; ok {
+		it := elem.Value.(*memoryItem[T])
+		if c.expired(it) {
+			expired = c.expire(elem)
+		} else {
+			cur, found = it.value, true
+		}
+	}
+
+	next, err := fn(cur, found)
+	if err != nil {
+		return expired, nil, err
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	return expired, c.storeLocked(key, next, expiresAt), nil
+}
+
+/* ------------------ Compare And Swap ------------------ */
+
+// CompareAndSwap stores newVal only if key holds a value deeply equal to
+// oldVal. Missing and expired keys never match.
+func (c *memoryCache[T]) CompareAndSwap(
+	ctx context.Context,
+	key string,
+	oldVal, newVal T,
+	ttl time.Duration,
+) (bool, error) {
+	swapped := false
+	err := c.Update(ctx, key, ttl, func(cur T, found bool) (T, error) {
+		if !found || !reflect.DeepEqual(cur, oldVal) {
+			return cur, errNoSwap
+		}
+		swapped = true
+		return newVal, nil
+	})
+	if err == errNoSwap {
+		return false, nil
+	}
+	return swapped, err
+}
+
+// errNoSwap aborts the update behind a failed CompareAndSwap.
+var errNoSwap = errors.New("no swap")
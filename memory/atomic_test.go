@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* ------------------ Update ------------------ */
+
+func TestUpdateConcurrentAppendsLoseNothing(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[[]int](t, testConfig())
+
+	const workers, each = 50, 20
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range each {
+				err := c.Update(ctx, "list", time.Minute, func(cur []int, _ bool) ([]int, error) {
+					return append(slices.Clone(cur), w*each+i), nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := c.Get(ctx, "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != workers*each {
+		t.Fatalf("list has %d items, want %d", len(got), workers*each)
+	}
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("item %d = %d, updates lost or duplicated", i, v)
+		}
+	}
+}
+
+func TestUpdateReportsFoundAndKeepsEntryOnError(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+
+	var sawFound []bool
+	inc := func(cur int, found bool) (int, error) {
+		sawFound = append(sawFound, found)
+		return cur + 1, nil
+	}
+	_ = c.Update(ctx, "n", time.Minute, inc)
+	_ = c.Update(ctx, "n", time.Minute, inc)
+	if !slices.Equal(sawFound, []bool{false, true}) {
+		t.Errorf("found = %v, want [false true]", sawFound)
+	}
+
+	errVeto := errors.New("veto")
+	err := c.Update(ctx, "n", time.Minute, func(int, bool) (int, error) { return 100, errVeto })
+	if !errors.Is(err, errVeto) {
+		t.Errorf("Update = %v, want fn's error", err)
+	}
+	if v, _ := c.Get(ctx, "n"); v != 2 {
+		t.Errorf("n = %d after a failed update, want 2", v)
+	}
+
+	// An expired entry is passed to fn as not found.
+	_ = c.Set(ctx, "old", 5, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_ = c.Update(ctx, "old", time.Minute, func(cur int, found bool) (int, error) {
+		if found || cur != 0 {
+			t.Errorf("expired entry passed as %d, found %v", cur, found)
+		}
+		return 1, nil
+	})
+}
+
+/* ------------------ CompareAndSwap ------------------ */
+
+func TestCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	type state struct{ Owner string }
+	c := newTestCache[state](t, testConfig())
+	_ = c.Set(ctx, "k", state{"a"}, time.Minute)
+
+	if ok, err := c.CompareAndSwap(ctx, "k", state{"a"}, state{"b"}, time.Minute); err != nil || !ok {
+		t.Errorf("matching swap = %v, %v", ok, err)
+	}
+	if ok, err := c.CompareAndSwap(ctx, "k", state{"a"}, state{"c"}, time.Minute); err != nil || ok {
+		t.Errorf("mismatched swap = %v, %v; want false, nil", ok, err)
+	}
+	if v, _ := c.Get(ctx, "k"); v.Owner != "b" {
+		t.Errorf("value = %+v, want b", v)
+	}
+
+	if ok, err := c.CompareAndSwap(ctx, "missing", state{}, state{"x"}, time.Minute); err != nil || ok {
+		t.Errorf("swap on a missing key = %v, %v; want false, nil", ok, err)
+	}
+	if ok, _ := c.Exists(ctx, "missing"); ok {
+		t.Error("failed swap created the key")
+	}
+
+	_ = c.Set(ctx, "old", state{"a"}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := c.CompareAndSwap(ctx, "old", state{"a"}, state{"b"}, time.Minute); ok {
+		t.Error("swap matched an expired entry")
+	}
+}
+
+func TestCompareAndSwapSingleWinner(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+	_ = c.Set(ctx, "k", 0, time.Minute)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	winners := 0
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := c.CompareAndSwap(ctx, "k", 0, i+1, time.Minute); ok {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if winners != 1 {
+		t.Errorf("%d swaps won, want 1", winners)
+	}
+}
@@ -3,20 +3,29 @@ package memory
 import (
 	"container/list"
 	"sync"
+	"time"
 )
 
 // entry holds the key-value pair in the LRU
 type entry[K comparable, V any] struct {
-	key   K
-	value V
+	key       K
+	value     V
+	expiresAt time.Time // zero means never
 }
 
-// LRU implements a thread-safe LRU cache without expiration
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// LRU implements a thread-safe LRU cache. Entries never expire unless
+// written with SetWithTTL; expired entries are dropped lazily on Get or by
+// the sweeper started with StartSweeper.
 type LRU[K comparable, V any] struct {
 	capacity int
 	list     *list.List
 	items    map[K]*list.Element
 	mu       sync.RWMutex
+	onEvict  func(K, V)
 }
 
 // NewLRU creates a new LRU cache
@@ -28,41 +37,132 @@ func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
 	}
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache, removing it if it has expired
 func (l *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
 	l.mu.Lock()
-	defer l.mu.Unlock()
 
 	elem, ok := l.items[key]
 	if !ok {
-		var zero V
+		l.mu.Unlock()
+		return zero, false
+	}
+
+	e := elem.Value.(*entry[K, V])
+	if e.expired(time.Now()) {
+		l.removeElement(elem)
+		onEvict := l.onEvict
+		l.mu.Unlock()
+		notify(onEvict, []*entry[K, V]{e})
 		return zero, false
 	}
 
 	l.list.MoveToFront(elem)
-	return elem.Value.(*entry[K, V]).value, true
+	l.mu.Unlock()
+	return e.value, true
 }
 
-// Set adds or updates a value in the cache
+// Set adds or updates a value in the cache; it never expires
 func (l *LRU[K, V]) Set(key K, value V) {
+	l.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL adds or updates a value that expires after ttl. A ttl <= 0
+// never expires.
+func (l *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
 	l.mu.Lock()
-	defer l.mu.Unlock()
 
 	// Update existing
 	if elem, ok := l.items[key]; ok {
 		l.list.MoveToFront(elem)
-		elem.Value.(*entry[K, V]).value = value
+		e := elem.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		l.mu.Unlock()
 		return
 	}
 
 	// Evict if at capacity
+	var evicted []*entry[K, V]
 	if l.list.Len() == l.capacity {
-		l.evict()
+		if e := l.evict(); e != nil {
+			evicted = append(evicted, e)
+		}
 	}
 
 	// Add new
-	elem := l.list.PushFront(&entry[K, V]{key, value})
+	elem := l.list.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
 	l.items[key] = elem
+	onEvict := l.onEvict
+	l.mu.Unlock()
+
+	notify(onEvict, evicted)
+}
+
+// SetOnEvict registers fn to be called with each entry evicted for
+// capacity or dropped after expiring, after the lock is released. Delete,
+// RemoveOldest and Clear do not call it. A nil fn removes the callback.
+func (l *LRU[K, V]) SetOnEvict(fn func(K, V)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onEvict = fn
+}
+
+// Resize changes the capacity, evicting least recently used entries if
+// the cache holds more than the new capacity. It returns how many were
+// evicted.
+func (l *LRU[K, V]) Resize(capacity int) int {
+	l.mu.Lock()
+	l.capacity = capacity
+
+	var evicted []*entry[K, V]
+	for capacity > 0 && l.list.Len() > capacity {
+		evicted = append(evicted, l.evict())
+	}
+	onEvict := l.onEvict
+	l.mu.Unlock()
+
+	notify(onEvict, evicted)
+	return len(evicted)
+}
+
+// GetOldest returns the least recently used entry without updating its
+// access time
+func (l *LRU[K, V]) GetOldest() (K, V, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	elem := l.list.Back()
+	if elem == nil {
+		var (
+			zk K
+			zv V
+		)
+		return zk, zv, false
+	}
+	e := elem.Value.(*entry[K, V])
+	return e.key, e.value, true
+}
+
+// RemoveOldest removes and returns the least recently used entry
+func (l *LRU[K, V]) RemoveOldest() (K, V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := l.evict()
+	if e == nil {
+		var (
+			zk K
+			zv V
+		)
+		return zk, zv, false
+	}
+	return e.key, e.value, true
 }
 
 // Delete removes a key from the cache
@@ -75,12 +175,55 @@ func (l *LRU[K, V]) Delete(key K) bool {
 		return false
 	}
 
-	l.list.Remove(elem)
-	delete(l.items, key)
+	l.removeElement(elem)
 	return true
 }
 
-// Len returns the number of items in the cache
+// DeleteExpired removes every expired entry and returns how many it
+// removed.
+func (l *LRU[K, V]) DeleteExpired() int {
+	now := time.Now()
+	var expired []*entry[K, V]
+
+	l.mu.Lock()
+	for _, elem := range l.items {
+		if e := elem.Value.(*entry[K, V]); e.expired(now) {
+			l.removeElement(elem)
+			expired = append(expired, e)
+		}
+	}
+	onEvict := l.onEvict
+	l.mu.Unlock()
+
+	notify(onEvict, expired)
+	return len(expired)
+}
+
+// StartSweeper runs DeleteExpired every interval until the returned stop
+// function is called. It is only needed when entries use SetWithTTL and
+// may never be read again.
+func (l *LRU[K, V]) StartSweeper(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				l.DeleteExpired()
+			}
+		}
+	}()
+
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// Len returns the number of items in the cache, including expired ones
+// not removed yet
 func (l *LRU[K, V]) Len() int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -96,22 +239,41 @@ func (l *LRU[K, V]) Clear() {
 	l.items = make(map[K]*list.Element)
 }
 
-// evict removes the least recently used item (must hold write lock)
-func (l *LRU[K, V]) evict() {
+// evict removes and returns the least recently used item, or nil if the
+// cache is empty (must hold write lock)
+func (l *LRU[K, V]) evict() *entry[K, V] {
 	elem := l.list.Back()
-	if elem != nil {
-		l.list.Remove(elem)
-		delete(l.items, elem.Value.(*entry[K, V]).key)
+	if elem == nil {
+		return nil
+	}
+	l.removeElement(elem)
+	return elem.Value.(*entry[K, V])
+}
+
+// removeElement unlinks elem (must hold write lock)
+func (l *LRU[K, V]) removeElement(elem *list.Element) {
+	l.list.Remove(elem)
+	delete(l.items, elem.Value.(*entry[K, V]).key)
+}
+
+// notify reports evicted entries to fn (called without the lock held)
+func notify[K comparable, V any](fn func(K, V), evicted []*entry[K, V]) {
+	if fn == nil {
+		return
+	}
+	for _, e := range evicted {
+		fn(e.key, e.value)
 	}
 }
 
-// Peek retrieves a value without updating access time
+// Peek retrieves a value without updating access time; expired entries
+// are reported missing
 func (l *LRU[K, V]) Peek(key K) (V, bool) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
 	elem, ok := l.items[key]
-	if !ok {
+	if !ok || elem.Value.(*entry[K, V]).expired(time.Now()) {
 		var zero V
 		return zero, false
 	}
@@ -119,14 +281,18 @@ func (l *LRU[K, V]) Peek(key K) (V, bool) {
 	return elem.Value.(*entry[K, V]).value, true
 }
 
-// Keys returns all keys in the cache (from most to least recently used)
+// Keys returns all unexpired keys in the cache (from most to least
+// recently used)
 func (l *LRU[K, V]) Keys() []K {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
+	now := time.Now()
 	keys := make([]K, 0, l.list.Len())
 	for elem := l.list.Front(); elem != nil; elem = elem.Next() {
-		keys = append(keys, elem.Value.(*entry[K, V]).key)
+		if e := elem.Value.(*entry[K, V]); !e.expired(now) {
+			keys = append(keys, e.key)
+		}
 	}
 	return keys
 }
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type evicted struct {
+	key   string
+	value int
+}
+
+// evictLog records OnEvict calls; the sweeper makes them from its own
+// goroutine.
+type evictLog struct {
+	mu  sync.Mutex
+	got []evicted
+}
+
+func (e *evictLog) list() []evicted {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.got)
+}
+
+// recordEvictions registers an OnEvict callback on l that records each
+// call. The callback reads l, which deadlocks if it runs under the lock.
+func recordEvictions(l *LRU[string, int]) *evictLog {
+	log := &evictLog{}
+	l.SetOnEvict(func(k string, v int) {
+		_ = l.Len()
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.got = append(log.got, evicted{k, v})
+	})
+	return log
+}
+
+func TestLRUOnEvictAtCapacity(t *testing.T) {
+	l := NewLRU[string, int](2)
+	got := recordEvictions(l)
+
+	l.Set("a", 1)
+	l.Set("b", 2)
+	l.Get("a")
+	l.Set("c", 3)
+
+	if want := []evicted{{"b", 2}}; !slices.Equal(got.list(), want) {
+		t.Errorf("evicted = %v, want %v", got.list(), want)
+	}
+	// Overwrites do not evict.
+	l.Set("c", 30)
+	if len(got.list()) != 1 {
+		t.Errorf("evicted = %v after an overwrite", got.list())
+	}
+}
+
+func TestLRUResize(t *testing.T) {
+	l := NewLRU[string, int](4)
+	got := recordEvictions(l)
+	for i, k := range []string{"a", "b", "c", "d"} {
+		l.Set(k, i)
+	}
+
+	if n := l.Resize(2); n != 2 {
+		t.Errorf("Resize(2) evicted %d, want 2", n)
+	}
+	if want := []evicted{{"a", 0}, {"b", 1}}; !slices.Equal(got.list(), want) {
+		t.Errorf("evicted = %v, want %v", got.list(), want)
+	}
+	if keys := l.Keys(); !slices.Equal(keys, []string{"d", "c"}) {
+		t.Errorf("Keys = %v, want [d c]", keys)
+	}
+
+	// Growing evicts nothing and makes room for more entries.
+	if n := l.Resize(3); n != 0 {
+		t.Errorf("Resize(3) evicted %d", n)
+	}
+	l.Set("e", 4)
+	if l.Len() != 3 || len(got.list()) != 2 {
+		t.Errorf("Len = %d, evicted = %v after growing", l.Len(), got.list())
+	}
+}
+
+func TestLRUOldest(t *testing.T) {
+	l := NewLRU[string, int](3)
+	got := recordEvictions(l)
+
+	if _, _, ok := l.GetOldest(); ok {
+		t.Error("GetOldest on an empty LRU reported an entry")
+	}
+	if _, _, ok := l.RemoveOldest(); ok {
+		t.Error("RemoveOldest on an empty LRU reported an entry")
+	}
+
+	l.Set("a", 1)
+	l.Set("b", 2)
+	if k, v, ok := l.GetOldest(); !ok || k != "a" || v != 1 {
+		t.Errorf("GetOldest = %q, %d, %v; want a, 1", k, v, ok)
+	}
+	// GetOldest does not count as a use.
+	if k, _, _ := l.GetOldest(); k != "a" {
+		t.Errorf("GetOldest = %q after peeking, want a", k)
+	}
+
+	if k, v, ok := l.RemoveOldest(); !ok || k != "a" || v != 1 {
+		t.Errorf("RemoveOldest = %q, %d, %v; want a, 1", k, v, ok)
+	}
+	if _, ok := l.Peek("a"); ok || l.Len() != 1 {
+		t.Error("RemoveOldest left the entry behind")
+	}
+	if len(got.list()) != 0 {
+		t.Errorf("RemoveOldest fired OnEvict: %v", got.list())
+	}
+}
+
+func TestLRUSetOnEvictNilRemovesCallback(t *testing.T) {
+	l := NewLRU[string, int](1)
+	got := recordEvictions(l)
+	l.SetOnEvict(nil)
+
+	l.Set("a", 1)
+	l.Set("b", 2)
+	if len(got.list()) != 0 {
+		t.Errorf("evicted = %v after removing the callback", got.list())
+	}
+}
+
+func TestLRUExpiresOnGet(t *testing.T) {
+	l := NewLRU[string, int](4)
+	got := recordEvictions(l)
+
+	l.SetWithTTL("short", 1, 10*time.Millisecond)
+	l.SetWithTTL("forever", 2, 0)
+	l.Set("plain", 3)
+	if v, ok := l.Get("short"); !ok || v != 1 {
+		t.Fatalf("Get(short) = %d, %v before expiry", v, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := l.Peek("short"); ok {
+		t.Error("Peek returned an expired entry")
+	}
+	if _, ok := l.Get("short"); ok {
+		t.Error("Get returned an expired entry")
+	}
+	if l.Len() != 2 {
+		t.Errorf("Len = %d, want the expired entry removed by Get", l.Len())
+	}
+	if want := []evicted{{"short", 1}}; !slices.Equal(got.list(), want) {
+		t.Errorf("evicted = %v, want %v", got.list(), want)
+	}
+	for _, k := range []string{"forever", "plain"} {
+		if _, ok := l.Get(k); !ok {
+			t.Errorf("Get(%s) missing; a zero TTL never expires", k)
+		}
+	}
+}
+
+func TestLRUOverwriteResetsTTL(t *testing.T) {
+	l := NewLRU[string, int](2)
+	l.SetWithTTL("k", 1, 10*time.Millisecond)
+	l.Set("k", 2)
+	time.Sleep(20 * time.Millisecond)
+	if v, ok := l.Get("k"); !ok || v != 2 {
+		t.Errorf("Get = %d, %v; the overwrite should never expire", v, ok)
+	}
+}
+
+func TestLRUSweeperRemovesExpired(t *testing.T) {
+	l := NewLRU[string, int](8)
+	got := recordEvictions(l)
+	for _, k := range []string{"a", "b", "c"} {
+		l.SetWithTTL(k, 1, 5*time.Millisecond)
+	}
+	l.Set("keep", 1)
+
+	stop := l.StartSweeper(5 * time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(time.Second)
+	for len(got.list()) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("evicted = %v, sweeper never removed the expired entries", got.list())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if keys := l.Keys(); !slices.Equal(keys, []string{"keep"}) {
+		t.Errorf("Keys = %v, want [keep]", keys)
+	}
+	stop()
+	stop() // stopping twice is safe
+}
+
+func TestLRUDeleteExpired(t *testing.T) {
+	l := NewLRU[string, int](4)
+	l.SetWithTTL("a", 1, time.Millisecond)
+	l.SetWithTTL("b", 1, time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	if keys := l.Keys(); !slices.Equal(keys, []string{"b"}) {
+		t.Errorf("Keys = %v, want expired entries hidden", keys)
+	}
+	if n := l.DeleteExpired(); n != 1 {
+		t.Errorf("DeleteExpired = %d, want 1", n)
+	}
+	if l.Len() != 1 {
+		t.Errorf("Len = %d, want 1", l.Len())
+	}
+}
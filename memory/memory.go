@@ -3,6 +3,8 @@ package memory
 import (
 	"container/list"
 	"context"
+	"path"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,8 +19,24 @@ import (
 
 type memoryItem[T any] struct {
 	key       string
+	name      string // key as given by the caller, for hooks
 	value     T
 	expiresAt time.Time
+	size      int64  // estimated, see estimateSize
+	cost      int64  // from costFn, or 1
+	touched   uint64 // memoryCache.clock at the last use
+
+	// tenant is nil unless the key's tenant has a quota; tenantElem is
+	// then the entry's place in the tenant's own list
+	tenant     *tenantUsage
+	tenantElem *list.Element
+}
+
+// eviction records an entry removed for capacity so its hook can fire
+// once the lock is released.
+type eviction struct {
+	key    string
+	reason config.EvictReason
 }
 
 type memoryCache[T any] struct {
@@ -29,11 +47,48 @@ type memoryCache[T any] struct {
 	stopCh   chan struct{}
 	capacity int
 	length   int64
+	bytes    int64
+
+	// lifetime counters reported in Stats
+	evictions   int64
+	expirations int64
+
+	// serializer encodes values for on-disk snapshots
+	serializer base.Serializer[T]
+
+	// cost-based capacity: entries are weighed by costFn and evicted
+	// until their total (guarded by mu) fits maxCost
+	costFn  func(T) int64
+	maxCost int64
+	cost    int64
+
+	// cleanup loop; retuned through intervalCh by Reconfigure
+	loopCtx     context.Context
+	loopMu      sync.Mutex
+	loopRunning bool
+	intervalCh  chan time.Duration
+
+	health metrics.HealthTracker
+
+	// how long expired entries stay readable through GetStale
+	staleGrace time.Duration
+
+	// tenant quota accounting (nil when no quotas are configured); clock
+	// orders uses across tenants' lists
+	tenantSep string
+	tenants   map[string]*tenantUsage
+	clock     uint64
 }
 
 /* ------------------ Constructor ------------------ */
 
-func NewMemory[T any](cfg config.Config) (*memoryCache[T], error) {
+func NewMemory[T any](cfg config.Config, opts ...Option[T]) (*memoryCache[T], error) {
+	return NewMemoryWithContext[T](context.Background(), cfg, opts...)
+}
+
+// NewMemoryWithContext ties the cleanup loop to ctx: it stops when ctx is
+// done or on Close, whichever comes first. The cache itself stays usable.
+func NewMemoryWithContext[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (*memoryCache[T], error) {
 	if cfg.EvictionPolicy != "" && cfg.EvictionPolicy != config.EvictLRU {
 		return nil, base.WrapError(base.OpSet, base.ErrInvalidConfig, "")
 	}
@@ -44,12 +99,34 @@ func NewMemory[T any](cfg config.Config) (*memoryCache[T], error) {
 		lru:      list.New(),
 		stopCh:   make(chan struct{}),
 		capacity: cfg.MaxSize,
+		maxCost:  cfg.MaxCost,
+
+		staleGrace: cfg.StaleGrace,
+
+		serializer: &base.JsonSerializer[T]{},
+		loopCtx:    ctx,
+		intervalCh: make(chan time.Duration, 1),
+	}
+	for _, opt := range opts {
+		opt(mc)
 	}
 
-	if cfg.CleanupInterval > 0 {
-		go mc.cleanupLoop(context.Background(), cfg.CleanupInterval)
+	if len(cfg.TenantQuotas) > 0 {
+		mc.tenantSep = cfg.TenantSeparator
+		if mc.tenantSep == "" {
+			mc.tenantSep = ":"
+		}
+		mc.tenants = newTenantUsage(cfg.TenantQuotas)
 	}
 
+	if cfg.SnapshotPath != "" {
+		if err := mc.loadSnapshot(cfg.SnapshotPath); err != nil {
+			return nil, err
+		}
+	}
+
+	mc.setCleanupInterval(cfg.CleanupInterval)
+
 	return mc, nil
 }
 
@@ -64,24 +141,153 @@ func (c *memoryCache[T]) remove(elem *list.Element) {
 	item := elem.Value.(*memoryItem[T])
 	delete(c.items, item.key)
 	atomic.AddInt64(&c.length, -1)
+	atomic.AddInt64(&c.bytes, -item.size)
+	c.cost -= item.cost
+	c.removeTenant(item)
+}
+
+// expire removes an expired entry and counts it. It reports false when
+// the entry is already gone, e.g. removed by a concurrent reader, or is
+// kept for GetStale because it expired less than staleGrace ago.
+func (c *memoryCache[T]) expire(elem *list.Element) bool {
+	it := elem.Value.(*memoryItem[T])
+	if c.items[it.key] != elem {
+		return false
+	}
+	if c.staleGrace > 0 && time.Now().Before(it.expiresAt.Add(c.staleGrace)) {
+		return false
+	}
+	c.remove(elem)
+	atomic.AddInt64(&c.expirations, 1)
+	return true
+}
+
+// evict removes the least recently used entry other than keep, preferring
+// entries of tenants that exceed their quota, and returns the removed item
+// (nil if there was none to remove).
+func (c *memoryCache[T]) evict(keep *list.Element) (*memoryItem[T], config.EvictReason) {
+	e, reason := c.quotaVictim(keep), config.EvictQuota
+	if e == nil {
+		e, reason = c.lru.Back(), config.EvictCapacity
+	}
+	if e == nil || e == keep {
+		return nil, ""
+	}
+	c.remove(e)
+	atomic.AddInt64(&c.evictions, 1)
+	return e.Value.(*memoryItem[T]), reason
 }
 
-func (c *memoryCache[T]) evict() {
-	if e := c.lru.Back(); e != nil {
-		c.remove(e)
+// costOf weighs value with the configured cost function; without one
+// every entry costs 1.
+func (c *memoryCache[T]) costOf(value T) int64 {
+	if c.costFn == nil {
+		return 1
 	}
+	return max(c.costFn(value), 0)
+}
+
+// shed evicts least recently used entries until the total cost fits
+// maxCost. keep, the entry just written, is never evicted, so a single
+// entry costlier than maxCost stays cached on its own.
+func (c *memoryCache[T]) shed(keep *list.Element) []eviction {
+	var evicted []eviction
+	for c.maxCost > 0 && c.cost > c.maxCost {
+		it, reason := c.evict(keep)
+		if it == nil {
+			break
+		}
+		evicted = append(evicted, eviction{it.name, reason})
+	}
+	return evicted
 }
 
 /* ------------------ Cache API ------------------ */
 
 func (c *memoryCache[T]) Get(ctx context.Context, key string) (T, error) {
+	val, _, err := c.get(ctx, key)
+	return val, err
+}
+
+// Persist removes key's expiry so it never expires. It returns
+// ErrCacheMiss if key is absent or already expired.
+func (c *memoryCache[T]) Persist(ctx context.Context, key string) error {
+	if err := c.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := c.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	elem, ok := c.items[c.base.FullKey(key)]
+	if !ok {
+		c.mu.Unlock()
+		return base.WrapError(base.OpPersist, base.ErrCacheMiss, key)
+	}
+	it := elem.Value.(*memoryItem[T])
+	if c.expired(it) {
+		removed := c.expire(elem)
+		c.mu.Unlock()
+		if removed {
+			c.base.FireExpire(key)
+		}
+		return base.WrapError(base.OpPersist, base.ErrCacheMiss, key)
+	}
+	it.expiresAt = time.Time{}
+	c.mu.Unlock()
+	return nil
+}
+
+// GetStale returns the value for key even if it has expired, as long as
+// it has not been removed yet; stale reports whether it had. Expired
+// entries are kept for cfg.StaleGrace, or until the next read or cleanup
+// pass without it. Only absent keys yield ErrCacheMiss.
+func (c *memoryCache[T]) GetStale(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+
+	if err := c.base.ValidateKey(key); err != nil {
+		return zero, false, err
+	}
+	if err := c.base.CheckContext(ctx); err != nil {
+		return zero, false, err
+	}
+
+	c.mu.RLock()
+	elem, ok := c.items[c.base.FullKey(key)]
+	if !ok {
+		c.mu.RUnlock()
+		c.base.FireMiss(key)
+		return zero, false, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	it := elem.Value.(*memoryItem[T])
+	val, stale := it.value, c.expired(it)
+	c.mu.RUnlock()
+
+	if !stale {
+		c.base.FireHit(key)
+	}
+	return val, stale, nil
+}
+
+// GetWithTTL returns the value and its remaining TTL (0 if it never
+// expires).
+func (c *memoryCache[T]) GetWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	val, expiresAt, err := c.get(ctx, key)
+	if err != nil || expiresAt.IsZero() {
+		return val, 0, err
+	}
+	return val, max(time.Until(expiresAt), time.Nanosecond), nil
+}
+
+func (c *memoryCache[T]) get(ctx context.Context, key string) (T, time.Time, error) {
 	var zero T
 
 	if err := c.base.ValidateKey(key); err != nil {
-		return zero, err
+		return zero, time.Time{}, err
 	}
 	if err := c.base.CheckContext(ctx); err != nil {
-		return zero, err
+		return zero, time.Time{}, err
 	}
 
 	fk := c.base.FullKey(key)
@@ -90,24 +296,33 @@ func (c *memoryCache[T]) Get(ctx context.Context, key string) (T, error) {
 	elem, ok := c.items[fk]
 	if !ok {
 		c.mu.RUnlock()
-		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+		c.base.FireMiss(key)
+		return zero, time.Time{}, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
 	}
 
 	item := elem.Value.(*memoryItem[T])
 	if c.expired(item) {
 		c.mu.RUnlock()
 		c.mu.Lock()
-		c.remove(elem)
+		removed := c.expire(elem)
 		c.mu.Unlock()
-		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+		if removed {
+			c.base.FireExpire(key)
+		}
+		c.base.FireMiss(key)
+		return zero, time.Time{}, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
 	}
+	val, expiresAt := item.value, item.expiresAt
 	c.mu.RUnlock()
 
 	c.mu.Lock()
-	c.lru.MoveToFront(elem)
+	if c.items[item.key] == elem {
+		c.touch(elem)
+	}
 	c.mu.Unlock()
 
-	return item.value, nil
+	c.base.FireHit(key)
+	return val, expiresAt, nil
 }
 
 func (c *memoryCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
@@ -118,52 +333,103 @@ func (c *memoryCache[T]) Set(ctx context.Context, key string, value T, ttl time.
 		return err
 	}
 
-	fk := c.base.FullKey(key)
-	ttl = c.base.ResolveTTL(ttl)
+	ttl = c.base.WriteTTL(ctx, ttl)
 
 	var expiresAt time.Time
 	if ttl > 0 {
 		expiresAt = time.Now().Add(ttl)
 	}
 
+	c.store(key, value, expiresAt)
+	return nil
+}
+
+// store inserts or replaces key with an absolute expiry, evicting if the
+// cache is full.
+func (c *memoryCache[T]) store(key string, value T, expiresAt time.Time) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	evicted := c.storeLocked(key, value, expiresAt)
+	c.mu.Unlock()
+
+	c.fireEvictions(evicted)
+}
+
+// storeLocked does the work of store with c.mu held and returns the
+// entries it evicted, so the caller can fire hooks after unlocking.
+func (c *memoryCache[T]) storeLocked(key string, value T, expiresAt time.Time) []eviction {
+	fk := c.base.FullKey(key)
+	cost := c.costOf(value)
 
 	if elem, ok := c.items[fk]; ok {
 		it := elem.Value.(*memoryItem[T])
+		size := c.estimateSize(fk, key, value)
+		atomic.AddInt64(&c.bytes, size-it.size)
+		if it.tenant != nil {
+			it.tenant.bytes += size - it.size
+		}
+		c.cost += cost - it.cost
 		it.value = value
 		it.expiresAt = expiresAt
-		c.lru.MoveToFront(elem)
-		return nil
+		it.size = size
+		it.cost = cost
+		c.touch(elem)
+		return c.shed(elem)
 	}
 
+	var evicted []eviction
 	if c.capacity > 0 && int(atomic.LoadInt64(&c.length)) >= c.capacity {
-		c.evict()
+		if it, reason := c.evict(nil); it != nil {
+			evicted = append(evicted, eviction{it.name, reason})
+		}
 	}
 
-	it := &memoryItem[T]{key: fk, value: value, expiresAt: expiresAt}
+	it := &memoryItem[T]{
+		key:       fk,
+		name:      key,
+		value:     value,
+		expiresAt: expiresAt,
+		tenant:    c.tenantOf(key),
+		size:      c.estimateSize(fk, key, value),
+		cost:      cost,
+	}
 	elem := c.lru.PushFront(it)
 	c.items[fk] = elem
 	atomic.AddInt64(&c.length, 1)
+	atomic.AddInt64(&c.bytes, it.size)
+	c.cost += cost
+	c.addTenant(elem)
+	return append(evicted, c.shed(elem)...)
+}
 
-	return nil
+func (c *memoryCache[T]) fireEvictions(evicted []eviction) {
+	for _, e := range evicted {
+		c.base.FireEvict(e.key, e.reason)
+	}
 }
 
 func (c *memoryCache[T]) Delete(ctx context.Context, keys ...string) error {
+	_, err := c.DeleteMany(ctx, keys)
+	return err
+}
+
+// DeleteMany deletes keys and returns how many were present.
+func (c *memoryCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
 	if err := c.base.CheckContext(ctx); err != nil {
-		return err
+		return 0, err
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	var n int64
 	for _, k := range keys {
 		fk := c.base.FullKey(k)
 		if e, ok := c.items[fk]; ok {
 			c.remove(e)
+			n++
 		}
 	}
-	return nil
+	return n, nil
 }
 
 func (c *memoryCache[T]) Exists(_ context.Context, key string) (bool, error) {
@@ -174,18 +440,22 @@ func (c *memoryCache[T]) Exists(_ context.Context, key string) (bool, error) {
 	fk := c.base.FullKey(key)
 
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	elem, ok := c.items[fk]
 	if !ok {
+		c.mu.Unlock()
 		return false, nil
 	}
 
 	it := elem.Value.(*memoryItem[T])
 	if c.expired(it) {
-		c.remove(elem)
+		removed := c.expire(elem)
+		c.mu.Unlock()
+		if removed {
+			c.base.FireExpire(key)
+		}
 		return false, nil
 	}
+	c.mu.Unlock()
 	return true, nil
 }
 
@@ -200,6 +470,12 @@ func (c *memoryCache[T]) Clear(ctx context.Context) error {
 	c.items = make(map[string]*list.Element)
 	c.lru.Init()
 	atomic.StoreInt64(&c.length, 0)
+	atomic.StoreInt64(&c.bytes, 0)
+	c.cost = 0
+	for _, u := range c.tenants {
+		u.entries, u.bytes = 0, 0
+		u.lru.Init()
+	}
 	return nil
 }
 
@@ -211,7 +487,7 @@ func (c *memoryCache[T]) Len(ctx context.Context) (int, error) {
 }
 
 func (c *memoryCache[T]) DeleteByPrefix(_ context.Context, prefix string) (int64, error) {
-	fp := c.base.FullKey(prefix)
+	fp := c.base.FullPrefix(prefix)
 	var n int64
 
 	c.mu.Lock()
@@ -226,15 +502,174 @@ func (c *memoryCache[T]) DeleteByPrefix(_ context.Context, prefix string) (int64
 	return n, nil
 }
 
+// CountByPrefix counts the unexpired entries whose logical key starts with
+// prefix.
+func (c *memoryCache[T]) CountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var n int64
+	c.mu.RLock()
+	for _, e := range c.items {
+		it := e.Value.(*memoryItem[T])
+		if !it.expiresAt.IsZero() && !now.Before(it.expiresAt) {
+			continue
+		}
+		if strings.HasPrefix(it.name, prefix) {
+			n++
+		}
+	}
+	c.mu.RUnlock()
+	return n, nil
+}
+
+// DeleteByPattern deletes the entries whose logical key matches the glob
+// pattern, using path.Match syntax (unlike Redis, * does not match '/').
+func (c *memoryCache[T]) DeleteByPattern(_ context.Context, pattern string) (int64, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, base.WrapError(base.OpDeleteByPattern, base.ErrInvalidArgument, pattern)
+	}
+
+	var n int64
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.items {
+		it := e.Value.(*memoryItem[T])
+		if ok, _ := path.Match(pattern, it.name); ok {
+			c.remove(e)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Keys returns the unexpired keys, without the configured cache prefix,
+// that start with prefix (all keys when prefix is empty), sorted.
+// Negative-cache markers are left out.
+func (c *memoryCache[T]) Keys(ctx context.Context, prefix string) ([]string, error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.RLock()
+	keys := make([]string, 0, len(c.items))
+	for _, e := range c.items {
+		it := e.Value.(*memoryItem[T])
+		if !it.expiresAt.IsZero() && !now.Before(it.expiresAt) {
+			continue
+		}
+		if strings.HasPrefix(it.name, prefix) && !base.IsNegativeKey(it.name) {
+			keys = append(keys, it.name)
+		}
+	}
+	c.mu.RUnlock()
+
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Range calls fn with each unexpired entry, in no particular order, until
+// fn returns false. It walks a snapshot taken under the read lock, so fn may
+// use the cache freely.
+func (c *memoryCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	type kv struct {
+		key   string
+		value T
+	}
+
+	now := time.Now()
+	c.mu.RLock()
+	snap := make([]kv, 0, len(c.items))
+	for _, e := range c.items {
+		it := e.Value.(*memoryItem[T])
+		if it.expiresAt.IsZero() || now.Before(it.expiresAt) {
+			snap = append(snap, kv{it.name, it.value})
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, e := range snap {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !fn(e.key, e.value) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close stops the cleanup loop and, with SnapshotPath set, writes the
+// live entries to disk.
 func (c *memoryCache[T]) Close() error {
 	close(c.stopCh)
+
+	if path := c.base.Cfg.SnapshotPath; path != "" {
+		return c.saveSnapshot(path)
+	}
 	return nil
 }
 
+// StartedAt returns when the cache was created or last reconnected.
+func (c *memoryCache[T]) StartedAt() time.Time {
+	return c.base.StartedAt()
+}
+
 func (c *memoryCache[T]) Ping(ctx context.Context) error {
 	return c.base.CheckContext(ctx)
 }
 
+// Health reports the in-process cache as healthy; it has no connection
+// that could fail.
+func (c *memoryCache[T]) Health(ctx context.Context) metrics.HealthStatus {
+	start := time.Now()
+	if c.Ping(ctx) == nil {
+		c.health.Record(time.Since(start), nil)
+	}
+	return c.health.Status("memory")
+}
+
+/* ------------------ Reconfigure ------------------ */
+
+// Reconfigure applies the hot-reloadable fields of cfg to a running cache:
+// TTL and TTLJitter (used by later writes), CleanupInterval, MaxSize and
+// MaxCost (shrinking either evicts least recently used entries). Other fields are
+// ignored; changing them requires a new cache.
+func (c *memoryCache[T]) Reconfigure(cfg config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return base.WrapError(base.OpInit, err, "")
+	}
+
+	c.base.SetTTL(cfg.TTL, cfg.TTLJitter)
+	c.setCleanupInterval(cfg.CleanupInterval)
+
+	var evicted []eviction
+
+	c.mu.Lock()
+	c.capacity = cfg.MaxSize
+	for c.capacity > 0 && int(atomic.LoadInt64(&c.length)) > c.capacity {
+		it, reason := c.evict(nil)
+		if it == nil {
+			break
+		}
+		evicted = append(evicted, eviction{it.name, reason})
+	}
+	c.maxCost = cfg.MaxCost
+	evicted = append(evicted, c.shed(nil)...)
+	c.mu.Unlock()
+
+	c.fireEvictions(evicted)
+	return nil
+}
+
 /* ------------------ Stats ------------------ */
 
 func (c *memoryCache[T]) Stats(ctx context.Context) metrics.CacheStats {
@@ -248,17 +683,45 @@ func (c *memoryCache[T]) Stats(ctx context.Context) metrics.CacheStats {
 	}
 
 	return metrics.CacheStats{
-		Backend: "memory",
-		Items:   int64(items),
-		Hits:    hits,
-		Misses:  misses,
-		HitRate: metrics.CalculateHitRate(hits, misses),
-		Uptime:  c.base.Uptime(),
+		Backend:     "memory",
+		Items:       int64(items),
+		Hits:        hits,
+		Misses:      misses,
+		HitRate:     metrics.CalculateHitRate(hits, misses),
+		BytesUsed:   atomic.LoadInt64(&c.bytes),
+		Evictions:   atomic.LoadInt64(&c.evictions),
+		Expirations: atomic.LoadInt64(&c.expirations),
+		Uptime:      c.base.Uptime(),
+		StartedAt:   c.base.StartedAt(),
 	}
 }
 
 /* ------------------ Cleanup ------------------ */
 
+// setCleanupInterval starts the cleanup loop or retunes the running one.
+// Non-positive intervals leave the current schedule alone.
+func (c *memoryCache[T]) setCleanupInterval(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	c.loopMu.Lock()
+	defer c.loopMu.Unlock()
+
+	if !c.loopRunning {
+		c.loopRunning = true
+		go c.cleanupLoop(c.loopCtx, d)
+		return
+	}
+
+	// Keep only the latest request if the loop has not caught up yet.
+	select {
+	case <-c.intervalCh:
+	default:
+	}
+	c.intervalCh <- d
+}
+
 func (c *memoryCache[T]) cleanupLoop(ctx context.Context, interval time.Duration) {
 	t := time.NewTicker(interval)
 	defer t.Stop()
@@ -267,6 +730,8 @@ func (c *memoryCache[T]) cleanupLoop(ctx context.Context, interval time.Duration
 		select {
 		case <-t.C:
 			c.deleteExpired()
+		case d := <-c.intervalCh:
+			t.Reset(d)
 		case <-ctx.Done():
 			return
 		case <-c.stopCh:
@@ -276,14 +741,19 @@ func (c *memoryCache[T]) cleanupLoop(ctx context.Context, interval time.Duration
 }
 
 func (c *memoryCache[T]) deleteExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	var expired []string
 
+	c.mu.Lock()
 	now := time.Now()
 	for _, e := range c.items {
 		it := e.Value.(*memoryItem[T])
-		if !it.expiresAt.IsZero() && now.After(it.expiresAt) {
-			c.remove(e)
+		if !it.expiresAt.IsZero() && now.After(it.expiresAt) && c.expire(e) {
+			expired = append(expired, it.name)
 		}
 	}
+	c.mu.Unlock()
+
+	for _, key := range expired {
+		c.base.FireExpire(key)
+	}
 }
@@ -0,0 +1,919 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func testConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	return cfg
+}
+
+func newTestCache[T any](t *testing.T, cfg config.Config, opts ...Option[T]) *memoryCache[T] {
+	t.Helper()
+	c, err := NewMemory[T](cfg, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// eventually polls cond for up to a second.
+func eventually(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return cond()
+}
+
+/* ------------------ Reconfigure ------------------ */
+
+func TestReconfigureAppliesHotFields(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.TTL = time.Minute
+	cfg.MaxSize = 10
+	c := newTestCache[int](t, cfg)
+
+	for i, k := range []string{"a", "b", "c", "d"} {
+		if err := c.Set(ctx, k, i, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ttl, _ := c.GetWithTTL(ctx, "a"); ttl > time.Minute {
+		t.Fatalf("TTL before reconfigure = %v", ttl)
+	}
+
+	cfg.TTL = time.Hour
+	cfg.MaxSize = 2
+	cfg.CleanupInterval = 10 * time.Millisecond
+	if err := c.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := c.Len(ctx); n != 2 {
+		t.Errorf("Len after shrinking MaxSize = %d, want 2", n)
+	}
+	if err := c.Set(ctx, "e", 5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ttl, _ := c.GetWithTTL(ctx, "e"); ttl <= 59*time.Minute {
+		t.Errorf("TTL after reconfigure = %v, want about 1h", ttl)
+	}
+
+	// The cleanup loop now runs and removes expired entries without reads.
+	if err := c.Set(ctx, "short", 0, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !eventually(t, func() bool { return atomic.LoadInt64(&c.expirations) == 1 }) {
+		t.Error("expired entry not cleaned up after enabling CleanupInterval")
+	}
+}
+
+func TestReconfigureRejectsInvalidConfig(t *testing.T) {
+	c := newTestCache[int](t, testConfig())
+
+	cfg := testConfig()
+	cfg.Type = "bogus"
+	if err := c.Reconfigure(cfg); err == nil {
+		t.Error("Reconfigure accepted an invalid config")
+	}
+}
+
+/* ------------------ Stats ------------------ */
+
+func TestStatsCountsEvictions(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.MaxSize = 3
+	c := newTestCache[int](t, cfg)
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		if err := c.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := c.Stats(ctx)
+	if stats.Evictions != 2 || stats.Expirations != 0 {
+		t.Errorf("evictions=%d expirations=%d, want 2 and 0", stats.Evictions, stats.Expirations)
+	}
+}
+
+func TestStatsCountsExpirations(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+
+	for i, k := range []string{"a", "b"} {
+		if err := c.Set(ctx, k, i, 10*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Set(ctx, "kept", 0, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	for _, k := range []string{"a", "b"} {
+		if _, err := c.Get(ctx, k); err == nil {
+			t.Fatalf("%s still cached after its TTL", k)
+		}
+	}
+
+	stats := c.Stats(ctx)
+	if stats.Expirations != 2 || stats.Evictions != 0 {
+		t.Errorf("expirations=%d evictions=%d, want 2 and 0", stats.Expirations, stats.Evictions)
+	}
+}
+
+func TestStatsBytesUsedFollowsContents(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[string](t, testConfig())
+	used := func() int64 { return c.Stats(ctx).BytesUsed }
+
+	if n := used(); n != 0 {
+		t.Fatalf("empty cache BytesUsed = %d", n)
+	}
+	_ = c.Set(ctx, "a", strings.Repeat("x", 100), time.Minute)
+	one := used()
+	if one < 100 {
+		t.Fatalf("BytesUsed = %d after a 100-byte value", one)
+	}
+	_ = c.Set(ctx, "b", strings.Repeat("x", 100), time.Minute)
+	if n := used(); n != 2*one {
+		t.Errorf("BytesUsed = %d for two equal entries, want %d", n, 2*one)
+	}
+
+	// Overwrites account for the size difference.
+	_ = c.Set(ctx, "b", strings.Repeat("x", 300), time.Minute)
+	if n := used(); n != 2*one+200 {
+		t.Errorf("BytesUsed = %d after growing b by 200, want %d", n, 2*one+200)
+	}
+
+	_ = c.Delete(ctx, "b")
+	if n := used(); n != one {
+		t.Errorf("BytesUsed = %d after Delete, want %d", n, one)
+	}
+	_ = c.Clear(ctx)
+	if n := used(); n != 0 {
+		t.Errorf("BytesUsed = %d after Clear", n)
+	}
+}
+
+func TestStatsBytesUsedShrinksOnEvictAndExpire(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.MaxSize = 2
+	c := newTestCache[int](t, cfg)
+
+	_ = c.Set(ctx, "a", 1, time.Minute)
+	_ = c.Set(ctx, "b", 2, time.Minute)
+	full := c.Stats(ctx).BytesUsed
+	_ = c.Set(ctx, "c", 3, time.Minute)
+	if n := c.Stats(ctx).BytesUsed; n != full {
+		t.Errorf("BytesUsed = %d after an eviction, want %d", n, full)
+	}
+
+	_ = c.Set(ctx, "d", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, _ = c.Get(ctx, "d")
+	if n := c.Stats(ctx).BytesUsed; n != full/2 {
+		t.Errorf("BytesUsed = %d after expiry, want %d", n, full/2)
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	type user struct{ Name string }
+	ints := newTestCache[int](t, testConfig())
+	users := newTestCache[user](t, testConfig())
+
+	if got, want := ints.estimateSize("p:k", "k", 7), entryOverhead+4+8; got != want {
+		t.Errorf("int entry = %d, want %d", got, want)
+	}
+	// Other types are measured by their JSON encoding, {"Name":"ada"}.
+	if got, want := users.estimateSize("p:k", "k", user{"ada"}), entryOverhead+4+14; got != want {
+		t.Errorf("struct entry = %d, want %d", got, want)
+	}
+}
+
+func TestHealthAlwaysHealthy(t *testing.T) {
+	h := newTestCache[int](t, testConfig()).Health(context.Background())
+	if !h.Healthy || h.Backend != "memory" || h.CheckedAt.IsZero() {
+		t.Errorf("Health = %+v", h)
+	}
+}
+
+/* ------------------ Tenant quotas ------------------ */
+
+func quotaConfig() config.Config {
+	cfg := testConfig()
+	cfg.MaxSize = 6
+	cfg.TenantQuotas = map[string]config.TenantQuota{"noisy": {MaxEntries: 2}}
+	return cfg
+}
+
+func TestEvictionPrefersTenantsOverQuota(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, quotaConfig())
+
+	// The quiet tenant's entries are the least recently used.
+	keys := []string{"quiet:1", "quiet:2", "noisy:1", "noisy:2", "noisy:3", "noisy:4", "noisy:5", "quiet:3"}
+	for i, k := range keys {
+		if err := c.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, k := range []string{"quiet:1", "quiet:2", "quiet:3"} {
+		if _, err := c.Get(ctx, k); err != nil {
+			t.Errorf("under-quota entry %s evicted: %v", k, err)
+		}
+	}
+	for _, k := range []string{"noisy:1", "noisy:2"} {
+		if _, err := c.Get(ctx, k); err == nil {
+			t.Errorf("oldest over-quota entry %s survived", k)
+		}
+	}
+	if n, _ := c.Len(ctx); n != 6 {
+		t.Errorf("Len = %d, want 6", n)
+	}
+}
+
+func TestEvictionFallsBackToLRUWithinQuota(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, quotaConfig())
+
+	keys := []string{"a:1", "a:2", "a:3", "noisy:1", "noisy:2", "b:1", "b:2"}
+	for i, k := range keys {
+		if err := c.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Get(ctx, "a:1"); err == nil {
+		t.Error("LRU entry a:1 survived with no tenant over quota")
+	}
+	if _, err := c.Get(ctx, "noisy:1"); err != nil {
+		t.Errorf("within-quota entry noisy:1 evicted: %v", err)
+	}
+}
+
+func TestEvictionEnforcesTenantByteQuota(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.MaxSize = 4
+	probe := newTestCache[string](t, testConfig())
+	big := strings.Repeat("x", 1000)
+	size := probe.estimateSize(probe.base.FullKey("blob:1"), "blob:1", big)
+	cfg.TenantQuotas = map[string]config.TenantQuota{"blob": {MaxBytes: size}}
+	c := newTestCache[string](t, cfg)
+
+	// Two large blobs exceed the tenant's bytes, not its entry count.
+	for _, k := range []string{"blob:1", "a:1", "blob:2", "a:2", "a:3"} {
+		if err := c.Set(ctx, k, big, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Get(ctx, "blob:1"); err == nil {
+		t.Error("entry of a tenant over its byte quota survived")
+	}
+	for _, k := range []string{"a:1", "blob:2", "a:2", "a:3"} {
+		if _, err := c.Get(ctx, k); err != nil {
+			t.Errorf("%s evicted: %v", k, err)
+		}
+	}
+	if u := c.tenants["blob"]; u.entries != 1 || u.bytes != size {
+		t.Errorf("blob usage = %d entries, %d bytes; want 1, %d", u.entries, u.bytes, size)
+	}
+}
+
+func TestQuotaEvictionFollowsTenantRecency(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.MaxSize = 6
+	cfg.TenantQuotas = map[string]config.TenantQuota{"x": {MaxEntries: 1}, "y": {MaxEntries: 1}}
+	c := newTestCache[int](t, cfg)
+
+	for i, k := range []string{"x:1", "y:1", "x:2", "y:2", "x:3", "y:3"} {
+		_ = c.Set(ctx, k, i, time.Minute)
+	}
+	// A hit makes x:1 the tenant's most recent entry, so y:1, the oldest
+	// over-quota entry left, goes first, then x:2.
+	if _, err := c.Get(ctx, "x:1"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set(ctx, "z:1", 0, time.Minute)
+	_ = c.Set(ctx, "z:2", 0, time.Minute)
+
+	for _, k := range []string{"y:1", "x:2"} {
+		if ok, _ := c.Exists(ctx, k); ok {
+			t.Errorf("%s survived, want it evicted", k)
+		}
+	}
+	for _, k := range []string{"x:1", "y:2", "x:3", "y:3", "z:1", "z:2"} {
+		if ok, _ := c.Exists(ctx, k); !ok {
+			t.Errorf("%s evicted", k)
+		}
+	}
+
+	_ = c.Clear(ctx)
+	for name, u := range c.tenants {
+		if u.entries != 0 || u.bytes != 0 || u.lru.Len() != 0 {
+			t.Errorf("tenant %s after Clear = %d entries, %d bytes", name, u.entries, u.bytes)
+		}
+	}
+}
+
+/* ------------------ Cost eviction ------------------ */
+
+// costConfig caps the summed value of int entries, each costing itself.
+func costConfig(log *hookLog, maxCost int64) (config.Config, Option[int]) {
+	cfg := hookedConfig(log)
+	cfg.Prefix = ""
+	cfg.MaxCost = maxCost
+	return cfg, WithCostFunc(func(v int) int64 { return int64(v) })
+}
+
+func TestCostlyEntryEvictsSeveral(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	cfg, opt := costConfig(log, 10)
+	c := newTestCache[int](t, cfg, opt)
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		_ = c.Set(ctx, k, 2, time.Minute)
+	}
+	// Reading a makes b the least recently used.
+	_, _ = c.Get(ctx, "a")
+
+	_ = c.Set(ctx, "big", 7, time.Minute)
+	keys, _ := c.Keys(ctx, "")
+	slices.Sort(keys)
+	if want := []string{"a", "big"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	for _, ev := range []string{"evict:capacity:b", "evict:capacity:c", "evict:capacity:d"} {
+		if !log.has(ev) {
+			t.Errorf("hook %s not fired: %v", ev, log.events)
+		}
+	}
+	if n := c.Stats(ctx).Evictions; n != 3 {
+		t.Errorf("evictions = %d, want 3", n)
+	}
+}
+
+func TestEntryCostlierThanMaxCostStaysAlone(t *testing.T) {
+	ctx := context.Background()
+	cfg, opt := costConfig(&hookLog{}, 10)
+	c := newTestCache[int](t, cfg, opt)
+
+	_ = c.Set(ctx, "small", 1, time.Minute)
+	_ = c.Set(ctx, "huge", 50, time.Minute)
+	keys, _ := c.Keys(ctx, "")
+	if !slices.Equal(keys, []string{"huge"}) {
+		t.Errorf("keys = %v, want only huge", keys)
+	}
+}
+
+func TestCostEvictionOnOverwriteAndReconfigure(t *testing.T) {
+	ctx := context.Background()
+	cfg, opt := costConfig(&hookLog{}, 10)
+	c := newTestCache[int](t, cfg, opt)
+
+	_ = c.Set(ctx, "a", 3, time.Minute)
+	_ = c.Set(ctx, "b", 3, time.Minute)
+	// Growing b to 8 pushes the total to 11.
+	_ = c.Set(ctx, "b", 8, time.Minute)
+	if ok, _ := c.Exists(ctx, "a"); ok {
+		t.Error("a kept although the overwrite exceeded MaxCost")
+	}
+
+	_ = c.Set(ctx, "b", 2, time.Minute)
+	_ = c.Set(ctx, "c", 2, time.Minute)
+	cfg.MaxCost = 2
+	if err := c.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	keys, _ := c.Keys(ctx, "")
+	if !slices.Equal(keys, []string{"c"}) {
+		t.Errorf("keys after shrinking MaxCost = %v, want [c]", keys)
+	}
+}
+
+/* ------------------ TTL jitter ------------------ */
+
+func TestSetAppliesTTLJitter(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.TTLJitter = 0.2
+	c := newTestCache[int](t, cfg)
+
+	distinct := make(map[time.Duration]struct{})
+	for i := range 50 {
+		k := "k" + strconv.Itoa(i)
+		if err := c.Set(ctx, k, i, 0); err != nil {
+			t.Fatal(err)
+		}
+		_, ttl, err := c.GetWithTTL(ctx, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ttl < cfg.TTL*8/10-time.Second || ttl > cfg.TTL*12/10 {
+			t.Fatalf("TTL = %v, want within ±20%% of %v", ttl, cfg.TTL)
+		}
+		distinct[ttl.Round(time.Second)] = struct{}{}
+	}
+	if len(distinct) < 10 {
+		t.Errorf("only %d distinct TTLs across 50 writes", len(distinct))
+	}
+}
+
+/* ------------------ Hooks ------------------ */
+
+// hookLog records hook calls as "event:key" strings.
+type hookLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *hookLog) add(event string) {
+	h.mu.Lock()
+	h.events = append(h.events, event)
+	h.mu.Unlock()
+}
+
+func (h *hookLog) has(event string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Contains(h.events, event)
+}
+
+func (h *hookLog) hooks() config.Hooks {
+	return config.Hooks{
+		OnHit:    func(key string) { h.add("hit:" + key) },
+		OnMiss:   func(key string) { h.add("miss:" + key) },
+		OnEvict:  func(key string, r config.EvictReason) { h.add("evict:" + string(r) + ":" + key) },
+		OnExpire: func(key string) { h.add("expire:" + key) },
+	}
+}
+
+func hookedConfig(log *hookLog) config.Config {
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	cfg.Hooks = log.hooks()
+	return cfg
+}
+
+func TestHooksHitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	c := newTestCache[int](t, hookedConfig(log))
+
+	if err := c.Set(ctx, "a", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+
+	for _, want := range []string{"hit:a", "miss:b"} {
+		if !log.has(want) {
+			t.Errorf("no %s event in %v", want, log.events)
+		}
+	}
+	if log.has("miss:a") || log.has("hit:b") {
+		t.Errorf("unexpected events %v", log.events)
+	}
+}
+
+func TestHooksEvictOnCapacity(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	cfg := hookedConfig(log)
+	cfg.MaxSize = 2
+	c := newTestCache[int](t, cfg)
+
+	for i, k := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !log.has("evict:capacity:a") {
+		t.Errorf("no capacity eviction of a in %v", log.events)
+	}
+}
+
+func TestHooksEvictOnQuota(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	cfg := quotaConfig()
+	cfg.Hooks = log.hooks()
+	c := newTestCache[int](t, cfg)
+
+	for i, k := range []string{"quiet:1", "noisy:1", "noisy:2", "noisy:3", "b:1", "b:2", "b:3"} {
+		if err := c.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !log.has("evict:tenant_quota:noisy:1") {
+		t.Errorf("no quota eviction of noisy:1 in %v", log.events)
+	}
+}
+
+func TestHooksExpireOnReadAndCleanup(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	cfg := hookedConfig(log)
+	cfg.CleanupInterval = 10 * time.Millisecond
+	c := newTestCache[int](t, cfg)
+
+	if err := c.Set(ctx, "read", 1, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(6 * time.Millisecond)
+	_, _ = c.Get(ctx, "read")
+	if !log.has("expire:read") {
+		t.Errorf("no expire event for an expired read in %v", log.events)
+	}
+
+	if err := c.Set(ctx, "swept", 1, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !eventually(t, func() bool { return log.has("expire:swept") }) {
+		t.Errorf("no expire event from the cleanup loop in %v", log.events)
+	}
+}
+
+func TestHooksRunWithoutCacheLock(t *testing.T) {
+	ctx := context.Background()
+	var c *memoryCache[int]
+	var reentered atomic.Int64
+	reenter := func(string) {
+		// Any of these would deadlock if the hook ran under c.mu.
+		_, _ = c.Len(ctx)
+		_ = c.Set(ctx, "other", 0, time.Minute)
+		reentered.Add(1)
+	}
+	cfg := testConfig()
+	cfg.MaxSize = 1
+	cfg.Hooks = config.Hooks{
+		OnHit:    reenter,
+		OnMiss:   reenter,
+		OnEvict:  func(k string, _ config.EvictReason) { reenter(k) },
+		OnExpire: reenter,
+	}
+	c = newTestCache[int](t, cfg)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Set(ctx, "a", 1, time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+		_, _ = c.Get(ctx, "a") // expire
+		_, _ = c.Get(ctx, "a") // miss
+		_ = c.Set(ctx, "b", 1, time.Minute)
+		_, _ = c.Get(ctx, "b") // hit
+		_ = c.Set(ctx, "c", 1, time.Minute)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a hook calling back into the cache deadlocked")
+	}
+	if reentered.Load() == 0 {
+		t.Error("no hook fired")
+	}
+}
+
+/* ------------------ Range ------------------ */
+
+func TestRangeVisitsLiveEntries(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+
+	want := map[string]int{"a": 1, "b": 2, "c": 3}
+	for k, v := range want {
+		if err := c.Set(ctx, k, v, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Set(ctx, "gone", 0, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	got := map[string]int{}
+	// fn may write to the cache: Range does not hold the lock while calling it.
+	err := c.Range(ctx, func(k string, v int) bool {
+		got[k] = v
+		_ = c.Set(ctx, k, v+10, time.Minute)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Range visited %v, want %v", got, want)
+	}
+}
+
+func TestRangeStopsEarly(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+	for i := range 10 {
+		if err := c.Set(ctx, "k"+strconv.Itoa(i), i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	calls := 0
+	_ = c.Range(ctx, func(string, int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("fn called %d times after returning false, want 1", calls)
+	}
+}
+
+/* ------------------ Keys ------------------ */
+
+func TestKeysStripsPrefixAndFilters(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	c := newTestCache[int](t, cfg)
+
+	for _, k := range []string{"user:2", "user:1", "order:1"} {
+		if err := c.Set(ctx, k, 0, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Set(ctx, "user:old", 0, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	all, err := c.Keys(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"order:1", "user:1", "user:2"}; !slices.Equal(all, want) {
+		t.Errorf("Keys(\"\") = %v, want %v", all, want)
+	}
+
+	users, err := c.Keys(ctx, "user:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user:1", "user:2"}; !slices.Equal(users, want) {
+		t.Errorf("Keys(user:) = %v, want %v", users, want)
+	}
+	if none, _ := c.Keys(ctx, "nope:"); len(none) != 0 {
+		t.Errorf("Keys(nope:) = %v, want none", none)
+	}
+}
+
+/* ------------------ Stale reads ------------------ */
+
+func TestGetStaleServesExpiredEntry(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.StaleGrace = time.Minute
+	c := newTestCache[string](t, cfg)
+
+	_ = c.Set(ctx, "fresh", "f", time.Minute)
+	_ = c.Set(ctx, "old", "o", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if v, stale, err := c.GetStale(ctx, "fresh"); err != nil || stale || v != "f" {
+		t.Errorf("GetStale(fresh) = %q, %v, %v", v, stale, err)
+	}
+	// Get misses, but the entry stays readable for the grace period.
+	if _, err := c.Get(ctx, "old"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Get(old) = %v, want a miss", err)
+	}
+	c.deleteExpired()
+	if v, stale, err := c.GetStale(ctx, "old"); err != nil || !stale || v != "o" {
+		t.Errorf("GetStale(old) = %q, %v, %v; want the stale value", v, stale, err)
+	}
+	if _, _, err := c.GetStale(ctx, "absent"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("GetStale(absent) = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestGetStaleAfterGraceMisses(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.StaleGrace = 10 * time.Millisecond
+	c := newTestCache[string](t, cfg)
+
+	_ = c.Set(ctx, "old", "o", time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	c.deleteExpired()
+	if _, _, err := c.GetStale(ctx, "old"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("GetStale past the grace = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestGetStaleWithoutGraceUntilRemoved(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[string](t, testConfig())
+
+	_ = c.Set(ctx, "old", "o", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// Nothing has removed the entry yet.
+	if v, stale, err := c.GetStale(ctx, "old"); err != nil || !stale || v != "o" {
+		t.Errorf("GetStale before removal = %q, %v, %v", v, stale, err)
+	}
+	_, _ = c.Get(ctx, "old")
+	if _, _, err := c.GetStale(ctx, "old"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("GetStale after Get removed it = %v, want ErrCacheMiss", err)
+	}
+}
+
+/* ------------------ Persist ------------------ */
+
+func TestPersistRemovesTTL(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[string](t, testConfig())
+
+	_ = c.Set(ctx, "k", "v", 20*time.Millisecond)
+	if err := c.Persist(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	v, ttl, err := c.GetWithTTL(ctx, "k")
+	if err != nil || v != "v" {
+		t.Fatalf("Get after the original TTL = %q, %v", v, err)
+	}
+	if ttl != 0 {
+		t.Errorf("TTL = %v, want no expiry", ttl)
+	}
+
+	if err := c.Persist(ctx, "absent"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Persist(absent) = %v, want ErrCacheMiss", err)
+	}
+	_ = c.Set(ctx, "old", "o", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if err := c.Persist(ctx, "old"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Persist(expired) = %v, want ErrCacheMiss", err)
+	}
+}
+
+/* ------------------ NoExpiration ------------------ */
+
+func TestNoExpirationNeverExpires(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.TTL = 10 * time.Millisecond
+	c := newTestCache[string](t, cfg)
+
+	_ = c.Set(ctx, "forever", "v", base.NoExpiration)
+	_ = c.Set(ctx, "default", "v", 0)
+	if _, ttl, _ := c.GetWithTTL(ctx, "default"); ttl <= 0 || ttl > cfg.TTL {
+		t.Errorf("zero TTL stored as %v, want the default", ttl)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if v, ttl, err := c.GetWithTTL(ctx, "forever"); err != nil || v != "v" || ttl != 0 {
+		t.Errorf("GetWithTTL(forever) = %q, %v, %v; want no expiry", v, ttl, err)
+	}
+	if _, err := c.Get(ctx, "default"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Get(default) = %v, want it expired", err)
+	}
+}
+
+/* ------------------ DeleteByPattern ------------------ */
+
+func TestDeleteByPatternMultiWildcard(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	c := newTestCache[int](t, cfg)
+
+	for _, k := range []string{"user:1:session:a", "user:2:session:b", "user:1:profile", "admin:1:session:a"} {
+		_ = c.Set(ctx, k, 1, time.Minute)
+	}
+	n, err := c.DeleteByPattern(ctx, "user:*:session:*")
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByPattern = %d, %v; want 2", n, err)
+	}
+	keys, _ := c.Keys(ctx, "")
+	slices.Sort(keys)
+	if want := []string{"admin:1:session:a", "user:1:profile"}; !slices.Equal(keys, want) {
+		t.Errorf("keys left = %v, want %v", keys, want)
+	}
+
+	// The cache prefix is not part of the pattern.
+	if n, _ := c.DeleteByPattern(ctx, "app:*"); n != 0 {
+		t.Errorf("pattern matched the cache prefix: %d deleted", n)
+	}
+	if _, err := c.DeleteByPattern(ctx, "user:[1"); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("malformed pattern = %v, want ErrInvalidArgument", err)
+	}
+}
+
+/* ------------------ CountByPrefix ------------------ */
+
+func TestCountByPrefixOverlapping(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	c := newTestCache[int](t, cfg)
+
+	for _, k := range []string{"user:1", "user:10", "user:100", "user:2", "users", "order:1"} {
+		_ = c.Set(ctx, k, 1, time.Minute)
+	}
+	_ = c.Set(ctx, "user:1old", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	for prefix, want := range map[string]int64{
+		"user:1": 3, "user:10": 2, "user:": 4, "user": 5, "": 6, "app:": 0,
+	} {
+		if n, err := c.CountByPrefix(ctx, prefix); err != nil || n != want {
+			t.Errorf("CountByPrefix(%q) = %d, %v; want %d", prefix, n, err, want)
+		}
+	}
+	// Counting leaves every entry in place, including the expired one.
+	if len(c.items) != 7 {
+		t.Errorf("%d entries after counting, want 7", len(c.items))
+	}
+}
+
+/* ------------------ Cleanup loop ------------------ */
+
+// cleanupConfig runs the cleanup loop every few milliseconds and counts
+// the entries it expires.
+func cleanupConfig(expired *atomic.Int64) config.Config {
+	cfg := testConfig()
+	cfg.CleanupInterval = 5 * time.Millisecond
+	cfg.Hooks.OnExpire = func(string) { expired.Add(1) }
+	return cfg
+}
+
+func TestCleanupLoopRunsUntilContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var expired atomic.Int64
+	c, err := NewMemoryWithContext[int](ctx, cleanupConfig(&expired))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	_ = c.Set(ctx, "a", 1, time.Millisecond)
+	if !eventually(t, func() bool { return expired.Load() == 1 }) {
+		t.Fatal("cleanup loop never expired the entry")
+	}
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	_ = c.Set(context.Background(), "b", 1, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if n := expired.Load(); n != 1 {
+		t.Errorf("cleanup ran after the context was cancelled: %d expirations", n)
+	}
+
+	// The cache stays usable once the loop is gone.
+	if err := c.Set(context.Background(), "c", 3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.Get(context.Background(), "c"); err != nil || v != 3 {
+		t.Errorf("Get after cancel = %d, %v", v, err)
+	}
+}
+
+func TestCleanupLoopStopsOnClose(t *testing.T) {
+	ctx := context.Background()
+	var expired atomic.Int64
+	c, err := NewMemoryWithContext[int](ctx, cleanupConfig(&expired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.Set(ctx, "a", 1, time.Millisecond)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := expired.Load(); n != 0 {
+		t.Errorf("cleanup ran after Close: %d expirations", n)
+	}
+}
@@ -0,0 +1,25 @@
+package memory
+
+import "github.com/os-golib/go-cache/internal/base"
+
+/* ------------------ Options ------------------ */
+
+// Option customizes a memory cache at construction time.
+type Option[T any] func(*memoryCache[T])
+
+// WithSerializer replaces the JSON serializer used for on-disk snapshots.
+func WithSerializer[T any](s base.Serializer[T]) Option[T] {
+	return func(c *memoryCache[T]) {
+		if s != nil {
+			c.serializer = s
+		}
+	}
+}
+
+// WithCostFunc weighs each entry with fn for cost-based eviction against
+// cfg.MaxCost. Without it every entry costs 1.
+func WithCostFunc[T any](fn func(T) int64) Option[T] {
+	return func(c *memoryCache[T]) {
+		c.costFn = fn
+	}
+}
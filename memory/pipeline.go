@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ GET MANY ------------------ */
+
+// GetManyPipeline reads all keys under a single lock. Misses and expired
+// entries are absent from the result; invalid keys are reported in a
+// *base.MultiError.
+func (c *memoryCache[T]) GetManyPipeline(ctx context.Context, keys []string) (map[string]T, error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]T, len(keys))
+	failed := make(map[string]error)
+	var hits, misses, expired []string
+
+	c.mu.Lock()
+	for _, k := range keys {
+		if err := c.base.ValidateKey(k); err != nil {
+			failed[k] = base.WrapError(base.OpGetManyPipeline, err, k)
+			continue
+		}
+
+		elem, ok := c.items[c.base.FullKey(k)]
+		if !ok {
+			misses = append(misses, k)
+			continue
+		}
+
+		it := elem.Value.(*memoryItem[T])
+		if c.expired(it) {
+			if c.expire(elem) {
+				expired = append(expired, k)
+			}
+			misses = append(misses, k)
+			continue
+		}
+
+		c.touch(elem)
+		result[k] = it.value
+		hits = append(hits, k)
+	}
+	c.mu.Unlock()
+
+	for _, k := range expired {
+		c.base.FireExpire(k)
+	}
+	for _, k := range misses {
+		c.base.FireMiss(k)
+	}
+	for _, k := range hits {
+		c.base.FireHit(k)
+	}
+
+	return result, base.NewMultiError(failed)
+}
+
+// GetManyOrdered is GetManyPipeline returning one Result per key in input
+// order, with Found false for misses and invalid keys.
+func (c *memoryCache[T]) GetManyOrdered(ctx context.Context, keys []string) ([]interfaces.Result[T], error) {
+	found, err := c.GetManyPipeline(ctx, keys)
+	if found == nil {
+		return nil, err
+	}
+
+	out := make([]interfaces.Result[T], len(keys))
+	for i, k := range keys {
+		v, ok := found[k]
+		out[i] = interfaces.Result[T]{Key: k, Value: v, Found: ok}
+	}
+	return out, err
+}
+
+/* ------------------ SET MANY ------------------ */
+
+func (c *memoryCache[T]) SetManyPipeline(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	failed, err := c.SetManyPipelineResult(ctx, items, ttl)
+	if err != nil {
+		return err
+	}
+	return base.FirstError(failed)
+}
+
+// SetManyPipelineResult writes all items under a single lock and reports
+// invalid keys. Each entry gets its own jittered TTL.
+func (c *memoryCache[T]) SetManyPipelineResult(
+	ctx context.Context,
+	items map[string]T,
+	ttl time.Duration,
+) (map[string]error, error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]error)
+	var evicted []eviction
+
+	ttl = c.base.ResolveTTL(ttl)
+	now := time.Now()
+
+	c.mu.Lock()
+	for k, v := range items {
+		if err := c.base.ValidateKey(k); err != nil {
+			failed[k] = base.WrapError(base.OpSetManyPipeline, err, k)
+			continue
+		}
+
+		var expiresAt time.Time
+		if d := c.base.JitterTTL(ctx, ttl); d > 0 {
+			expiresAt = now.Add(d)
+		}
+		evicted = append(evicted, c.storeLocked(k, v, expiresAt)...)
+	}
+	c.mu.Unlock()
+
+	c.fireEvictions(evicted)
+	return failed, nil
+}
+
+/* ------------------ EXISTS MANY ------------------ */
+
+// ExistsMany checks all keys under a single lock. Expired entries report
+// false and are removed on the way.
+func (c *memoryCache[T]) ExistsMany(ctx context.Context, keys []string) (map[string]bool, error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if err := c.base.ValidateKey(k); err != nil {
+			return nil, base.WrapError(base.OpExists, err, k)
+		}
+	}
+
+	out := make(map[string]bool, len(keys))
+	var expired []string
+
+	c.mu.Lock()
+	for _, k := range keys {
+		elem, ok := c.items[c.base.FullKey(k)]
+		if !ok {
+			out[k] = false
+			continue
+		}
+		if c.expired(elem.Value.(*memoryItem[T])) {
+			if c.expire(elem) {
+				expired = append(expired, k)
+			}
+			out[k] = false
+			continue
+		}
+		out[k] = true
+	}
+	c.mu.Unlock()
+
+	for _, k := range expired {
+		c.base.FireExpire(k)
+	}
+	return out, nil
+}
+
+/* ------------------ EXPIRE MANY ------------------ */
+
+// ExpireMany resets the expiry of every live key under a single lock.
+// Missing and expired keys are skipped.
+func (c *memoryCache[T]) ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := c.base.ValidateKey(k); err != nil {
+			return base.WrapError(base.OpExpireMany, err, k)
+		}
+	}
+
+	ttl = c.base.ResolveTTL(ttl)
+	now := time.Now()
+	var expired []string
+
+	c.mu.Lock()
+	for _, k := range keys {
+		elem, ok := c.items[c.base.FullKey(k)]
+		if !ok {
+			continue
+		}
+		it := elem.Value.(*memoryItem[T])
+		if c.expired(it) {
+			if c.expire(elem) {
+				expired = append(expired, k)
+			}
+			continue
+		}
+
+		it.expiresAt = time.Time{}
+		if d := c.base.JitterTTL(ctx, ttl); d > 0 {
+			it.expiresAt = now.Add(d)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, k := range expired {
+		c.base.FireExpire(k)
+	}
+	return nil
+}
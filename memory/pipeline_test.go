@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+var (
+	_ interfaces.PipelineGetter[int] = (*memoryCache[int])(nil)
+	_ interfaces.PipelineSetter[int] = (*memoryCache[int])(nil)
+	_ interfaces.OrderedGetter[int]  = (*memoryCache[int])(nil)
+)
+
+func TestGetManyPipelineOmitsMisses(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	c := newTestCache[int](t, hookedConfig(log))
+
+	_ = c.Set(ctx, "a", 1, time.Minute)
+	_ = c.Set(ctx, "b", 2, time.Minute)
+	_ = c.Set(ctx, "old", 3, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	got, err := c.GetManyPipeline(ctx, []string{"a", "b", "missing", "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(got, want) {
+		t.Errorf("GetManyPipeline = %v, want %v", got, want)
+	}
+	for _, ev := range []string{"hit:a", "hit:b", "miss:missing", "miss:old", "expire:old"} {
+		if !log.has(ev) {
+			t.Errorf("hook %s not fired: %v", ev, log.events)
+		}
+	}
+}
+
+func TestGetManyPipelineReportsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+	_ = c.Set(ctx, "a", 1, time.Minute)
+
+	got, err := c.GetManyPipeline(ctx, []string{"a", ""})
+	if len(got) != 1 || got["a"] != 1 {
+		t.Errorf("result = %v, want a only", got)
+	}
+	var multi *base.MultiError
+	if !errors.As(err, &multi) || !errors.Is(multi.Errors[""], base.ErrKeyEmpty) {
+		t.Errorf("err = %v, want a MultiError for the empty key", err)
+	}
+}
+
+func TestSetManyPipelineStoresAllAndEvicts(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.MaxSize = 3
+	c := newTestCache[int](t, cfg)
+
+	_ = c.Set(ctx, "old", 0, time.Minute)
+	items := map[string]int{"a": 1, "b": 2, "c": 3}
+	if err := c.SetManyPipeline(ctx, items, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetManyPipeline(ctx, []string{"a", "b", "c", "old"})
+	if err != nil || !maps.Equal(got, items) {
+		t.Errorf("after SetManyPipeline = %v, %v; want %v with old evicted", got, err, items)
+	}
+
+	failed, err := c.SetManyPipelineResult(ctx, map[string]int{"d": 4, "": 5}, time.Minute)
+	if err != nil || len(failed) != 1 || !errors.Is(failed[""], base.ErrKeyEmpty) {
+		t.Errorf("SetManyPipelineResult = %v, %v; want only the empty key", failed, err)
+	}
+}
+
+func TestExistsManyReportsExpiredAsAbsent(t *testing.T) {
+	ctx := context.Background()
+	log := &hookLog{}
+	c := newTestCache[int](t, hookedConfig(log))
+
+	_ = c.Set(ctx, "present", 1, time.Minute)
+	_ = c.Set(ctx, "old", 2, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	got, err := c.ExistsMany(ctx, []string{"present", "absent", "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"present": true, "absent": false, "old": false}; !maps.Equal(got, want) {
+		t.Errorf("ExistsMany = %v, want %v", got, want)
+	}
+	// The expired entry is cleaned up on the way.
+	if n, _ := c.Len(ctx); n != 1 || !log.has("expire:old") {
+		t.Errorf("Len = %d, events %v; want old removed", n, log.events)
+	}
+
+	if _, err := c.ExistsMany(ctx, []string{"present", ""}); !errors.Is(err, base.ErrKeyEmpty) {
+		t.Errorf("ExistsMany with an empty key = %v, want ErrKeyEmpty", err)
+	}
+}
+
+func TestExpireManyUpdatesExistingKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+
+	_ = c.Set(ctx, "a", 1, time.Second)
+	_ = c.Set(ctx, "b", 2, base.NoExpiration)
+	if err := c.ExpireMany(ctx, []string{"a", "b", "absent"}, time.Hour); err != nil {
+		t.Fatalf("ExpireMany = %v", err)
+	}
+	for _, k := range []string{"a", "b"} {
+		if _, ttl, _ := c.GetWithTTL(ctx, k); ttl < 59*time.Minute || ttl > time.Hour {
+			t.Errorf("%s TTL = %v, want about 1h", k, ttl)
+		}
+	}
+	if ok, _ := c.Exists(ctx, "absent"); ok {
+		t.Error("ExpireMany created an absent key")
+	}
+
+	// NoExpiration removes the TTL; expired keys are not revived.
+	_ = c.Set(ctx, "old", 3, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if err := c.ExpireMany(ctx, []string{"a", "old"}, base.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if _, ttl, _ := c.GetWithTTL(ctx, "a"); ttl != 0 {
+		t.Errorf("a TTL = %v after NoExpiration, want none", ttl)
+	}
+	if _, err := c.Get(ctx, "old"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Get(old) = %v, want the expired key to stay gone", err)
+	}
+}
+
+func TestGetManyOrderedKeepsInputOrder(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[int](t, testConfig())
+	_ = c.Set(ctx, "a", 1, time.Minute)
+	_ = c.Set(ctx, "c", 3, time.Minute)
+
+	got, err := c.GetManyOrdered(ctx, []string{"c", "b", "a", "d", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interfaces.Result[int]{
+		{Key: "c", Value: 3, Found: true},
+		{Key: "b"},
+		{Key: "a", Value: 1, Found: true},
+		{Key: "d"},
+		{Key: "c", Value: 3, Found: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetManyOrdered = %v, want %v", got, want)
+	}
+
+	got, err = c.GetManyOrdered(ctx, []string{"a", ""})
+	if !errors.Is(err, base.ErrKeyEmpty) {
+		t.Errorf("GetManyOrdered with an empty key = %v, want ErrKeyEmpty", err)
+	}
+	if len(got) != 2 || !got[0].Found || got[1].Found {
+		t.Errorf("GetManyOrdered = %v, want a hit then the invalid key unfound", got)
+	}
+}
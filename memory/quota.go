@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"container/list"
+	"strings"
+
+	"github.com/os-golib/go-cache/config"
+)
+
+/* ------------------ Tenant Quotas ------------------ */
+
+// tenantUsage tracks one tenant that has a quota. Its entries are kept in
+// their own recency list, so an over-quota tenant's oldest entry is found
+// without scanning the whole cache.
+type tenantUsage struct {
+	quota   config.TenantQuota
+	entries int
+	bytes   int64
+	lru     *list.List // of elements of memoryCache.lru, most recent first
+}
+
+func (u *tenantUsage) over() bool {
+	return (u.quota.MaxEntries > 0 && u.entries > u.quota.MaxEntries) ||
+		(u.quota.MaxBytes > 0 && u.bytes > u.quota.MaxBytes)
+}
+
+func newTenantUsage(quotas map[string]config.TenantQuota) map[string]*tenantUsage {
+	tenants := make(map[string]*tenantUsage, len(quotas))
+	for name, q := range quotas {
+		tenants[name] = &tenantUsage{quota: q, lru: list.New()}
+	}
+	return tenants
+}
+
+// tenantOf returns the usage of key's tenant, or nil if it has no quota.
+func (c *memoryCache[T]) tenantOf(key string) *tenantUsage {
+	if c.tenants == nil {
+		return nil
+	}
+	tenant, _, _ := strings.Cut(key, c.tenantSep)
+	return c.tenants[tenant]
+}
+
+// touch marks elem as the most recently used, in the cache and in its
+// tenant's list.
+func (c *memoryCache[T]) touch(elem *list.Element) {
+	c.lru.MoveToFront(elem)
+	it := elem.Value.(*memoryItem[T])
+	c.clock++
+	it.touched = c.clock
+	if it.tenant != nil {
+		it.tenant.lru.MoveToFront(it.tenantElem)
+	}
+}
+
+func (c *memoryCache[T]) addTenant(elem *list.Element) {
+	it := elem.Value.(*memoryItem[T])
+	c.clock++
+	it.touched = c.clock
+	if u := it.tenant; u != nil {
+		u.entries++
+		u.bytes += it.size
+		it.tenantElem = u.lru.PushFront(elem)
+	}
+}
+
+func (c *memoryCache[T]) removeTenant(it *memoryItem[T]) {
+	if u := it.tenant; u != nil {
+		u.entries--
+		u.bytes -= it.size
+		u.lru.Remove(it.tenantElem)
+	}
+}
+
+// quotaVictim returns the least recently used entry among tenants over
+// their quota, or nil if none is. It looks only at the back of each
+// tenant's list, so the cost grows with the number of quotas, not entries.
+func (c *memoryCache[T]) quotaVictim(keep *list.Element) *list.Element {
+	var victim *list.Element
+	for _, u := range c.tenants {
+		if !u.over() || u.lru.Len() == 0 {
+			continue
+		}
+		e := u.lru.Back().Value.(*list.Element)
+		if e == keep {
+			continue
+		}
+		if victim == nil || e.Value.(*memoryItem[T]).touched < victim.Value.(*memoryItem[T]).touched {
+			victim = e
+		}
+	}
+	return victim
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"unsafe"
+)
+
+/* ------------------ Size Estimation ------------------ */
+
+// entryOverhead approximates the per-entry bookkeeping: the map slot, the
+// list element and the memoryItem header.
+const entryOverhead = int64(unsafe.Sizeof(memoryItem[struct{}]{})) + 64
+
+// estimateSize approximates the bytes held by an entry. Strings and byte
+// slices count their length, fixed-size primitives their unsafe.Sizeof;
+// any other value is measured by its serialized length, falling back to
+// unsafe.Sizeof if it cannot be encoded. The key is counted twice because
+// both the full and the logical key are kept. The result is an estimate
+// of payload size, not of Go heap usage.
+func (c *memoryCache[T]) estimateSize(fullKey, name string, value T) int64 {
+	n := entryOverhead + int64(len(fullKey)+len(name))
+
+	switch v := any(value).(type) {
+	case string:
+		return n + int64(len(v))
+	case []byte:
+		return n + int64(len(v))
+	case bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128:
+		return n + int64(unsafe.Sizeof(value))
+	}
+
+	if data, err := c.serializer.Encode(value); err == nil {
+		return n + int64(len(data))
+	}
+	return n + int64(unsafe.Sizeof(value))
+}
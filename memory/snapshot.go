@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Snapshot ------------------ */
+
+const snapshotVersion = 1
+
+type snapshotFile struct {
+	Version int             `json:"version"`
+	Entries []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	Key       string    `json:"k"`
+	Value     []byte    `json:"v"`
+	ExpiresAt time.Time `json:"exp,omitempty"`
+}
+
+// saveSnapshot writes live entries, least recently used first, through a
+// temporary file so a crash never leaves a truncated snapshot behind.
+func (c *memoryCache[T]) saveSnapshot(path string) error {
+	now := time.Now()
+	snap := snapshotFile{Version: snapshotVersion}
+
+	c.mu.RLock()
+	for e := c.lru.Back(); e != nil; e = e.Prev() {
+		it := e.Value.(*memoryItem[T])
+		if !it.expiresAt.IsZero() && !now.Before(it.expiresAt) {
+			continue
+		}
+		data, err := c.serializer.Encode(it.value)
+		if err != nil {
+			c.mu.RUnlock()
+			return fmt.Errorf("snapshot %q: %w", it.name, err)
+		}
+		snap.Entries = append(snap.Entries, snapshotEntry{Key: it.name, Value: data, ExpiresAt: it.expiresAt})
+	}
+	c.mu.RUnlock()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadSnapshot restores entries saved by saveSnapshot, skipping expired
+// ones. A missing file is not an error.
+func (c *memoryCache[T]) loadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("load snapshot: unsupported version %d", snap.Version)
+	}
+
+	now := time.Now()
+	for _, e := range snap.Entries {
+		if !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt) {
+			continue
+		}
+		val, err := c.serializer.Decode(e.Value)
+		if err != nil {
+			// Entries of an incompatible type are dropped, not fatal.
+			continue
+		}
+		c.store(e.Key, val, e.ExpiresAt)
+	}
+	return nil
+}
+
+/* ------------------ Dump / Restore ------------------ */
+
+// Dump returns every unexpired entry with its remaining TTL, keyed without
+// the cache prefix.
+func (c *memoryCache[T]) Dump(ctx context.Context) (map[string]interfaces.Entry[T], error) {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.RLock()
+	out := make(map[string]interfaces.Entry[T], len(c.items))
+	for _, e := range c.items {
+		it := e.Value.(*memoryItem[T])
+		var ttl time.Duration
+		if !it.expiresAt.IsZero() {
+			if ttl = it.expiresAt.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+		out[it.name] = interfaces.Entry[T]{Value: it.value, TTL: ttl}
+	}
+	c.mu.RUnlock()
+
+	return out, nil
+}
+
+// Restore stores entries under one lock, overwriting existing keys. Each
+// expiry is TTL from now; a TTL of 0 or NoExpiration never expires and
+// any other negative TTL is skipped.
+func (c *memoryCache[T]) Restore(ctx context.Context, entries map[string]interfaces.Entry[T]) error {
+	if err := c.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	for k := range entries {
+		if err := c.base.ValidateKey(k); err != nil {
+			return base.WrapError(base.OpRestore, err, k)
+		}
+	}
+
+	now := time.Now()
+	var evicted []eviction
+
+	c.mu.Lock()
+	for k, e := range entries {
+		if e.TTL < 0 && e.TTL != base.NoExpiration {
+			continue
+		}
+		var expiresAt time.Time
+		if e.TTL > 0 {
+			expiresAt = now.Add(e.TTL)
+		}
+		evicted = append(evicted, c.storeLocked(k, e.Value, expiresAt)...)
+	}
+	c.mu.Unlock()
+
+	c.fireEvictions(evicted)
+	return nil
+}
@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+func snapshotConfig(t *testing.T) (path string, open func() *memoryCache[string]) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "cache.snap")
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	cfg.SnapshotPath = path
+	return path, func() *memoryCache[string] {
+		c, err := NewMemory[string](cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, open := snapshotConfig(t)
+
+	c := open()
+	if err := c.Set(ctx, "a", "alpha", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "forever", "f", base.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = open()
+	t.Cleanup(func() { _ = c.Close() })
+	v, ttl, err := c.GetWithTTL(ctx, "a")
+	if err != nil || v != "alpha" {
+		t.Fatalf("restored a = %q, %v", v, err)
+	}
+	if ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("restored TTL = %v, want the remaining hour", ttl)
+	}
+	if v, ttl, err := c.GetWithTTL(ctx, "forever"); err != nil || v != "f" || ttl != 0 {
+		t.Errorf("restored forever = %q, %v, %v; want no expiry", v, ttl, err)
+	}
+}
+
+func TestSnapshotSkipsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	_, open := snapshotConfig(t)
+
+	c := open()
+	if err := c.Set(ctx, "short", "s", 30*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "long", "l", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// short was live when saved but has expired by the time it is loaded.
+	time.Sleep(40 * time.Millisecond)
+	c = open()
+	t.Cleanup(func() { _ = c.Close() })
+	if _, err := c.Get(ctx, "short"); err == nil {
+		t.Error("expired entry restored")
+	}
+	if n, _ := c.Len(ctx); n != 1 {
+		t.Errorf("Len = %d after reload, want 1", n)
+	}
+}
+
+func TestSnapshotMissingAndCorruptFiles(t *testing.T) {
+	path, open := snapshotConfig(t)
+
+	// No file yet: start empty.
+	c := open()
+	if n, _ := c.Len(context.Background()); n != 0 {
+		t.Errorf("Len = %d without a snapshot", n)
+	}
+	_ = c.Close()
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.SnapshotPath = path
+	if _, err := NewMemory[string](cfg); err == nil {
+		t.Error("NewMemory accepted a corrupt snapshot")
+	}
+}
+
+/* ------------------ Dump / Restore ------------------ */
+
+func TestDumpRestoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.Prefix = "app:"
+	src := newTestCache[string](t, cfg)
+
+	_ = src.Set(ctx, "a", "alpha", time.Hour)
+	_ = src.Set(ctx, "forever", "f", base.NoExpiration)
+	_ = src.Set(ctx, "gone", "g", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	entries, err := src.Dump(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Dump = %v, want a and forever only", entries)
+	}
+	if e := entries["a"]; e.Value != "alpha" || e.TTL <= 59*time.Minute || e.TTL > time.Hour {
+		t.Errorf("dumped a = %+v, want alpha with about an hour left", e)
+	}
+	if e := entries["forever"]; e.Value != "f" || e.TTL != 0 {
+		t.Errorf("dumped forever = %+v, want no expiry", e)
+	}
+
+	// Entries built by hand may mark no expiry with NoExpiration; other
+	// negative TTLs are dropped.
+	entries["pinned"] = interfaces.Entry[string]{Value: "p", TTL: base.NoExpiration}
+	entries["stale"] = interfaces.Entry[string]{Value: "s", TTL: -time.Second}
+
+	dst := newTestCache[string](t, cfg)
+	if err := dst.Restore(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+	if v, ttl, err := dst.GetWithTTL(ctx, "pinned"); err != nil || v != "p" || ttl != 0 {
+		t.Errorf("restored pinned = %q, %v, %v; want no expiry", v, ttl, err)
+	}
+	if _, err := dst.Get(ctx, "stale"); err == nil {
+		t.Error("entry with a negative TTL was restored")
+	}
+	if v, ttl, err := dst.GetWithTTL(ctx, "a"); err != nil || v != "alpha" || (entries["a"].TTL-ttl).Abs() > time.Second {
+		t.Errorf("restored a = %q, %v, %v; want TTL near %v", v, ttl, err, entries["a"].TTL)
+	}
+	if v, ttl, err := dst.GetWithTTL(ctx, "forever"); err != nil || v != "f" || ttl != 0 {
+		t.Errorf("restored forever = %q, %v, %v", v, ttl, err)
+	}
+}
+
+func TestRestoreExpiresFromRestoreTime(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[string](t, testConfig())
+
+	entries := map[string]interfaces.Entry[string]{
+		"k":       {Value: "v", TTL: 30 * time.Millisecond},
+		"invalid": {Value: "x", TTL: -time.Second},
+	}
+	if err := c.Restore(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := c.Exists(ctx, "invalid"); ok {
+		t.Error("entry with a negative TTL restored")
+	}
+	if _, ttl, err := c.GetWithTTL(ctx, "k"); err != nil || ttl <= 0 || ttl > 30*time.Millisecond {
+		t.Errorf("GetWithTTL = %v, %v; want at most 30ms left", ttl, err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, err := c.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("Get after the restored TTL = %v, want a miss", err)
+	}
+}
+
+func TestRestoreRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache[string](t, testConfig())
+
+	err := c.Restore(ctx, map[string]interfaces.Entry[string]{"ok": {Value: "v"}, "": {Value: "v"}})
+	if err == nil {
+		t.Fatal("Restore accepted an empty key")
+	}
+	if ok, _ := c.Exists(ctx, "ok"); ok {
+		t.Error("Restore stored entries despite rejecting the batch")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Dump(cancelled); err == nil {
+		t.Error("Dump ignored a cancelled context")
+	}
+}
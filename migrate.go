@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Migration ------------------ */
+
+const defaultMigrateBatchSize = 100
+
+// MigrateOptions controls Migrate. The zero value copies everything with
+// the destination's default TTL, one batch at a time.
+type MigrateOptions struct {
+	// Prefix restricts the copy to logical keys starting with it.
+	Prefix string
+
+	// TTL applied to every copied entry; zero uses the destination default.
+	TTL time.Duration
+
+	// BatchSize is the number of entries per SetManyPipeline call
+	// (default 100).
+	BatchSize int
+
+	// Concurrency is the number of batches written at once (default 1).
+	Concurrency int
+}
+
+// Migrate copies the entries of src into dst through SetManyPipeline and
+// returns how many were written. Keys are copied as logical keys, so src
+// and dst may use different prefixes. src must support Range. The first
+// failure stops the copy; entries already written stay in dst.
+func Migrate[T any](
+	ctx context.Context,
+	src, dst interfaces.AdvancedCache[T],
+	opts MigrateOptions,
+) (int64, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultMigrateBatchSize
+	}
+	workers := max(opts.Concurrency, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		copied   atomic.Int64
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	batches := make(chan map[string]T)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				failed, err := dst.SetManyPipelineResult(ctx, b, opts.TTL)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				copied.Add(int64(len(b) - len(failed)))
+				if err := base.FirstError(failed); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	send := func(b map[string]T) bool {
+		select {
+		case batches <- b:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	batch := make(map[string]T, batchSize)
+	err := src.Range(ctx, func(key string, value T) bool {
+		if !strings.HasPrefix(key, opts.Prefix) {
+			return true
+		}
+		batch[key] = value
+		if len(batch) < batchSize {
+			return true
+		}
+		if !send(batch) {
+			return false
+		}
+		batch = make(map[string]T, batchSize)
+		return true
+	})
+	if err == nil && len(batch) > 0 {
+		send(batch)
+	}
+	close(batches)
+	wg.Wait()
+
+	switch {
+	case firstErr != nil:
+		return copied.Load(), fmt.Errorf("migrate: %w", firstErr)
+	case err != nil:
+		return copied.Load(), fmt.Errorf("migrate: %w", err)
+	case ctx.Err() != nil:
+		return copied.Load(), fmt.Errorf("migrate: %w", ctx.Err())
+	}
+	return copied.Load(), nil
+}
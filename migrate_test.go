@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+func newTestAdvanced[T any](t *testing.T) interfaces.AdvancedCache[T] {
+	t.Helper()
+	c, err := NewAdvancedMemory[T]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func newAdvancedFrom[T any](t *testing.T, cfg config.Config) interfaces.AdvancedCache[T] {
+	t.Helper()
+	c, err := NewAdvanced[T](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestMigrateMemoryToRedis(t *testing.T) {
+	ctx := context.Background()
+	mcfg := config.DefaultConfig()
+	mcfg.Prefix = "mem:"
+	mcfg.MaxSize = 2000
+	src := newAdvancedFrom[int](t, mcfg)
+
+	mr := miniredis.RunT(t)
+	rcfg := redisConfig(mr)
+	rcfg.Prefix = "app:"
+	dst := newAdvancedFrom[int](t, rcfg)
+
+	for i := range 1000 {
+		if err := src.Set(ctx, "user:"+strconv.Itoa(i), i, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := Migrate(ctx, src, dst, MigrateOptions{TTL: 10 * time.Minute, BatchSize: 64, Concurrency: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1000 {
+		t.Errorf("Migrate copied %d entries, want 1000", n)
+	}
+	if keys := mr.Keys(); len(keys) != 1000 {
+		t.Fatalf("Redis holds %d keys, want 1000", len(keys))
+	}
+	// Keys are rewritten under the destination prefix with the chosen TTL.
+	if !mr.Exists("app:user:999") || mr.Exists("mem:user:999") {
+		t.Errorf("user:999 not stored under app:")
+	}
+	if ttl := mr.TTL("app:user:0"); ttl != 10*time.Minute {
+		t.Errorf("TTL = %v, want 10m", ttl)
+	}
+	if v, err := dst.Get(ctx, "user:500"); err != nil || v != 500 {
+		t.Errorf("dst Get(user:500) = %d, %v", v, err)
+	}
+}
+
+func TestMigratePrefixFilter(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newTestAdvanced[string](t), newTestAdvanced[string](t)
+	_ = src.Set(ctx, "user:1", "a", time.Minute)
+	_ = src.Set(ctx, "user:2", "b", time.Minute)
+	_ = src.Set(ctx, "order:1", "c", time.Minute)
+
+	n, err := Migrate(ctx, src, dst, MigrateOptions{Prefix: "user:"})
+	if err != nil || n != 2 {
+		t.Fatalf("Migrate = %d, %v; want 2", n, err)
+	}
+	if ok, _ := dst.Exists(ctx, "order:1"); ok {
+		t.Error("order:1 copied despite the prefix filter")
+	}
+}
+
+func TestMigrateStopsOnDestinationFailure(t *testing.T) {
+	ctx := context.Background()
+	src := newTestAdvanced[int](t)
+	for i := range 10 {
+		_ = src.Set(ctx, strconv.Itoa(i), i, time.Minute)
+	}
+	mr := miniredis.RunT(t)
+	rcfg := redisConfig(mr)
+	rcfg.MaxRetries = -1
+	dst := newAdvancedFrom[int](t, rcfg)
+	mr.SetError("READONLY replica")
+
+	if _, err := Migrate(ctx, src, dst, MigrateOptions{BatchSize: 2}); err == nil {
+		t.Error("Migrate succeeded while every write failed")
+	}
+}
+
+func TestMigrateSkipsNegativeMarkers(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newTestAdvanced[string](t), newTestAdvanced[string](t)
+
+	if err := src.Set(ctx, "user:1", "ann", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_, err := src.GetOrSetWithNegative(ctx, "user:2", time.Minute, time.Minute, func() (string, bool, error) {
+		return "", false, nil
+	})
+	if !errors.Is(err, base.ErrNegativeCached) {
+		t.Fatal(err)
+	}
+
+	n, err := Migrate(ctx, src, dst, MigrateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Migrate copied %d entries, want 1", n)
+	}
+	if exists, _ := dst.Exists(ctx, base.NegativeKey("user:2")); exists {
+		t.Error("negative marker copied to the destination")
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/advanced"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/memcached"
+	"github.com/os-golib/go-cache/memory"
+	"github.com/os-golib/go-cache/redis"
+)
+
+/* ------------------ Options ------------------ */
+
+// Option customizes the backend built by the New* constructors. Options
+// carry what cannot live in config.Config because it depends on T.
+type Option[T any] func(*options[T])
+
+type options[T any] struct {
+	serializer base.Serializer[T]
+	loader     func(ctx context.Context, key string) (T, error)
+	costFn     func(T) int64
+	earlyBeta  float64
+
+	retryAttempts int
+	retryDelay    time.Duration
+
+	adaptiveMin, adaptiveMax time.Duration
+	adaptivePrefix           func(key string) string
+
+	evictOnDecodeError bool
+}
+
+// WithSerializer selects the serializer the backend uses to encode values:
+// Redis and memcached for every read and write, memory for snapshots.
+// Defaults to JSON.
+func WithSerializer[T any](s base.Serializer[T]) Option[T] {
+	return func(o *options[T]) {
+		o.serializer = s
+	}
+}
+
+// WithLoader makes Get on a miss load the value with fn and cache it with
+// the configured TTL, as GetOrSet would. The loader's error is returned
+// when it cannot produce the value. Only the NewAdvanced* constructors use
+// it; nil keeps plain misses.
+func WithLoader[T any](fn func(ctx context.Context, key string) (T, error)) Option[T] {
+	return func(o *options[T]) {
+		o.loader = fn
+	}
+}
+
+// WithCostFunc weighs memory cache entries with fn for eviction against
+// MaxCost. Redis ignores it.
+func WithCostFunc[T any](fn func(T) int64) Option[T] {
+	return func(o *options[T]) {
+		o.costFn = fn
+	}
+}
+
+// WithEarlyExpiration enables XFetch-style probabilistic early refresh in
+// GetOrSet with the given beta (1 is a good default). Only the NewAdvanced*
+// constructors use it.
+func WithEarlyExpiration[T any](beta float64) Option[T] {
+	return func(o *options[T]) {
+		o.earlyBeta = beta
+	}
+}
+
+// WithEvictOnDecodeError makes the Redis backend delete values it cannot
+// decode and report them as misses, so a serializer change heals itself
+// instead of failing reads until the entries expire. Memory ignores it.
+func WithEvictOnDecodeError[T any](evict bool) Option[T] {
+	return func(o *options[T]) {
+		o.evictOnDecodeError = evict
+	}
+}
+
+// WithRetry retries advanced cache operations failing with connection or
+// lock errors, up to maxAttempts in total, with jittered exponential
+// backoff from baseDelay. Misses and serialization or context errors are
+// never retried. Only the NewAdvanced* constructors use it.
+func WithRetry[T any](maxAttempts int, baseDelay time.Duration) Option[T] {
+	return func(o *options[T]) {
+		o.retryAttempts = maxAttempts
+		o.retryDelay = baseDelay
+	}
+}
+
+// WithAdaptiveTTL scales the TTL GetOrSet writes by the hit rate seen for
+// the key's prefix, within [minTTL, maxTTL]: hot prefixes are kept longer,
+// churny ones expire sooner. prefixFn extracts the prefix; nil uses the
+// key up to its first ':'. Off unless given. Only the NewAdvanced*
+// constructors use it.
+func WithAdaptiveTTL[T any](minTTL, maxTTL time.Duration, prefixFn func(key string) string) Option[T] {
+	return func(o *options[T]) {
+		o.adaptiveMin = minTTL
+		o.adaptiveMax = maxTTL
+		o.adaptivePrefix = prefixFn
+	}
+}
+
+func buildOptions[T any](opts []Option[T]) options[T] {
+	var o options[T]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o options[T]) redisOptions() []redis.Option[T] {
+	var opts []redis.Option[T]
+	if o.serializer != nil {
+		opts = append(opts, redis.WithSerializer(o.serializer))
+	}
+	if o.evictOnDecodeError {
+		opts = append(opts, redis.WithEvictOnDecodeError[T](true))
+	}
+	return opts
+}
+
+func (o options[T]) memcachedOptions() []memcached.Option[T] {
+	var opts []memcached.Option[T]
+	if o.serializer != nil {
+		opts = append(opts, memcached.WithSerializer(o.serializer))
+	}
+	return opts
+}
+
+func (o options[T]) memoryOptions() []memory.Option[T] {
+	var opts []memory.Option[T]
+	if o.serializer != nil {
+		opts = append(opts, memory.WithSerializer(o.serializer))
+	}
+	if o.costFn != nil {
+		opts = append(opts, memory.WithCostFunc(o.costFn))
+	}
+	return opts
+}
+
+func (o options[T]) advancedOptions() []advanced.Option[T] {
+	var opts []advanced.Option[T]
+	if o.loader != nil {
+		opts = append(opts, advanced.WithLoader[T](o.loader))
+	}
+	if o.earlyBeta > 0 {
+		opts = append(opts, advanced.WithEarlyExpiration[T](o.earlyBeta))
+	}
+	if o.retryAttempts > 1 {
+		opts = append(opts, advanced.WithRetry[T](o.retryAttempts, o.retryDelay))
+	}
+	if o.adaptiveMax > 0 {
+		opts = append(opts, advanced.WithAdaptiveTTL[T](o.adaptiveMin, o.adaptiveMax, o.adaptivePrefix))
+	}
+	return opts
+}
@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// tagSerializer stores strings as "tag:" + value and counts its calls, so
+// tests can see both the wire format and that it was used for decoding.
+type tagSerializer struct {
+	encodes, decodes atomic.Int64
+}
+
+func (s *tagSerializer) Encode(v string) ([]byte, error) {
+	s.encodes.Add(1)
+	return []byte("tag:" + v), nil
+}
+
+func (s *tagSerializer) Decode(b []byte) (string, error) {
+	s.decodes.Add(1)
+	v, ok := bytes.CutPrefix(b, []byte("tag:"))
+	if !ok {
+		return "", errors.New("not tagged")
+	}
+	return string(v), nil
+}
+
+func redisConfig(mr *miniredis.Miniredis) config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Type = config.TypeRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	return cfg
+}
+
+func assertSerializerUsed(t *testing.T, c interface {
+	Set(context.Context, string, string, time.Duration) error
+	Get(context.Context, string) (string, error)
+}, mr *miniredis.Miniredis, s *tagSerializer, prefix string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := c.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := mr.Get(prefix + "k"); err != nil || raw != "tag:v" {
+		t.Errorf("stored payload = %q, %v; want the custom encoding", raw, err)
+	}
+	if got, err := c.Get(ctx, "k"); err != nil || got != "v" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if s.encodes.Load() == 0 || s.decodes.Load() == 0 {
+		t.Errorf("serializer calls: %d encodes, %d decodes", s.encodes.Load(), s.decodes.Load())
+	}
+}
+
+func TestWithSerializerUsedByRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := redisConfig(mr)
+	s := &tagSerializer{}
+
+	c, err := NewAdvanced[string](cfg, WithSerializer[string](s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	assertSerializerUsed(t, c, mr, s, cfg.Prefix)
+}
+
+func TestWithSerializerUsedBySharedRedisClient(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := redisConfig(mr)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	s := &tagSerializer{}
+
+	c, err := NewAdvancedFromRedisClient[string](client, cfg, WithSerializer[string](s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	assertSerializerUsed(t, c, mr, s, cfg.Prefix)
+}
+
+func TestDefaultSerializerIsJSON(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := redisConfig(mr)
+
+	c, err := New[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	if err := c.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := (&base.JsonSerializer[string]{}).Encode("v")
+	if raw, _ := mr.Get(cfg.Prefix + "k"); raw != string(want) {
+		t.Errorf("stored payload = %q, want JSON %q", raw, want)
+	}
+}
+
+func TestWithLoaderReadsThrough(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	c, err := NewAdvanced[string](cfg, WithLoader(func(_ context.Context, key string) (string, error) {
+		return "db:" + key, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	if v, err := c.Get(ctx, "user:1"); err != nil || v != "db:user:1" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	if ok, _ := c.Exists(ctx, "user:1"); !ok {
+		t.Error("loaded value not cached")
+	}
+}
+
+func TestWithCostFuncReachesMemory(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.MaxCost = 10
+	c, err := New[string](cfg, WithCostFunc(func(v string) int64 { return int64(len(v)) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	_ = c.Set(ctx, "a", "123456", time.Minute)
+	_ = c.Set(ctx, "b", "123456", time.Minute)
+	if ok, _ := c.Exists(ctx, "a"); ok {
+		t.Error("a kept although the summed cost exceeds MaxCost")
+	}
+	if ok, _ := c.Exists(ctx, "b"); !ok {
+		t.Error("b evicted")
+	}
+}
+
+func TestWithEvictOnDecodeErrorReachesRedis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := redisConfig(mr)
+	c, err := New[int](cfg, WithEvictOnDecodeError[int](true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	_ = mr.Set(cfg.Prefix+"k", "not a number")
+	if _, err := c.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("Get = %v, want a miss", err)
+	}
+	if mr.Exists(cfg.Prefix + "k") {
+		t.Error("corrupt value not deleted")
+	}
+}
+
+func TestWithAdaptiveTTLReachesAdvanced(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := redisConfig(mr)
+	c, err := NewAdvanced[int](cfg, WithAdaptiveTTL[int](time.Second, time.Hour, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	fn := func() (int, error) { return 1, nil }
+	for range 30 {
+		_, _ = c.GetOrSet(ctx, "user:1", time.Minute, fn)
+	}
+	_ = c.Delete(ctx, "user:1")
+	_, _ = c.GetOrSet(ctx, "user:1", time.Minute, fn)
+
+	if ttl := mr.TTL(cfg.Prefix + "user:1"); ttl <= 2*time.Minute {
+		t.Errorf("TTL = %v, want the hot key kept longer than 1m", ttl)
+	}
+}
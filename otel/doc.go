@@ -0,0 +1,8 @@
+// Package otel adapts an OpenTelemetry trace.Tracer to the cache's tracing
+// hook. It is built only with the "otel" build tag so builds without it
+// do not compile OpenTelemetry in:
+//
+//	go build -tags otel ./...
+//
+// Wire it in with Builder.WithTracer(otel.NewTracer(tp.Tracer("cache"))).
+package otel
@@ -0,0 +1,66 @@
+//go:build otel
+
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Tracer ------------------ */
+
+type tracer struct {
+	t trace.Tracer
+}
+
+// NewTracer returns a cache tracer that starts spans with t.
+func NewTracer(t trace.Tracer) interfaces.Tracer {
+	return &tracer{t: t}
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, interfaces.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &span{s: s}
+}
+
+/* ------------------ Span ------------------ */
+
+type span struct {
+	s trace.Span
+}
+
+func (s *span) SetAttribute(key string, value any) {
+	s.s.SetAttributes(toAttribute(key, value))
+}
+
+func (s *span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.s.End()
+}
+
+func toAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,108 @@
+//go:build otel
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	cache "github.com/os-golib/go-cache"
+)
+
+func newRecorder(t *testing.T) (*tracetest.InMemoryExporter, trace.Tracer) {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return exp, tp.Tracer("cache-test")
+}
+
+func attrs(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	out := make(map[attribute.Key]attribute.Value)
+	for _, kv := range s.Attributes {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func TestTracerRecordsCacheOperations(t *testing.T) {
+	exp, tr := newRecorder(t)
+
+	cfg, err := cache.NewBuilder().WithMemory().WithTracer(NewTracer(tr)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := cache.NewAdvanced[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].Name != "cache.set" || spans[1].Name != "cache.get" {
+		t.Fatalf("span names = %q, %q", spans[0].Name, spans[1].Name)
+	}
+
+	get := spans[1]
+	if get.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kind = %v, want client", get.SpanKind)
+	}
+	a := attrs(get)
+	if got := a["cache.operation"].AsString(); got != "get" {
+		t.Errorf("cache.operation = %q", got)
+	}
+	if got := a["cache.backend"].AsString(); got != "memory" {
+		t.Errorf("cache.backend = %q", got)
+	}
+	if got := a["cache.key_count"].AsInt64(); got != 1 {
+		t.Errorf("cache.key_count = %d", got)
+	}
+	if !a["cache.hit"].AsBool() {
+		t.Error("cache.hit not recorded as true")
+	}
+}
+
+func TestSpanRecordsError(t *testing.T) {
+	exp, tr := newRecorder(t)
+
+	_, s := NewTracer(tr).Start(context.Background(), "cache.get")
+	s.SetAttribute("cache.key", "k")
+	s.SetAttribute("cache.size", 3)
+	s.RecordError(errors.New("boom"))
+	s.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	if got.Status.Code != codes.Error || got.Status.Description != "boom" {
+		t.Errorf("status = %+v", got.Status)
+	}
+	if len(got.Events) != 1 || got.Events[0].Name != "exception" {
+		t.Errorf("events = %+v", got.Events)
+	}
+	a := attrs(got)
+	if a["cache.key"].AsString() != "k" || a["cache.size"].AsInt64() != 3 {
+		t.Errorf("attributes = %v", got.Attributes)
+	}
+}
@@ -0,0 +1,199 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Set If Absent ------------------ */
+
+// setIfAbsentScript writes ARGV[1] with a PX of ARGV[2] (0 = no expiry)
+// unless KEYS[1] already exists, in which case the current value is
+// returned. Read and write happen in one round trip.
+var setIfAbsentScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur then
+	return cur
+end
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return false
+`)
+
+// SetIfAbsent stores value unless key already holds one. It reports true
+// when value was written; otherwise it returns the value that won. An
+// existing entry written with an older serializer version is overwritten.
+func (r *redisCache[T]) SetIfAbsent(ctx context.Context, key string, value T, ttl time.Duration) (T, bool, error) {
+	var zero T
+
+	if err := r.base.ValidateKey(key); err != nil {
+		return zero, false, err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return zero, false, err
+	}
+
+	data, err := r.serializer.Encode(value)
+	if err != nil {
+		return zero, false, base.SerializationError(base.OpSet, base.ErrSerialize, err, key)
+	}
+
+	fk := r.base.FullKey(key)
+	ttl = expiration(r.base.WriteTTL(ctx, ttl))
+
+	cur, err := setIfAbsentScript.Run(ctx, r.client, []string{fk}, data, ttl.Milliseconds()).Text()
+	if err == redis.Nil {
+		return value, true, nil
+	}
+	if err != nil {
+		return zero, false, wrapError(base.OpSet, err, key)
+	}
+
+	existing, err := r.serializer.Decode([]byte(cur))
+	if errors.Is(err, base.ErrStaleVersion) {
+		if err := r.client.Set(ctx, fk, data, ttl).Err(); err != nil {
+			return zero, false, wrapError(base.OpSet, err, key)
+		}
+		return value, true, nil
+	}
+	if err != nil {
+		return zero, false, base.SerializationError(base.OpGet, base.ErrDeserialize, err, key)
+	}
+	r.repair(ctx, fk, []byte(cur), existing)
+	return existing, false, nil
+}
+
+/* ------------------ Read-Modify-Write ------------------ */
+
+// updateRetries bounds how often Update retries after losing a WATCH race.
+const updateRetries = 16
+
+// Update runs fn on key's current value and writes the result with
+// WATCH/MULTI/EXEC, retrying when another client changed key in between.
+// fn may therefore run more than once. After updateRetries conflicts it
+// gives up with ErrConflict.
+func (r *redisCache[T]) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) error {
+	if err := r.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	fk := r.base.FullKey(key)
+	var fnErr error
+
+	txf := func(tx *redis.Tx) error {
+		var cur T
+		found := false
+
+		data, err := tx.Get(ctx, fk).Bytes()
+		switch {
+		case err == redis.Nil:
+		case err != nil:
+			return err
+		default:
+			v, err := r.serializer.Decode(data)
+			if err != nil && !errors.Is(err, base.ErrStaleVersion) {
+				return base.SerializationError(base.OpUpdate, base.ErrDeserialize, err, key)
+			}
+			cur, found = v, err == nil
+		}
+
+		next, err := fn(cur, found)
+		if err != nil {
+			fnErr = err
+			return err
+		}
+		out, err := r.serializer.Encode(next)
+		if err != nil {
+			return base.SerializationError(base.OpUpdate, base.ErrSerialize, err, key)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, fk, out, expiration(r.base.WriteTTL(ctx, ttl)))
+			return nil
+		})
+		return err
+	}
+
+	for range updateRetries {
+		err := r.client.Watch(ctx, txf, fk)
+		switch {
+		case err == nil:
+			return nil
+		case fnErr != nil:
+			return fnErr
+		case errors.Is(err, redis.TxFailedErr):
+			continue
+		case base.IsSerializationError(err):
+			return err
+		default:
+			return wrapError(base.OpUpdate, err, key)
+		}
+	}
+	return base.WrapError(base.OpUpdate, base.ErrConflict, key)
+}
+
+/* ------------------ Compare And Swap ------------------ */
+
+// casScript replaces KEYS[1] with ARGV[2] (PX ARGV[3], 0 = no expiry)
+// only while it holds exactly ARGV[1].
+var casScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap stores newVal only if key's serialized value is
+// byte-for-byte equal to that of oldVal. Missing keys never match.
+func (r *redisCache[T]) CompareAndSwap(
+	ctx context.Context,
+	key string,
+	oldVal, newVal T,
+	ttl time.Duration,
+) (bool, error) {
+	if err := r.base.ValidateKey(key); err != nil {
+		return false, err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	oldData, err := r.serializer.Encode(oldVal)
+	if err != nil {
+		return false, base.SerializationError(base.OpCompareAndSwap, base.ErrSerialize, err, key)
+	}
+	newData, err := r.serializer.Encode(newVal)
+	if err != nil {
+		return false, base.SerializationError(base.OpCompareAndSwap, base.ErrSerialize, err, key)
+	}
+
+	ttl = expiration(r.base.WriteTTL(ctx, ttl))
+	n, err := casScript.Run(ctx, r.client, []string{r.base.FullKey(key)}, oldData, newData, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, wrapError(base.OpCompareAndSwap, err, key)
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Update ------------------ */
+
+func TestUpdateConcurrentAppendsLoseNothing(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+
+	// Separate caches behave like separate processes.
+	const workers, each = 8, 10
+	var wg sync.WaitGroup
+	for w := range workers {
+		r := newTestCache[[]int](t, cfg)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range each {
+				for {
+					err := r.Update(ctx, "list", time.Minute, func(cur []int, _ bool) ([]int, error) {
+						return append(cur, w*each+i), nil
+					})
+					// A conflict means nothing was written; try again.
+					if errors.Is(err, base.ErrConflict) {
+						continue
+					}
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					break
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := newTestCache[[]int](t, cfg).Get(ctx, "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != workers*each {
+		t.Fatalf("list has %d items, want %d", len(got), workers*each)
+	}
+	slices.Sort(got)
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("item %d = %d, updates lost or duplicated", i, v)
+		}
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+
+	_ = r.Set(ctx, "n", 1, time.Minute)
+	errVeto := errors.New("veto")
+	if err := r.Update(ctx, "n", time.Minute, func(int, bool) (int, error) { return 9, errVeto }); !errors.Is(err, errVeto) {
+		t.Errorf("Update = %v, want fn's error", err)
+	}
+	if v, _ := r.Get(ctx, "n"); v != 1 {
+		t.Errorf("n = %d after a failed update, want 1", v)
+	}
+
+	mr.Set(cfg.Prefix+"bad", "not json")
+	err := r.Update(ctx, "bad", time.Minute, func(int, bool) (int, error) {
+		t.Error("fn ran on an undecodable value")
+		return 0, nil
+	})
+	if !errors.Is(err, base.ErrDeserialize) {
+		t.Errorf("Update(corrupt) = %v, want ErrDeserialize", err)
+	}
+}
+
+/* ------------------ CompareAndSwap ------------------ */
+
+func TestCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[string](t, cfg)
+	_ = r.Set(ctx, "k", "a", base.NoExpiration)
+
+	if ok, err := r.CompareAndSwap(ctx, "k", "a", "b", time.Minute); err != nil || !ok {
+		t.Errorf("matching swap = %v, %v", ok, err)
+	}
+	if ttl := mr.TTL(cfg.Prefix + "k"); ttl != time.Minute {
+		t.Errorf("swapped value TTL = %v, want 1m", ttl)
+	}
+	if ok, err := r.CompareAndSwap(ctx, "k", "a", "c", time.Minute); err != nil || ok {
+		t.Errorf("mismatched swap = %v, %v; want false, nil", ok, err)
+	}
+	if v, _ := r.Get(ctx, "k"); v != "b" {
+		t.Errorf("value = %q, want b", v)
+	}
+
+	if ok, err := r.CompareAndSwap(ctx, "missing", "", "x", time.Minute); err != nil || ok {
+		t.Errorf("swap on a missing key = %v, %v; want false, nil", ok, err)
+	}
+	if mr.Exists(cfg.Prefix + "missing") {
+		t.Error("failed swap created the key")
+	}
+
+	if ok, _ := r.CompareAndSwap(ctx, "k", "b", "d", base.NoExpiration); !ok {
+		t.Fatal("swap to NoExpiration failed")
+	}
+	if ttl := mr.TTL(cfg.Prefix + "k"); ttl != 0 {
+		t.Errorf("NoExpiration swap left TTL %v", ttl)
+	}
+}
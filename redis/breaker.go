@@ -0,0 +1,185 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Circuit Breaker ------------------ */
+
+// Breaker states as reported in CacheStats.CircuitState.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+const defaultBreakerCooldown = 30 * time.Second
+
+// breaker is a go-redis hook that fails commands fast once threshold
+// consecutive connection-level failures were seen. After cooldown a single
+// probe is let through: success closes the circuit, failure reopens it.
+// Redis reply errors and misses do not count as failures; calls ended by
+// their context count as neither failure nor success.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	onReject  func()
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration, onReject func()) *breaker {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		onReject:  onReject,
+		state:     CircuitClosed,
+	}
+}
+
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may proceed, moving an open circuit to
+// half-open once the cooldown has elapsed.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+	}
+
+	if b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.state == CircuitHalfOpen
+	if probe {
+		b.probing = false
+	}
+
+	// A call cut short by its caller's context says nothing either way.
+	// A probe that was cut short still did not prove the server is back.
+	if base.IsContextError(err) {
+		if probe {
+			b.state = CircuitOpen
+			b.openedAt = time.Now()
+		}
+		return
+	}
+
+	if !isFailure(err) {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if probe || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breaker) reject() error {
+	if b.onReject != nil {
+		b.onReject()
+	}
+	return fmt.Errorf("%w: %w", base.ErrConnection, base.ErrCircuitOpen)
+}
+
+// isFailure reports whether err says something about Redis availability.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+
+	// A reply error means the server answered.
+	var rerr redis.Error
+	return !errors.As(err, &rerr)
+}
+
+/* ------------------ Hook ------------------ */
+
+// guardedKey marks a context whose command already passed the breaker.
+// Commands issued while it runs, such as the HELLO go-redis sends on a
+// new connection, share its outcome instead of being counted again.
+type guardedKey struct{}
+
+func guarded(ctx context.Context) bool {
+	return ctx.Value(guardedKey{}) != nil
+}
+
+func (b *breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if guarded(ctx) {
+			return next(ctx, cmd)
+		}
+		if !b.allow() {
+			err := b.reject()
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(context.WithValue(ctx, guardedKey{}, true), cmd)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if guarded(ctx) {
+			return next(ctx, cmds)
+		}
+		if !b.allow() {
+			err := b.reject()
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(context.WithValue(ctx, guardedKey{}, true), cmds)
+		b.record(err)
+		return err
+	}
+}
@@ -0,0 +1,190 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// newBreakerCache returns a cache whose breaker opens after 3 failures
+// for 100ms. The client does not retry, so each failure costs one dial.
+func newBreakerCache(t *testing.T, mr *miniredis.Miniredis) *redisCache[string] {
+	t.Helper()
+	cfg := testConfig(mr)
+	cfg.BreakerThreshold = 3
+	cfg.BreakerCooldown = 100 * time.Millisecond
+
+	client := redis.NewClient(&redis.Options{
+		Addr:          mr.Addr(),
+		MaxRetries:    -1,
+		DialerRetries: 1,
+		PoolSize:      100,
+	})
+	r, err := NewFromClient[string](client, cfg, WithClientOwnership[string](true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newBreakerCache(t, mr)
+
+	mr.Close()
+	for i := range 3 {
+		if _, err := r.Get(ctx, "k"); err == nil || errors.Is(err, base.ErrCircuitOpen) {
+			t.Fatalf("failure %d = %v, want a connection error", i+1, err)
+		}
+	}
+	if got := r.Stats(ctx).CircuitState; got != CircuitOpen {
+		t.Fatalf("CircuitState after 3 failures = %q, want open", got)
+	}
+
+	// While open, calls fail fast without touching the network.
+	start := time.Now()
+	_, err := r.Get(ctx, "k")
+	if !errors.Is(err, base.ErrCircuitOpen) || !base.IsConnectionError(err) {
+		t.Errorf("Get while open = %v, want ErrCircuitOpen", err)
+	}
+	if d := time.Since(start); d > 10*time.Millisecond {
+		t.Errorf("rejected call took %v", d)
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(120 * time.Millisecond)
+	// Stats would itself send the probe, so read the state directly.
+	if got := r.breaker.State(); got != CircuitHalfOpen {
+		t.Errorf("state after cooldown = %q, want half_open", got)
+	}
+	if err := r.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("probe after cooldown = %v", err)
+	}
+	if got := r.Stats(ctx).CircuitState; got != CircuitClosed {
+		t.Errorf("CircuitState after a successful probe = %q, want closed", got)
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newBreakerCache(t, mr)
+
+	mr.Close()
+	for range 3 {
+		_, _ = r.Get(ctx, "k")
+	}
+	time.Sleep(120 * time.Millisecond)
+
+	if _, err := r.Get(ctx, "k"); err == nil || errors.Is(err, base.ErrCircuitOpen) {
+		t.Fatalf("probe = %v, want the connection error", err)
+	}
+	if _, err := r.Get(ctx, "k"); !errors.Is(err, base.ErrCircuitOpen) {
+		t.Errorf("Get after a failed probe = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerIgnoresReplyErrorsAndMisses(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newBreakerCache(t, mr)
+
+	for range 5 {
+		if _, err := r.Get(ctx, "missing"); !base.IsCacheMiss(err) {
+			t.Fatalf("Get = %v, want a miss", err)
+		}
+	}
+	mr.SetError("ERR busy")
+	for range 5 {
+		_, _ = r.Get(ctx, "k")
+	}
+	mr.SetError("")
+
+	if got := r.Stats(ctx).CircuitState; got != CircuitClosed {
+		t.Errorf("CircuitState = %q after misses and reply errors, want closed", got)
+	}
+}
+
+func TestBreakerLeavesSharedClientAlone(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.BreakerThreshold = 1
+	cfg.BreakerCooldown = time.Minute
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1, DialerRetries: 1})
+	t.Cleanup(func() { _ = client.Close() })
+	r, err := NewFromClient[string](client, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	mr.Close()
+	_, _ = r.Get(ctx, "k")
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A breaker on the shared client would now reject its other users.
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Errorf("shared client Ping = %v after the cache saw a failure", err)
+	}
+	if r.breaker != nil {
+		t.Error("breaker created for a client the cache does not own")
+	}
+}
+
+func TestBreakerIgnoresContextErrors(t *testing.T) {
+	errDown := errors.New("connection refused")
+	b := newBreaker(3, 50*time.Millisecond, nil)
+
+	// Canceled calls neither add to nor reset the failure count.
+	for _, err := range []error{errDown, errDown, context.Canceled, context.DeadlineExceeded} {
+		if !b.allow() {
+			t.Fatal("closed circuit rejected a call")
+		}
+		b.record(err)
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Fatalf("state = %q after 2 failures and 2 canceled calls, want closed", got)
+	}
+	b.allow()
+	b.record(errDown)
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("state = %q after the third failure, want open", got)
+	}
+
+	// A probe cut short by its deadline leaves the circuit open.
+	time.Sleep(60 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("no probe allowed after the cooldown")
+	}
+	b.record(context.DeadlineExceeded)
+	if got := b.State(); got != CircuitOpen {
+		t.Errorf("state after a timed-out probe = %q, want open", got)
+	}
+	if b.allow() {
+		t.Error("call allowed right after a timed-out probe")
+	}
+
+	// The next probe still decides.
+	time.Sleep(60 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("no second probe allowed after the cooldown")
+	}
+	b.record(nil)
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("state after a successful probe = %q, want closed", got)
+	}
+}
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Error Classification ------------------ */
+
+// wrapError classifies a raw client error before wrapping it, so callers
+// can match sentinels such as base.ErrClusterRedirect.
+func wrapError(op base.Op, err error, key string) error {
+	return base.WrapError(op, classifyError(err), key)
+}
+
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := redis.IsMovedError(err); ok {
+		return fmt.Errorf("%w: %v", base.ErrClusterRedirect, err)
+	}
+	if _, ok := redis.IsAskError(err); ok {
+		return fmt.Errorf("%w: %v", base.ErrClusterRedirect, err)
+	}
+	return err
+}
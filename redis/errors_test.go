@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+// clusterNode makes mr answer like a Redis Cluster node: CLUSTER INFO
+// reports a cluster state and GET on a "moved:" or "ask:" key redirects.
+func clusterNode(mr *miniredis.Miniredis) {
+	mr.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+		switch {
+		case cmd == "CLUSTER" && len(args) == 1 && strings.EqualFold(args[0], "INFO"):
+			c.WriteBulk("cluster_state:ok\r\ncluster_slots_assigned:16384\r\n")
+			return true
+		case cmd == "GET" && strings.Contains(args[0], "moved:"):
+			c.WriteError("MOVED 3999 127.0.0.1:6381")
+			return true
+		case cmd == "GET" && strings.Contains(args[0], "ask:"):
+			c.WriteError("ASK 3999 127.0.0.1:6381")
+			return true
+		}
+		return false
+	})
+}
+
+// captureLogs sends the default slog output to a buffer for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestRedirectsClassifiedAsClusterRedirect(t *testing.T) {
+	ctx := context.Background()
+	captureLogs(t)
+	mr := miniredis.RunT(t)
+	clusterNode(mr)
+	r := newTestCache[string](t, testConfig(mr))
+
+	for _, key := range []string{"moved:k", "ask:k"} {
+		_, err := r.Get(ctx, key)
+		if !errors.Is(err, base.ErrClusterRedirect) || !base.IsClusterRedirect(err) {
+			t.Errorf("Get(%q) = %v, want ErrClusterRedirect", key, err)
+		}
+		if errors.Is(err, base.ErrCacheMiss) {
+			t.Errorf("Get(%q) reported a redirect as a miss", key)
+		}
+	}
+
+	if _, err := r.Get(ctx, "plain"); errors.Is(err, base.ErrClusterRedirect) {
+		t.Errorf("Get(plain) = %v, a miss classified as a redirect", err)
+	}
+}
+
+func TestClassifyErrorLeavesOtherErrors(t *testing.T) {
+	other := errors.New("ERR wrong kind of value")
+	if got := classifyError(other); got != other {
+		t.Errorf("classifyError(%v) = %v, want it unchanged", other, got)
+	}
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) != nil")
+	}
+}
+
+func TestStartupWarnsOnClusterEndpoint(t *testing.T) {
+	logs := captureLogs(t)
+	mr := miniredis.RunT(t)
+	mr.RequireUserAuth("user", "secret")
+	clusterNode(mr)
+
+	cfg := testConfig(mr)
+	cfg.RedisURL = "redis://user:secret@" + mr.Addr()
+	r := newTestCache[string](t, cfg)
+
+	if !r.cluster {
+		t.Error("cluster endpoint not detected")
+	}
+	out := logs.String()
+	if !strings.Contains(out, "cluster mode enabled") {
+		t.Errorf("no cluster warning logged: %q", out)
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("warning leaks the password: %q", out)
+	}
+}
+
+func TestStartupQuietOnStandaloneEndpoint(t *testing.T) {
+	logs := captureLogs(t)
+	r := newTestCache[string](t, testConfig(miniredis.RunT(t)))
+
+	if r.cluster {
+		t.Error("standalone endpoint reported as a cluster")
+	}
+	if strings.Contains(logs.String(), "cluster") {
+		t.Errorf("unexpected warning: %q", logs.String())
+	}
+}
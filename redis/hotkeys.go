@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+/* ------------------ Hot Keys ------------------ */
+
+const (
+	defaultHotKeysLimit = 10_000
+	defaultHotKeysTTL   = 24 * time.Hour
+
+	// hits are buffered and written at most this often, or sooner once
+	// this many distinct keys are pending
+	hotKeysFlushInterval = time.Second
+	hotKeysMaxPending    = 1000
+)
+
+// hotKeys counts hits locally and adds them to the sorted set in one
+// pipeline per flush, so tracking does not cost a round trip per hit.
+// Every flush trims the set to its limit hottest members and refreshes
+// its TTL, so it neither grows without bound nor outlives its users.
+type hotKeys struct {
+	zkey  string
+	limit int64
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	pending map[string]float64
+	flushed time.Time
+}
+
+func newHotKeys(cfg config.Config) *hotKeys {
+	if cfg.HotKeysKey == "" {
+		return nil
+	}
+	h := &hotKeys{
+		zkey:    cfg.HotKeysKey,
+		limit:   defaultHotKeysLimit,
+		ttl:     defaultHotKeysTTL,
+		pending: make(map[string]float64),
+		flushed: time.Now(),
+	}
+	if cfg.HotKeysLimit > 0 {
+		h.limit = int64(cfg.HotKeysLimit)
+	}
+	if cfg.HotKeysTTL > 0 {
+		h.ttl = cfg.HotKeysTTL
+	}
+	return h
+}
+
+// hit counts one hit on key and returns the batch to write if a flush is
+// due, nil otherwise.
+func (h *hotKeys) hit(key string) map[string]float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.pending[key]++
+	if len(h.pending) < hotKeysMaxPending && time.Since(h.flushed) < hotKeysFlushInterval {
+		return nil
+	}
+	return h.takeLocked()
+}
+
+// take returns the pending batch, if any, for an immediate flush.
+func (h *hotKeys) take() map[string]float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.takeLocked()
+}
+
+func (h *hotKeys) takeLocked() map[string]float64 {
+	h.flushed = time.Now()
+	if len(h.pending) == 0 {
+		return nil
+	}
+	batch := h.pending
+	h.pending = make(map[string]float64, len(batch))
+	return batch
+}
+
+// write adds batch to the set, trims it and refreshes its TTL.
+func (h *hotKeys) write(ctx context.Context, client redis.Cmdable, batch map[string]float64) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	pipe := client.Pipeline()
+	for key, n := range batch {
+		pipe.ZIncrBy(ctx, h.zkey, n, key)
+	}
+	pipe.ZRemRangeByRank(ctx, h.zkey, 0, -h.limit-1)
+	pipe.Expire(ctx, h.zkey, h.ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// trackHit counts a hit on key, if tracking is enabled. Failures only skew
+// the ranking and are ignored.
+func (r *redisCache[T]) trackHit(ctx context.Context, key string) {
+	if r.hot == nil {
+		return
+	}
+	if batch := r.hot.hit(key); batch != nil {
+		_ = r.hot.write(ctx, r.client, batch)
+	}
+}
+
+// HotKeys returns up to n keys with the most hits, hottest first, after
+// writing any hits still buffered here. It returns nothing when hot-key
+// tracking is disabled.
+func (r *redisCache[T]) HotKeys(ctx context.Context, n int) ([]string, error) {
+	if r.hot == nil || n <= 0 {
+		return nil, nil
+	}
+	if err := r.hot.write(ctx, r.client, r.hot.take()); err != nil {
+		return nil, wrapError(base.OpGet, err, "")
+	}
+
+	keys, err := r.client.ZRevRange(ctx, r.hot.zkey, 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, wrapError(base.OpGet, err, "")
+	}
+	return keys, nil
+}
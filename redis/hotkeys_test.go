@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestHotKeysBatchesHits(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.HotKeysKey = "hot"
+	r := newTestCache[string](t, cfg)
+	_ = r.Set(ctx, "a", "v", time.Minute)
+	_ = r.Set(ctx, "b", "v", time.Minute)
+
+	before := mr.CommandCount()
+	for range 5 {
+		_, _ = r.Get(ctx, "a")
+	}
+	_, _ = r.Get(ctx, "b")
+	if n := mr.CommandCount() - before; n != 6 {
+		t.Errorf("6 hits sent %d commands, want only the 6 GETs", n)
+	}
+	if mr.Exists("hot") {
+		t.Error("hits written before a flush was due")
+	}
+
+	// HotKeys writes what is buffered before reading.
+	if keys, err := r.HotKeys(ctx, 10); err != nil || !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("HotKeys = %v, %v; want [a b]", keys, err)
+	}
+	if score, _ := mr.ZScore("hot", "a"); score != 5 {
+		t.Errorf("score of a = %v, want 5", score)
+	}
+
+	// Once the interval has passed the next hit flushes.
+	r.hot.flushed = time.Now().Add(-2 * hotKeysFlushInterval)
+	_, _ = r.Get(ctx, "b")
+	if score, _ := mr.ZScore("hot", "b"); score != 2 {
+		t.Errorf("score of b after a due flush = %v, want 2", score)
+	}
+}
+
+func TestHotKeysSetIsTrimmedAndExpires(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.HotKeysKey = "hot"
+	cfg.HotKeysLimit = 2
+	cfg.HotKeysTTL = time.Hour
+	r := newTestCache[string](t, cfg)
+
+	for k, hits := range map[string]int{"a": 3, "b": 2, "c": 1} {
+		_ = r.Set(ctx, k, "v", time.Minute)
+		for range hits {
+			_, _ = r.Get(ctx, k)
+		}
+	}
+	if keys, err := r.HotKeys(ctx, 10); err != nil || !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("HotKeys = %v, %v; want the 2 hottest", keys, err)
+	}
+	if members, _ := mr.ZMembers("hot"); len(members) != 2 {
+		t.Errorf("set holds %v, want it trimmed to 2", members)
+	}
+	if ttl := mr.TTL("hot"); ttl != time.Hour {
+		t.Errorf("set TTL = %v, want 1h", ttl)
+	}
+}
+
+func TestCloseFlushesHotKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.HotKeysKey = "hot"
+	r, err := NewRedisCache[string](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = r.Set(ctx, "a", "v", time.Minute)
+	_, _ = r.Get(ctx, "a")
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if score, _ := mr.ZScore("hot", "a"); score != 1 {
+		t.Errorf("score of a after Close = %v, want the buffered hit", score)
+	}
+	if ttl := mr.TTL("hot"); ttl != defaultHotKeysTTL {
+		t.Errorf("set TTL = %v, want the default %v", ttl, defaultHotKeysTTL)
+	}
+}
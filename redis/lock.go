@@ -2,43 +2,92 @@ package redis
 
 import (
 	"context"
+	"crypto/rand"
+	"fmt"
+	mrand "math/rand/v2"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
 	"github.com/os-golib/go-cache/internal/base"
 )
 
-const lockValue = "1"
+/* ------------------ Scripts ------------------ */
+
+// unlockScript deletes KEYS[1] only while it still holds the caller's
+// token, so an expired lock re-acquired by someone else is left alone.
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// extendScript resets the TTL of KEYS[1] only while it holds the token.
+var extendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// newLockToken returns a random (version 4) UUID identifying a lock owner.
+func newLockToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
 
 /* ------------------ Lock API ------------------ */
 
-// TryLock attempts to acquire a distributed lock using SET NX
+// TryLock attempts to acquire a distributed lock using SET NX. On success
+// it returns the owner token that Unlock and ExtendLock require.
 func (r *redisCache[T]) TryLock(
 	ctx context.Context,
 	key string,
 	ttl time.Duration,
-) (bool, error) {
+) (string, bool, error) {
 	if err := r.base.ValidateKey(key); err != nil {
-		return false, err
+		return "", false, err
 	}
 	if err := r.base.CheckContext(ctx); err != nil {
-		return false, err
+		return "", false, err
+	}
+
+	// A lock without a TTL would outlive a crashed owner.
+	if ttl == base.NoExpiration {
+		return "", false, base.WrapError(base.OpLock, base.ErrInvalidArgument, key)
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, base.WrapError(base.OpLock, err, key)
 	}
 
 	ttl = r.base.ResolveTTL(ttl)
 	lockKey := r.base.FullKey("lock:" + key)
 
-	acquired, err := r.client.SetNX(ctx, lockKey, lockValue, ttl).Result()
+	acquired, err := r.client.SetNX(ctx, lockKey, token, ttl).Result()
 	if err != nil {
-		return false, base.WrapError(base.OpLock, err, key)
+		return "", false, wrapError(base.OpLock, err, key)
+	}
+	if !acquired {
+		return "", false, nil
 	}
 
-	return acquired, nil
+	return token, true, nil
 }
 
-// Unlock releases a distributed lock
+// Unlock releases a distributed lock held with token. It returns
+// base.ErrLockNotHeld if the lock expired or belongs to another owner.
 func (r *redisCache[T]) Unlock(
 	ctx context.Context,
 	key string,
+	token string,
 ) error {
 	if err := r.base.ValidateKey(key); err != nil {
 		return err
@@ -49,13 +98,60 @@ func (r *redisCache[T]) Unlock(
 
 	lockKey := r.base.FullKey("lock:" + key)
 
-	if err := r.client.Del(ctx, lockKey).Err(); err != nil {
-		return base.WrapError(base.OpUnlock, err, key)
+	n, err := unlockScript.Run(ctx, r.client, []string{lockKey}, token).Int64()
+	if err != nil {
+		return wrapError(base.OpUnlock, err, key)
+	}
+	if n == 0 {
+		return base.WrapError(base.OpUnlock, base.ErrLockNotHeld, key)
 	}
 
 	return nil
 }
 
+const (
+	lockRetryMin = 5 * time.Millisecond
+	lockRetryMax = 100 * time.Millisecond
+)
+
+// Lock is TryLock that keeps retrying with jittered exponential backoff
+// until the lock is acquired or wait has elapsed, in which case it returns
+// base.ErrLockAcquire. A wait of zero or less tries once. The returned
+// token releases the lock through Unlock.
+func (r *redisCache[T]) Lock(
+	ctx context.Context,
+	key string,
+	ttl, wait time.Duration,
+) (string, error) {
+	deadline := time.Now().Add(wait)
+	backoff := lockRetryMin
+
+	for {
+		token, acquired, err := r.TryLock(ctx, key, ttl)
+		if err != nil {
+			return "", err
+		}
+		if acquired {
+			return token, nil
+		}
+
+		left := time.Until(deadline)
+		if left <= 0 {
+			return "", base.WrapError(base.OpLock, base.ErrLockAcquire, key)
+		}
+
+		sleep := min(backoff/2+mrand.N(backoff/2+1), left)
+		t := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return "", base.WrapError(base.OpLock, ctx.Err(), key)
+		case <-t.C:
+		}
+		backoff = min(backoff*2, lockRetryMax)
+	}
+}
+
 // WithLock executes a function while holding a distributed lock
 func (r *redisCache[T]) WithLock(
 	ctx context.Context,
@@ -63,7 +159,7 @@ func (r *redisCache[T]) WithLock(
 	ttl time.Duration,
 	fn func() error,
 ) error {
-	acquired, err := r.TryLock(ctx, key, ttl)
+	token, acquired, err := r.TryLock(ctx, key, ttl)
 	if err != nil {
 		return err
 	}
@@ -72,8 +168,37 @@ func (r *redisCache[T]) WithLock(
 	}
 
 	defer func() {
-		_ = r.Unlock(ctx, key)
+		_ = r.Unlock(ctx, key, token)
 	}()
 
 	return fn()
 }
+
+// ExtendLock resets the TTL of a lock held with token. It reports false
+// when the lock has expired, been released or changed owner.
+func (r *redisCache[T]) ExtendLock(
+	ctx context.Context,
+	key string,
+	token string,
+	ttl time.Duration,
+) (bool, error) {
+	if err := r.base.ValidateKey(key); err != nil {
+		return false, err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return false, err
+	}
+
+	if ttl == base.NoExpiration {
+		return false, base.WrapError(base.OpLock, base.ErrInvalidArgument, key)
+	}
+
+	ttl = r.base.ResolveTTL(ttl)
+	lockKey := r.base.FullKey("lock:" + key)
+
+	n, err := extendScript.Run(ctx, r.client, []string{lockKey}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, wrapError(base.OpLock, err, key)
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestLockTokensAreUniqueUUIDs(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		tok, err := newLockToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !uuidV4.MatchString(tok) {
+			t.Fatalf("token %q is not a version 4 UUID", tok)
+		}
+		if seen[tok] {
+			t.Fatalf("token %q repeated", tok)
+		}
+		seen[tok] = true
+	}
+}
+
+func TestTryLockStoresOwnerToken(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[string](t, cfg)
+
+	token, ok, err := r.TryLock(ctx, "job", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	if got, _ := mr.Get(cfg.Prefix + "lock:job"); got != token {
+		t.Errorf("lock value = %q, want the owner token %q", got, token)
+	}
+	if _, ok, _ := r.TryLock(ctx, "job", time.Minute); ok {
+		t.Error("second TryLock acquired a held lock")
+	}
+	if _, _, err := r.TryLock(ctx, "job", base.NoExpiration); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("TryLock without TTL = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestStaleOwnerCannotUnlock(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	slow, fast := newTestCache[string](t, cfg), newTestCache[string](t, cfg)
+
+	stale, ok, err := slow.TryLock(ctx, "job", time.Second)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+
+	// The slow owner's lock expires and another process takes it over.
+	mr.FastForward(2 * time.Second)
+	current, ok, err := fast.TryLock(ctx, "job", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("TryLock after expiry = %v, %v", ok, err)
+	}
+
+	if err := slow.Unlock(ctx, "job", stale); !errors.Is(err, base.ErrLockNotHeld) {
+		t.Errorf("stale Unlock = %v, want ErrLockNotHeld", err)
+	}
+	if got, _ := mr.Get(cfg.Prefix + "lock:job"); got != current {
+		t.Fatalf("stale Unlock removed the new owner's lock (value %q)", got)
+	}
+	if held, _ := slow.ExtendLock(ctx, "job", stale, time.Minute); held {
+		t.Error("stale owner extended the new owner's lock")
+	}
+
+	if err := fast.Unlock(ctx, "job", current); err != nil {
+		t.Errorf("owner Unlock = %v", err)
+	}
+	if mr.Exists(cfg.Prefix + "lock:job") {
+		t.Error("lock left behind after the owner released it")
+	}
+	if err := fast.Unlock(ctx, "job", current); !errors.Is(err, base.ErrLockNotHeld) {
+		t.Errorf("second Unlock = %v, want ErrLockNotHeld", err)
+	}
+}
+
+func TestWithLockReleasesOwnLock(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[string](t, cfg)
+
+	ran := false
+	err := r.WithLock(ctx, "job", time.Minute, func() error {
+		ran = true
+		if _, ok, _ := r.TryLock(ctx, "job", time.Minute); ok {
+			t.Error("lock not held inside WithLock")
+		}
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("WithLock = %v, ran %v", err, ran)
+	}
+	if mr.Exists(cfg.Prefix + "lock:job") {
+		t.Error("WithLock did not release the lock")
+	}
+
+	// A lock held elsewhere makes WithLock fail without running fn.
+	if _, ok, _ := r.TryLock(ctx, "job", time.Minute); !ok {
+		t.Fatal("TryLock failed")
+	}
+	err = r.WithLock(ctx, "job", time.Minute, func() error {
+		t.Error("fn ran without the lock")
+		return nil
+	})
+	if !errors.Is(err, base.ErrLockAcquire) {
+		t.Errorf("contended WithLock = %v, want ErrLockAcquire", err)
+	}
+}
+
+/* ------------------ Blocking Lock ------------------ */
+
+func TestLockWaitsForRelease(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	first, second := newTestCache[string](t, cfg), newTestCache[string](t, cfg)
+
+	token, err := first.Lock(ctx, "job", time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const hold = 50 * time.Millisecond
+	acquired := make(chan time.Duration, 1)
+	start := time.Now()
+	go func() {
+		tok, err := second.Lock(ctx, "job", time.Minute, time.Second)
+		if err != nil {
+			t.Errorf("second Lock = %v", err)
+		}
+		acquired <- time.Since(start)
+		_ = second.Unlock(ctx, "job", tok)
+	}()
+
+	time.Sleep(hold)
+	select {
+	case <-acquired:
+		t.Fatal("second Lock returned while the first still held the lock")
+	default:
+	}
+	if err := first.Unlock(ctx, "job", token); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case d := <-acquired:
+		if d < hold {
+			t.Errorf("second Lock acquired after %v, before the unlock", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second Lock never acquired the released lock")
+	}
+}
+
+func TestLockTimesOut(t *testing.T) {
+	ctx := context.Background()
+	r := newTestCache[string](t, testConfig(miniredis.RunT(t)))
+
+	if _, err := r.Lock(ctx, "job", time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err := r.Lock(ctx, "job", time.Minute, 30*time.Millisecond)
+	if !errors.Is(err, base.ErrLockAcquire) {
+		t.Errorf("Lock = %v, want ErrLockAcquire", err)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond || d > 500*time.Millisecond {
+		t.Errorf("Lock gave up after %v, want about the 30ms wait", d)
+	}
+
+	// A zero wait tries once.
+	if _, err := r.Lock(ctx, "job", time.Minute, 0); !errors.Is(err, base.ErrLockAcquire) {
+		t.Errorf("Lock without wait = %v, want ErrLockAcquire", err)
+	}
+}
+
+func TestLockHonoursContext(t *testing.T) {
+	r := newTestCache[string](t, testConfig(miniredis.RunT(t)))
+	if _, err := r.Lock(context.Background(), "job", time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := r.Lock(ctx, "job", time.Minute, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lock = %v, want the context deadline", err)
+	}
+}
@@ -0,0 +1,36 @@
+package redis
+
+import "github.com/os-golib/go-cache/internal/base"
+
+/* ------------------ Options ------------------ */
+
+// Option customizes a redis cache at construction time.
+type Option[T any] func(*redisCache[T])
+
+// WithClientOwnership controls whether Close also closes the underlying
+// client. Caches built by NewRedisContext own their client; caches built
+// by NewFromClient do not unless this option says otherwise.
+func WithClientOwnership[T any](owns bool) Option[T] {
+	return func(r *redisCache[T]) {
+		r.ownsClient = owns
+	}
+}
+
+// WithSerializer replaces the default JSON serializer.
+func WithSerializer[T any](s base.Serializer[T]) Option[T] {
+	return func(r *redisCache[T]) {
+		if s != nil {
+			r.serializer = s
+		}
+	}
+}
+
+// WithEvictOnDecodeError makes reads that hit a value the serializer
+// cannot decode delete it and report a miss instead of ErrDeserialize, so
+// callers recompute it. Useful after changing the serializer or the
+// shape of T.
+func WithEvictOnDecodeError[T any](evict bool) Option[T] {
+	return func(r *redisCache[T]) {
+		r.evictOnDecodeError = evict
+	}
+}
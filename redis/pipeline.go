@@ -2,11 +2,13 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
 )
 
 /* ------------------ Pipeline Result ------------------ */
@@ -19,6 +21,9 @@ import (
 
 /* ------------------ GET MANY (Pipeline) ------------------ */
 
+// GetManyPipeline fetches keys in one round trip. Misses are absent from
+// the result; keys that could not be read or decoded are reported in a
+// *base.MultiError alongside the keys that were.
 func (r *redisCache[T]) GetManyPipeline(
 	ctx context.Context,
 	keys []string,
@@ -31,12 +36,33 @@ func (r *redisCache[T]) GetManyPipeline(
 		return nil, err
 	}
 
-	result, err := r.executePipelineGet(ctx, keys)
-	if err != nil {
-		return nil, base.WrapError(base.OpGet, err, "")
+	result, failed := r.executePipelineGet(ctx, keys)
+	return result, base.NewMultiError(failed)
+}
+
+// GetManyOrdered is GetManyPipeline returning one Result per key in input
+// order, with Found false for misses and for keys reported in the
+// *base.MultiError.
+func (r *redisCache[T]) GetManyOrdered(
+	ctx context.Context,
+	keys []string,
+) ([]interfaces.Result[T], error) {
+	if len(keys) == 0 {
+		return []interfaces.Result[T]{}, nil
 	}
 
-	return result, nil
+	if err := r.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	found, failed := r.executePipelineGet(ctx, keys)
+
+	out := make([]interfaces.Result[T], len(keys))
+	for i, k := range keys {
+		v, ok := found[k]
+		out[i] = interfaces.Result[T]{Key: k, Value: v, Found: ok}
+	}
+	return out, base.NewMultiError(failed)
 }
 
 /* ------------------ SET MANY (Pipeline) ------------------ */
@@ -46,35 +72,61 @@ func (r *redisCache[T]) SetManyPipeline(
 	items map[string]T,
 	ttl time.Duration,
 ) error {
+	failed, err := r.SetManyPipelineResult(ctx, items, ttl)
+	if err != nil {
+		return err
+	}
+	return base.FirstError(failed)
+}
+
+// SetManyPipelineResult writes every item it can and reports the ones that
+// failed (invalid key, serialization, backend) keyed by their cache key.
+func (r *redisCache[T]) SetManyPipelineResult(
+	ctx context.Context,
+	items map[string]T,
+	ttl time.Duration,
+) (map[string]error, error) {
+	failed := make(map[string]error)
 	if len(items) == 0 {
-		return nil
+		return failed, nil
 	}
 
 	if err := r.base.CheckContext(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
 	ttl = r.base.ResolveTTL(ttl)
 	pipe := r.client.Pipeline()
+	cmds := make(map[string]*redis.StatusCmd, len(items))
 
 	for k, v := range items {
 		if err := r.base.ValidateKey(k); err != nil {
-			return err
+			failed[k] = err
+			continue
 		}
 
 		data, err := r.serializer.Encode(v)
 		if err != nil {
-			return base.WrapError(base.OpSet, base.ErrSerialize, k)
+			failed[k] = base.SerializationError(base.OpSet, base.ErrSerialize, err, k)
+			continue
 		}
 
-		pipe.Set(ctx, r.base.FullKey(k), data, ttl)
+		cmds[k] = pipe.Set(ctx, r.base.FullKey(k), data, expiration(r.base.JitterTTL(ctx, ttl)))
+	}
+
+	if len(cmds) == 0 {
+		return failed, nil
 	}
 
 	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
-		return base.WrapError(base.OpSet, err, "")
+		for k, cmd := range cmds {
+			if cerr := cmd.Err(); cerr != nil {
+				failed[k] = wrapError(base.OpSet, cerr, k)
+			}
+		}
 	}
 
-	return nil
+	return failed, nil
 }
 
 /* ------------------ Internal: Pipeline GET ------------------ */
@@ -82,39 +134,131 @@ func (r *redisCache[T]) SetManyPipeline(
 func (r *redisCache[T]) executePipelineGet(
 	ctx context.Context,
 	keys []string,
-) (map[string]T, error) {
+) (map[string]T, map[string]error) {
+	result := make(map[string]T, len(keys))
+	failed := make(map[string]error)
+
 	pipe := r.client.Pipeline()
-	cmds := make([]*redis.StringCmd, len(keys))
+	cmds := make(map[string]*redis.StringCmd, len(keys))
 
-	for i, k := range keys {
+	for _, k := range keys {
 		if err := r.base.ValidateKey(k); err != nil {
-			return nil, err
+			failed[k] = base.WrapError(base.OpGetManyPipeline, err, k)
+			continue
 		}
-		cmds[i] = pipe.Get(ctx, r.base.FullKey(k))
+		cmds[k] = pipe.Get(ctx, r.base.FullKey(k))
 	}
-
-	_, execErr := pipe.Exec(ctx)
-	if execErr != nil && execErr != redis.Nil {
-		return nil, execErr
+	if len(cmds) == 0 {
+		return result, failed
 	}
 
-	result := make(map[string]T, len(keys))
+	// Per-command errors carry the exec failure, if any.
+	_, _ = pipe.Exec(ctx)
 
-	for i, cmd := range cmds {
+	// Stale payloads are rewritten in one more round trip, only if any.
+	var repairs redis.Pipeliner
+
+	for k, cmd := range cmds {
 		data, err := cmd.Bytes()
 		if err == redis.Nil {
+			r.base.FireMiss(k)
 			continue
 		}
 		if err != nil {
-			return nil, err
+			failed[k] = wrapError(base.OpGetManyPipeline, err, k)
+			continue
 		}
 
 		val, derr := r.serializer.Decode(data)
+		if errors.Is(derr, base.ErrStaleVersion) {
+			r.base.FireMiss(k)
+			continue
+		}
 		if derr != nil {
-			return nil, derr
+			if err := r.decodeFailure(ctx, base.OpGetManyPipeline, k, data, derr); !base.IsCacheMiss(err) {
+				failed[k] = err
+			}
+			continue
 		}
-		result[keys[i]] = val
+		if fresh, ok := r.repaired(data, val); ok {
+			if repairs == nil {
+				repairs = r.client.Pipeline()
+			}
+			repairs.Set(ctx, r.base.FullKey(k), fresh, redis.KeepTTL)
+		}
+		result[k] = val
+		r.base.FireHit(k)
+	}
+
+	if repairs != nil {
+		_, _ = repairs.Exec(ctx)
 	}
+	return result, failed
+}
+
+/* ------------------ EXISTS MANY (Pipeline) ------------------ */
 
-	return result, nil
+// ExistsMany reports for every key whether it is cached, using one
+// pipelined EXISTS per key.
+func (r *redisCache[T]) ExistsMany(ctx context.Context, keys []string) (map[string]bool, error) {
+	if err := r.base.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if err := r.base.ValidateKey(k); err != nil {
+			return nil, base.WrapError(base.OpExists, err, k)
+		}
+	}
+
+	out := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, k := range keys {
+		cmds[i] = pipe.Exists(ctx, r.base.FullKey(k))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, wrapError(base.OpExists, err, "")
+	}
+
+	for i, k := range keys {
+		out[k] = cmds[i].Val() > 0
+	}
+	return out, nil
+}
+
+/* ------------------ EXPIRE MANY (Pipeline) ------------------ */
+
+// ExpireMany resets the TTL of every existing key with one pipelined
+// PEXPIRE per key. Missing keys are skipped; NoExpiration persists them.
+func (r *redisCache[T]) ExpireMany(ctx context.Context, keys []string, ttl time.Duration) error {
+	if err := r.base.CheckContext(ctx); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := r.base.ValidateKey(k); err != nil {
+			return base.WrapError(base.OpExpireMany, err, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ttl = r.base.ResolveTTL(ttl)
+	pipe := r.client.Pipeline()
+	for _, k := range keys {
+		fk := r.base.FullKey(k)
+		if d := r.base.JitterTTL(ctx, ttl); d > 0 {
+			pipe.PExpire(ctx, fk, d)
+		} else {
+			pipe.Persist(ctx, fk)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return wrapError(base.OpExpireMany, err, "")
+	}
+	return nil
 }
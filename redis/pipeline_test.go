@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+func TestSetManyPipelineResultKeyErrors(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[string](t, cfg)
+
+	failed, err := r.SetManyPipelineResult(ctx, map[string]string{"a": "1", " ": "2"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("failed = %v, want only the blank key", failed)
+	}
+	// The validation error is reported as is, not wrapped a second time.
+	if got := failed[" "]; got != base.ErrKeyEmpty {
+		t.Errorf("blank key error = %#v, want ErrKeyEmpty", got)
+	}
+	if !mr.Exists(cfg.Prefix + "a") {
+		t.Error("valid key not written")
+	}
+}
+
+func TestSetManyPipelineResultReportsUnserializableValue(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[any](t, cfg)
+
+	items := map[string]any{"a": 1, "bad": make(chan int), "c": "three"}
+	failed, err := r.SetManyPipelineResult(ctx, items, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || !errors.Is(failed["bad"], base.ErrSerialize) {
+		t.Fatalf("failed = %v, want only bad with ErrSerialize", failed)
+	}
+	for _, k := range []string{"a", "c"} {
+		if !mr.Exists(cfg.Prefix + k) {
+			t.Errorf("%s not stored next to the failing value", k)
+		}
+	}
+
+	// SetManyPipeline delegates and reports the failure as an error.
+	if err := r.SetManyPipeline(ctx, items, time.Minute); !errors.Is(err, base.ErrSerialize) {
+		t.Errorf("SetManyPipeline = %v, want ErrSerialize", err)
+	}
+}
+
+func TestGetManyPipelineReturnsPartialResult(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+
+	if err := r.SetManyPipeline(ctx, map[string]int{"a": 1, "b": 2}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	mr.Set(cfg.Prefix+"bad", "not json")
+
+	got, err := r.GetManyPipeline(ctx, []string{"a", "missing", "bad", "b"})
+	if want := map[string]int{"a": 1, "b": 2}; !maps.Equal(got, want) {
+		t.Errorf("result = %v, want %v", got, want)
+	}
+	var multi *base.MultiError
+	if !errors.As(err, &multi) || !base.IsPartial(err) {
+		t.Fatalf("err = %v, want a *MultiError", err)
+	}
+	if len(multi.Errors) != 1 || !errors.Is(multi.Errors["bad"], base.ErrDeserialize) {
+		t.Errorf("failures = %v, want only bad with ErrDeserialize", multi.Errors)
+	}
+	if !errors.Is(err, base.ErrDeserialize) || errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("MultiError unwraps to the wrong errors: %v", err)
+	}
+}
+
+func TestGetManyPipelineMissesAreNotErrors(t *testing.T) {
+	ctx := context.Background()
+	r := newTestCache[int](t, testConfig(miniredis.RunT(t)))
+
+	got, err := r.GetManyPipeline(ctx, []string{"x", "y"})
+	if err != nil || len(got) != 0 {
+		t.Errorf("GetManyPipeline of misses = %v, %v; want empty, nil", got, err)
+	}
+}
+
+func TestExistsManyMixesPresentAbsentAndExpired(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[int](t, testConfig(mr))
+
+	_ = r.Set(ctx, "present", 1, time.Minute)
+	_ = r.Set(ctx, "old", 2, time.Second)
+	mr.FastForward(2 * time.Second)
+
+	got, err := r.ExistsMany(ctx, []string{"present", "absent", "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"present": true, "absent": false, "old": false}; !maps.Equal(got, want) {
+		t.Errorf("ExistsMany = %v, want %v", got, want)
+	}
+
+	if got, err := r.ExistsMany(ctx, nil); err != nil || len(got) != 0 {
+		t.Errorf("ExistsMany(nil) = %v, %v", got, err)
+	}
+	if _, err := r.ExistsMany(ctx, []string{"present", ""}); !errors.Is(err, base.ErrKeyEmpty) {
+		t.Errorf("ExistsMany with an empty key = %v, want ErrKeyEmpty", err)
+	}
+}
+
+func TestExpireManyUpdatesExistingKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+
+	_ = r.Set(ctx, "a", 1, time.Second)
+	_ = r.Set(ctx, "b", 2, base.NoExpiration)
+	if err := r.ExpireMany(ctx, []string{"a", "b", "absent"}, time.Hour); err != nil {
+		t.Fatalf("ExpireMany = %v", err)
+	}
+	for _, k := range []string{"a", "b"} {
+		if ttl := mr.TTL(cfg.Prefix + k); ttl != time.Hour {
+			t.Errorf("%s TTL = %v, want 1h", k, ttl)
+		}
+	}
+	if mr.Exists(cfg.Prefix + "absent") {
+		t.Error("ExpireMany created an absent key")
+	}
+
+	if err := r.ExpireMany(ctx, []string{"a"}, base.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL(cfg.Prefix + "a"); ttl != 0 {
+		t.Errorf("a TTL = %v after NoExpiration, want none", ttl)
+	}
+	if err := r.ExpireMany(ctx, []string{"a", ""}, time.Hour); !errors.Is(err, base.ErrKeyEmpty) {
+		t.Errorf("ExpireMany with an empty key = %v, want ErrKeyEmpty", err)
+	}
+}
+
+func TestGetManyOrderedInterleavesHitsAndMisses(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+	_ = r.Set(ctx, "a", 1, time.Minute)
+	_ = r.Set(ctx, "c", 3, time.Minute)
+	_ = mr.Set(cfg.Prefix+"bad", "{not json")
+
+	got, err := r.GetManyOrdered(ctx, []string{"c", "b", "bad", "a", "d"})
+	var me *base.MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 || me.Errors["bad"] == nil {
+		t.Fatalf("GetManyOrdered error = %v, want only bad reported", err)
+	}
+	want := []interfaces.Result[int]{
+		{Key: "c", Value: 3, Found: true},
+		{Key: "b"},
+		{Key: "bad"},
+		{Key: "a", Value: 1, Found: true},
+		{Key: "d"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetManyOrdered = %v, want %v", got, want)
+	}
+
+	if got, err := r.GetManyOrdered(ctx, nil); err != nil || len(got) != 0 {
+		t.Errorf("GetManyOrdered(nil) = %v, %v", got, err)
+	}
+}
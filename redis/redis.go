@@ -2,6 +2,12 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -17,28 +23,30 @@ type redisCache[T any] struct {
 	base       *base.Base
 	client     *redis.Client
 	serializer base.Serializer[T]
+	ownsClient bool
+	pingFailed atomic.Bool
+	health     metrics.HealthTracker
+	breaker    *breaker
+	cluster    bool     // endpoint reported cluster mode at startup
+	hot        *hotKeys // nil unless HotKeysKey is set
+
+	// evictOnDecodeError turns undecodable values into misses
+	evictOnDecodeError bool
 }
 
 /* ------------------ Constructor ------------------ */
 
-func NewRedisCache[T any](cfg config.Config) (*redisCache[T], error) {
-	return NewRedisContext[T](context.Background(), cfg)
+func NewRedisCache[T any](cfg config.Config, opts ...Option[T]) (*redisCache[T], error) {
+	return NewRedisContext[T](context.Background(), cfg, opts...)
 }
 
-func NewRedisContext[T any](ctx context.Context, cfg config.Config) (*redisCache[T], error) {
+func NewRedisContext[T any](ctx context.Context, cfg config.Config, opts ...Option[T]) (*redisCache[T], error) {
 	opt, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
-		return nil, base.WrapError(base.OpSet, err, "")
+		return nil, wrapError(base.OpSet, err, "")
 	}
 
-	// Apply config
-	opt.PoolSize = cfg.PoolSize
-	opt.MinIdleConns = cfg.MinIdleConn
-	opt.MaxRetries = cfg.MaxRetries
-	opt.DialTimeout = cfg.DialTimeout
-	opt.ReadTimeout = cfg.ReadTimeout
-	opt.WriteTimeout = cfg.WriteTimeout
-
+	applyConfig(opt, cfg)
 	client := redis.NewClient(opt)
 
 	// Ensure timeout
@@ -57,11 +65,93 @@ func NewRedisContext[T any](ctx context.Context, cfg config.Config) (*redisCache
 		return nil, base.WrapError(base.OpPing, base.ErrConnection, "")
 	}
 
-	return &redisCache[T]{
+	cluster := warnIfCluster(ctx, client, cfg.RedisURL)
+
+	r := newRedisCache(client, cfg, true, opts...)
+	r.cluster = cluster
+	return r, nil
+}
+
+// applyConfig copies cfg onto options parsed from cfg.RedisURL. Explicit
+// DB and credential fields win over the URL.
+func applyConfig(opt *redis.Options, cfg config.Config) {
+	opt.PoolSize = cfg.PoolSize
+	opt.MinIdleConns = cfg.MinIdleConn
+	opt.MaxRetries = cfg.MaxRetries
+	opt.DialTimeout = cfg.DialTimeout
+	opt.ReadTimeout = cfg.ReadTimeout
+	opt.WriteTimeout = cfg.WriteTimeout
+
+	if cfg.RedisDB != nil {
+		opt.DB = *cfg.RedisDB
+	}
+	if cfg.RedisUsername != "" {
+		opt.Username = cfg.RedisUsername
+	}
+	if cfg.RedisPassword != "" {
+		opt.Password = cfg.RedisPassword
+	}
+}
+
+// NewFromClient wraps an existing client instead of dialing a new one.
+// No startup ping is issued and, unless WithClientOwnership(true) is given,
+// Close leaves the shared client open and the circuit breaker is off.
+func NewFromClient[T any](client *redis.Client, cfg config.Config, opts ...Option[T]) (*redisCache[T], error) {
+	if client == nil {
+		return nil, base.WrapError(base.OpInit, base.ErrInvalidConfig, "")
+	}
+	return newRedisCache(client, cfg, false, opts...), nil
+}
+
+func newRedisCache[T any](
+	client *redis.Client,
+	cfg config.Config,
+	owns bool,
+	opts ...Option[T],
+) *redisCache[T] {
+	r := &redisCache[T]{
 		base:       base.NewBase(cfg),
 		client:     client,
 		serializer: &base.JsonSerializer[T]{},
-	}, nil
+		ownsClient: owns,
+		hot:        newHotKeys(cfg),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	// The hook is installed on the client itself, so it is only added to
+	// clients the cache owns: on a shared client it would guard, and trip
+	// for, every other user, and outlive Close.
+	if cfg.BreakerThreshold > 0 && r.ownsClient {
+		r.breaker = newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, func() {
+			r.base.RecordError("circuit_open")
+		})
+		client.AddHook(r.breaker)
+	}
+	return r
+}
+
+// warnIfCluster logs when the endpoint is a Redis Cluster node, and
+// reports whether it is: a plain client will see MOVED/ASK replies for
+// keys owned by other nodes.
+func warnIfCluster(ctx context.Context, client *redis.Client, rawURL string) bool {
+	info, err := client.ClusterInfo(ctx).Result()
+	if err != nil || !strings.Contains(info, "cluster_state:") {
+		return false
+	}
+	slog.Warn("go-cache: redis endpoint has cluster mode enabled but a non-cluster client is used; "+
+		"cross-slot keys will fail with ErrClusterRedirect",
+		"url", redactURL(rawURL))
+	return true
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
 }
 
 /* ------------------ Cache API ------------------ */
@@ -78,20 +168,136 @@ func (r *redisCache[T]) Get(ctx context.Context, key string) (T, error) {
 
 	data, err := r.client.Get(ctx, r.base.FullKey(key)).Bytes()
 	if err == redis.Nil {
+		r.base.FireMiss(key)
 		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
 	}
 	if err != nil {
-		return zero, base.WrapError(base.OpGet, err, key)
+		return zero, wrapError(base.OpGet, err, key)
 	}
 
 	val, err := r.serializer.Decode(data)
+	if errors.Is(err, base.ErrStaleVersion) {
+		r.base.FireMiss(key)
+		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
 	if err != nil {
-		return zero, base.WrapError(base.OpGet, base.ErrDeserialize, key)
+		return zero, r.decodeFailure(ctx, base.OpGet, key, data, err)
 	}
 
+	r.repair(ctx, r.base.FullKey(key), data, val)
+	r.trackHit(ctx, key)
+	r.base.FireHit(key)
 	return val, nil
 }
 
+// decodeFailure reports that key holds data the serializer cannot decode.
+// With WithEvictOnDecodeError the entry is deleted, unless it was
+// overwritten meanwhile, and the read becomes a miss.
+func (r *redisCache[T]) decodeFailure(ctx context.Context, op base.Op, key string, data []byte, err error) error {
+	if !r.evictOnDecodeError {
+		return base.SerializationError(op, base.ErrDeserialize, err, key)
+	}
+
+	// unlockScript deletes a key only while it holds the given value.
+	_ = unlockScript.Run(ctx, r.client, []string{r.base.FullKey(key)}, data).Err()
+	r.base.FireMiss(key)
+	return base.WrapError(op, base.ErrCacheMiss, key)
+}
+
+// GetWithTTL returns the value and its remaining TTL (0 if it never
+// expires), read in a single round trip.
+func (r *redisCache[T]) GetWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	var zero T
+
+	if err := r.base.ValidateKey(key); err != nil {
+		return zero, 0, err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return zero, 0, err
+	}
+
+	fk := r.base.FullKey(key)
+	pipe := r.client.Pipeline()
+	get := pipe.Get(ctx, fk)
+	pttl := pipe.PTTL(ctx, fk)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return zero, 0, wrapError(base.OpGet, err, key)
+	}
+
+	data, err := get.Bytes()
+	if err == redis.Nil {
+		r.base.FireMiss(key)
+		return zero, 0, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return zero, 0, wrapError(base.OpGet, err, key)
+	}
+
+	val, err := r.serializer.Decode(data)
+	if errors.Is(err, base.ErrStaleVersion) {
+		r.base.FireMiss(key)
+		return zero, 0, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+	if err != nil {
+		return zero, 0, r.decodeFailure(ctx, base.OpGet, key, data, err)
+	}
+
+	r.repair(ctx, fk, data, val)
+	r.trackHit(ctx, key)
+	r.base.FireHit(key)
+
+	// PTTL is negative for keys without an expiry.
+	remaining := pttl.Val()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return val, remaining, nil
+}
+
+// Persist removes key's expiry so it never expires. It returns
+// ErrCacheMiss if key does not exist.
+func (r *redisCache[T]) Persist(ctx context.Context, key string) error {
+	if err := r.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	// PERSIST returns 0 both for a missing key and for one without a TTL,
+	// so EXISTS tells them apart.
+	fk := r.base.FullKey(key)
+	pipe := r.client.Pipeline()
+	pipe.Persist(ctx, fk)
+	exists := pipe.Exists(ctx, fk)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return wrapError(base.OpPersist, err, key)
+	}
+	if exists.Val() == 0 {
+		return base.WrapError(base.OpPersist, base.ErrCacheMiss, key)
+	}
+	return nil
+}
+
+// repair rewrites a payload the serializer flags as stale, keeping the
+// key's remaining TTL. fk is the full Redis key. Every decode path calls
+// it; failures are ignored: the next read retries.
+func (r *redisCache[T]) repair(ctx context.Context, fk string, data []byte, val T) {
+	if fresh, ok := r.repaired(data, val); ok {
+		_ = r.client.Set(ctx, fk, fresh, redis.KeepTTL).Err()
+	}
+}
+
+// repaired returns val re-encoded when data is in an outdated format.
+func (r *redisCache[T]) repaired(data []byte, val T) ([]byte, bool) {
+	sc, ok := r.serializer.(base.StaleChecker)
+	if !ok || !sc.Stale(data) {
+		return nil, false
+	}
+	fresh, err := r.serializer.Encode(val)
+	return fresh, err == nil
+}
+
 func (r *redisCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
 	if err := r.base.ValidateKey(key); err != nil {
 		return err
@@ -102,22 +308,39 @@ func (r *redisCache[T]) Set(ctx context.Context, key string, value T, ttl time.D
 
 	data, err := r.serializer.Encode(value)
 	if err != nil {
-		return base.WrapError(base.OpSet, base.ErrSerialize, key)
+		return base.SerializationError(base.OpSet, base.ErrSerialize, err, key)
 	}
 
-	ttl = r.base.ResolveTTL(ttl)
-	if err := r.client.Set(ctx, r.base.FullKey(key), data, ttl).Err(); err != nil {
-		return base.WrapError(base.OpSet, err, key)
+	ttl = r.base.WriteTTL(ctx, ttl)
+	if err := r.client.Set(ctx, r.base.FullKey(key), data, expiration(ttl)).Err(); err != nil {
+		return wrapError(base.OpSet, err, key)
 	}
 	return nil
 }
 
+// expiration maps a resolved TTL to a go-redis expiration. go-redis reads
+// -1 as KEEPTTL, so NoExpiration must become 0 (no expiry).
+func expiration(ttl time.Duration) time.Duration {
+	if ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
 func (r *redisCache[T]) Delete(ctx context.Context, keys ...string) error {
+	_, err := r.DeleteMany(ctx, keys)
+	return err
+}
+
+// DeleteMany deletes keys in DEL commands of at most DeleteBatchSize keys,
+// sent in one pipeline, and returns how many existed. Against a cluster
+// node, batches are also split by hash slot to avoid CROSSSLOT errors.
+func (r *redisCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
 	if err := r.base.CheckContext(ctx); err != nil {
-		return err
+		return 0, err
 	}
 	if len(keys) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	full := make([]string, len(keys))
@@ -125,10 +348,52 @@ func (r *redisCache[T]) Delete(ctx context.Context, keys ...string) error {
 		full[i] = r.base.FullKey(k)
 	}
 
-	if err := r.client.Del(ctx, full...).Err(); err != nil {
-		return base.WrapError(base.OpDelete, err, "")
+	batches := chunk(full, r.deleteBatchSize())
+	if r.cluster {
+		batches = batches[:0]
+		for _, group := range groupBySlot(full) {
+			batches = append(batches, chunk(group, r.deleteBatchSize())...)
+		}
 	}
-	return nil
+
+	if len(batches) == 1 {
+		n, err := r.client.Del(ctx, batches[0]...).Result()
+		if err != nil {
+			return 0, wrapError(base.OpDelete, err, "")
+		}
+		return n, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(batches))
+	for i, b := range batches {
+		cmds[i] = pipe.Del(ctx, b...)
+	}
+	_, execErr := pipe.Exec(ctx)
+
+	var total int64
+	for _, cmd := range cmds {
+		total += cmd.Val()
+	}
+	if execErr != nil {
+		return total, wrapError(base.OpDelete, execErr, "")
+	}
+	return total, nil
+}
+
+func (r *redisCache[T]) deleteBatchSize() int {
+	if n := r.base.Cfg.DeleteBatchSize; n > 0 {
+		return n
+	}
+	return defaultDeleteBatchSize
+}
+
+// scanCount is the COUNT hint passed to every SCAN.
+func (r *redisCache[T]) scanCount() int64 {
+	if n := r.base.Cfg.ScanCount; n > 0 {
+		return int64(n)
+	}
+	return defaultScanCount
 }
 
 func (r *redisCache[T]) Exists(ctx context.Context, key string) (bool, error) {
@@ -138,23 +403,23 @@ func (r *redisCache[T]) Exists(ctx context.Context, key string) (bool, error) {
 
 	n, err := r.client.Exists(ctx, r.base.FullKey(key)).Result()
 	if err != nil {
-		return false, base.WrapError(base.OpExists, err, key)
+		return false, wrapError(base.OpExists, err, key)
 	}
 	return n > 0, nil
 }
 
 func (r *redisCache[T]) Clear(ctx context.Context) error {
-	pattern := r.base.FullKey("") + "*"
+	pattern := r.base.FullPrefix("") + "*"
 	var cursor uint64
 
 	for {
-		keys, next, err := r.client.Scan(ctx, cursor, pattern, 1000).Result()
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, r.scanCount()).Result()
 		if err != nil {
-			return base.WrapError(base.OpClear, err, "")
+			return wrapError(base.OpClear, err, "")
 		}
 		if len(keys) > 0 {
 			if err := r.client.Del(ctx, keys...).Err(); err != nil {
-				return base.WrapError(base.OpClear, err, "")
+				return wrapError(base.OpClear, err, "")
 			}
 		}
 		cursor = next
@@ -170,14 +435,14 @@ func (r *redisCache[T]) Len(ctx context.Context) (int, error) {
 		return 0, err
 	}
 
-	pattern := r.base.FullKey("") + "*"
+	pattern := r.base.FullPrefix("") + "*"
 	var cursor uint64
 	var total int
 
 	for {
-		keys, next, err := r.client.Scan(ctx, cursor, pattern, 1000).Result()
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, r.scanCount()).Result()
 		if err != nil {
-			return 0, base.WrapError(base.OpLen, err, "")
+			return 0, wrapError(base.OpLen, err, "")
 		}
 		total += len(keys)
 		cursor = next
@@ -189,19 +454,50 @@ func (r *redisCache[T]) Len(ctx context.Context) (int, error) {
 }
 
 func (r *redisCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
-	pattern := r.base.FullKey(prefix) + "*"
+	return r.deleteMatching(ctx, base.OpDeleteByPrefix, prefix, r.base.FullPrefix(prefix)+"*")
+}
+
+// DeleteByPattern deletes the keys whose logical key matches the glob
+// pattern; the cache prefix is prepended before scanning.
+func (r *redisCache[T]) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	return r.deleteMatching(ctx, base.OpDeleteByPattern, pattern, r.base.FullPrefix(pattern))
+}
+
+// CountByPrefix counts the keys starting with prefix by paging through
+// SCAN; keys written meanwhile may or may not be counted.
+func (r *redisCache[T]) CountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	pattern := r.base.FullPrefix(prefix) + "*"
 	var cursor uint64
 	var total int64
 
 	for {
-		keys, next, err := r.client.Scan(ctx, cursor, pattern, 1000).Result()
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, r.scanCount()).Result()
 		if err != nil {
-			return total, base.WrapError(base.OpDeleteByPrefix, err, prefix)
+			return total, wrapError(base.OpCountByPrefix, err, prefix)
+		}
+		total += int64(len(keys))
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
+// deleteMatching deletes every key matching the SCAN pattern match.
+func (r *redisCache[T]) deleteMatching(ctx context.Context, op base.Op, arg, match string) (int64, error) {
+	var cursor uint64
+	var total int64
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, match, r.scanCount()).Result()
+		if err != nil {
+			return total, wrapError(op, err, arg)
 		}
 		if len(keys) > 0 {
 			n, err := r.client.Del(ctx, keys...).Result()
 			if err != nil {
-				return total, base.WrapError(base.OpDeleteByPrefix, err, prefix)
+				return total, wrapError(op, err, arg)
 			}
 			total += n
 		}
@@ -213,17 +509,100 @@ func (r *redisCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int6
 	return total, nil
 }
 
+// Range calls fn with each entry under the cache prefix until fn returns
+// false. It pages through SCAN and fetches each page with one pipelined
+// GET, so it is not a point-in-time snapshot: entries written or deleted
+// meanwhile may or may not be seen. Keys shortened by MaxKeyLength are
+// reported in their hashed form.
+func (r *redisCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	prefix := r.base.FullPrefix("")
+	var cursor uint64
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, prefix+"*", r.scanCount()).Result()
+		if err != nil {
+			return wrapError(base.OpGet, err, "")
+		}
+
+		if len(keys) > 0 {
+			pipe := r.client.Pipeline()
+			cmds := make([]*redis.StringCmd, len(keys))
+			for i, k := range keys {
+				cmds[i] = pipe.Get(ctx, k)
+			}
+			// Reply errors, such as WRONGTYPE for a non-string key under
+			// the prefix, only skip that key.
+			var rerr redis.Error
+			if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil && !errors.As(err, &rerr) {
+				return wrapError(base.OpGet, err, "")
+			}
+
+			for i, cmd := range cmds {
+				data, err := cmd.Bytes()
+				if err != nil {
+					// Expired or deleted since SCAN, or not a cache entry.
+					continue
+				}
+				val, err := r.serializer.Decode(data)
+				if err != nil {
+					continue
+				}
+				r.repair(ctx, keys[i], data, val)
+				if !fn(strings.TrimPrefix(keys[i], prefix), val) {
+					return nil
+				}
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// StartedAt returns when the cache was created or last reconnected.
+func (r *redisCache[T]) StartedAt() time.Time {
+	return r.base.StartedAt()
+}
+
 func (r *redisCache[T]) Ping(ctx context.Context) error {
 	if err := r.base.CheckContext(ctx); err != nil {
 		return err
 	}
-	if err := r.client.Ping(ctx).Err(); err != nil {
-		return base.WrapError(base.OpPing, err, "")
+	start := time.Now()
+	err := r.client.Ping(ctx).Err()
+	if !base.IsContextError(err) {
+		r.health.Record(time.Since(start), err)
+	}
+	if err != nil {
+		r.pingFailed.Store(true)
+		return wrapError(base.OpPing, err, "")
+	}
+
+	// First successful ping after a failure: treat as a reconnect.
+	if r.pingFailed.Swap(false) {
+		r.base.ResetStartTime()
 	}
 	return nil
 }
 
+// Health pings the server and reports the result together with the
+// failure streak.
+func (r *redisCache[T]) Health(ctx context.Context) metrics.HealthStatus {
+	_ = r.Ping(ctx)
+	return r.health.Status("redis")
+}
+
 func (r *redisCache[T]) Close() error {
+	if r.hot != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_ = r.hot.write(ctx, r.client, r.hot.take())
+		cancel()
+	}
+	if !r.ownsClient {
+		return nil
+	}
 	return r.client.Close()
 }
 
@@ -239,12 +618,58 @@ func (r *redisCache[T]) Stats(ctx context.Context) metrics.CacheStats {
 		misses += s.Misses
 	}
 
-	return metrics.CacheStats{
-		Backend: "redis",
-		Items:   int64(items),
-		Hits:    hits,
-		Misses:  misses,
-		HitRate: metrics.CalculateHitRate(hits, misses),
-		Uptime:  r.base.Uptime(),
+	stats := metrics.CacheStats{
+		Backend:   "redis",
+		Items:     int64(items),
+		Hits:      hits,
+		Misses:    misses,
+		HitRate:   metrics.CalculateHitRate(hits, misses),
+		Uptime:    r.base.Uptime(),
+		StartedAt: r.base.StartedAt(),
+	}
+	if r.breaker != nil {
+		stats.CircuitState = r.breaker.State()
+	}
+	stats.Evictions, stats.Expirations = r.serverEvictions(ctx)
+	stats.Pool = r.poolStats()
+	return stats
+}
+
+func (r *redisCache[T]) poolStats() *metrics.PoolStats {
+	ps := r.client.PoolStats()
+	if ps == nil {
+		return nil
+	}
+	return &metrics.PoolStats{
+		TotalConns: int64(ps.TotalConns),
+		IdleConns:  int64(ps.IdleConns),
+		StaleConns: int64(ps.StaleConns),
+		Hits:       int64(ps.Hits),
+		Misses:     int64(ps.Misses),
+		Timeouts:   int64(ps.Timeouts),
+	}
+}
+
+// serverEvictions reads evicted_keys and expired_keys from INFO stats.
+// Redis only tracks them per server, so they include keys outside this
+// cache's prefix. Both are 0 if INFO fails.
+func (r *redisCache[T]) serverEvictions(ctx context.Context) (evicted, expired int64) {
+	info, err := r.client.Info(ctx, "stats").Result()
+	if err != nil {
+		return 0, 0
+	}
+
+	for _, line := range strings.Split(info, "\n") {
+		name, val, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "evicted_keys":
+			evicted, _ = strconv.ParseInt(val, 10, 64)
+		case "expired_keys":
+			expired, _ = strconv.ParseInt(val, 10, 64)
+		}
 	}
+	return evicted, expired
 }
@@ -0,0 +1,510 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func testConfig(mr *miniredis.Miniredis) config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Type = config.TypeRedis
+	cfg.RedisURL = "redis://" + mr.Addr()
+	return cfg
+}
+
+func newTestCache[T any](t *testing.T, cfg config.Config, opts ...Option[T]) *redisCache[T] {
+	t.Helper()
+	r, err := NewRedisCache[T](cfg, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+/* ------------------ Connection settings ------------------ */
+
+func intPtr(n int) *int { return &n }
+
+func TestApplyConfigOverridesURL(t *testing.T) {
+	tests := []struct {
+		name                 string
+		url                  string
+		db                   *int
+		user, pass           string
+		wantDB               int
+		wantUser, wantPasswd string
+	}{
+		{"url only", "redis://u:p@localhost:6379/3", nil, "", "", 3, "u", "p"},
+		{"db override", "redis://localhost:6379/3", intPtr(5), "", "", 5, "", ""},
+		{"db zero override", "redis://localhost:6379/3", intPtr(0), "", "", 0, "", ""},
+		{"credentials override", "redis://u:p@localhost:6379/1", nil, "admin", "secret", 1, "admin", "secret"},
+		{"password only", "redis://u:p@localhost:6379", nil, "", "secret", 0, "u", "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := redis.ParseURL(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.DefaultConfig()
+			cfg.RedisDB, cfg.RedisUsername, cfg.RedisPassword = tt.db, tt.user, tt.pass
+
+			applyConfig(opt, cfg)
+
+			if opt.DB != tt.wantDB || opt.Username != tt.wantUser || opt.Password != tt.wantPasswd {
+				t.Errorf("got db=%d user=%q pass=%q; want db=%d user=%q pass=%q",
+					opt.DB, opt.Username, opt.Password, tt.wantDB, tt.wantUser, tt.wantPasswd)
+			}
+		})
+	}
+}
+
+func TestExplicitDBZeroOverridesURL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cfg := testConfig(mr)
+	cfg.RedisURL += "/3"
+	cfg.RedisDB = intPtr(0)
+	r := newTestCache[string](t, cfg)
+
+	if err := r.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.DB(0).Exists(cfg.Prefix + "k") {
+		t.Error("key not written to database 0")
+	}
+	if mr.DB(3).Exists(cfg.Prefix + "k") {
+		t.Error("key written to the URL's database 3")
+	}
+}
+
+/* ------------------ Shared clients ------------------ */
+
+func TestNewFromClientLeavesSharedClientOpen(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	r, err := NewFromClient[string](client, testConfig(mr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Errorf("shared client closed by the cache: %v", err)
+	}
+	if got, err := client.Get(ctx, testConfig(mr).Prefix+"k").Result(); err != nil || got == "" {
+		t.Errorf("value written through the shared client = %q, %v", got, err)
+	}
+}
+
+func TestNewFromClientWithOwnershipClosesClient(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	r, err := NewFromClient[string](client, testConfig(mr), WithClientOwnership[string](true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(ctx).Err(); !errors.Is(err, redis.ErrClosed) {
+		t.Errorf("Ping after Close = %v, want redis.ErrClosed", err)
+	}
+}
+
+func TestNewFromClientRejectsNil(t *testing.T) {
+	if _, err := NewFromClient[string](nil, config.DefaultConfig()); !errors.Is(err, base.ErrInvalidConfig) {
+		t.Errorf("NewFromClient(nil) = %v, want ErrInvalidConfig", err)
+	}
+}
+
+/* ------------------ Uptime ------------------ */
+
+func TestReconnectResetsStartedAt(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[string](t, testConfig(mr))
+
+	started := r.StartedAt()
+	time.Sleep(20 * time.Millisecond)
+	if err := r.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !r.StartedAt().Equal(started) {
+		t.Error("healthy ping reset StartedAt")
+	}
+
+	// Simulate an outage followed by recovery.
+	mr.SetError("ERR server unavailable")
+	if err := r.Ping(ctx); err == nil {
+		t.Fatal("Ping succeeded during the outage")
+	}
+	if !r.StartedAt().Equal(started) {
+		t.Error("failed ping reset StartedAt")
+	}
+	mr.SetError("")
+	if err := r.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.StartedAt().After(started) {
+		t.Errorf("StartedAt = %v after reconnect, want later than %v", r.StartedAt(), started)
+	}
+	stats := r.Stats(ctx)
+	if stats.Uptime >= 20*time.Millisecond || !stats.StartedAt.Equal(r.StartedAt()) {
+		t.Errorf("stats uptime=%v startedAt=%v after reconnect", stats.Uptime, stats.StartedAt)
+	}
+}
+
+/* ------------------ Health ------------------ */
+
+func TestHealthReflectsFailingPing(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[string](t, testConfig(mr))
+
+	if h := r.Health(ctx); !h.Healthy || h.Backend != "redis" || h.CheckedAt.IsZero() {
+		t.Fatalf("healthy status = %+v", h)
+	}
+
+	mr.SetError("ERR server unavailable")
+	_ = r.Health(ctx)
+	h := r.Health(ctx)
+	if h.Healthy || h.ConsecutiveFailures != 2 || !strings.Contains(h.LastError, "server unavailable") {
+		t.Errorf("failing status = %+v", h)
+	}
+
+	mr.SetError("")
+	if h := r.Health(ctx); !h.Healthy || h.ConsecutiveFailures != 0 {
+		t.Errorf("recovered status = %+v", h)
+	}
+
+	// A cancelled caller says nothing about the server.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if h := r.Health(cancelled); !h.Healthy {
+		t.Errorf("cancelled check marked the server unhealthy: %+v", h)
+	}
+}
+
+/* ------------------ Pool stats ------------------ */
+
+func TestStatsReportPool(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig(miniredis.RunT(t))
+	cfg.PoolSize = 4
+	r := newTestCache[string](t, cfg)
+
+	for i := range 20 {
+		_ = r.Set(ctx, "k", "v", time.Minute)
+		if _, err := r.Get(ctx, "k"); err != nil {
+			t.Fatalf("Get %d: %v", i, err)
+		}
+	}
+
+	p := r.Stats(ctx).Pool
+	if p == nil {
+		t.Fatal("Stats has no pool stats")
+	}
+	if p.TotalConns < 1 || p.TotalConns > 4 {
+		t.Errorf("TotalConns = %d, want 1..4", p.TotalConns)
+	}
+	if p.IdleConns > p.TotalConns {
+		t.Errorf("IdleConns = %d exceeds TotalConns = %d", p.IdleConns, p.TotalConns)
+	}
+	if p.Hits+p.Misses < 40 {
+		t.Errorf("Hits + Misses = %d, want a checkout per command", p.Hits+p.Misses)
+	}
+	if p.Misses < 1 || p.Timeouts != 0 {
+		t.Errorf("Misses = %d, Timeouts = %d; want the first dial and no timeouts", p.Misses, p.Timeouts)
+	}
+}
+
+/* ------------------ Long keys ------------------ */
+
+func TestLongKeysStoredHashed(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.MaxKeyLength = len(cfg.Prefix) + config.HashedKeyLength
+	r := newTestCache[string](t, cfg)
+
+	long := "/search?q=" + strings.Repeat("a", 200)
+	if err := r.Set(ctx, long, "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Get(ctx, long); err != nil || got != "v" {
+		t.Fatalf("Get(long) = %q, %v", got, err)
+	}
+	for _, k := range mr.Keys() {
+		if len(k) > cfg.MaxKeyLength {
+			t.Errorf("stored key %q not bounded", k)
+		}
+	}
+}
+
+/* ------------------ Versioned keys ------------------ */
+
+func TestVersionsDoNotCollide(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.Prefix = "app:"
+	cfg.Version = "1"
+	v1 := newTestCache[string](t, cfg)
+	cfg.Version = "2"
+	v2 := newTestCache[string](t, cfg)
+
+	_ = v1.Set(ctx, "k", "old", time.Minute)
+	if !mr.Exists("app:v1:k") {
+		t.Fatalf("stored keys = %v, want app:v1:k", mr.Keys())
+	}
+	if _, err := v2.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("v2 Get = %v, want the v1 entry orphaned", err)
+	}
+
+	_ = v2.Set(ctx, "k", "new", time.Minute)
+	if got, _ := v1.Get(ctx, "k"); got != "old" {
+		t.Errorf("v1 Get = %q, want old", got)
+	}
+	if n, _ := v2.Len(ctx); n != 1 {
+		t.Errorf("v2 Len = %d, want only its own key", n)
+	}
+	if err := v2.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("app:v1:k") || mr.Exists("app:v2:k") {
+		t.Errorf("keys after v2 Clear = %v, want only app:v1:k", mr.Keys())
+	}
+}
+
+/* ------------------ Hooks ------------------ */
+
+func TestHooksFireOnHitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+
+	var hits, misses []string
+	cfg.Hooks = config.Hooks{
+		OnHit:  func(key string) { hits = append(hits, key) },
+		OnMiss: func(key string) { misses = append(misses, key) },
+	}
+	r := newTestCache[string](t, cfg)
+
+	if err := r.Set(ctx, "a", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = r.Get(ctx, "a")
+	_, _ = r.Get(ctx, "b")
+	_, _ = r.GetManyPipeline(ctx, []string{"a", "c"})
+
+	if want := []string{"a", "a"}; !slices.Equal(hits, want) {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+	if want := []string{"b", "c"}; !slices.Equal(misses, want) {
+		t.Errorf("misses = %v, want %v", misses, want)
+	}
+}
+
+/* ------------------ Range ------------------ */
+
+func TestRangeVisitsAllKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[int](t, testConfig(mr))
+
+	want := map[string]int{}
+	for i := range 250 {
+		k := "k" + strconv.Itoa(i)
+		want[k] = i
+		if err := r.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Keys outside the prefix and non-string keys under it are skipped.
+	mr.Set("other:x", "1")
+	if _, err := mr.ZAdd(testConfig(mr).Prefix+"hot", 1, "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	err := r.Range(ctx, func(k string, v int) bool {
+		got[k] = v
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Range visited %d keys, want %d", len(got), len(want))
+	}
+}
+
+func TestRangeStopsEarly(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[int](t, testConfig(mr))
+
+	for i := range 50 {
+		if err := r.Set(ctx, "k"+strconv.Itoa(i), i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := 0
+	if err := r.Range(ctx, func(string, int) bool {
+		calls++
+		return calls < 3
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("fn called %d times after returning false, want 3", calls)
+	}
+}
+
+/* ------------------ Persist ------------------ */
+
+func TestPersistRemovesTTL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[string](t, testConfig(mr))
+
+	_ = r.Set(ctx, "k", "v", time.Second)
+	if err := r.Persist(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(time.Hour)
+
+	v, ttl, err := r.GetWithTTL(ctx, "k")
+	if err != nil || v != "v" {
+		t.Fatalf("Get after the original TTL = %q, %v", v, err)
+	}
+	if ttl != 0 {
+		t.Errorf("TTL = %v, want no expiry", ttl)
+	}
+
+	// Persisting a key without a TTL is fine; a missing key is a miss.
+	if err := r.Persist(ctx, "k"); err != nil {
+		t.Errorf("Persist(permanent) = %v", err)
+	}
+	if err := r.Persist(ctx, "absent"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Persist(absent) = %v, want ErrCacheMiss", err)
+	}
+}
+
+/* ------------------ NoExpiration ------------------ */
+
+func TestNoExpirationStoresWithoutTTL(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.TTL = time.Minute
+	r := newTestCache[string](t, cfg)
+
+	_ = r.Set(ctx, "default", "v", 0)
+	if ttl := mr.TTL(cfg.Prefix + "default"); ttl != time.Minute {
+		t.Errorf("zero TTL stored as %v, want the 1m default", ttl)
+	}
+
+	// Overwriting a key that has a TTL must not keep it (go-redis reads -1
+	// as KEEPTTL).
+	_ = r.Set(ctx, "forever", "v", time.Second)
+	_ = r.Set(ctx, "forever", "v", base.NoExpiration)
+	_ = r.SetManyPipeline(ctx, map[string]string{"bulk": "v"}, base.NoExpiration)
+	if _, _, err := r.SetIfAbsent(ctx, "absent", "v", base.NoExpiration); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"forever", "bulk", "absent"} {
+		if ttl := mr.TTL(cfg.Prefix + k); ttl != 0 {
+			t.Errorf("%s stored with TTL %v, want none", k, ttl)
+		}
+	}
+
+	mr.FastForward(24 * time.Hour)
+	if v, err := r.Get(ctx, "forever"); err != nil || v != "v" {
+		t.Errorf("Get(forever) after a day = %q, %v", v, err)
+	}
+	if _, err := r.Get(ctx, "default"); !errors.Is(err, base.ErrCacheMiss) {
+		t.Errorf("Get(default) after a day = %v, want a miss", err)
+	}
+}
+
+/* ------------------ DeleteByPattern ------------------ */
+
+func TestDeleteByPatternMultiWildcard(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+
+	for _, k := range []string{"user:1:session:a", "user:2:session:b", "user:1:profile", "admin:1:session:a"} {
+		_ = r.Set(ctx, k, 1, time.Minute)
+	}
+	// A matching key outside the cache prefix is left alone.
+	mr.Set("other:user:1:session:a", "1")
+
+	n, err := r.DeleteByPattern(ctx, "user:*:session:*")
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByPattern = %d, %v; want 2", n, err)
+	}
+	keys := mr.Keys()
+	want := []string{cfg.Prefix + "admin:1:session:a", cfg.Prefix + "user:1:profile", "other:user:1:session:a"}
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys left = %v, want %v", keys, want)
+	}
+}
+
+/* ------------------ CountByPrefix ------------------ */
+
+func TestCountByPrefixOverlapping(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[int](t, cfg)
+
+	// Enough keys to take several SCAN pages.
+	for i := range 2500 {
+		mr.Set(cfg.Prefix+"bulk:"+strconv.Itoa(i), "1")
+	}
+	for _, k := range []string{"user:1", "user:10", "user:100", "user:2"} {
+		_ = r.Set(ctx, k, 1, time.Minute)
+	}
+	mr.Set("other:user:1", "1")
+
+	for prefix, want := range map[string]int64{
+		"user:1": 3, "user:10": 2, "user:": 4, "bulk:": 2500, "": 2504,
+	} {
+		if n, err := r.CountByPrefix(ctx, prefix); err != nil || n != want {
+			t.Errorf("CountByPrefix(%q) = %d, %v; want %d", prefix, n, err, want)
+		}
+	}
+	if n := len(mr.Keys()); n != 2505 {
+		t.Errorf("%d keys after counting, want 2505", n)
+	}
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+)
+
+/* ------------------ Semaphore ------------------ */
+
+// acquireScript drops holders whose TTL has passed, then adds ARGV[3] with
+// its expiry as score if fewer than ARGV[1] remain. Time comes from the
+// server so holders on different hosts agree on expiry.
+var acquireScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[3])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// semaphore keeps one sorted-set member per holder, scored by the time the
+// holder expires.
+type semaphore[T any] struct {
+	r     *redisCache[T]
+	key   string
+	limit int
+}
+
+// Semaphore returns a distributed semaphore admitting up to limit holders
+// of key at once. It is not fair: slots go to whichever caller asks first
+// once one frees up. A holder that never releases loses its slot when its
+// TTL passes.
+func (r *redisCache[T]) Semaphore(key string, limit int) interfaces.Semaphore {
+	return &semaphore[T]{r: r, key: key, limit: limit}
+}
+
+// Acquire takes a slot for ttl. release gives it back early and is safe to
+// call more than once; it is nil when ok is false.
+func (s *semaphore[T]) Acquire(ctx context.Context, ttl time.Duration) (func(), bool, error) {
+	r := s.r
+	if err := r.base.ValidateKey(s.key); err != nil {
+		return nil, false, err
+	}
+	if err := r.base.CheckContext(ctx); err != nil {
+		return nil, false, err
+	}
+
+	// A slot without a TTL would outlive a crashed holder.
+	if s.limit <= 0 || ttl == base.NoExpiration {
+		return nil, false, base.WrapError(base.OpLock, base.ErrInvalidArgument, s.key)
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, false, base.WrapError(base.OpLock, err, s.key)
+	}
+
+	ttl = r.base.ResolveTTL(ttl)
+	semKey := r.base.FullKey("sem:" + s.key)
+
+	n, err := acquireScript.Run(ctx, r.client, []string{semKey}, s.limit, ttl.Milliseconds(), token).Int64()
+	if err != nil {
+		return nil, false, wrapError(base.OpLock, err, s.key)
+	}
+	if n == 0 {
+		return nil, false, nil
+	}
+
+	// Release outlives the caller's context, which may be done by then.
+	rctx := context.WithoutCancel(ctx)
+	release := func() {
+		_ = r.client.ZRem(rctx, semKey, token).Err()
+	}
+	return release, true, nil
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+func TestSemaphoreAdmitsAtMostLimit(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	const limit = 5
+
+	var (
+		admitted atomic.Int64
+		wg       sync.WaitGroup
+	)
+	for range 20 {
+		// Each caller is its own process as far as Redis can tell.
+		sem := newTestCache[string](t, cfg).Semaphore("api", limit)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := sem.Acquire(ctx, time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				admitted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := admitted.Load(); n != limit {
+		t.Errorf("%d holders admitted, want %d", n, limit)
+	}
+}
+
+func TestSemaphoreBoundsConcurrentHolders(t *testing.T) {
+	ctx := context.Background()
+	sem := newTestCache[string](t, testConfig(miniredis.RunT(t))).Semaphore("api", 3)
+
+	var (
+		cur, peak, done atomic.Int64
+		wg              sync.WaitGroup
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for done.Load() < 30 {
+				release, ok, err := sem.Acquire(ctx, time.Minute)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !ok {
+					time.Sleep(time.Millisecond)
+					continue
+				}
+				n := cur.Add(1)
+				for p := peak.Load(); n > p; p = peak.Load() {
+					if peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				cur.Add(-1)
+				done.Add(1)
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 3 {
+		t.Errorf("%d holders at once, limit is 3", p)
+	}
+}
+
+func TestSemaphoreReleaseFreesSlot(t *testing.T) {
+	ctx := context.Background()
+	sem := newTestCache[string](t, testConfig(miniredis.RunT(t))).Semaphore("api", 1)
+
+	release, ok, err := sem.Acquire(ctx, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Acquire = %v, %v", ok, err)
+	}
+	if r, ok, _ := sem.Acquire(ctx, time.Minute); ok || r != nil {
+		t.Error("second holder admitted with limit 1")
+	}
+
+	release()
+	release() // releasing twice is safe
+	if _, ok, err := sem.Acquire(ctx, time.Minute); err != nil || !ok {
+		t.Errorf("Acquire after release = %v, %v", ok, err)
+	}
+}
+
+func TestSemaphoreStaleHolderExpires(t *testing.T) {
+	ctx := context.Background()
+	sem := newTestCache[string](t, testConfig(miniredis.RunT(t))).Semaphore("api", 1)
+
+	// The holder never releases.
+	if _, ok, err := sem.Acquire(ctx, 30*time.Millisecond); err != nil || !ok {
+		t.Fatalf("Acquire = %v, %v", ok, err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, ok, err := sem.Acquire(ctx, time.Minute); err != nil || !ok {
+		t.Errorf("Acquire after the holder's TTL = %v, %v; want the slot back", ok, err)
+	}
+}
+
+func TestSemaphoreRejectsInvalidArguments(t *testing.T) {
+	ctx := context.Background()
+	r := newTestCache[string](t, testConfig(miniredis.RunT(t)))
+
+	if _, _, err := r.Semaphore("api", 0).Acquire(ctx, time.Minute); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("limit 0 = %v, want ErrInvalidArgument", err)
+	}
+	if _, _, err := r.Semaphore("api", 1).Acquire(ctx, base.NoExpiration); !errors.Is(err, base.ErrInvalidArgument) {
+		t.Errorf("no TTL = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/os-golib/go-cache/internal/base"
+)
+
+type profileV1 struct {
+	Name string `json:"name"`
+}
+
+type profileV2 struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+}
+
+func migrateProfile(oldVersion int, data []byte) (profileV2, error) {
+	if oldVersion != 1 {
+		return profileV2{}, errors.New("no migration")
+	}
+	var old profileV1
+	if err := json.Unmarshal(data, &old); err != nil {
+		return profileV2{}, err
+	}
+	first, last, _ := strings.Cut(old.Name, " ")
+	return profileV2{First: first, Last: last}, nil
+}
+
+func TestGetMigratesAndRewritesOldVersion(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+
+	v1 := newTestCache[profileV1](t, cfg, WithSerializer[profileV1](
+		base.VersionedSerializer[profileV1]{Inner: base.JsonSerializer[profileV1]{}, Version: 1}))
+	if err := v1.Set(ctx, "p", profileV1{Name: "Ada Lovelace"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := newTestCache[profileV2](t, cfg, WithSerializer[profileV2](base.VersionedSerializer[profileV2]{
+		Inner:   base.JsonSerializer[profileV2]{},
+		Version: 2,
+		Migrate: migrateProfile,
+	}))
+	got, err := v2.Get(ctx, "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (profileV2{First: "Ada", Last: "Lovelace"}) {
+		t.Errorf("Get = %+v, want the migrated value", got)
+	}
+
+	raw, err := mr.Get(cfg.Prefix + "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, "v2:") {
+		t.Errorf("stored payload %q not rewritten at v2", raw)
+	}
+	if ttl := mr.TTL(cfg.Prefix + "p"); ttl <= 59*time.Minute {
+		t.Errorf("TTL after rewrite = %v, want the original hour kept", ttl)
+	}
+}
+
+func TestEveryReadPathRepairsOldVersion(t *testing.T) {
+	reads := map[string]func(ctx context.Context, c *redisCache[profileV2]) error{
+		"GetWithTTL": func(ctx context.Context, c *redisCache[profileV2]) error {
+			_, _, err := c.GetWithTTL(ctx, "p")
+			return err
+		},
+		"GetManyPipeline": func(ctx context.Context, c *redisCache[profileV2]) error {
+			_, err := c.GetManyPipeline(ctx, []string{"p"})
+			return err
+		},
+		"GetManyOrdered": func(ctx context.Context, c *redisCache[profileV2]) error {
+			_, err := c.GetManyOrdered(ctx, []string{"p"})
+			return err
+		},
+		"Range": func(ctx context.Context, c *redisCache[profileV2]) error {
+			return c.Range(ctx, func(string, profileV2) bool { return true })
+		},
+		"SetIfAbsent": func(ctx context.Context, c *redisCache[profileV2]) error {
+			_, _, err := c.SetIfAbsent(ctx, "p", profileV2{}, time.Hour)
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			mr := miniredis.RunT(t)
+			cfg := testConfig(mr)
+
+			v1 := newTestCache[profileV1](t, cfg, WithSerializer[profileV1](
+				base.VersionedSerializer[profileV1]{Inner: base.JsonSerializer[profileV1]{}, Version: 1}))
+			if err := v1.Set(ctx, "p", profileV1{Name: "Ada Lovelace"}, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			v2 := newTestCache[profileV2](t, cfg, WithSerializer[profileV2](base.VersionedSerializer[profileV2]{
+				Inner:   base.JsonSerializer[profileV2]{},
+				Version: 2,
+				Migrate: migrateProfile,
+			}))
+			if err := read(ctx, v2); err != nil {
+				t.Fatal(err)
+			}
+
+			if raw, _ := mr.Get(cfg.Prefix + "p"); !strings.HasPrefix(raw, "v2:") {
+				t.Errorf("stored payload %q not rewritten at v2", raw)
+			}
+			if ttl := mr.TTL(cfg.Prefix + "p"); ttl <= 59*time.Minute {
+				t.Errorf("TTL after rewrite = %v, want the original hour kept", ttl)
+			}
+		})
+	}
+}
+
+func TestGetWithoutMigratorTreatsOldVersionAsMiss(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+
+	v1 := newTestCache[string](t, cfg, WithSerializer[string](
+		base.VersionedSerializer[string]{Inner: base.JsonSerializer[string]{}, Version: 1}))
+	if err := v1.Set(ctx, "k", "old", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := newTestCache[string](t, cfg, WithSerializer[string](
+		base.VersionedSerializer[string]{Inner: base.JsonSerializer[string]{}, Version: 2}))
+	if _, err := v2.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("Get = %v, want a miss", err)
+	}
+}
+
+/* ------------------ Undecodable values ------------------ */
+
+func TestEvictOnDecodeErrorTurnsCorruptValueIntoMiss(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[profileV2](t, cfg, WithEvictOnDecodeError[profileV2](true))
+
+	for _, read := range []struct {
+		name string
+		get  func(key string) error
+	}{
+		{"Get", func(k string) error { _, err := r.Get(ctx, k); return err }},
+		{"GetWithTTL", func(k string) error { _, _, err := r.GetWithTTL(ctx, k); return err }},
+	} {
+		if err := mr.Set(cfg.Prefix+"p", "{not json"); err != nil {
+			t.Fatal(err)
+		}
+		if err := read.get("p"); !base.IsCacheMiss(err) || errors.Is(err, base.ErrDeserialize) {
+			t.Errorf("%s = %v, want a miss", read.name, err)
+		}
+		if mr.Exists(cfg.Prefix + "p") {
+			t.Errorf("%s left the corrupt value behind", read.name)
+		}
+		if err := read.get("p"); !base.IsCacheMiss(err) {
+			t.Errorf("second %s = %v, want a clean miss", read.name, err)
+		}
+	}
+}
+
+func TestEvictOnDecodeErrorInPipelineGet(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[profileV2](t, cfg, WithEvictOnDecodeError[profileV2](true))
+
+	if err := r.Set(ctx, "good", profileV2{First: "ann"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	_ = mr.Set(cfg.Prefix+"bad", "{not json")
+
+	got, err := r.GetManyPipeline(ctx, []string{"good", "bad"})
+	if err != nil {
+		t.Fatalf("GetManyPipeline = %v, want the corrupt key reported as a miss", err)
+	}
+	if _, ok := got["bad"]; ok || got["good"].First != "ann" {
+		t.Errorf("GetManyPipeline = %v, want only good", got)
+	}
+	if mr.Exists(cfg.Prefix + "bad") {
+		t.Error("corrupt value not deleted")
+	}
+}
+
+func TestDecodeErrorKeptByDefault(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[profileV2](t, cfg)
+
+	_ = mr.Set(cfg.Prefix+"p", "{not json")
+	if _, err := r.Get(ctx, "p"); !errors.Is(err, base.ErrDeserialize) {
+		t.Errorf("Get = %v, want ErrDeserialize", err)
+	}
+	if !mr.Exists(cfg.Prefix + "p") {
+		t.Error("value deleted without WithEvictOnDecodeError")
+	}
+}
+
+func TestPipelineSerializationErrorsNameTheKey(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	r := newTestCache[float64](t, cfg)
+
+	_ = mr.Set(cfg.Prefix+"user:7", "{not json")
+	_ = r.Set(ctx, "user:8", 8, time.Minute)
+	_, err := r.GetManyPipeline(ctx, []string{"user:7", "user:8"})
+	if !errors.Is(err, base.ErrDeserialize) {
+		t.Fatalf("GetManyPipeline = %v, want ErrDeserialize", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "user:7") || strings.Contains(msg, "user:8") {
+		t.Errorf("GetManyPipeline error = %q, want only the failing key", msg)
+	}
+
+	// JSON cannot encode NaN.
+	failed, err := r.SetManyPipelineResult(ctx, map[string]float64{"ok": 1, "nan": math.NaN()}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ferr := failed["nan"]
+	if len(failed) != 1 || !errors.Is(ferr, base.ErrSerialize) {
+		t.Fatalf("failed = %v, want nan with ErrSerialize", failed)
+	}
+	if msg := ferr.Error(); !strings.Contains(msg, "nan") || !strings.Contains(msg, "unsupported value") {
+		t.Errorf("SetManyPipelineResult error = %q, want the key and the encoder's cause", msg)
+	}
+}
@@ -0,0 +1,68 @@
+package redis
+
+import "strings"
+
+/* ------------------ Key Batching ------------------ */
+
+const (
+	defaultDeleteBatchSize = 500
+	defaultScanCount       = 1000
+	clusterSlots           = 16384
+)
+
+// chunk splits keys into consecutive batches of at most size keys.
+func chunk(keys []string, size int) [][]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	out := make([][]string, 0, (len(keys)+size-1)/size)
+	for start := 0; start < len(keys); start += size {
+		out = append(out, keys[start:min(start+size, len(keys))])
+	}
+	return out
+}
+
+// groupBySlot partitions keys by Redis Cluster hash slot, preserving the
+// relative order of keys within each slot.
+func groupBySlot(keys []string) [][]string {
+	index := make(map[uint16]int)
+	var out [][]string
+	for _, k := range keys {
+		s := hashSlot(k)
+		i, ok := index[s]
+		if !ok {
+			i = len(out)
+			index[s] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], k)
+	}
+	return out
+}
+
+// hashSlot implements the Redis Cluster key → slot mapping, honouring
+// {hash tags}.
+func hashSlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return crc16(key) % clusterSlots
+}
+
+// crc16 is CRC-16/XMODEM, the checksum used for cluster slots.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
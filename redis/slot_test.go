@@ -0,0 +1,280 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+func TestChunkBoundaries(t *testing.T) {
+	keys := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strconv.Itoa(i)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		n, size int
+		want    []int
+	}{
+		{0, 3, nil},
+		{1, 3, []int{1}},
+		{3, 3, []int{3}},
+		{4, 3, []int{3, 1}},
+		{7, 3, []int{3, 3, 1}},
+	} {
+		var got []int
+		for _, b := range chunk(keys(tt.n), tt.size) {
+			got = append(got, len(b))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("chunk(%d keys, %d) sizes = %v, want %v", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestHashSlot(t *testing.T) {
+	// Reference values from the Redis Cluster specification and CLUSTER KEYSLOT.
+	if got := crc16("123456789"); got != 0x31C3 {
+		t.Errorf("crc16(123456789) = %#x, want 0x31c3", got)
+	}
+	if got := hashSlot("foo"); got != 12182 {
+		t.Errorf("hashSlot(foo) = %d, want 12182", got)
+	}
+	if hashSlot("{user1000}.following") != hashSlot("{user1000}.followers") {
+		t.Error("keys sharing a hash tag map to different slots")
+	}
+	// An empty tag does not count; the whole key is hashed.
+	if hashSlot("foo{}{bar}") != crc16("foo{}{bar}")%clusterSlots {
+		t.Error("empty hash tag used")
+	}
+}
+
+func TestGroupBySlotKeepsOrder(t *testing.T) {
+	keys := []string{"{a}1", "{b}1", "{a}2", "{b}2", "{a}3"}
+	groups := groupBySlot(keys)
+	want := [][]string{{"{a}1", "{a}2", "{a}3"}, {"{b}1", "{b}2"}}
+	if !slices.EqualFunc(groups, want, slices.Equal[[]string]) {
+		t.Errorf("groupBySlot = %v, want %v", groups, want)
+	}
+}
+
+/* ------------------ DeleteMany ------------------ */
+
+// delRecorder records the key count of every DEL and, when crossSlot is
+// set, rejects DELs spanning hash slots as a cluster node would.
+type delRecorder struct {
+	mu        sync.Mutex
+	sizes     []int
+	crossSlot bool
+}
+
+func (d *delRecorder) hook(c *server.Peer, cmd string, args ...string) bool {
+	if cmd != "DEL" {
+		return false
+	}
+	d.mu.Lock()
+	d.sizes = append(d.sizes, len(args))
+	d.mu.Unlock()
+	if d.crossSlot {
+		for _, k := range args[1:] {
+			if hashSlot(k) != hashSlot(args[0]) {
+				c.WriteError("CROSSSLOT Keys in request don't hash to the same slot")
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func seedKeys(t *testing.T, r *redisCache[int], keys ...string) {
+	t.Helper()
+	for _, k := range keys {
+		if err := r.Set(context.Background(), k, 1, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDeleteManyBatches(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.DeleteBatchSize = 2
+	r := newTestCache[int](t, cfg)
+	rec := &delRecorder{}
+	mr.Server().SetPreHook(rec.hook)
+
+	seedKeys(t, r, "a", "b", "c", "d")
+	n, err := r.DeleteMany(ctx, []string{"a", "b", "c", "d", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("DeleteMany = %d, want 4", n)
+	}
+	if want := []int{2, 2, 1}; !slices.Equal(rec.sizes, want) {
+		t.Errorf("DEL sizes = %v, want %v", rec.sizes, want)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys left: %v", keys)
+	}
+}
+
+func TestDeleteManySingleBatchAndEmpty(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.DeleteBatchSize = 2
+	r := newTestCache[int](t, cfg)
+	rec := &delRecorder{}
+	mr.Server().SetPreHook(rec.hook)
+
+	if n, err := r.DeleteMany(ctx, nil); n != 0 || err != nil {
+		t.Errorf("DeleteMany(nil) = %d, %v", n, err)
+	}
+	seedKeys(t, r, "a", "b")
+	if n, err := r.DeleteMany(ctx, []string{"a", "b"}); n != 2 || err != nil {
+		t.Errorf("DeleteMany(a, b) = %d, %v", n, err)
+	}
+	if want := []int{2}; !slices.Equal(rec.sizes, want) {
+		t.Errorf("DEL sizes = %v, want %v", rec.sizes, want)
+	}
+}
+
+func TestDeleteManyGroupsBySlotOnCluster(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[int](t, testConfig(mr))
+	r.cluster = true
+	rec := &delRecorder{crossSlot: true}
+	mr.Server().SetPreHook(rec.hook)
+
+	keys := []string{"{u1}a", "{u2}a", "{u1}b", "{u3}a", "{u2}b"}
+	seedKeys(t, r, keys...)
+	n, err := r.DeleteMany(ctx, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("DeleteMany = %d, want 5", n)
+	}
+	if len(rec.sizes) != 3 {
+		t.Errorf("DEL sizes = %v, want one DEL per slot", rec.sizes)
+	}
+}
+
+/* ------------------ SCAN count ------------------ */
+
+// scanRecorder records the COUNT argument of every SCAN.
+type scanRecorder struct {
+	mu     sync.Mutex
+	counts []string
+}
+
+func (s *scanRecorder) hook(_ *server.Peer, cmd string, args ...string) bool {
+	if cmd != "SCAN" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i+1 < len(args); i++ {
+		if strings.EqualFold(args[i], "COUNT") {
+			s.counts = append(s.counts, args[i+1])
+		}
+	}
+	return false
+}
+
+func (s *scanRecorder) seen() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.counts)
+}
+
+func TestScanUsesConfiguredCount(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.ScanCount = 7
+	r := newTestCache[int](t, cfg)
+	seedKeys(t, r, "user:1", "user:2", "order:1")
+	rec := &scanRecorder{}
+	mr.Server().SetPreHook(rec.hook)
+
+	ops := []struct {
+		name string
+		run  func() error
+	}{
+		{"Len", func() error { _, err := r.Len(ctx); return err }},
+		{"CountByPrefix", func() error { _, err := r.CountByPrefix(ctx, "user:"); return err }},
+		{"Range", func() error { return r.Range(ctx, func(string, int) bool { return true }) }},
+		{"DeleteByPrefix", func() error { _, err := r.DeleteByPrefix(ctx, "user:"); return err }},
+		{"Clear", func() error { return r.Clear(ctx) }},
+	}
+	for _, op := range ops {
+		before := len(rec.seen())
+		if err := op.run(); err != nil {
+			t.Fatalf("%s: %v", op.name, err)
+		}
+		counts := rec.seen()[before:]
+		if len(counts) == 0 {
+			t.Errorf("%s issued no SCAN", op.name)
+		}
+		for _, c := range counts {
+			if c != "7" {
+				t.Errorf("%s scanned with COUNT %s, want 7", op.name, c)
+			}
+		}
+	}
+}
+
+func TestScanCountDefault(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	r := newTestCache[int](t, testConfig(mr))
+	rec := &scanRecorder{}
+	mr.Server().SetPreHook(rec.hook)
+
+	if _, err := r.Len(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{strconv.Itoa(defaultScanCount)}; !slices.Equal(rec.seen(), want) {
+		t.Errorf("SCAN counts = %v, want %v", rec.seen(), want)
+	}
+}
+
+func TestSmallScanCountVisitsEveryKey(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	cfg := testConfig(mr)
+	cfg.ScanCount = 1
+	r := newTestCache[int](t, cfg)
+	seedKeys(t, r, "a", "b", "c", "d", "e")
+
+	if n, err := r.Len(ctx); err != nil || n != 5 {
+		t.Errorf("Len = %d, %v; want 5", n, err)
+	}
+	if err := r.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys left after Clear: %v", keys)
+	}
+}
+
+func TestScanCountValidated(t *testing.T) {
+	cfg := testConfig(miniredis.RunT(t))
+	cfg.ScanCount = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate accepted a negative scan_count")
+	}
+}
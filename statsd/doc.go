@@ -0,0 +1,9 @@
+// Package statsd periodically exports a cache's metrics to StatsD or
+// DogStatsD. It depends on no client library: any client with Count and
+// Timing methods in the DogStatsD style, such as datadog-go's
+// *statsd.Client, satisfies Client, and NewUDPClient provides a minimal
+// one.
+//
+//	exp := statsd.NewExporter(c.Metrics(), client, 10*time.Second)
+//	defer exp.Close()
+package statsd
@@ -0,0 +1,169 @@
+package statsd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+/* ------------------ Exporter ------------------ */
+
+const (
+	defaultFlushInterval = 10 * time.Second
+	defaultPrefix        = "cache."
+)
+
+// Client is the subset of a DogStatsD client the exporter uses.
+type Client interface {
+	Count(name string, value int64, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+}
+
+// Option customizes an Exporter.
+type Option func(*Exporter)
+
+// WithPrefix replaces the "cache." prefix of every metric name.
+func WithPrefix(prefix string) Option {
+	return func(e *Exporter) {
+		e.prefix = prefix
+	}
+}
+
+// WithBackend sets the backend tag. Without it the backend is read once
+// from the collector's stats source, which for Redis means one key count.
+func WithBackend(name string) Option {
+	return func(e *Exporter) {
+		e.backend = name
+	}
+}
+
+// WithTags adds tags, such as "service:api", to every metric.
+func WithTags(tags ...string) Option {
+	return func(e *Exporter) {
+		e.tags = append(e.tags, tags...)
+	}
+}
+
+// Exporter sends, every flush interval, what each operation did since the
+// previous flush: the counters <prefix>operations, hits, misses and errors,
+// and the mean duration as the timer <prefix>duration. Every metric is
+// tagged operation:<op> and backend:<backend>.
+type Exporter struct {
+	c       *metrics.Collector
+	client  Client
+	prefix  string
+	backend string
+	tags    []string
+
+	mu   sync.Mutex
+	last map[string]metrics.SnapshotStats
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewExporter starts exporting c through client every flushInterval
+// (default 10s) until Close.
+func NewExporter(c *metrics.Collector, client Client, flushInterval time.Duration, opts ...Option) *Exporter {
+	e := &Exporter{
+		c:      c,
+		client: client,
+		prefix: defaultPrefix,
+		last:   make(map[string]metrics.SnapshotStats),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	go e.loop(flushInterval)
+	return e
+}
+
+func (e *Exporter) loop(interval time.Duration) {
+	defer close(e.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			_ = e.Flush()
+		}
+	}
+}
+
+// Flush sends the changes since the previous flush now. It returns the
+// first client error; metrics after it are still sent.
+func (e *Exporter) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.backend == "" {
+		e.backend = e.c.Report(context.Background()).Stats.Backend
+	}
+
+	var firstErr error
+	send := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for op, cur := range e.c.Snapshot() {
+		prev := e.last[op]
+		e.last[op] = cur
+
+		// A Reset since the last flush restarts the counts from zero.
+		if cur.Count < prev.Count {
+			prev = metrics.SnapshotStats{}
+		}
+
+		tags := e.tagsFor(op)
+		count := cur.Count - prev.Count
+		if count > 0 {
+			send(e.client.Count(e.prefix+"operations", count, tags, 1))
+			send(e.client.Timing(e.prefix+"duration", (cur.TotalDuration-prev.TotalDuration)/time.Duration(count), tags, 1))
+		}
+		if n := cur.Hits - prev.Hits; n > 0 {
+			send(e.client.Count(e.prefix+"hits", n, tags, 1))
+		}
+		if n := cur.Misses - prev.Misses; n > 0 {
+			send(e.client.Count(e.prefix+"misses", n, tags, 1))
+		}
+		if n := cur.Errors - prev.Errors; n > 0 {
+			send(e.client.Count(e.prefix+"errors", n, tags, 1))
+		}
+	}
+	return firstErr
+}
+
+func (e *Exporter) tagsFor(op string) []string {
+	tags := make([]string, 0, len(e.tags)+2)
+	tags = append(tags, "operation:"+op)
+	if e.backend != "" {
+		tags = append(tags, "backend:"+e.backend)
+	}
+	return append(tags, e.tags...)
+}
+
+// Close stops the flusher and sends what is left. The client is not
+// closed.
+func (e *Exporter) Close() error {
+	var err error
+	e.stopOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		err = e.Flush()
+	})
+	return err
+}
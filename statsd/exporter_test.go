@@ -0,0 +1,191 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+// sentMetric is one Count or Timing call seen by fakeClient.
+type sentMetric struct {
+	name  string
+	value int64
+	tags  string
+}
+
+// fakeClient records what the exporter sends; Timing values are kept in
+// microseconds.
+type fakeClient struct {
+	mu   sync.Mutex
+	sent []sentMetric
+	err  error
+}
+
+func (f *fakeClient) Count(name string, value int64, tags []string, _ float64) error {
+	return f.add(name, value, tags)
+}
+
+func (f *fakeClient) Timing(name string, value time.Duration, tags []string, _ float64) error {
+	return f.add(name, value.Microseconds(), tags)
+}
+
+func (f *fakeClient) add(name string, value int64, tags []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, sentMetric{name: name, value: value, tags: strings.Join(tags, ",")})
+	return f.err
+}
+
+// take returns and forgets what was sent, sorted by name.
+func (f *fakeClient) take() []sentMetric {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := f.sent
+	f.sent = nil
+	slices.SortFunc(out, func(a, b sentMetric) int { return strings.Compare(a.name+a.tags, b.name+b.tags) })
+	return out
+}
+
+// newExporter returns an exporter that only flushes when told to.
+func newExporter(t *testing.T, c *metrics.Collector, client Client, opts ...Option) *Exporter {
+	t.Helper()
+	e := NewExporter(c, client, time.Hour, opts...)
+	t.Cleanup(func() { _ = e.Close() })
+	return e
+}
+
+func TestFlushSendsCountersAndTimers(t *testing.T) {
+	c := metrics.NewCollector()
+	c.RecordOperation("get", 10*time.Millisecond, 1)
+	c.RecordOperation("get", 20*time.Millisecond, 1)
+	c.RecordOperation("get", 30*time.Millisecond, 1)
+	c.RecordHit("get", 2)
+	c.RecordMiss("get", 1)
+	c.RecordError("get")
+
+	client := &fakeClient{}
+	e := newExporter(t, c, client, WithBackend("memory"), WithTags("service:api"))
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	const tags = "operation:get,backend:memory,service:api"
+	want := []sentMetric{
+		{"cache.duration", 20_000, tags},
+		{"cache.errors", 1, tags},
+		{"cache.hits", 2, tags},
+		{"cache.misses", 1, tags},
+		{"cache.operations", 3, tags},
+	}
+	if got := client.take(); !slices.Equal(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+}
+
+func TestFlushSendsOnlyChanges(t *testing.T) {
+	c := metrics.NewCollector()
+	c.RecordOperation("get", 10*time.Millisecond, 1)
+	c.RecordOperation("set", 10*time.Millisecond, 1)
+
+	client := &fakeClient{}
+	e := newExporter(t, c, client, WithBackend("memory"), WithPrefix("app.cache."))
+	_ = e.Flush()
+	client.take()
+
+	_ = e.Flush()
+	if len(client.take()) != 0 {
+		t.Error("unchanged collector sent metrics")
+	}
+
+	c.RecordOperation("get", 40*time.Millisecond, 1)
+	c.RecordOperation("get", 60*time.Millisecond, 1)
+	_ = e.Flush()
+	want := []sentMetric{
+		{"app.cache.duration", 50_000, "operation:get,backend:memory"},
+		{"app.cache.operations", 2, "operation:get,backend:memory"},
+	}
+	if got := client.take(); !slices.Equal(got, want) {
+		t.Errorf("sent %v, want the interval's %v", got, want)
+	}
+
+	// A Reset restarts the counts instead of going negative.
+	c.Reset()
+	c.RecordOperation("get", 10*time.Millisecond, 1)
+	_ = e.Flush()
+	for _, m := range client.take() {
+		if m.name == "app.cache.operations" && m.value != 1 {
+			t.Errorf("operations after Reset = %d, want 1", m.value)
+		}
+	}
+}
+
+func TestBackendTagFromStatsSource(t *testing.T) {
+	c := metrics.NewCollector()
+	c.SetStatsSource(func(context.Context) metrics.CacheStats {
+		return metrics.NewStatsBuilder("redis").Build()
+	})
+	c.RecordOperation("get", time.Millisecond, 1)
+
+	client := &fakeClient{}
+	_ = newExporter(t, c, client).Flush()
+	for _, m := range client.take() {
+		if m.tags != "operation:get,backend:redis" {
+			t.Errorf("%s tags = %q, want the source's backend", m.name, m.tags)
+		}
+	}
+}
+
+func TestFlushReturnsFirstClientError(t *testing.T) {
+	c := metrics.NewCollector()
+	c.RecordOperation("get", time.Millisecond, 1)
+	c.RecordHit("get", 1)
+
+	errDown := errors.New("agent down")
+	client := &fakeClient{err: errDown}
+	if err := newExporter(t, c, client, WithBackend("memory")).Flush(); !errors.Is(err, errDown) {
+		t.Errorf("Flush = %v, want %v", err, errDown)
+	}
+	if n := len(client.take()); n != 3 {
+		t.Errorf("sent %d metrics, want all 3 despite the error", n)
+	}
+}
+
+func TestExporterFlushesPeriodicallyAndOnClose(t *testing.T) {
+	c := metrics.NewCollector()
+	c.RecordOperation("get", time.Millisecond, 1)
+
+	client := &fakeClient{}
+	e := NewExporter(c, client, 10*time.Millisecond, WithBackend("memory"))
+
+	deadline := time.Now().Add(time.Second)
+	for len(client.take()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no periodic flush")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	c.RecordOperation("get", time.Millisecond, 1)
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sent := client.take()
+	if !slices.ContainsFunc(sent, func(m sentMetric) bool { return m.name == "cache.operations" && m.value == 1 }) {
+		t.Errorf("Close sent %v, want the remaining operation", sent)
+	}
+
+	c.RecordOperation("get", time.Millisecond, 1)
+	time.Sleep(30 * time.Millisecond)
+	if got := client.take(); len(got) != 0 {
+		t.Errorf("sent %v after Close", got)
+	}
+	if err := e.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ------------------ UDP Client ------------------ */
+
+// UDPClient sends each metric as one DogStatsD datagram. It is meant for
+// setups without a StatsD library; sends are unbuffered and unsampled.
+type UDPClient struct {
+	conn net.Conn
+}
+
+// NewUDPClient dials the StatsD agent at addr (host:port).
+func NewUDPClient(addr string) (*UDPClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: dial %s: %w", addr, err)
+	}
+	return &UDPClient{conn: conn}, nil
+}
+
+func (u *UDPClient) Count(name string, value int64, tags []string, rate float64) error {
+	return u.send(name, strconv.FormatInt(value, 10), "c", tags, rate)
+}
+
+// Timing sends value in milliseconds.
+func (u *UDPClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	ms := strconv.FormatFloat(float64(value)/float64(time.Millisecond), 'f', -1, 64)
+	return u.send(name, ms, "ms", tags, rate)
+}
+
+// send writes name:value|type[|@rate][|#tags].
+func (u *UDPClient) send(name, value, typ string, tags []string, rate float64) error {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(typ)
+	if rate > 0 && rate < 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	if len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(tags, ","))
+	}
+
+	_, err := u.conn.Write([]byte(b.String()))
+	return err
+}
+
+func (u *UDPClient) Close() error {
+	return u.conn.Close()
+}
@@ -0,0 +1,42 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+/* ------------------ UDP Client ------------------ */
+
+func TestUDPClientWritesDogStatsDLines(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pc.Close() })
+
+	u, err := NewUDPClient(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = u.Close() })
+
+	_ = u.Count("cache.hits", 3, []string{"operation:get", "backend:redis"}, 0.5)
+	_ = u.Timing("cache.duration", 1500*time.Microsecond, nil, 1)
+
+	want := []string{
+		"cache.hits:3|c|@0.5|#operation:get,backend:redis",
+		"cache.duration:1.5|ms",
+	}
+	buf := make([]byte, 512)
+	for _, w := range want {
+		_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != w {
+			t.Errorf("datagram = %q, want %q", got, w)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+/* ------------------ Types ------------------ */
+
+// Envelope is the value stored in both tiers. ExpiresAt is absolute, so a
+// value promoted from L2 to L1 keeps its original deadline instead of
+// receiving a fresh TTL.
+type Envelope[T any] struct {
+	Value     T         `json:"v"`
+	ExpiresAt time.Time `json:"exp,omitempty"`
+}
+
+func (e Envelope[T]) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// remaining returns the lifetime left, or 0 when the envelope never
+// expires.
+func (e Envelope[T]) remaining(now time.Time) time.Duration {
+	if e.ExpiresAt.IsZero() {
+		return 0
+	}
+	return e.ExpiresAt.Sub(now)
+}
+
+type tieredCache[T any] struct {
+	base *base.Base
+	l1   interfaces.Cache[Envelope[T]]
+	l2   interfaces.Cache[Envelope[T]]
+}
+
+/* ------------------ Constructor ------------------ */
+
+// New layers a fast L1 (typically memory) over a shared L2 (typically
+// Redis). Writes go to both tiers; L1 misses are filled from L2.
+func New[T any](
+	l1 interfaces.Cache[Envelope[T]],
+	l2 interfaces.Cache[Envelope[T]],
+	cfg config.Config,
+) (*tieredCache[T], error) {
+	if l1 == nil || l2 == nil {
+		return nil, base.WrapError(base.OpInit, base.ErrInvalidConfig, "")
+	}
+
+	return &tieredCache[T]{
+		base: base.NewBase(cfg),
+		l1:   l1,
+		l2:   l2,
+	}, nil
+}
+
+/* ------------------ Warmup ------------------ */
+
+// Warmup copies up to n of the hottest L2 keys into L1 so a new node does
+// not start cold. It needs an L2 that tracks hits (interfaces.HotKeyProvider)
+// and returns how many entries were loaded.
+func (t *tieredCache[T]) Warmup(ctx context.Context, n int) (int, error) {
+	hp, ok := t.l2.(interfaces.HotKeyProvider)
+	if !ok || n <= 0 {
+		return 0, nil
+	}
+
+	keys, err := hp.HotKeys(ctx, n)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	envs, err := t.fetchL2(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	loaded := 0
+	for _, key := range keys {
+		if env, ok := envs[key]; ok && !env.expired(now) && t.promote(ctx, key, env, now) {
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+// fetchL2 reads keys from L2 in one round trip when possible. Missing and
+// unreadable keys are left out.
+func (t *tieredCache[T]) fetchL2(ctx context.Context, keys []string) (map[string]Envelope[T], error) {
+	if pg, ok := t.l2.(interfaces.PipelineGetter[Envelope[T]]); ok {
+		envs, err := pg.GetManyPipeline(ctx, keys)
+		if base.IsPartial(err) {
+			err = nil
+		}
+		return envs, err
+	}
+
+	out := make(map[string]Envelope[T], len(keys))
+	for _, key := range keys {
+		env, err := t.l2.Get(ctx, key)
+		if base.IsContextError(err) {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		out[key] = env
+	}
+	return out, nil
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (t *tieredCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+
+	if err := t.base.ValidateKey(key); err != nil {
+		return zero, err
+	}
+	if err := t.base.CheckContext(ctx); err != nil {
+		return zero, err
+	}
+
+	now := time.Now()
+
+	if env, err := t.l1.Get(ctx, key); err == nil && !env.expired(now) {
+		return env.Value, nil
+	}
+
+	env, err := t.l2.Get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	if env.expired(now) {
+		return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+	}
+
+	t.promote(ctx, key, env, now)
+	return env.Value, nil
+}
+
+// promote copies env into L1 with its remaining lifetime, unjittered as it
+// was jittered when written; L1 errors are not fatal.
+func (t *tieredCache[T]) promote(ctx context.Context, key string, env Envelope[T], now time.Time) bool {
+	ttl := env.remaining(now)
+	if ttl <= 0 {
+		return false
+	}
+	return t.l1.Set(base.WithExactTTL(ctx), key, env, ttl) == nil
+}
+
+func (t *tieredCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := t.base.ValidateKey(key); err != nil {
+		return err
+	}
+	if err := t.base.CheckContext(ctx); err != nil {
+		return err
+	}
+
+	// Jitter once here so both tiers and the envelope share one deadline.
+	ttl = t.base.WriteTTL(ctx, ttl)
+	ctx = base.WithExactTTL(ctx)
+	env := Envelope[T]{Value: value}
+	if ttl > 0 {
+		env.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	if err := t.l2.Set(ctx, key, env, ttl); err != nil {
+		return err
+	}
+	return t.l1.Set(ctx, key, env, ttl)
+}
+
+// Update runs the read-modify-write on L2, the authoritative tier, and
+// refreshes L1 with the result.
+func (t *tieredCache[T]) Update(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	fn func(current T, found bool) (T, error),
+) error {
+	u, ok := t.l2.(interfaces.Updater[Envelope[T]])
+	if !ok {
+		return fmt.Errorf("Update not supported")
+	}
+
+	ttl = t.base.WriteTTL(ctx, ttl)
+	ctx = base.WithExactTTL(ctx)
+	var env Envelope[T]
+	err := u.Update(ctx, key, ttl, func(cur Envelope[T], found bool) (Envelope[T], error) {
+		found = found && !cur.expired(time.Now())
+		v, err := fn(cur.Value, found)
+		if err != nil {
+			return cur, err
+		}
+		env = Envelope[T]{Value: v}
+		if ttl > 0 {
+			env.ExpiresAt = time.Now().Add(ttl)
+		}
+		return env, nil
+	})
+	if err != nil {
+		return err
+	}
+	return t.l1.Set(ctx, key, env, ttl)
+}
+
+func (t *tieredCache[T]) Delete(ctx context.Context, keys ...string) error {
+	return errors.Join(t.l1.Delete(ctx, keys...), t.l2.Delete(ctx, keys...))
+}
+
+// DeleteMany deletes keys from both tiers and reports the L2 count.
+func (t *tieredCache[T]) DeleteMany(ctx context.Context, keys []string) (int64, error) {
+	l1Err := t.l1.Delete(ctx, keys...)
+
+	d, ok := t.l2.(interfaces.BulkDeleter)
+	if !ok {
+		return 0, errors.Join(l1Err, t.l2.Delete(ctx, keys...))
+	}
+	n, err := d.DeleteMany(ctx, keys)
+	return n, errors.Join(l1Err, err)
+}
+
+func (t *tieredCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, err := t.l1.Exists(ctx, key); err == nil && ok {
+		return true, nil
+	}
+	return t.l2.Exists(ctx, key)
+}
+
+func (t *tieredCache[T]) Clear(ctx context.Context) error {
+	return errors.Join(t.l1.Clear(ctx), t.l2.Clear(ctx))
+}
+
+// Len reports the L2 size, which is the authoritative tier.
+func (t *tieredCache[T]) Len(ctx context.Context) (int, error) {
+	return t.l2.Len(ctx)
+}
+
+func (t *tieredCache[T]) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	var total int64
+	var errs []error
+
+	for _, c := range []interfaces.Cache[Envelope[T]]{t.l1, t.l2} {
+		d, ok := c.(interfaces.PrefixDeleter)
+		if !ok {
+			continue
+		}
+		n, err := d.DeleteByPrefix(ctx, prefix)
+		errs = append(errs, err)
+		if c == t.l2 {
+			total = n
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// CountByPrefix counts in L2, the authoritative tier.
+func (t *tieredCache[T]) CountByPrefix(ctx context.Context, prefix string) (int64, error) {
+	pc, ok := t.l2.(interfaces.PrefixCounter)
+	if !ok {
+		return 0, fmt.Errorf("CountByPrefix not supported")
+	}
+	return pc.CountByPrefix(ctx, prefix)
+}
+
+func (t *tieredCache[T]) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	var total int64
+	var errs []error
+
+	for _, c := range []interfaces.Cache[Envelope[T]]{t.l1, t.l2} {
+		d, ok := c.(interfaces.PatternDeleter)
+		if !ok {
+			continue
+		}
+		n, err := d.DeleteByPattern(ctx, pattern)
+		errs = append(errs, err)
+		if c == t.l2 {
+			total = n
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// Range walks L2, the authoritative tier, when it supports iteration.
+func (t *tieredCache[T]) Range(ctx context.Context, fn func(key string, value T) bool) error {
+	r, ok := t.l2.(interfaces.Ranger[Envelope[T]])
+	if !ok {
+		return fmt.Errorf("Range not supported")
+	}
+
+	now := time.Now()
+	return r.Range(ctx, func(key string, env Envelope[T]) bool {
+		if env.expired(now) {
+			return true
+		}
+		return fn(key, env.Value)
+	})
+}
+
+func (t *tieredCache[T]) Ping(ctx context.Context) error {
+	if err := t.l1.Ping(ctx); err != nil {
+		return err
+	}
+	return t.l2.Ping(ctx)
+}
+
+func (t *tieredCache[T]) Close() error {
+	return errors.Join(t.l1.Close(), t.l2.Close())
+}
+
+/* ------------------ Stats ------------------ */
+
+func (t *tieredCache[T]) Stats(ctx context.Context) metrics.CacheStats {
+	items, _ := t.Len(ctx)
+
+	return metrics.NewStatsBuilder("tiered").
+		WithItems(int64(items)).
+		WithUptime(t.base.Uptime()).
+		WithStartedAt(t.base.StartedAt()).
+		Build()
+}
@@ -0,0 +1,213 @@
+package tiered
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/memory"
+)
+
+// tier is a memory backend that can report remaining TTLs.
+type tier[T any] interface {
+	interfaces.Cache[Envelope[T]]
+	interfaces.TTLGetter[Envelope[T]]
+}
+
+func jitteredConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	cfg.TTLJitter = 0.5
+	return cfg
+}
+
+func newTier[T any](t *testing.T, cfg config.Config) tier[T] {
+	t.Helper()
+	m, err := memory.NewMemory[Envelope[T]](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+// assertSameDeadline checks that both tiers expire key when its envelope
+// does.
+func assertSameDeadline[T any](t *testing.T, l1, l2 tier[T], key string) {
+	t.Helper()
+	ctx := context.Background()
+	env1, ttl1, err := l1.GetWithTTL(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env2, ttl2, err := l2.GetWithTTL(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const slack = 50 * time.Millisecond
+	if d := (ttl1 - ttl2).Abs(); d > slack {
+		t.Errorf("L1 TTL %v and L2 TTL %v differ", ttl1, ttl2)
+	}
+	if d := (time.Until(env1.ExpiresAt) - ttl1).Abs(); d > slack {
+		t.Errorf("envelope expires in %v, L1 entry in %v", time.Until(env1.ExpiresAt), ttl1)
+	}
+	if !env1.ExpiresAt.Equal(env2.ExpiresAt) {
+		t.Errorf("envelope deadlines differ: %v and %v", env1.ExpiresAt, env2.ExpiresAt)
+	}
+}
+
+func TestSetJittersTTLOnce(t *testing.T) {
+	ctx := context.Background()
+	cfg := jitteredConfig()
+	l1, l2 := newTier[string](t, cfg), newTier[string](t, cfg)
+	c, err := New[string](l1, l2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if err := c.Set(ctx, key, "v", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		assertSameDeadline(t, l1, l2, key)
+	}
+}
+
+func TestUpdateJittersTTLOnce(t *testing.T) {
+	ctx := context.Background()
+	cfg := jitteredConfig()
+	l1, l2 := newTier[int](t, cfg), newTier[int](t, cfg)
+	c, err := New[int](l1, l2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Update(ctx, "n", time.Hour, func(cur int, _ bool) (int, error) { return cur + 1, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameDeadline(t, l1, l2, "n")
+}
+
+func TestPromoteKeepsDeadline(t *testing.T) {
+	ctx := context.Background()
+	cfg := jitteredConfig()
+	l1, l2 := newTier[string](t, cfg), newTier[string](t, cfg)
+	c, err := New[string](l1, l2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Set(ctx, "k", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := l1.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	assertSameDeadline(t, l1, l2, "k")
+}
+
+func newTiered[T any](t *testing.T) (*tieredCache[T], tier[T], tier[T]) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	l1, l2 := newTier[T](t, cfg), newTier[T](t, cfg)
+	c, err := New[T](l1, l2, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, l1, l2
+}
+
+func TestPromotedEntryExpiresAtL2Deadline(t *testing.T) {
+	ctx := context.Background()
+	c, l1, _ := newTiered[string](t)
+
+	// Written only to L2, as another node would.
+	const lifetime = 300 * time.Millisecond
+	env := Envelope[string]{Value: "v", ExpiresAt: time.Now().Add(lifetime)}
+	if err := c.l2.Set(ctx, "k", env, lifetime); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	_, ttl, err := l1.GetWithTTL(ctx, "k")
+	if err != nil {
+		t.Fatalf("entry not promoted to L1: %v", err)
+	}
+	if ttl > 160*time.Millisecond {
+		t.Errorf("promoted L1 TTL = %v, want the ~150ms left in L2", ttl)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if _, err := l1.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("L1 still holds the entry past L2's deadline: %v", err)
+	}
+	if _, err := c.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Errorf("Get past the deadline = %v, want a miss", err)
+	}
+}
+
+func TestGetIgnoresExpiredEnvelope(t *testing.T) {
+	ctx := context.Background()
+	c, l1, _ := newTiered[string](t)
+
+	// The backend still holds the value, but its envelope has expired.
+	past := Envelope[string]{Value: "old", ExpiresAt: time.Now().Add(-time.Second)}
+	if err := c.l2.Set(ctx, "k", past, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "k"); !base.IsCacheMiss(err) {
+		t.Fatalf("Get = %v, want a miss", err)
+	}
+	if ok, _ := l1.Exists(ctx, "k"); ok {
+		t.Error("expired envelope promoted to L1")
+	}
+}
+
+func TestDeleteByPatternClearsBothTiers(t *testing.T) {
+	ctx := context.Background()
+	c, l1, l2 := newTiered[int](t)
+
+	for _, k := range []string{"user:1:session:a", "user:1:profile"} {
+		if err := c.Set(ctx, k, 1, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := c.DeleteByPattern(ctx, "user:*:session:*")
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteByPattern = %d, %v; want 1", n, err)
+	}
+	for name, tr := range map[string]tier[int]{"L1": l1, "L2": l2} {
+		if ok, _ := tr.Exists(ctx, "user:1:session:a"); ok {
+			t.Errorf("%s still holds the matching key", name)
+		}
+		if ok, _ := tr.Exists(ctx, "user:1:profile"); !ok {
+			t.Errorf("%s lost the non-matching key", name)
+		}
+	}
+}
+
+func TestCountByPrefixCountsL2(t *testing.T) {
+	ctx := context.Background()
+	c, l1, l2 := newTiered[int](t)
+
+	_ = c.Set(ctx, "user:1", 1, time.Minute)
+	// Present only in L2, as when written by another node.
+	_ = l2.Set(ctx, "user:2", Envelope[int]{Value: 2}, time.Minute)
+	_ = l1.Set(ctx, "user:3", Envelope[int]{Value: 3}, time.Minute)
+
+	if n, err := c.CountByPrefix(ctx, "user:"); err != nil || n != 2 {
+		t.Errorf("CountByPrefix = %d, %v; want the 2 keys in L2", n, err)
+	}
+}
@@ -0,0 +1,335 @@
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/internal/metrics"
+)
+
+const (
+	defaultInterval = time.Second
+	defaultBuffer   = 1000
+)
+
+/* ------------------ Types ------------------ */
+
+type pending[T any] struct {
+	value     T
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+func (p pending[T]) expired(now time.Time) bool {
+	return !p.expiresAt.IsZero() && !now.Before(p.expiresAt)
+}
+
+type writeBehindCache[T any] struct {
+	base *base.Base
+	next interfaces.Cache[T]
+
+	interval time.Duration
+	capacity int
+	drop     bool
+
+	mu       sync.Mutex
+	buf      map[string]pending[T]
+	flushing map[string]pending[T] // batch being written, still readable
+	flushed  chan struct{}         // closed after each flush
+	dropped  atomic.Int64
+
+	flushMu sync.Mutex // serializes flushes with Delete and Clear
+
+	kick      chan struct{}
+	stopCh    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+/* ------------------ Constructor ------------------ */
+
+// New wraps next so that Set only buffers the write; a background worker
+// flushes the buffer to next in batches. Reads see buffered values. Close
+// flushes whatever is pending before closing next.
+func New[T any](next interfaces.Cache[T], cfg config.Config) (*writeBehindCache[T], error) {
+	if next == nil {
+		return nil, base.WrapError(base.OpInit, base.ErrInvalidConfig, "")
+	}
+
+	w := &writeBehindCache[T]{
+		base:     base.NewBase(cfg),
+		next:     next,
+		interval: cfg.WriteBehindInterval,
+		capacity: cfg.WriteBehindBuffer,
+		drop:     cfg.WriteBehindDrop,
+		buf:      make(map[string]pending[T]),
+		flushed:  make(chan struct{}),
+		kick:     make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if w.interval <= 0 {
+		w.interval = defaultInterval
+	}
+	if w.capacity <= 0 {
+		w.capacity = defaultBuffer
+	}
+
+	go w.loop()
+	return w, nil
+}
+
+/* ------------------ Flushing ------------------ */
+
+func (w *writeBehindCache[T]) loop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+		_ = w.Flush(context.Background())
+	}
+}
+
+func (w *writeBehindCache[T]) wake() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Flush writes every buffered entry to the backend, batching entries that
+// share a TTL. Entries are written with the TTL they were set with, so a
+// flush delay adds at most one interval to their lifetime. Failed writes
+// are counted as errors and not retried.
+func (w *writeBehindCache[T]) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	batch := w.buf
+	w.buf = make(map[string]pending[T])
+	w.flushing = batch
+	w.mu.Unlock()
+
+	err := w.write(ctx, batch)
+
+	w.mu.Lock()
+	w.flushing = nil
+	close(w.flushed)
+	w.flushed = make(chan struct{})
+	w.mu.Unlock()
+
+	if err != nil {
+		w.base.RecordError("write_behind_flush")
+	}
+	return err
+}
+
+func (w *writeBehindCache[T]) write(ctx context.Context, batch map[string]pending[T]) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	groups := make(map[time.Duration]map[string]T)
+	for k, p := range batch {
+		if p.expired(now) {
+			continue
+		}
+		g := groups[p.ttl]
+		if g == nil {
+			g = make(map[string]T)
+			groups[p.ttl] = g
+		}
+		g[k] = p.value
+	}
+
+	var errs []error
+	ps, bulk := w.next.(interfaces.PipelineSetter[T])
+	for ttl, items := range groups {
+		if bulk {
+			errs = append(errs, ps.SetManyPipeline(ctx, items, ttl))
+			continue
+		}
+		for k, v := range items {
+			errs = append(errs, w.next.Set(ctx, k, v, ttl))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookup returns the buffered entry for key, including one being flushed.
+func (w *writeBehindCache[T]) lookup(key string) (pending[T], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if p, ok := w.buf[key]; ok {
+		return p, true
+	}
+	p, ok := w.flushing[key]
+	return p, ok
+}
+
+/* ------------------ Cache API ------------------ */
+
+func (w *writeBehindCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+
+	if err := w.base.ValidateKey(key); err != nil {
+		return zero, err
+	}
+	if err := w.base.CheckContext(ctx); err != nil {
+		return zero, err
+	}
+
+	if p, ok := w.lookup(key); ok {
+		if p.expired(time.Now()) {
+			return zero, base.WrapError(base.OpGet, base.ErrCacheMiss, key)
+		}
+		return p.value, nil
+	}
+	return w.next.Get(ctx, key)
+}
+
+// Set buffers the write. When the buffer is full it triggers a flush and
+// waits for it, or drops the write (counted in Stats.Dropped) when the
+// cache is configured to drop.
+func (w *writeBehindCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := w.base.ValidateKey(key); err != nil {
+		return err
+	}
+
+	ttl = w.base.ResolveTTL(ttl)
+	p := pending[T]{value: value, ttl: ttl}
+	if ttl > 0 {
+		p.expiresAt = time.Now().Add(ttl)
+	}
+
+	for {
+		if err := w.base.CheckContext(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-w.stopCh:
+			return base.WrapError(base.OpSet, base.ErrClosed, key)
+		default:
+		}
+
+		w.mu.Lock()
+		if _, ok := w.buf[key]; ok || len(w.buf) < w.capacity {
+			w.buf[key] = p
+			full := len(w.buf) >= w.capacity
+			w.mu.Unlock()
+			if full {
+				w.wake()
+			}
+			return nil
+		}
+		if w.drop {
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			w.base.RecordError("write_behind_drop")
+			w.wake()
+			return nil
+		}
+		flushed := w.flushed
+		w.mu.Unlock()
+
+		w.wake()
+		select {
+		case <-flushed:
+		case <-w.stopCh:
+			return base.WrapError(base.OpSet, base.ErrClosed, key)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *writeBehindCache[T]) Delete(ctx context.Context, keys ...string) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	for _, k := range keys {
+		delete(w.buf, k)
+	}
+	w.mu.Unlock()
+
+	return w.next.Delete(ctx, keys...)
+}
+
+func (w *writeBehindCache[T]) Exists(ctx context.Context, key string) (bool, error) {
+	if p, ok := w.lookup(key); ok {
+		return !p.expired(time.Now()), nil
+	}
+	return w.next.Exists(ctx, key)
+}
+
+// Clear drops pending writes and clears the backend.
+func (w *writeBehindCache[T]) Clear(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	w.buf = make(map[string]pending[T])
+	w.mu.Unlock()
+
+	return w.next.Clear(ctx)
+}
+
+// Len flushes pending writes so the backend count is exact.
+func (w *writeBehindCache[T]) Len(ctx context.Context) (int, error) {
+	if err := w.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return w.next.Len(ctx)
+}
+
+func (w *writeBehindCache[T]) Ping(ctx context.Context) error {
+	return w.next.Ping(ctx)
+}
+
+// Close stops the worker, flushes pending writes and closes the backend.
+func (w *writeBehindCache[T]) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stopCh)
+		<-w.done
+		w.closeErr = errors.Join(w.Flush(context.Background()), w.next.Close())
+	})
+	return w.closeErr
+}
+
+/* ------------------ Stats ------------------ */
+
+func (w *writeBehindCache[T]) Stats(ctx context.Context) metrics.CacheStats {
+	stats := metrics.NewStatsBuilder("write_behind").
+		WithUptime(w.base.Uptime()).
+		WithStartedAt(w.base.StartedAt()).
+		Build()
+
+	if sp, ok := w.next.(interfaces.StatProvider); ok {
+		stats = sp.Stats(ctx)
+	}
+
+	w.mu.Lock()
+	stats.Pending = int64(len(w.buf) + len(w.flushing))
+	w.mu.Unlock()
+	stats.Dropped = w.dropped.Load()
+	return stats
+}
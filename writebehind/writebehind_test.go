@@ -0,0 +1,251 @@
+package writebehind
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/os-golib/go-cache/config"
+	"github.com/os-golib/go-cache/internal/base"
+	"github.com/os-golib/go-cache/internal/interfaces"
+	"github.com/os-golib/go-cache/memory"
+)
+
+// recordingCache is a memory backend that records bulk write sizes and
+// stays readable after Close, so tests can inspect what was flushed.
+type recordingCache struct {
+	interfaces.Cache[int]
+
+	mu      sync.Mutex
+	batches []int
+	closed  bool
+}
+
+func (r *recordingCache) SetManyPipeline(ctx context.Context, items map[string]int, ttl time.Duration) error {
+	r.mu.Lock()
+	r.batches = append(r.batches, len(items))
+	r.mu.Unlock()
+	return r.Cache.(interfaces.PipelineSetter[int]).SetManyPipeline(ctx, items, ttl)
+}
+
+func (r *recordingCache) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recordingCache) stored(t *testing.T, key string) (int, bool) {
+	t.Helper()
+	v, err := r.Cache.Get(context.Background(), key)
+	if err != nil && !base.IsCacheMiss(err) {
+		t.Fatal(err)
+	}
+	return v, err == nil
+}
+
+func testConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CleanupInterval = 0
+	cfg.WriteBehindInterval = time.Hour
+	return cfg
+}
+
+func newTestCache(t *testing.T, cfg config.Config) (*writeBehindCache[int], *recordingCache) {
+	t.Helper()
+	m, err := memory.NewMemory[int](cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+
+	next := &recordingCache{Cache: m}
+	w, err := New[int](next, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w, next
+}
+
+/* ------------------ Flushing ------------------ */
+
+func TestSetBuffersUntilFlush(t *testing.T) {
+	ctx := context.Background()
+	w, next := newTestCache(t, testConfig())
+
+	for i, k := range []string{"a", "b", "c"} {
+		if err := w.Set(ctx, k, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := next.stored(t, "a"); ok {
+		t.Fatal("write reached the backend before a flush")
+	}
+	// Reads see buffered values.
+	if v, err := w.Get(ctx, "b"); err != nil || v != 1 {
+		t.Errorf("Get(b) = %d, %v", v, err)
+	}
+	if ok, _ := w.Exists(ctx, "c"); !ok {
+		t.Error("Exists(c) = false for a buffered write")
+	}
+	if s := w.Stats(ctx); s.Pending != 3 {
+		t.Errorf("Pending = %d, want 3", s.Pending)
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i, k := range []string{"a", "b", "c"} {
+		if v, ok := next.stored(t, k); !ok || v != i {
+			t.Errorf("backend %s = %d, %v after flush", k, v, ok)
+		}
+	}
+	if len(next.batches) != 1 || next.batches[0] != 3 {
+		t.Errorf("batches = %v, want one batch of 3", next.batches)
+	}
+	if s := w.Stats(ctx); s.Pending != 0 {
+		t.Errorf("Pending = %d after flush", s.Pending)
+	}
+}
+
+func TestBackgroundFlushAfterInterval(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.WriteBehindInterval = 10 * time.Millisecond
+	w, next := newTestCache(t, cfg)
+
+	_ = w.Set(ctx, "a", 1, time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := next.stored(t, "a"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("write never flushed by the worker")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCloseFlushesPendingWrites(t *testing.T) {
+	ctx := context.Background()
+	w, next := newTestCache(t, testConfig())
+
+	_ = w.Set(ctx, "a", 1, time.Minute)
+	_ = w.Set(ctx, "b", 2, time.Minute)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, want := range map[string]int{"a": 1, "b": 2} {
+		if v, ok := next.stored(t, k); !ok || v != want {
+			t.Errorf("backend %s = %d, %v after Close", k, v, ok)
+		}
+	}
+	if !next.closed {
+		t.Error("Close did not close the backend")
+	}
+	if err := w.Set(ctx, "c", 3, time.Minute); !errors.Is(err, base.ErrClosed) {
+		t.Errorf("Set after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestFlushSkipsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	w, next := newTestCache(t, testConfig())
+
+	_ = w.Set(ctx, "short", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := w.Get(ctx, "short"); !base.IsCacheMiss(err) {
+		t.Errorf("Get of an expired buffered write = %v, want a miss", err)
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := next.stored(t, "short"); ok {
+		t.Error("expired write flushed to the backend")
+	}
+}
+
+func TestDeleteDropsBufferedWrite(t *testing.T) {
+	ctx := context.Background()
+	w, next := newTestCache(t, testConfig())
+
+	_ = w.Set(ctx, "a", 1, time.Minute)
+	if err := w.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Flush(ctx)
+	if _, ok := next.stored(t, "a"); ok {
+		t.Error("deleted write flushed to the backend")
+	}
+}
+
+/* ------------------ Backpressure ------------------ */
+
+func TestFullBufferDropsWrites(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.WriteBehindBuffer = 2
+	cfg.WriteBehindDrop = true
+	w, _ := newTestCache(t, cfg)
+
+	// Hold the flush so the buffer stays full.
+	w.flushMu.Lock()
+	_ = w.Set(ctx, "a", 1, time.Minute)
+	_ = w.Set(ctx, "b", 2, time.Minute)
+	if err := w.Set(ctx, "c", 3, time.Minute); err != nil {
+		t.Fatalf("dropped Set = %v, want nil", err)
+	}
+	// Overwriting a buffered key still fits.
+	if err := w.Set(ctx, "a", 10, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	w.flushMu.Unlock()
+
+	if s := w.Stats(ctx); s.Dropped != 1 {
+		t.Errorf("Dropped = %d, want 1", s.Dropped)
+	}
+	if _, err := w.Get(ctx, "c"); !base.IsCacheMiss(err) {
+		t.Errorf("Get(c) = %v, want the dropped write to be missing", err)
+	}
+	if v, _ := w.Get(ctx, "a"); v != 10 {
+		t.Errorf("Get(a) = %d, want 10", v)
+	}
+}
+
+func TestFullBufferBlocksUntilFlushed(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+	cfg.WriteBehindBuffer = 2
+	w, next := newTestCache(t, cfg)
+
+	_ = w.Set(ctx, "a", 1, time.Minute)
+	_ = w.Set(ctx, "b", 2, time.Minute)
+
+	// The full buffer wakes the worker; the third Set waits for its flush.
+	if err := w.Set(ctx, "c", 3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "b"} {
+		if _, ok := next.stored(t, k); !ok {
+			t.Errorf("%s not flushed before the blocked Set returned", k)
+		}
+	}
+	if v, err := w.Get(ctx, "c"); err != nil || v != 3 {
+		t.Errorf("Get(c) = %d, %v", v, err)
+	}
+
+	// A blocked Set gives up with its context.
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	_ = w.Set(ctx, "d", 4, time.Minute)
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := w.Set(short, "e", 5, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("blocked Set = %v, want DeadlineExceeded", err)
+	}
+}